@@ -0,0 +1,120 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// supported access log formats
+const (
+	accessLogCommon   = "common"
+	accessLogCombined = "combined"
+	accessLogJSON     = "json"
+)
+
+var (
+	accessLogFormats = []string{accessLogCommon, accessLogCombined, accessLogJSON}
+
+	// access log lines are written here, separate from other logs
+	accessLogWriter io.Writer = os.Stdout
+)
+
+// accessLogEntry is a single request logged in the json format
+type accessLogEntry struct {
+	Time      string  `json:"time"`
+	Remote    string  `json:"remote"`
+	User      string  `json:"user"`
+	Method    string  `json:"method"`
+	URI       string  `json:"uri"`
+	Protocol  string  `json:"protocol"`
+	Filter    string  `json:"filter"`
+	Status    int     `json:"status"`
+	Bytes     int     `json:"bytes"`
+	Latency   float64 `json:"latency"`
+	Referer   string  `json:"referer"`
+	UserAgent string  `json:"userAgent"`
+}
+
+// dashIfEmpty returns "-" for empty strings, it's used for missing fields in
+// common and combined log formats
+func dashIfEmpty(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// formatAccessLog returns the access log line for the request using given
+// format, common and combined formats have the request duration in seconds
+// appended to every line
+func formatAccessLog(format string, c *gin.Context, start time.Time, latency time.Duration) string {
+	size := c.Writer.Size()
+	if size < 0 {
+		size = 0
+	}
+
+	switch format {
+	case accessLogJSON:
+		entry := accessLogEntry{
+			Time:      start.Format(time.RFC3339Nano),
+			Remote:    c.ClientIP(),
+			User:      getUserName(c),
+			Method:    c.Request.Method,
+			URI:       c.Request.RequestURI,
+			Protocol:  c.Request.Proto,
+			Filter:    c.Query("q"),
+			Status:    c.Writer.Status(),
+			Bytes:     size,
+			Latency:   latency.Seconds(),
+			Referer:   c.Request.Referer(),
+			UserAgent: c.Request.UserAgent(),
+		}
+		line, err := json.Marshal(entry)
+		if err != nil {
+			log.Errorf("Failed to encode access log entry: %s", err)
+			return ""
+		}
+		return string(line)
+	default:
+		bytes := "-"
+		if size > 0 {
+			bytes = fmt.Sprintf("%d", size)
+		}
+		line := fmt.Sprintf("%s - %s [%s] %q %d %s",
+			c.ClientIP(),
+			dashIfEmpty(getUserName(c)),
+			start.Format("02/Jan/2006:15:04:05 -0700"),
+			fmt.Sprintf("%s %s %s", c.Request.Method, c.Request.RequestURI, c.Request.Proto),
+			c.Writer.Status(),
+			bytes,
+		)
+		if format == accessLogCombined {
+			line += fmt.Sprintf(" %q %q", dashIfEmpty(c.Request.Referer()), dashIfEmpty(c.Request.UserAgent()))
+		}
+		return fmt.Sprintf("%s %.6f", line, latency.Seconds())
+	}
+}
+
+// accessLog returns a middleware that will write a line to the access log
+// for every request once it's completed, it doesn't do anything if format is
+// empty
+func accessLog(format string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if format == "" {
+			c.Next()
+			return
+		}
+		start := time.Now()
+		c.Next()
+		if line := formatAccessLog(format, c, start, time.Since(start)); line != "" {
+			fmt.Fprintln(accessLogWriter, line)
+		}
+	}
+}
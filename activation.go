@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// first file descriptor passed by systemd, see sd_listen_fds(3)
+	listenFdsStart = 3
+	// sockets with this FileDescriptorName are used for the gRPC API, all
+	// other sockets are used for HTTP requests
+	grpcSocketName = "grpc"
+)
+
+// activatedListeners holds sockets passed by systemd socket activation
+type activatedListeners struct {
+	http net.Listener
+	grpc net.Listener
+}
+
+// getActivatedListeners returns sockets passed to this process by systemd,
+// both listeners will be nil if the process wasn't socket activated,
+// environment variables used to pass sockets are cleared so that they are not
+// inherited by child processes
+func getActivatedListeners(fdsStart int) (activatedListeners, error) {
+	listeners := activatedListeners{}
+
+	pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		return listeners, nil
+	}
+	fds, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || fds <= 0 {
+		return listeners, nil
+	}
+	names := strings.Split(os.Getenv("LISTEN_FDNAMES"), ":")
+	for _, env := range []string{"LISTEN_PID", "LISTEN_FDS", "LISTEN_FDNAMES"} {
+		os.Unsetenv(env)
+	}
+
+	for i := 0; i < fds; i++ {
+		fd := fdsStart + i
+		name := ""
+		if i < len(names) {
+			name = names[i]
+		}
+		syscall.CloseOnExec(fd)
+		file := os.NewFile(uintptr(fd), name)
+		listener, err := net.FileListener(file)
+		file.Close()
+		if err != nil {
+			return listeners, fmt.Errorf("Socket %d passed by systemd isn't a listening socket: %s", fd, err)
+		}
+
+		switch {
+		case name == grpcSocketName && listeners.grpc == nil:
+			listeners.grpc = listener
+		case name != grpcSocketName && listeners.http == nil:
+			listeners.http = listener
+		default:
+			log.Warningf("Ignoring extra socket '%s' passed by systemd", name)
+			listener.Close()
+		}
+	}
+	return listeners, nil
+}
@@ -1,13 +1,82 @@
 package main
 
 import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/cloudflare/unsee/internal/alertmanager"
+	"github.com/cloudflare/unsee/internal/config"
+	"github.com/cloudflare/unsee/internal/counts"
 	"github.com/cloudflare/unsee/internal/filters"
 	"github.com/cloudflare/unsee/internal/models"
+	"github.com/cloudflare/unsee/internal/rules"
+	"github.com/cloudflare/unsee/internal/slices"
 )
 
+// alerts are counted by the value of this label in summary responses
+const summarySeverityLabel = "severity"
+
+// topLabelValuesLimit is the number of values returned by the top label
+// values endpoint if limit isn't set
+const topLabelValuesLimit = 10
+
+// filter macros are referenced in filters using $name syntax
+var filterMacroNameRegex = regexp.MustCompile("^[a-zA-Z_][a-zA-Z0-9_]*$")
+
+// getFilterMacros parses filter macros from the config, each macro uses
+// name:filter format
+func getFilterMacros() (map[string]string, error) {
+	macros := map[string]string{}
+	for _, s := range config.Config.FilterMacros {
+		if s == "" {
+			continue
+		}
+		z := strings.SplitN(s, ":", 2)
+		if len(z) != 2 || z[1] == "" {
+			return nil, fmt.Errorf("invalid filter macro '%s', expected format 'name:filter'", s)
+		}
+		if !filterMacroNameRegex.MatchString(z[0]) {
+			return nil, fmt.Errorf("invalid filter macro name '%s'", z[0])
+		}
+		matchFilters, _ := getFiltersFromQuery(z[1])
+		for _, filter := range matchFilters {
+			if !filter.GetIsValid() {
+				return nil, fmt.Errorf("invalid filter macro '%s': invalid filter expression '%s'", s, filter.GetRawText())
+			}
+		}
+		macros[z[0]] = z[1]
+	}
+	return macros, nil
+}
+
+// expandFilterMacros replaces every $name filter with the filter defined by
+// the macro, it returns the expanded query and a map of all expanded macros
+// (macro name -> filter), references to unknown macros are left untouched
+func expandFilterMacros(q string) (string, map[string]string) {
+	expanded := map[string]string{}
+	macros, err := getFilterMacros()
+	if err != nil || len(macros) == 0 || q == "" {
+		return q, expanded
+	}
+
+	qList := []string{}
+	for _, filterExpression := range strings.Split(q, ",") {
+		if strings.HasPrefix(filterExpression, "$") {
+			name := strings.TrimPrefix(filterExpression, "$")
+			if macro, found := macros[name]; found {
+				expanded[name] = macro
+				filterExpression = macro
+			}
+		}
+		qList = append(qList, filterExpression)
+	}
+	return strings.Join(qList, ","), expanded
+}
+
 func getFiltersFromQuery(filterString string) ([]filters.FilterT, bool) {
 	validFilters := false
 	matchFilters := []filters.FilterT{}
@@ -22,26 +91,106 @@ func getFiltersFromQuery(filterString string) ([]filters.FilterT, bool) {
 	return matchFilters, validFilters
 }
 
+// apiFilter returns the filter as it's included in API responses, filters
+// that are invalid or will never match any alert, like regex filters with an
+// invalid regex, include the reason
+func apiFilter(filter filters.FilterT) models.Filter {
+	return models.Filter{
+		Text:    filter.GetRawText(),
+		Hits:    filter.GetHits(),
+		IsValid: filter.GetIsValid(),
+		Error:   filters.Validate(filter.GetRawText()),
+	}
+}
+
+// alertMatchesFilters returns true if there are no valid filters or the alert
+// matches all valid filters
+func alertMatchesFilters(alert *models.Alert, matchFilters []filters.FilterT, validFilters bool, matches int) bool {
+	if !validFilters {
+		return true
+	}
+	results := []bool{}
+	for _, filter := range matchFilters {
+		if filter.GetIsValid() {
+			match := filter.Match(alert, matches)
+			results = append(results, match)
+		}
+	}
+	return slices.BoolInSlice(results, true) && !slices.BoolInSlice(results, false)
+}
+
+// filterAlertGroups returns copies of alert groups with only alerts matching
+// the filter query, groups without any matching alert are skipped, it stops
+// and returns the context error if ctx is cancelled
+func filterAlertGroups(ctx context.Context, groups []models.AlertGroup, q string) ([]models.AlertGroup, error) {
+	matchFilters, validFilters := getFiltersFromQuery(q)
+
+	filtered := []models.AlertGroup{}
+	var matches int
+	for _, ag := range groups {
+		for _, filter := range matchFilters {
+			if gf, ok := filter.(filters.GroupFilterT); ok {
+				gf.SetGroup(&ag)
+			}
+		}
+
+		agCopy := ag
+		agCopy.Alerts = models.AlertList{}
+		agCopy.StateCount = map[string]int{}
+		for _, state := range models.AlertStateList {
+			agCopy.StateCount[state] = 0
+		}
+		for _, alert := range ag.Alerts {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			if alertMatchesFilters(&alert, matchFilters, validFilters, matches) {
+				matches++
+				alert.UpdateFingerprints()
+				agCopy.Alerts = append(agCopy.Alerts, alert)
+				agCopy.StateCount[alert.State]++
+			}
+		}
+		if len(agCopy.Alerts) > 0 {
+			agCopy.Hash = agCopy.ContentFingerprint()
+			filtered = append(filtered, agCopy)
+		}
+	}
+
+	for _, filter := range matchFilters {
+		if lf, ok := filter.(filters.GroupLimitFilterT); ok && filter.GetIsValid() {
+			filtered = lf.LimitGroups(filtered)
+		}
+	}
+	return filtered, nil
+}
+
 func countLabel(countStore models.LabelsCountMap, key string, val string) {
 	if _, found := countStore[key]; !found {
 		countStore[key] = make(map[string]int)
 	}
-	if _, found := countStore[key][val]; found {
-		countStore[key][val]++
-	} else {
-		countStore[key][val] = 1
-	}
+	countStore[key][val]++
 }
 
 func getUpstreams() models.AlertmanagerAPISummary {
 	summary := models.AlertmanagerAPISummary{}
 
+	now := time.Now()
 	upstreams := alertmanager.GetAlertmanagers()
 	for _, upstream := range upstreams {
+		lastRefresh := upstream.LastCollected()
 		u := models.AlertmanagerAPIStatus{
-			Name:  upstream.Name,
-			URI:   upstream.URI,
-			Error: upstream.Error(),
+			Name:        upstream.Name,
+			URI:         upstream.URI,
+			Error:       upstream.Error(),
+			Stale:       upstream.IsStale() || (!lastRefresh.IsZero() && now.Sub(lastRefresh) > maxDataAge()),
+			LastRefresh: lastRefresh,
+			ClockSkewed: upstream.IsClockSkewed(),
+			Paused:      upstream.IsPaused(),
+		}
+		if skew, known := upstream.ClockSkew(); known {
+			seconds := skew.Seconds()
+			u.ClockSkew = &seconds
 		}
 		summary.Instances = append(summary.Instances, u)
 
@@ -55,3 +204,573 @@ func getUpstreams() models.AlertmanagerAPISummary {
 
 	return summary
 }
+
+// getAlertGroupDetails returns details of given alert group, annotations with
+// the same value on all alerts are moved to shared annotations
+func getAlertGroupDetails(ag models.AlertGroup) models.AlertGroupDetails {
+	details := models.AlertGroupDetails{
+		Silences:      map[string]models.Silence{},
+		Alertmanagers: []models.AlertmanagerAPIStatus{},
+	}
+
+	upstreamNames := []string{}
+	for _, alert := range ag.Alerts {
+		for _, am := range alert.Alertmanager {
+			if !slices.StringInSlice(upstreamNames, am.Name) {
+				upstreamNames = append(upstreamNames, am.Name)
+			}
+			for id, silence := range am.Silences {
+				details.Silences[id] = silence
+			}
+		}
+	}
+
+	// SplitAnnotations returns copies, so we don't modify the group passed to us
+	shared, alerts := ag.SplitAnnotations()
+	for i := range alerts {
+		alerts[i].Timeline = alertTimeline.Transitions(&alerts[i])
+		alerts[i].Rule = rules.Lookup(&alerts[i])
+	}
+	details.AlertGroup = ag
+	details.Alerts = alerts
+	details.SharedAnnotations = shared
+
+	for _, upstream := range getUpstreams().Instances {
+		if slices.StringInSlice(upstreamNames, upstream.Name) {
+			details.Alertmanagers = append(details.Alertmanagers, upstream)
+		}
+	}
+
+	return details
+}
+
+// clusterSilences holds silences collected from every member of an
+// Alertmanager cluster, keyed by the member name
+type clusterSilences struct {
+	name     string
+	members  []string
+	silences map[string]map[string]models.Silence
+}
+
+// getClusterSilences returns silences of all clusters from
+// ALERTMANAGER_CLUSTERS, sorted by the cluster name, members that failed the
+// last collection are skipped as their silences aren't known
+func getClusterSilences() []clusterSilences {
+	upstreamClusters, _ := getUpstreamClusters()
+	clusters := []clusterSilences{}
+	for name, members := range upstreamClusters {
+		cs := clusterSilences{name: name, members: []string{}, silences: map[string]map[string]models.Silence{}}
+		for _, member := range members {
+			am := alertmanager.GetAlertmanagerByName(member)
+			if am == nil || am.Error() != "" || am.IsStale() || am.LastCollected().IsZero() {
+				continue
+			}
+			cs.members = append(cs.members, member)
+			cs.silences[member] = am.Silences()
+		}
+		sort.Strings(cs.members)
+		if len(cs.members) > 1 {
+			clusters = append(clusters, cs)
+		}
+	}
+	sort.Slice(clusters, func(i, j int) bool {
+		return clusters[i].name < clusters[j].name
+	})
+	return clusters
+}
+
+// getSilenceConflicts returns inconsistencies of the silence between members of
+// every cluster, a silence is missing on a member if any other member has it
+// and it's not expired everywhere, it has a conflicting end time if members
+// have different endsAt, those ending earlier are listed
+func getSilenceConflicts(id string, clusters []clusterSilences, now time.Time) []models.SilenceConflict {
+	conflicts := []models.SilenceConflict{}
+	for _, cs := range clusters {
+		missing := []string{}
+		copies := map[string]models.Silence{}
+		expired := true
+		for _, member := range cs.members {
+			silence, found := cs.silences[member][id]
+			if !found {
+				missing = append(missing, member)
+				continue
+			}
+			copies[member] = silence
+			if silence.StateAt(now) != models.SilenceStateExpired {
+				expired = false
+			}
+		}
+		if len(copies) == 0 || expired {
+			continue
+		}
+		if len(missing) > 0 {
+			conflicts = append(conflicts, models.SilenceConflict{Cluster: cs.name, Type: models.SilenceConflictMissing, Alertmanagers: missing})
+			continue
+		}
+
+		var endsAt time.Time
+		for _, silence := range copies {
+			if silence.EndsAt.After(endsAt) {
+				endsAt = silence.EndsAt
+			}
+		}
+		earlier := []string{}
+		for _, member := range cs.members {
+			if copies[member].EndsAt.Before(endsAt) {
+				earlier = append(earlier, member)
+			}
+		}
+		if len(earlier) > 0 {
+			conflicts = append(conflicts, models.SilenceConflict{Cluster: cs.name, Type: models.SilenceConflictEndsAt, Alertmanagers: earlier})
+		}
+	}
+	return conflicts
+}
+
+// recreateSilence returns the payload creating an expired silence again, it
+// starts now and lasts as long as the original silence did, silences expired
+// before they started use SILENCE_DEFAULT_DURATION or last an hour if it's
+// not set
+func recreateSilence(silence models.Silence, now time.Time) *models.SilencePayload {
+	duration := silence.EndsAt.Sub(silence.StartsAt)
+	if duration <= 0 {
+		duration = config.Config.SilenceDefaultDuration
+	}
+	if duration <= 0 {
+		duration = time.Hour
+	}
+	start := now.UTC().Truncate(time.Second)
+	return &models.SilencePayload{
+		Matchers:  silence.Matchers,
+		StartsAt:  start,
+		EndsAt:    start.Add(duration),
+		CreatedBy: silence.CreatedBy,
+		Comment:   silence.Comment,
+	}
+}
+
+// getSilences returns all silences collected from Alertmanager upstreams, with
+// the number of alerts each one is muting, author and state must match if
+// not empty, comment is a case insensitive substring match, restricted
+// tenants will only get silences muting alerts they can see
+func getSilences(t tenant, author, comment, state string, now time.Time) []models.ManagedSilence {
+	alertCount := map[string]int{}
+	for _, ag := range t.alertGroups() {
+		for _, alert := range ag.Alerts {
+			silenceIDs := map[string]bool{}
+			for _, am := range alert.Alertmanager {
+				for id := range am.Silences {
+					silenceIDs[id] = true
+				}
+			}
+			for id := range silenceIDs {
+				alertCount[id]++
+			}
+		}
+	}
+
+	clusters := getClusterSilences()
+	silences := []models.ManagedSilence{}
+	for _, silence := range alertmanager.DedupSilences() {
+		silence.State = silence.StateAt(now)
+		silence.AlertCount = alertCount[silence.ID]
+		silence.Conflicts = getSilenceConflicts(silence.ID, clusters, now)
+		if t.restricted && silence.AlertCount == 0 {
+			continue
+		}
+		if author != "" && !strings.EqualFold(silence.CreatedBy, author) {
+			continue
+		}
+		if comment != "" && !strings.Contains(strings.ToLower(silence.Comment), strings.ToLower(comment)) {
+			continue
+		}
+		if state != "" && silence.State != state {
+			continue
+		}
+		if silence.State == models.SilenceStateExpired {
+			if config.Config.SilenceExpiredRetention > 0 && now.Sub(silence.EndsAt) > config.Config.SilenceExpiredRetention {
+				continue
+			}
+			silence.Recreate = recreateSilence(silence.Silence, now)
+		}
+		silences = append(silences, silence)
+	}
+	return silences
+}
+
+// getSilenceAuthors returns a sorted list of silence authors, authors of all
+// silences seen since startup are returned unless the tenant is restricted,
+// restricted tenants only get authors of silences they can see
+func getSilenceAuthors(t tenant, now time.Time) []string {
+	if !t.restricted {
+		return alertmanager.SilenceAuthors()
+	}
+	seen := map[string]bool{}
+	authors := []string{}
+	for _, silence := range getSilences(t, "", "", "", now) {
+		if silence.CreatedBy != "" && !seen[silence.CreatedBy] {
+			seen[silence.CreatedBy] = true
+			authors = append(authors, silence.CreatedBy)
+		}
+	}
+	sort.Strings(authors)
+	return authors
+}
+
+// countAlerts returns the number of all alerts, counted by state and severity,
+// it's recorded after every collection for the history endpoint
+func countAlerts(groups []models.AlertGroup, now time.Time) counts.Sample {
+	sample := counts.Sample{
+		Timestamp:  now,
+		States:     map[string]int{},
+		Severities: map[string]int{},
+	}
+	for _, ag := range groups {
+		for _, alert := range ag.Alerts {
+			sample.Total++
+			sample.States[alert.State]++
+			if severity, found := alert.Labels[summarySeverityLabel]; found {
+				sample.Severities[severity]++
+			}
+		}
+	}
+	return sample
+}
+
+// getAlertsSummary returns the number of alerts the tenant can see matching
+// the query, counted by state, severity and Alertmanager upstream
+func getAlertsSummary(t tenant, q string, now time.Time) models.AlertsSummary {
+	ts, _ := now.UTC().MarshalText()
+	summary := models.AlertsSummary{
+		Timestamp:  string(ts),
+		States:     map[string]int{},
+		Severities: map[string]int{},
+		Upstreams:  map[string]int{},
+		Filters:    []models.Filter{},
+	}
+	for _, state := range models.AlertStateList {
+		summary.States[state] = 0
+	}
+	for _, upstream := range alertmanager.GetAlertmanagers() {
+		summary.Upstreams[upstream.Name] = 0
+	}
+
+	matchFilters, validFilters := getFiltersFromQuery(q)
+	for _, ag := range t.alertGroups() {
+		for _, filter := range matchFilters {
+			if gf, ok := filter.(filters.GroupFilterT); ok {
+				gf.SetGroup(&ag)
+			}
+		}
+		groupMatched := false
+		for _, alert := range ag.Alerts {
+			if !alertMatchesFilters(&alert, matchFilters, validFilters, summary.Total) {
+				continue
+			}
+			groupMatched = true
+			summary.Total++
+			summary.States[alert.State]++
+			if severity, found := alert.Labels[summarySeverityLabel]; found {
+				summary.Severities[severity]++
+			}
+			for _, am := range alert.Alertmanager {
+				summary.Upstreams[am.Name]++
+			}
+		}
+		if groupMatched {
+			summary.Groups++
+		}
+	}
+
+	if q != "" {
+		for _, filter := range matchFilters {
+			summary.Filters = append(summary.Filters, apiFilter(filter))
+		}
+	}
+	return summary
+}
+
+// getTopLabelValues returns the most frequent values of given label across
+// alerts matching the query, with no more than limit values
+func getTopLabelValues(t tenant, label string, q string, limit int, now time.Time) models.TopLabelValues {
+	ts, _ := now.UTC().MarshalText()
+	top := models.TopLabelValues{
+		Timestamp: string(ts),
+		Label:     label,
+		Values:    []models.LabelValueCount{},
+		Filters:   []models.Filter{},
+	}
+
+	counts := map[string]int{}
+	matched := 0
+	matchFilters, validFilters := getFiltersFromQuery(q)
+	for _, ag := range t.alertGroups() {
+		for _, filter := range matchFilters {
+			if gf, ok := filter.(filters.GroupFilterT); ok {
+				gf.SetGroup(&ag)
+			}
+		}
+		for _, alert := range ag.Alerts {
+			if !alertMatchesFilters(&alert, matchFilters, validFilters, matched) {
+				continue
+			}
+			matched++
+			if value, found := alert.Labels[label]; found {
+				counts[value]++
+				top.Total++
+			}
+		}
+	}
+
+	for value, count := range counts {
+		top.Values = append(top.Values, models.LabelValueCount{Value: value, Count: count})
+	}
+	sort.Slice(top.Values, func(i, j int) bool {
+		if top.Values[i].Count != top.Values[j].Count {
+			return top.Values[i].Count > top.Values[j].Count
+		}
+		return top.Values[i].Value < top.Values[j].Value
+	})
+	if len(top.Values) > limit {
+		top.Values = top.Values[:limit]
+	}
+
+	if q != "" {
+		for _, filter := range matchFilters {
+			top.Filters = append(top.Filters, apiFilter(filter))
+		}
+	}
+	return top
+}
+
+// getSuggestions returns typed autocomplete suggestions for the filter
+// expression being typed, only the last expression of the query is used,
+// depending on what was typed so far it will suggest label names and
+// keywords, operators or values, only alerts the tenant can see are used
+func getSuggestions(t tenant, query string) models.SuggestionsResponse {
+	expressions := strings.Split(query, ",")
+	context := strings.TrimSpace(expressions[len(expressions)-1])
+	resp := models.SuggestionsResponse{Context: context, Suggestions: []models.Suggestion{}}
+
+	name, operator, value := filters.SplitExpression(context)
+	if name == "" {
+		// name is being typed
+		labelCount := map[string]int{}
+		for _, ag := range t.alertGroups() {
+			for _, alert := range ag.Alerts {
+				for key := range alert.Labels {
+					labelCount[key]++
+				}
+			}
+		}
+		labels := []string{}
+		for key := range labelCount {
+			labels = append(labels, key)
+		}
+		sort.Strings(labels)
+		for _, key := range labels {
+			if strings.HasPrefix(strings.ToLower(key), strings.ToLower(context)) {
+				resp.Suggestions = append(resp.Suggestions, models.Suggestion{
+					Type:   models.SuggestionLabelName,
+					Value:  key,
+					Filter: key,
+					Weight: labelCount[key],
+				})
+			}
+		}
+		for _, keyword := range filters.Keywords() {
+			if strings.HasPrefix(keyword, strings.ToLower(context)) {
+				resp.Suggestions = append(resp.Suggestions, models.Suggestion{
+					Type:   models.SuggestionKeyword,
+					Value:  keyword,
+					Filter: keyword,
+				})
+			}
+		}
+		// full name was typed, suggest operators for it
+		if labelCount[context] > 0 || slices.StringInSlice(filters.Keywords(), context) {
+			name = context
+		}
+	}
+
+	if name != "" && value == "" {
+		for _, op := range filters.SupportedOperators(name) {
+			if strings.HasPrefix(op, operator) && op != operator {
+				resp.Suggestions = append(resp.Suggestions, models.Suggestion{
+					Type:   models.SuggestionOperator,
+					Value:  op,
+					Filter: name + op,
+				})
+			}
+		}
+	}
+
+	if operator != "" {
+		prefix := strings.ToLower(name + operator + value)
+		for _, hint := range t.autocomplete() {
+			hintName, hintOperator, hintValue := filters.SplitExpression(hint.Value)
+			if hintName == name && hintOperator == operator && strings.HasPrefix(strings.ToLower(hint.Value), prefix) {
+				resp.Suggestions = append(resp.Suggestions, models.Suggestion{
+					Type:   models.SuggestionValue,
+					Value:  hintValue,
+					Filter: hint.Value,
+					Weight: hint.Weight,
+				})
+			}
+		}
+	}
+
+	sort.SliceStable(resp.Suggestions, func(i, j int) bool {
+		if resp.Suggestions[i].Weight != resp.Suggestions[j].Weight {
+			return resp.Suggestions[i].Weight > resp.Suggestions[j].Weight
+		}
+		if resp.Suggestions[i].Type == models.SuggestionValue && resp.Suggestions[j].Type == models.SuggestionValue {
+			return resp.Suggestions[i].Value < resp.Suggestions[j].Value
+		}
+		return false
+	})
+
+	return resp
+}
+
+// maxDataAge returns how old the last successful collection can be before
+// upstream data is considered stale, collections run every AlertmanagerTTL and
+// each one can take up to 3*AlertmanagerTimeout
+func maxDataAge() time.Duration {
+	return config.Config.AlertmanagerTTL + 3*config.Config.AlertmanagerTimeout
+}
+
+// checkReadiness returns an error unless at least one upstream was
+// successfully collected recently and upstreams required by
+// READINESS_UPSTREAMS were collected after startup, paused upstreams are
+// skipped and there's no error if collection from all upstreams is paused
+func checkReadiness(now time.Time) error {
+	maxAge := maxDataAge()
+	upstreams := alertmanager.GetAlertmanagers()
+	if len(upstreams) > 0 && len(pausedUpstreams()) == len(upstreams) {
+		// collection was paused on purpose, so stale data is expected
+		return nil
+	}
+	if err := startupStatus(); err != nil {
+		return err
+	}
+	for _, upstream := range upstreams {
+		if upstream.IsPaused() {
+			continue
+		}
+		lastCollected := upstream.LastCollected()
+		if !lastCollected.IsZero() && now.Sub(lastCollected) <= maxAge {
+			return nil
+		}
+	}
+	return fmt.Errorf("no Alertmanager upstream was successfully collected in the last %s", maxAge)
+}
+
+// checkUpstreams returns an error listing all upstreams and their errors
+// unless at least one of them was collected without errors
+func checkUpstreams() error {
+	failed := []string{}
+	for _, upstream := range alertmanager.GetAlertmanagers() {
+		if upstream.Error() == "" && !upstream.LastCollected().IsZero() {
+			return nil
+		}
+		failed = append(failed, fmt.Sprintf("%s: %s", upstream.Name, upstream.Error()))
+	}
+	return fmt.Errorf("none of Alertmanager upstreams could be collected, %s", strings.Join(failed, ", "))
+}
+
+// getFilterPresets parses filter presets from the config, each preset uses
+// name:filter format
+func getFilterPresets() ([]models.FilterPreset, error) {
+	presets := []models.FilterPreset{}
+	for _, s := range config.Config.FilterPresets {
+		if s == "" {
+			continue
+		}
+		z := strings.SplitN(s, ":", 2)
+		if len(z) != 2 || z[0] == "" || z[1] == "" {
+			return nil, fmt.Errorf("invalid filter preset '%s', expected format 'name:filter'", s)
+		}
+		if err := validateFilterQuery(z[1]); err != nil {
+			return nil, fmt.Errorf("invalid filter preset '%s': %s", s, err)
+		}
+		presets = append(presets, models.FilterPreset{Name: z[0], Filter: z[1]})
+	}
+	return presets, nil
+}
+
+// getBlackholeFilters returns filter queries from ALERTS_BLACKHOLE_FILTERS with
+// macros expanded, filters that depend on the whole alert group can't be used
+// as alerts are dropped before groups are built
+func getBlackholeFilters() ([]string, error) {
+	queries := []string{}
+	for _, s := range config.Config.AlertsBlackholeFilters {
+		if s == "" {
+			continue
+		}
+		if err := validateFilterQuery(s); err != nil {
+			return nil, fmt.Errorf("invalid blackhole filter '%s': %s", s, err)
+		}
+		q, _ := expandFilterMacros(s)
+		matchFilters, _ := getFiltersFromQuery(q)
+		for _, filter := range matchFilters {
+			_, isGroupFilter := filter.(filters.GroupFilterT)
+			_, isGroupLimitFilter := filter.(filters.GroupLimitFilterT)
+			// @limit depends on the number of alerts already matched by the
+			// query, which isn't tracked during collection
+			isLimitFilter := strings.HasPrefix(filter.GetRawText(), "@limit")
+			if isGroupFilter || isGroupLimitFilter || isLimitFilter {
+				return nil, fmt.Errorf("invalid blackhole filter '%s': '%s' can't be used to drop alerts", s, filter.GetRawText())
+			}
+		}
+		queries = append(queries, q)
+	}
+	return queries, nil
+}
+
+// getLabelDisplayNames parses label display names from the config, each
+// entry uses label:name format
+func getLabelDisplayNames() (map[string]string, error) {
+	names := map[string]string{}
+	for _, s := range config.Config.LabelDisplayNames {
+		if s == "" {
+			continue
+		}
+		z := strings.SplitN(s, ":", 2)
+		if len(z) != 2 || z[0] == "" || z[1] == "" {
+			return nil, fmt.Errorf("invalid label display name '%s', expected format 'label:name'", s)
+		}
+		if _, found := names[z[0]]; found {
+			return nil, fmt.Errorf("invalid label display name '%s', display name for label '%s' is already set", s, z[0])
+		}
+		names[z[0]] = z[1]
+	}
+	return names, nil
+}
+
+// groupCollapseHint returns true if clients should only render a summary of
+// alerts for the group, based on GROUP_COLLAPSE_SIZE and GROUP_COLLAPSE_FILTER
+func groupCollapseHint(ag *models.AlertGroup) bool {
+	if config.Config.GroupCollapseSize > 0 && len(ag.Alerts) > config.Config.GroupCollapseSize {
+		return true
+	}
+	if config.Config.GroupCollapseFilter == "" {
+		return false
+	}
+	q, _ := expandFilterMacros(config.Config.GroupCollapseFilter)
+	matchFilters, validFilters := getFiltersFromQuery(q)
+	if !validFilters {
+		return false
+	}
+	for _, filter := range matchFilters {
+		if gf, ok := filter.(filters.GroupFilterT); ok {
+			gf.SetGroup(ag)
+		}
+	}
+	for i := range ag.Alerts {
+		if alertMatchesFilters(&ag.Alerts[i], matchFilters, validFilters, 0) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/cloudflare/unsee/internal/config"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// parseNetworks parses a list of CIDRs, plain IP addresses are also
+// accepted and will only match that address
+func parseNetworks(list []string) ([]*net.IPNet, error) {
+	networks := []*net.IPNet{}
+	for _, s := range list {
+		if s == "" {
+			continue
+		}
+		if !strings.Contains(s, "/") {
+			ip := net.ParseIP(s)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid IP address '%s'", s)
+			}
+			bits := 128
+			if ip.To4() != nil {
+				bits = 32
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid network '%s': %s", s, err)
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+// getAllowedNetworks returns networks allowed to access unsee and networks
+// that are only allowed to access the metrics and health check endpoints
+func getAllowedNetworks() ([]*net.IPNet, []*net.IPNet, error) {
+	allowed, err := parseNetworks(config.Config.AllowedNetworks)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid ALLOWED_NETWORKS: %s", err)
+	}
+	monitoring, err := parseNetworks(config.Config.MetricsAllowedNetworks)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid METRICS_ALLOWED_NETWORKS: %s", err)
+	}
+	return allowed, monitoring, nil
+}
+
+func networksContain(networks []*net.IPNet, ip net.IP) bool {
+	for _, network := range networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// remoteHost returns the address of the client connection without the port,
+// X-Forwarded-For header is ignored since anyone could set it
+func remoteHost(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// ipAllowlist returns a handler that will only pass requests from allowed
+// networks to the next handler, client address is always taken from the
+// connection, X-Forwarded-For header is ignored since anyone could set it,
+// requests for metrics and health check endpoints are also allowed from
+// monitoring networks, nothing is checked if no allowed network is configured
+func ipAllowlist(next http.Handler, allowed []*net.IPNet, monitoring []*net.IPNet) http.Handler {
+	if len(allowed) == 0 {
+		return next
+	}
+	monitoringPaths := []string{getViewURL("/metrics"), getViewURL("/healthz"), getViewURL("/readyz")}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := remoteHost(r)
+		ip := net.ParseIP(host)
+		if ip != nil {
+			if networksContain(allowed, ip) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			for _, p := range monitoringPaths {
+				if r.URL.Path == p && networksContain(monitoring, ip) {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+		}
+
+		log.Warningf("[%s] %s %s rejected, client address is not allowed", host, r.Method, r.URL.Path)
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": "access from your address is not allowed"})
+	})
+}
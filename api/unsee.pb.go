@@ -0,0 +1,915 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: api/unsee.proto
+
+// unsee gRPC API, exposes deduplicated alert groups collected from all
+// Alertmanager upstreams, Go code is generated using `make proto`
+
+package api
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type ListRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// list of filter expressions, the same as used in the UI, for example
+	// ["@state=active", "cluster=prod"]
+	Filters       []string `protobuf:"bytes,1,rep,name=filters,proto3" json:"filters,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRequest) Reset() {
+	*x = ListRequest{}
+	mi := &file_api_unsee_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRequest) ProtoMessage() {}
+
+func (x *ListRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_unsee_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRequest.ProtoReflect.Descriptor instead.
+func (*ListRequest) Descriptor() ([]byte, []int) {
+	return file_api_unsee_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ListRequest) GetFilters() []string {
+	if x != nil {
+		return x.Filters
+	}
+	return nil
+}
+
+type ListResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Groups        []*AlertGroup          `protobuf:"bytes,1,rep,name=groups,proto3" json:"groups,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListResponse) Reset() {
+	*x = ListResponse{}
+	mi := &file_api_unsee_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListResponse) ProtoMessage() {}
+
+func (x *ListResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_unsee_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListResponse.ProtoReflect.Descriptor instead.
+func (*ListResponse) Descriptor() ([]byte, []int) {
+	return file_api_unsee_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ListResponse) GetGroups() []*AlertGroup {
+	if x != nil {
+		return x.Groups
+	}
+	return nil
+}
+
+type WatchRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Filters       []string               `protobuf:"bytes,1,rep,name=filters,proto3" json:"filters,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchRequest) Reset() {
+	*x = WatchRequest{}
+	mi := &file_api_unsee_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchRequest) ProtoMessage() {}
+
+func (x *WatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_unsee_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchRequest.ProtoReflect.Descriptor instead.
+func (*WatchRequest) Descriptor() ([]byte, []int) {
+	return file_api_unsee_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *WatchRequest) GetFilters() []string {
+	if x != nil {
+		return x.Filters
+	}
+	return nil
+}
+
+type SilenceMatcher struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Value         string                 `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	IsRegex       bool                   `protobuf:"varint,3,opt,name=is_regex,json=isRegex,proto3" json:"is_regex,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SilenceMatcher) Reset() {
+	*x = SilenceMatcher{}
+	mi := &file_api_unsee_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SilenceMatcher) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SilenceMatcher) ProtoMessage() {}
+
+func (x *SilenceMatcher) ProtoReflect() protoreflect.Message {
+	mi := &file_api_unsee_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SilenceMatcher.ProtoReflect.Descriptor instead.
+func (*SilenceMatcher) Descriptor() ([]byte, []int) {
+	return file_api_unsee_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *SilenceMatcher) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *SilenceMatcher) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+func (x *SilenceMatcher) GetIsRegex() bool {
+	if x != nil {
+		return x.IsRegex
+	}
+	return false
+}
+
+type Silence struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Matchers      []*SilenceMatcher      `protobuf:"bytes,2,rep,name=matchers,proto3" json:"matchers,omitempty"`
+	StartsAt      *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=starts_at,json=startsAt,proto3" json:"starts_at,omitempty"`
+	EndsAt        *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=ends_at,json=endsAt,proto3" json:"ends_at,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	CreatedBy     string                 `protobuf:"bytes,6,opt,name=created_by,json=createdBy,proto3" json:"created_by,omitempty"`
+	Comment       string                 `protobuf:"bytes,7,opt,name=comment,proto3" json:"comment,omitempty"`
+	JiraId        string                 `protobuf:"bytes,8,opt,name=jira_id,json=jiraId,proto3" json:"jira_id,omitempty"`
+	JiraUrl       string                 `protobuf:"bytes,9,opt,name=jira_url,json=jiraUrl,proto3" json:"jira_url,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Silence) Reset() {
+	*x = Silence{}
+	mi := &file_api_unsee_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Silence) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Silence) ProtoMessage() {}
+
+func (x *Silence) ProtoReflect() protoreflect.Message {
+	mi := &file_api_unsee_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Silence.ProtoReflect.Descriptor instead.
+func (*Silence) Descriptor() ([]byte, []int) {
+	return file_api_unsee_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Silence) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Silence) GetMatchers() []*SilenceMatcher {
+	if x != nil {
+		return x.Matchers
+	}
+	return nil
+}
+
+func (x *Silence) GetStartsAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartsAt
+	}
+	return nil
+}
+
+func (x *Silence) GetEndsAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EndsAt
+	}
+	return nil
+}
+
+func (x *Silence) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Silence) GetCreatedBy() string {
+	if x != nil {
+		return x.CreatedBy
+	}
+	return ""
+}
+
+func (x *Silence) GetComment() string {
+	if x != nil {
+		return x.Comment
+	}
+	return ""
+}
+
+func (x *Silence) GetJiraId() string {
+	if x != nil {
+		return x.JiraId
+	}
+	return ""
+}
+
+func (x *Silence) GetJiraUrl() string {
+	if x != nil {
+		return x.JiraUrl
+	}
+	return ""
+}
+
+type AlertmanagerInstance struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Uri           string                 `protobuf:"bytes,2,opt,name=uri,proto3" json:"uri,omitempty"`
+	State         string                 `protobuf:"bytes,3,opt,name=state,proto3" json:"state,omitempty"`
+	StartsAt      *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=starts_at,json=startsAt,proto3" json:"starts_at,omitempty"`
+	EndsAt        *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=ends_at,json=endsAt,proto3" json:"ends_at,omitempty"`
+	Source        string                 `protobuf:"bytes,6,opt,name=source,proto3" json:"source,omitempty"`
+	Silences      []*Silence             `protobuf:"bytes,7,rep,name=silences,proto3" json:"silences,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AlertmanagerInstance) Reset() {
+	*x = AlertmanagerInstance{}
+	mi := &file_api_unsee_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AlertmanagerInstance) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AlertmanagerInstance) ProtoMessage() {}
+
+func (x *AlertmanagerInstance) ProtoReflect() protoreflect.Message {
+	mi := &file_api_unsee_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AlertmanagerInstance.ProtoReflect.Descriptor instead.
+func (*AlertmanagerInstance) Descriptor() ([]byte, []int) {
+	return file_api_unsee_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *AlertmanagerInstance) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *AlertmanagerInstance) GetUri() string {
+	if x != nil {
+		return x.Uri
+	}
+	return ""
+}
+
+func (x *AlertmanagerInstance) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *AlertmanagerInstance) GetStartsAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartsAt
+	}
+	return nil
+}
+
+func (x *AlertmanagerInstance) GetEndsAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EndsAt
+	}
+	return nil
+}
+
+func (x *AlertmanagerInstance) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *AlertmanagerInstance) GetSilences() []*Silence {
+	if x != nil {
+		return x.Silences
+	}
+	return nil
+}
+
+type Annotation struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Value         string                 `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	Visible       bool                   `protobuf:"varint,3,opt,name=visible,proto3" json:"visible,omitempty"`
+	IsLink        bool                   `protobuf:"varint,4,opt,name=is_link,json=isLink,proto3" json:"is_link,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Annotation) Reset() {
+	*x = Annotation{}
+	mi := &file_api_unsee_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Annotation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Annotation) ProtoMessage() {}
+
+func (x *Annotation) ProtoReflect() protoreflect.Message {
+	mi := &file_api_unsee_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Annotation.ProtoReflect.Descriptor instead.
+func (*Annotation) Descriptor() ([]byte, []int) {
+	return file_api_unsee_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Annotation) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Annotation) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+func (x *Annotation) GetVisible() bool {
+	if x != nil {
+		return x.Visible
+	}
+	return false
+}
+
+func (x *Annotation) GetIsLink() bool {
+	if x != nil {
+		return x.IsLink
+	}
+	return false
+}
+
+type Alert struct {
+	state         protoimpl.MessageState  `protogen:"open.v1"`
+	Annotations   []*Annotation           `protobuf:"bytes,1,rep,name=annotations,proto3" json:"annotations,omitempty"`
+	Labels        map[string]string       `protobuf:"bytes,2,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	StartsAt      *timestamppb.Timestamp  `protobuf:"bytes,3,opt,name=starts_at,json=startsAt,proto3" json:"starts_at,omitempty"`
+	EndsAt        *timestamppb.Timestamp  `protobuf:"bytes,4,opt,name=ends_at,json=endsAt,proto3" json:"ends_at,omitempty"`
+	State         string                  `protobuf:"bytes,5,opt,name=state,proto3" json:"state,omitempty"`
+	Fingerprint   string                  `protobuf:"bytes,6,opt,name=fingerprint,proto3" json:"fingerprint,omitempty"`
+	Alertmanager  []*AlertmanagerInstance `protobuf:"bytes,7,rep,name=alertmanager,proto3" json:"alertmanager,omitempty"`
+	Receiver      string                  `protobuf:"bytes,8,opt,name=receiver,proto3" json:"receiver,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Alert) Reset() {
+	*x = Alert{}
+	mi := &file_api_unsee_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Alert) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Alert) ProtoMessage() {}
+
+func (x *Alert) ProtoReflect() protoreflect.Message {
+	mi := &file_api_unsee_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Alert.ProtoReflect.Descriptor instead.
+func (*Alert) Descriptor() ([]byte, []int) {
+	return file_api_unsee_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Alert) GetAnnotations() []*Annotation {
+	if x != nil {
+		return x.Annotations
+	}
+	return nil
+}
+
+func (x *Alert) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *Alert) GetStartsAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartsAt
+	}
+	return nil
+}
+
+func (x *Alert) GetEndsAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EndsAt
+	}
+	return nil
+}
+
+func (x *Alert) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *Alert) GetFingerprint() string {
+	if x != nil {
+		return x.Fingerprint
+	}
+	return ""
+}
+
+func (x *Alert) GetAlertmanager() []*AlertmanagerInstance {
+	if x != nil {
+		return x.Alertmanager
+	}
+	return nil
+}
+
+func (x *Alert) GetReceiver() string {
+	if x != nil {
+		return x.Receiver
+	}
+	return ""
+}
+
+type AlertGroup struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Receiver      string                 `protobuf:"bytes,2,opt,name=receiver,proto3" json:"receiver,omitempty"`
+	Labels        map[string]string      `protobuf:"bytes,3,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Alerts        []*Alert               `protobuf:"bytes,4,rep,name=alerts,proto3" json:"alerts,omitempty"`
+	Hash          string                 `protobuf:"bytes,5,opt,name=hash,proto3" json:"hash,omitempty"`
+	StateCount    map[string]int64       `protobuf:"bytes,6,rep,name=state_count,json=stateCount,proto3" json:"state_count,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AlertGroup) Reset() {
+	*x = AlertGroup{}
+	mi := &file_api_unsee_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AlertGroup) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AlertGroup) ProtoMessage() {}
+
+func (x *AlertGroup) ProtoReflect() protoreflect.Message {
+	mi := &file_api_unsee_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AlertGroup.ProtoReflect.Descriptor instead.
+func (*AlertGroup) Descriptor() ([]byte, []int) {
+	return file_api_unsee_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *AlertGroup) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *AlertGroup) GetReceiver() string {
+	if x != nil {
+		return x.Receiver
+	}
+	return ""
+}
+
+func (x *AlertGroup) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *AlertGroup) GetAlerts() []*Alert {
+	if x != nil {
+		return x.Alerts
+	}
+	return nil
+}
+
+func (x *AlertGroup) GetHash() string {
+	if x != nil {
+		return x.Hash
+	}
+	return ""
+}
+
+func (x *AlertGroup) GetStateCount() map[string]int64 {
+	if x != nil {
+		return x.StateCount
+	}
+	return nil
+}
+
+type AlertEvent struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// one of added, resolved, silenced or changed
+	Type          string            `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	GroupId       string            `protobuf:"bytes,2,opt,name=group_id,json=groupId,proto3" json:"group_id,omitempty"`
+	Receiver      string            `protobuf:"bytes,3,opt,name=receiver,proto3" json:"receiver,omitempty"`
+	Labels        map[string]string `protobuf:"bytes,4,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Alert         *Alert            `protobuf:"bytes,5,opt,name=alert,proto3" json:"alert,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AlertEvent) Reset() {
+	*x = AlertEvent{}
+	mi := &file_api_unsee_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AlertEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AlertEvent) ProtoMessage() {}
+
+func (x *AlertEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_api_unsee_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AlertEvent.ProtoReflect.Descriptor instead.
+func (*AlertEvent) Descriptor() ([]byte, []int) {
+	return file_api_unsee_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *AlertEvent) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *AlertEvent) GetGroupId() string {
+	if x != nil {
+		return x.GroupId
+	}
+	return ""
+}
+
+func (x *AlertEvent) GetReceiver() string {
+	if x != nil {
+		return x.Receiver
+	}
+	return ""
+}
+
+func (x *AlertEvent) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *AlertEvent) GetAlert() *Alert {
+	if x != nil {
+		return x.Alert
+	}
+	return nil
+}
+
+var File_api_unsee_proto protoreflect.FileDescriptor
+
+const file_api_unsee_proto_rawDesc = "" +
+	"\n" +
+	"\x0fapi/unsee.proto\x12\bunsee.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"'\n" +
+	"\vListRequest\x12\x18\n" +
+	"\afilters\x18\x01 \x03(\tR\afilters\"<\n" +
+	"\fListResponse\x12,\n" +
+	"\x06groups\x18\x01 \x03(\v2\x14.unsee.v1.AlertGroupR\x06groups\"(\n" +
+	"\fWatchRequest\x12\x18\n" +
+	"\afilters\x18\x01 \x03(\tR\afilters\"U\n" +
+	"\x0eSilenceMatcher\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value\x12\x19\n" +
+	"\bis_regex\x18\x03 \x01(\bR\aisRegex\"\xe5\x02\n" +
+	"\aSilence\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x124\n" +
+	"\bmatchers\x18\x02 \x03(\v2\x18.unsee.v1.SilenceMatcherR\bmatchers\x127\n" +
+	"\tstarts_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\bstartsAt\x123\n" +
+	"\aends_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\x06endsAt\x129\n" +
+	"\n" +
+	"created_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12\x1d\n" +
+	"\n" +
+	"created_by\x18\x06 \x01(\tR\tcreatedBy\x12\x18\n" +
+	"\acomment\x18\a \x01(\tR\acomment\x12\x17\n" +
+	"\ajira_id\x18\b \x01(\tR\x06jiraId\x12\x19\n" +
+	"\bjira_url\x18\t \x01(\tR\ajiraUrl\"\x87\x02\n" +
+	"\x14AlertmanagerInstance\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x10\n" +
+	"\x03uri\x18\x02 \x01(\tR\x03uri\x12\x14\n" +
+	"\x05state\x18\x03 \x01(\tR\x05state\x127\n" +
+	"\tstarts_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\bstartsAt\x123\n" +
+	"\aends_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\x06endsAt\x12\x16\n" +
+	"\x06source\x18\x06 \x01(\tR\x06source\x12-\n" +
+	"\bsilences\x18\a \x03(\v2\x11.unsee.v1.SilenceR\bsilences\"i\n" +
+	"\n" +
+	"Annotation\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value\x12\x18\n" +
+	"\avisible\x18\x03 \x01(\bR\avisible\x12\x17\n" +
+	"\ais_link\x18\x04 \x01(\bR\x06isLink\"\xb5\x03\n" +
+	"\x05Alert\x126\n" +
+	"\vannotations\x18\x01 \x03(\v2\x14.unsee.v1.AnnotationR\vannotations\x123\n" +
+	"\x06labels\x18\x02 \x03(\v2\x1b.unsee.v1.Alert.LabelsEntryR\x06labels\x127\n" +
+	"\tstarts_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\bstartsAt\x123\n" +
+	"\aends_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\x06endsAt\x12\x14\n" +
+	"\x05state\x18\x05 \x01(\tR\x05state\x12 \n" +
+	"\vfingerprint\x18\x06 \x01(\tR\vfingerprint\x12B\n" +
+	"\falertmanager\x18\a \x03(\v2\x1e.unsee.v1.AlertmanagerInstanceR\falertmanager\x12\x1a\n" +
+	"\breceiver\x18\b \x01(\tR\breceiver\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xf0\x02\n" +
+	"\n" +
+	"AlertGroup\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1a\n" +
+	"\breceiver\x18\x02 \x01(\tR\breceiver\x128\n" +
+	"\x06labels\x18\x03 \x03(\v2 .unsee.v1.AlertGroup.LabelsEntryR\x06labels\x12'\n" +
+	"\x06alerts\x18\x04 \x03(\v2\x0f.unsee.v1.AlertR\x06alerts\x12\x12\n" +
+	"\x04hash\x18\x05 \x01(\tR\x04hash\x12E\n" +
+	"\vstate_count\x18\x06 \x03(\v2$.unsee.v1.AlertGroup.StateCountEntryR\n" +
+	"stateCount\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1a=\n" +
+	"\x0fStateCountEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x03R\x05value:\x028\x01\"\xf3\x01\n" +
+	"\n" +
+	"AlertEvent\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12\x19\n" +
+	"\bgroup_id\x18\x02 \x01(\tR\agroupId\x12\x1a\n" +
+	"\breceiver\x18\x03 \x01(\tR\breceiver\x128\n" +
+	"\x06labels\x18\x04 \x03(\v2 .unsee.v1.AlertEvent.LabelsEntryR\x06labels\x12%\n" +
+	"\x05alert\x18\x05 \x01(\v2\x0f.unsee.v1.AlertR\x05alert\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x012w\n" +
+	"\x05Unsee\x125\n" +
+	"\x04List\x12\x15.unsee.v1.ListRequest\x1a\x16.unsee.v1.ListResponse\x127\n" +
+	"\x05Watch\x12\x16.unsee.v1.WatchRequest\x1a\x14.unsee.v1.AlertEvent0\x01B!Z\x1fgithub.com/cloudflare/unsee/apib\x06proto3"
+
+var (
+	file_api_unsee_proto_rawDescOnce sync.Once
+	file_api_unsee_proto_rawDescData []byte
+)
+
+func file_api_unsee_proto_rawDescGZIP() []byte {
+	file_api_unsee_proto_rawDescOnce.Do(func() {
+		file_api_unsee_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_api_unsee_proto_rawDesc), len(file_api_unsee_proto_rawDesc)))
+	})
+	return file_api_unsee_proto_rawDescData
+}
+
+var file_api_unsee_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
+var file_api_unsee_proto_goTypes = []any{
+	(*ListRequest)(nil),           // 0: unsee.v1.ListRequest
+	(*ListResponse)(nil),          // 1: unsee.v1.ListResponse
+	(*WatchRequest)(nil),          // 2: unsee.v1.WatchRequest
+	(*SilenceMatcher)(nil),        // 3: unsee.v1.SilenceMatcher
+	(*Silence)(nil),               // 4: unsee.v1.Silence
+	(*AlertmanagerInstance)(nil),  // 5: unsee.v1.AlertmanagerInstance
+	(*Annotation)(nil),            // 6: unsee.v1.Annotation
+	(*Alert)(nil),                 // 7: unsee.v1.Alert
+	(*AlertGroup)(nil),            // 8: unsee.v1.AlertGroup
+	(*AlertEvent)(nil),            // 9: unsee.v1.AlertEvent
+	nil,                           // 10: unsee.v1.Alert.LabelsEntry
+	nil,                           // 11: unsee.v1.AlertGroup.LabelsEntry
+	nil,                           // 12: unsee.v1.AlertGroup.StateCountEntry
+	nil,                           // 13: unsee.v1.AlertEvent.LabelsEntry
+	(*timestamppb.Timestamp)(nil), // 14: google.protobuf.Timestamp
+}
+var file_api_unsee_proto_depIdxs = []int32{
+	8,  // 0: unsee.v1.ListResponse.groups:type_name -> unsee.v1.AlertGroup
+	3,  // 1: unsee.v1.Silence.matchers:type_name -> unsee.v1.SilenceMatcher
+	14, // 2: unsee.v1.Silence.starts_at:type_name -> google.protobuf.Timestamp
+	14, // 3: unsee.v1.Silence.ends_at:type_name -> google.protobuf.Timestamp
+	14, // 4: unsee.v1.Silence.created_at:type_name -> google.protobuf.Timestamp
+	14, // 5: unsee.v1.AlertmanagerInstance.starts_at:type_name -> google.protobuf.Timestamp
+	14, // 6: unsee.v1.AlertmanagerInstance.ends_at:type_name -> google.protobuf.Timestamp
+	4,  // 7: unsee.v1.AlertmanagerInstance.silences:type_name -> unsee.v1.Silence
+	6,  // 8: unsee.v1.Alert.annotations:type_name -> unsee.v1.Annotation
+	10, // 9: unsee.v1.Alert.labels:type_name -> unsee.v1.Alert.LabelsEntry
+	14, // 10: unsee.v1.Alert.starts_at:type_name -> google.protobuf.Timestamp
+	14, // 11: unsee.v1.Alert.ends_at:type_name -> google.protobuf.Timestamp
+	5,  // 12: unsee.v1.Alert.alertmanager:type_name -> unsee.v1.AlertmanagerInstance
+	11, // 13: unsee.v1.AlertGroup.labels:type_name -> unsee.v1.AlertGroup.LabelsEntry
+	7,  // 14: unsee.v1.AlertGroup.alerts:type_name -> unsee.v1.Alert
+	12, // 15: unsee.v1.AlertGroup.state_count:type_name -> unsee.v1.AlertGroup.StateCountEntry
+	13, // 16: unsee.v1.AlertEvent.labels:type_name -> unsee.v1.AlertEvent.LabelsEntry
+	7,  // 17: unsee.v1.AlertEvent.alert:type_name -> unsee.v1.Alert
+	0,  // 18: unsee.v1.Unsee.List:input_type -> unsee.v1.ListRequest
+	2,  // 19: unsee.v1.Unsee.Watch:input_type -> unsee.v1.WatchRequest
+	1,  // 20: unsee.v1.Unsee.List:output_type -> unsee.v1.ListResponse
+	9,  // 21: unsee.v1.Unsee.Watch:output_type -> unsee.v1.AlertEvent
+	20, // [20:22] is the sub-list for method output_type
+	18, // [18:20] is the sub-list for method input_type
+	18, // [18:18] is the sub-list for extension type_name
+	18, // [18:18] is the sub-list for extension extendee
+	0,  // [0:18] is the sub-list for field type_name
+}
+
+func init() { file_api_unsee_proto_init() }
+func file_api_unsee_proto_init() {
+	if File_api_unsee_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_api_unsee_proto_rawDesc), len(file_api_unsee_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   14,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_api_unsee_proto_goTypes,
+		DependencyIndexes: file_api_unsee_proto_depIdxs,
+		MessageInfos:      file_api_unsee_proto_msgTypes,
+	}.Build()
+	File_api_unsee_proto = out.File
+	file_api_unsee_proto_goTypes = nil
+	file_api_unsee_proto_depIdxs = nil
+}
@@ -0,0 +1,172 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: api/unsee.proto
+
+// unsee gRPC API, exposes deduplicated alert groups collected from all
+// Alertmanager upstreams, Go code is generated using `make proto`
+
+package api
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Unsee_List_FullMethodName  = "/unsee.v1.Unsee/List"
+	Unsee_Watch_FullMethodName = "/unsee.v1.Unsee/Watch"
+)
+
+// UnseeClient is the client API for Unsee service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type UnseeClient interface {
+	// List returns all alert groups matching the filter
+	List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListResponse, error)
+	// Watch streams a message for every alert change matching the filter, it's
+	// sent after every collection from Alertmanager
+	Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[AlertEvent], error)
+}
+
+type unseeClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewUnseeClient(cc grpc.ClientConnInterface) UnseeClient {
+	return &unseeClient{cc}
+}
+
+func (c *unseeClient) List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListResponse)
+	err := c.cc.Invoke(ctx, Unsee_List_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *unseeClient) Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[AlertEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Unsee_ServiceDesc.Streams[0], Unsee_Watch_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchRequest, AlertEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Unsee_WatchClient = grpc.ServerStreamingClient[AlertEvent]
+
+// UnseeServer is the server API for Unsee service.
+// All implementations must embed UnimplementedUnseeServer
+// for forward compatibility.
+type UnseeServer interface {
+	// List returns all alert groups matching the filter
+	List(context.Context, *ListRequest) (*ListResponse, error)
+	// Watch streams a message for every alert change matching the filter, it's
+	// sent after every collection from Alertmanager
+	Watch(*WatchRequest, grpc.ServerStreamingServer[AlertEvent]) error
+	mustEmbedUnimplementedUnseeServer()
+}
+
+// UnimplementedUnseeServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedUnseeServer struct{}
+
+func (UnimplementedUnseeServer) List(context.Context, *ListRequest) (*ListResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method List not implemented")
+}
+func (UnimplementedUnseeServer) Watch(*WatchRequest, grpc.ServerStreamingServer[AlertEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method Watch not implemented")
+}
+func (UnimplementedUnseeServer) mustEmbedUnimplementedUnseeServer() {}
+func (UnimplementedUnseeServer) testEmbeddedByValue()               {}
+
+// UnsafeUnseeServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to UnseeServer will
+// result in compilation errors.
+type UnsafeUnseeServer interface {
+	mustEmbedUnimplementedUnseeServer()
+}
+
+func RegisterUnseeServer(s grpc.ServiceRegistrar, srv UnseeServer) {
+	// If the following call pancis, it indicates UnimplementedUnseeServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Unsee_ServiceDesc, srv)
+}
+
+func _Unsee_List_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UnseeServer).List(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Unsee_List_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UnseeServer).List(ctx, req.(*ListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Unsee_Watch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(UnseeServer).Watch(m, &grpc.GenericServerStream[WatchRequest, AlertEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Unsee_WatchServer = grpc.ServerStreamingServer[AlertEvent]
+
+// Unsee_ServiceDesc is the grpc.ServiceDesc for Unsee service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Unsee_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "unsee.v1.Unsee",
+	HandlerType: (*UnseeServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "List",
+			Handler:    _Unsee_List_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Watch",
+			Handler:       _Unsee_Watch_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "api/unsee.proto",
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/cloudflare/unsee/internal/config"
+
+	"github.com/gin-gonic/gin"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// API keys can be passed using this header or query argument
+	apiKeyHeader     = "X-API-Key"
+	apiKeyQueryParam = "api_key"
+	// cookie set when the dashboard is loaded, so the UI can query the API
+	// without a key
+	dashboardCookie = "unsee_dashboard"
+)
+
+// getAPIKeys parses API keys from the config, each key uses name:key format,
+// returned map is keyed by the key with the name as the value
+func getAPIKeys() (map[string]string, error) {
+	keys := map[string]string{}
+	names := map[string]bool{}
+	for i, s := range config.Config.ApiKeys {
+		if s == "" {
+			continue
+		}
+		z := strings.SplitN(s, ":", 2)
+		if len(z) != 2 || z[0] == "" || z[1] == "" {
+			return nil, fmt.Errorf("invalid API key at position %d, expected format 'name:key'", i+1)
+		}
+		if names[z[0]] {
+			return nil, fmt.Errorf("duplicated API key name '%s'", z[0])
+		}
+		names[z[0]] = true
+		keys[z[1]] = z[0]
+	}
+	return keys, nil
+}
+
+// dashboardToken returns the value of the dashboard cookie, it's derived from
+// all API keys so it's the same on every instance sharing the config
+func dashboardToken(keys map[string]string) string {
+	values := []string{}
+	for key := range keys {
+		values = append(values, key)
+	}
+	sort.Strings(values)
+	return fmt.Sprintf("%x", sha256.Sum256([]byte("unsee-dashboard:"+strings.Join(values, " "))))
+}
+
+// setDashboardCookie will allow the UI to query API endpoints if API keys are
+// enabled, the cookie is only set for authenticated users, otherwise anyone
+// could get it by loading the dashboard
+func setDashboardCookie(c *gin.Context, keys map[string]string) {
+	if len(keys) == 0 || getUserName(c) == "" {
+		return
+	}
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     dashboardCookie,
+		Value:    dashboardToken(keys),
+		Path:     config.Config.WebPrefix,
+		HttpOnly: true,
+		SameSite: http.SameSiteStrictMode,
+	})
+}
+
+// isReadOnlyRequest returns true for requests that can't change anything
+func isReadOnlyRequest(c *gin.Context) bool {
+	return c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead
+}
+
+// requireAPIKey returns a middleware that will reject requests without a valid
+// API key or the dashboard cookie, API keys only give read-only access unless
+// the request is also sent by an authenticated user, the cookie is only
+// accepted together with an authenticated user, it doesn't do anything if
+// there are no API keys configured
+func requireAPIKey(keys map[string]string) gin.HandlerFunc {
+	token := dashboardToken(keys)
+	return func(c *gin.Context) {
+		if len(keys) == 0 {
+			c.Next()
+			return
+		}
+
+		// the cookie is the same for every user, so it's only trusted for
+		// requests from users authenticated using basic auth or by a reverse
+		// proxy
+		if cookie, err := c.Cookie(dashboardCookie); err == nil && getUserName(c) != "" && subtle.ConstantTimeCompare([]byte(cookie), []byte(token)) == 1 {
+			c.Next()
+			return
+		}
+
+		key := c.Request.Header.Get(apiKeyHeader)
+		if key == "" {
+			key = c.Query(apiKeyQueryParam)
+		}
+		if key == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": fmt.Sprintf("missing API key, pass it using %s header or %s query argument", apiKeyHeader, apiKeyQueryParam)})
+			return
+		}
+
+		name, found := keys[key]
+		if !found {
+			log.Warningf("[%s] Invalid API key used for %s %s", c.ClientIP(), c.Request.Method, c.Request.URL.Path)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid API key"})
+			return
+		}
+		if !isReadOnlyRequest(c) && getUserName(c) == "" {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "API keys only allow read-only access, other requests must also be authenticated as a user"})
+			return
+		}
+
+		log.Debugf("[%s] Request authenticated using '%s' API key", c.ClientIP(), name)
+		c.Next()
+	}
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cloudflare/unsee/internal/models"
+	"github.com/cloudflare/unsee/internal/slices"
+	"github.com/cloudflare/unsee/pkg/apiv1"
+
+	"github.com/gin-gonic/gin"
+)
+
+// /api/v1/ endpoints return the same data as legacy endpoints, but responses
+// are converted to frozen types from the apiv1 package, so changes to models
+// don't affect integrations using them
+
+func silenceToV1(silence models.Silence) apiv1.Silence {
+	s := apiv1.Silence{
+		ID:        silence.ID,
+		Matchers:  []apiv1.SilenceMatcher{},
+		StartsAt:  silence.StartsAt,
+		EndsAt:    silence.EndsAt,
+		CreatedAt: silence.CreatedAt,
+		CreatedBy: silence.CreatedBy,
+		Comment:   silence.Comment,
+		JiraID:    silence.JiraID,
+		JiraURL:   silence.JiraURL,
+	}
+	for _, m := range silence.Matchers {
+		s.Matchers = append(s.Matchers, apiv1.SilenceMatcher{Name: m.Name, Value: m.Value, IsRegex: m.IsRegex})
+	}
+	return s
+}
+
+func alertToV1(alert models.Alert) apiv1.Alert {
+	a := apiv1.Alert{
+		Annotations:  []apiv1.Annotation{},
+		Labels:       alert.Labels,
+		StartsAt:     alert.StartsAt,
+		EndsAt:       alert.EndsAt,
+		State:        alert.State,
+		Fingerprint:  alert.Fingerprint,
+		Receiver:     alert.Receiver,
+		Alertmanager: []apiv1.AlertmanagerInstance{},
+	}
+	for _, annotation := range alert.Annotations {
+		a.Annotations = append(a.Annotations, apiv1.Annotation{
+			Name:    annotation.Name,
+			Value:   annotation.Value,
+			Visible: annotation.Visible,
+			IsLink:  annotation.IsLink,
+		})
+	}
+	for _, am := range alert.Alertmanager {
+		instance := apiv1.AlertmanagerInstance{
+			Name:     am.Name,
+			URI:      am.URI,
+			State:    am.State,
+			StartsAt: am.StartsAt,
+			EndsAt:   am.EndsAt,
+			Source:   am.Source,
+			Silences: []apiv1.Silence{},
+		}
+		silenceIDs := []string{}
+		for id := range am.Silences {
+			silenceIDs = append(silenceIDs, id)
+		}
+		sort.Strings(silenceIDs)
+		for _, id := range silenceIDs {
+			instance.Silences = append(instance.Silences, silenceToV1(am.Silences[id]))
+		}
+		a.Alertmanager = append(a.Alertmanager, instance)
+	}
+	return a
+}
+
+func alertGroupToV1(ag models.AlertGroup) apiv1.AlertGroup {
+	g := apiv1.AlertGroup{
+		ID:         ag.ID,
+		Receiver:   ag.Receiver,
+		Labels:     ag.Labels,
+		Alerts:     []apiv1.Alert{},
+		Hash:       ag.Hash,
+		StateCount: map[string]int{},
+	}
+	for _, alert := range ag.Alerts {
+		g.Alerts = append(g.Alerts, alertToV1(alert))
+	}
+	for state, count := range ag.StateCount {
+		g.StateCount[state] = count
+	}
+	return g
+}
+
+// alert groups with alerts matching the optional q argument, json
+func apiV1Alerts(c *gin.Context) {
+	noCache(c)
+	start := time.Now()
+	defer logView(c, start)
+
+	q, _ := expandFilterMacros(c.Query("q"))
+	if q != "" {
+		if err := validateFilterQuery(q); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	countFilterTerms(q)
+
+	groups, err := filterAlertGroups(c.Request.Context(), getTenant(c).alertGroups(), q)
+	if err != nil {
+		// the request timed out or the client went away
+		return
+	}
+
+	resp := apiv1.AlertsResponse{
+		Status:    apiv1.Status,
+		Timestamp: start.UTC(),
+		Version:   version,
+		Groups:    []apiv1.AlertGroup{},
+	}
+	for _, ag := range groups {
+		resp.Groups = append(resp.Groups, alertGroupToV1(ag))
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// a single alert group, json
+func apiV1AlertGroup(c *gin.Context) {
+	noCache(c)
+	start := time.Now()
+	defer logView(c, start)
+
+	for _, ag := range getTenant(c).alertGroups() {
+		if ag.ID == c.Param("id") {
+			c.JSON(http.StatusOK, apiv1.AlertGroupResponse{
+				Status:    apiv1.Status,
+				Timestamp: start.UTC(),
+				Version:   version,
+				Group:     alertGroupToV1(ag),
+			})
+			return
+		}
+	}
+	c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("alert group '%s' not found", c.Param("id"))})
+}
+
+// deduplicated silences, json, accepts the same arguments as silences.json
+func apiV1Silences(c *gin.Context) {
+	noCache(c)
+	start := time.Now()
+	defer logView(c, start)
+
+	state := c.Query("state")
+	if state != "" && !slices.StringInSlice(models.SilenceStateList, state) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid silence state '%s', expected one of: %s", state, strings.Join(models.SilenceStateList, ", "))})
+		return
+	}
+
+	resp := apiv1.SilencesResponse{
+		Status:    apiv1.Status,
+		Timestamp: start.UTC(),
+		Version:   version,
+		Silences:  []apiv1.ManagedSilence{},
+	}
+	for _, silence := range getSilences(getTenant(c), c.Query("author"), c.Query("comment"), state, start) {
+		resp.Silences = append(resp.Silences, apiv1.ManagedSilence{
+			Silence:       silenceToV1(silence.Silence),
+			State:         silence.State,
+			Alertmanagers: silence.Alertmanagers,
+			AlertCount:    silence.AlertCount,
+		})
+	}
+	c.JSON(http.StatusOK, resp)
+}
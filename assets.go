@@ -1,28 +1,52 @@
 package main
 
 import (
+	"bytes"
 	"errors"
 	"html/template"
+	"io/ioutil"
 	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
 	"strings"
+	texttemplate "text/template"
+
+	"github.com/cloudflare/unsee/internal/config"
 
 	assetfs "github.com/elazarl/go-bindata-assetfs"
 	log "github.com/sirupsen/logrus"
 )
 
 type binaryFileSystem struct {
-	fs http.FileSystem
+	fs   http.FileSystem
+	root string
 }
 
+// Open will first try to open the file from ASSETS_PATH if it's set and only
+// fallback to binary assets if it's not found there
 func (b *binaryFileSystem) Open(name string) (http.File, error) {
+	if config.Config.AssetsPath != "" {
+		dir := http.Dir(filepath.Join(config.Config.AssetsPath, filepath.FromSlash(b.root)))
+		if f, err := dir.Open(name); err == nil {
+			// directories are never listed, same as with binary assets
+			if stat, err := f.Stat(); err == nil && !stat.IsDir() {
+				return f, nil
+			}
+			f.Close()
+		}
+	}
 	return b.fs.Open(name)
 }
 
 func (b *binaryFileSystem) Exists(prefix string, filepath string) bool {
 	if p := strings.TrimPrefix(filepath, prefix); len(p) < len(filepath) {
-		if _, err := b.fs.Open(p); err != nil {
+		f, err := b.Open(p)
+		if err != nil {
 			return false
 		}
+		f.Close()
 		return true
 	}
 	return false
@@ -36,17 +60,63 @@ func newBinaryFileSystem(root string) *binaryFileSystem {
 		Prefix:   root,
 	}
 	return &binaryFileSystem{
-		fs,
+		fs:   fs,
+		root: root,
+	}
+}
+
+// readAsset returns the content of the asset file, files from ASSETS_PATH
+// take precedence over binary assets with the same name
+func readAsset(name string) ([]byte, error) {
+	if config.Config.AssetsPath != "" {
+		content, err := ioutil.ReadFile(filepath.Join(config.Config.AssetsPath, filepath.FromSlash(name)))
+		if err == nil {
+			return content, nil
+		}
+		if !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+	return Asset(name)
+}
+
+// assetNames returns sorted names of all binary assets and all files found
+// in ASSETS_PATH
+func assetNames() []string {
+	names := AssetNames()
+	if config.Config.AssetsPath != "" {
+		err := filepath.Walk(config.Config.AssetsPath, func(p string, info os.FileInfo, err error) error {
+			if err != nil || info.IsDir() {
+				return err
+			}
+			rel, err := filepath.Rel(config.Config.AssetsPath, p)
+			if err != nil {
+				return err
+			}
+			name := path.Clean(filepath.ToSlash(rel))
+			for _, n := range names {
+				if n == name {
+					return nil
+				}
+			}
+			names = append(names, name)
+			return nil
+		})
+		if err != nil {
+			log.Errorf("Failed to list files in '%s': %s", config.Config.AssetsPath, err)
+		}
 	}
+	sort.Strings(names)
+	return names
 }
 
-// load all templates from binary asset resource
+// load all templates from binary asset resource and ASSETS_PATH
 // this function will iterate all files with given prefix (e.g. /templates/)
 // and return Template instance with all templates loaded
 func loadTemplates(t *template.Template, prefix string) *template.Template {
-	for _, filename := range AssetNames() {
+	for _, filename := range assetNames() {
 		if strings.HasPrefix(filename, prefix) {
-			templateContent, err := Asset(filename)
+			templateContent, err := readAsset(filename)
 			if err != nil {
 				log.Fatal(err)
 			}
@@ -71,3 +141,39 @@ func loadTemplates(t *template.Template, prefix string) *template.Template {
 	}
 	return t
 }
+
+// client side templates embedded in index.html, those are underscore templates
+// wrapped in script tags that html/template would parse as JavaScript
+var clientTemplateNames = []string{
+	"templates/alertgroup.html",
+	"templates/summary.html",
+	"templates/errors.html",
+	"templates/modal.html",
+	"templates/silence.html",
+	"templates/history.html",
+}
+
+// clientTemplates are set on startup using loadClientTemplates()
+var clientTemplates template.HTML
+
+// loadClientTemplates returns all client side templates rendered using
+// text/template, so they're included in index.html without any escaping,
+// those are static so it only needs to be done once on startup
+func loadClientTemplates() template.HTML {
+	var buf bytes.Buffer
+	for _, name := range clientTemplateNames {
+		content, err := readAsset(name)
+		if err != nil {
+			log.Fatal(err)
+		}
+		t, err := texttemplate.New(name).Parse(string(content))
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err = t.Execute(&buf, nil); err != nil {
+			log.Fatal(err)
+		}
+		buf.WriteString("\n")
+	}
+	return template.HTML(buf.String())
+}
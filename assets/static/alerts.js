@@ -15,10 +15,25 @@ const templates = require("./templates");
 const ui = require("./ui");
 const unsee = require("./unsee");
 
-var labelCache = new LRUMap(1000);
+var labelCache = new LRUMap(1000),
+    labelNames = {},
+    // receivers and their group IDs rendered when grouping by receiver
+    receiverLayout = "";
 
 function AlertGroup(groupData) {
     $.extend(this, groupData);
+    // annotations with the same value on every alert are only sent once per
+    // group, merge those back so every alert is rendered with all annotations
+    var shared = this.sharedAnnotations || [];
+    if (shared.length > 0) {
+        $.each(this.alerts, function(i, alert) {
+            alert.annotations = shared.concat(alert.annotations).sort(function(a, b) {
+                if (a.name < b.name) return -1;
+                if (a.name > b.name) return 1;
+                return 0;
+            });
+        });
+    }
 }
 
 AlertGroup.prototype.Render = function() {
@@ -70,12 +85,23 @@ function sortMapByKey(mapToSort) {
         sorted.push({
             key: key,
             value: mapToSort[key],
-            text: key + ": " + mapToSort[key]
+            text: labelText(key, mapToSort[key])
         });
     });
     return sorted;
 }
 
+// label names displayed in the UI, filters always use original names
+function setLabelNames(names) {
+    labelNames = names || {};
+    labelCache.clear();
+}
+
+function labelText(key, value) {
+    var name = labelNames[key] !== undefined ? labelNames[key] : key;
+    return name + ": " + value;
+}
+
 function getLabelAttrs(key, value) {
     var label = key + ": " + value;
 
@@ -83,7 +109,7 @@ function getLabelAttrs(key, value) {
     if (attrs !== undefined) return attrs;
 
     attrs = {
-        text: label,
+        text: labelText(key, value),
         class: "label label-list " + colors.getClass(key, value),
         style: colors.getStyle(key, value)
     };
@@ -98,7 +124,9 @@ function humanizeTimestamps() {
         var ts = moment($(elem).data("ts"), moment.ISO_8601);
         var label = ts.fromNow();
         $(elem).find(".label-ts-span").text(label);
-        $(elem).attr("data-ts-title", ts.toString());
+        // timestamps formatted by unsee use the configured TIMEZONE instead
+        // of the browser one
+        $(elem).attr("data-ts-title", $(elem).data("ts-display") || ts.toString());
         var tsAge = now.diff(ts, "minutes");
         if (tsAge >= 0 && tsAge < 3) {
             $(elem).addClass("recent-alert").find(".incident-indicator").removeClass("hidden");
@@ -134,8 +162,26 @@ function updateAlerts(apiResponse) {
 
     var dirty = false;
 
+    // when grouping by receiver groups must be rendered in the order of
+    // receivers, so the grid is cleared every time that order changes
+    var byReceiver = apiResponse.grouping === "receiver";
+    var layout = "";
+    if (byReceiver) {
+        layout = $.map(apiResponse.receivers, function(receiver) {
+            return receiver.receiver + ":" + receiver.groups.join(",");
+        }).join(" ");
+    }
+    if (layout !== receiverLayout) {
+        grid.clear();
+        receiverLayout = layout;
+        dirty = true;
+    }
+
     // handle already existing groups
     $.each(grid.items(), function(i, existingGroup) {
+        if ($(existingGroup).hasClass("receiver-header")) {
+            return;
+        }
         var group = groups[existingGroup.id];
         if (group !== undefined && existingGroup.dataset !== undefined) {
             // group still present, check if changed
@@ -161,13 +207,38 @@ function updateAlerts(apiResponse) {
 
     // render new groups
     var content = [];
-    $.each(groups, function(id, group) {
-        content.push(group.Render());
-    });
-    // append new groups in chunks
-    if (content.length > 0) {
-        grid.append($(content.splice(0, 100).join("\n")));
-        dirty = true;
+    if (byReceiver) {
+        $.each(apiResponse.receivers, function(i, receiver) {
+            var header = templates.renderTemplate("receiverHeader", {receiver: receiver});
+            var existingHeader = $(".receiver-header").filter(function() {
+                return $(this).attr("data-receiver") === receiver.receiver;
+            });
+            if (existingHeader.length > 0) {
+                // only counts have changed
+                existingHeader.html($(header).html());
+            } else {
+                content.push(header);
+            }
+            $.each(receiver.groups, function(j, id) {
+                if (groups[id] !== undefined) {
+                    content.push(groups[id].Render());
+                }
+            });
+        });
+        // all groups are appended at once so they stay under their receivers
+        if (content.length > 0) {
+            grid.append($(content.join("\n")));
+            dirty = true;
+        }
+    } else {
+        $.each(groups, function(id, group) {
+            content.push(group.Render());
+        });
+        // append new groups in chunks
+        if (content.length > 0) {
+            grid.append($(content.splice(0, 100).join("\n")));
+            dirty = true;
+        }
     }
 
     // always refresh timestamp labels
@@ -190,3 +261,5 @@ function updateAlerts(apiResponse) {
 exports.updateAlerts = updateAlerts;
 exports.sortMapByKey = sortMapByKey;
 exports.getLabelAttrs = getLabelAttrs;
+exports.setLabelNames = setLabelNames;
+exports.labelText = labelText;
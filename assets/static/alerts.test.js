@@ -22,3 +22,17 @@ test("alerts getLabelAttrs()", () => {
         "text": "@state: unprocessed"
     });
 });
+
+test("alerts labelText()", () => {
+    window.jQuery = require("jquery");
+    const alerts = require("./alerts");
+    alerts.setLabelNames({"kubernetes_namespace": "namespace"});
+    expect(alerts.labelText("kubernetes_namespace", "default")).toBe("namespace: default");
+    expect(alerts.labelText("cluster", "prod")).toBe("cluster: prod");
+    expect(alerts.getLabelAttrs("kubernetes_namespace", "default").text).toBe("namespace: default");
+    expect(alerts.sortMapByKey({"kubernetes_namespace": "default"})).toEqual([
+        {key: "kubernetes_namespace", value: "default", text: "namespace: default"}
+    ]);
+    alerts.setLabelNames({});
+    expect(alerts.labelText("kubernetes_namespace", "default")).toBe("kubernetes_namespace: default");
+});
@@ -9,6 +9,7 @@ var specialLabels = {
     "@state: unprocessed": "label-default",
     "@state: active": "label-danger",
     "@state: suppressed": "label-success",
+    "@flapping: true": "label-warning",
 };
 
 function init(staticColors) {
@@ -124,6 +124,15 @@ function init(params) {
         Selector: "#append-top"
     });
 
+    newOption({
+        Cookie: "groupByReceiver",
+        QueryParam: "groupbyreceiver",
+        Selector: "#group-by-receiver",
+        Action: function() {
+            unsee.triggerReload();
+        }
+    });
+
 }
 
 exports.init = init;
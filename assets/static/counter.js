@@ -10,6 +10,16 @@ var selectors = {
 
 var favicon = false;
 
+// set page title, prefixed with the title configured for this instance
+function setTitle(face) {
+    var title = $("body").data("unsee-title");
+    if (title) {
+        document.title = title + " " + face;
+    } else {
+        document.title = face;
+    }
+}
+
 function hide() {
     $(selectors.counter).hide();
     $(selectors.spinner).children().removeClass("spinner-success spinner-error");
@@ -28,13 +38,13 @@ function setCounter(val) {
     // set alert count css based on the number of alerts
     if (val === 0) {
         $(selectors.counter).removeClass("text-warning text-danger").addClass("text-success");
-        document.title = "(◕‿◕)";
+        setTitle("(◕‿◕)");
     } else if (val < 10) {
         $(selectors.counter).removeClass("text-success text-danger").addClass("text-warning");
-        document.title = "(◕_◕)";
+        setTitle("(◕_◕)");
     } else {
         $(selectors.counter).removeClass("text-success text-warning").addClass("text-danger");
-        document.title = "(◕︵◕)";
+        setTitle("(◕︵◕)");
     }
 }
 
@@ -70,3 +80,4 @@ exports.setCounter = setCounter;
 exports.markError = markError;
 exports.markSuccess = markSuccess;
 exports.markUnknown = markUnknown;
+exports.setTitle = setTitle;
@@ -82,3 +82,16 @@ test("spinner children is green after success", () => {
     expect($("#spinner-child").hasClass("spinner-success")).toBe(true);
     expect($("#spinner-child").hasClass("spinner-error")).toBe(false);
 });
+
+test("page title is prefixed with the configured title", () => {
+    document.body.innerHTML = mockHTML;
+    $("body").data("unsee-title", "PROD EU");
+
+    counter.init();
+    counter.setCounter(0);
+    expect(document.title).toBe("PROD EU (◕‿◕)");
+
+    $("body").removeData("unsee-title");
+    counter.setTitle("(◕ O ◕)");
+    expect(document.title).toBe("(◕ O ◕)");
+});
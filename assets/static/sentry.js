@@ -3,16 +3,64 @@
 const Raven = require("raven-js");
 const $ = require("jquery");
 
+// replace values of labels that shouldn't be sent to sentry in filters
+// (cluster=foo) and maps ("cluster":"foo")
+function scrubLabels(text, labels) {
+    if (!text || labels.length === 0) {
+        return text;
+    }
+    var names = labels.map(function(label) {
+        return label.replace(/[.*+?^${}()|[\]\\]/g, "\\$&");
+    });
+    var re = new RegExp("(\\b(?:" + names.join("|") + ")\"?(?:=~|!~|!=|=|>|<|:)\"?)[^,&\\s\"'\\]}]+", "g");
+    return text.replace(re, "$1[Filtered]");
+}
+
 // init sentry client if sentry dsn is set
 if ($("body").data("raven-dsn")) {
     var dsn = $("body").data("raven-dsn");
+    var labels = String($("body").data("raven-sensitive-labels") || "").split(" ").filter(function(label) {
+        return label !== "";
+    });
+    var options = {
+        release: $("body").data("raven-release") || $("body").data("unsee-version")
+    };
+    if ($("body").data("raven-environment")) {
+        options.environment = $("body").data("raven-environment");
+    }
+    if ($("body").data("raven-sample-rate") !== undefined) {
+        options.sampleRate = parseFloat($("body").data("raven-sample-rate"));
+    }
+    if (labels.length > 0) {
+        options.dataCallback = function(data) {
+            if (data.message) {
+                data.message = scrubLabels(data.message, labels);
+            }
+            if (data.request && data.request.url) {
+                var url = data.request.url;
+                try {
+                    // filters are URL encoded in the query string
+                    url = decodeURIComponent(url);
+                } catch (error) {
+                    // keep the original URL if it can't be decoded
+                }
+                data.request.url = scrubLabels(url, labels);
+            }
+            if (data.exception && data.exception.values) {
+                data.exception.values.forEach(function(exception) {
+                    exception.value = scrubLabels(exception.value, labels);
+                });
+            }
+            return data;
+        };
+    }
     // raven itself can fail if invalid DSN is passed
     try {
-        Raven.config(dsn, {
-            release: $("body").data("unsee-version")
-        }).install();
+        Raven.config(dsn, options).install();
     } catch (error) {
         var msg = "Sentry error: " + error.message;
         $("#raven-error").text(msg).removeClass("hidden");
     }
 }
+
+exports.scrubLabels = scrubLabels;
@@ -5,3 +5,12 @@ test("sentry loaded", () => {
     const Raven = require("raven-js");
     expect(Raven.lastEventId()).toBeNull();
 });
+
+test("sentry scrubLabels", () => {
+    const sentry = require("./sentry");
+    expect(sentry.scrubLabels("?q=pod=foo-123,cluster=dev", ["pod"])).toBe("?q=pod=[Filtered],cluster=dev");
+    expect(sentry.scrubLabels("pod!=foo @state=active", ["pod"])).toBe("pod!=[Filtered] @state=active");
+    expect(sentry.scrubLabels("{\"pod\":\"foo\",\"job\":\"node\"}", ["pod", "job"])).toBe("{\"pod\":\"[Filtered]\",\"job\":\"[Filtered]\"}");
+    expect(sentry.scrubLabels("xpod=foo", ["pod"])).toBe("xpod=foo");
+    expect(sentry.scrubLabels("pod=foo", [])).toBe("pod=foo");
+});
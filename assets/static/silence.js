@@ -99,6 +99,20 @@ function silenceFormJSONRender() {
     $("#silenceJSONBlob").html(d.join(""));
 }
 
+// fill the list of author suggestions with authors of all known silences
+function silenceFormLoadAuthors() {
+    $.ajax({
+        url: "silences/authors.json",
+        success: function(authors) {
+            if (!$.isArray(authors)) return;
+            var datalist = $("#silenceAuthors");
+            $.each(authors, function(i, author) {
+                datalist.append($("<option>").attr("value", author));
+            });
+        }
+    });
+}
+
 function silenceFormUpdateDuration(event) {
     // skip if datetimepicker isn't ready yet
     if (!$("#startsAt").data("DateTimePicker") || !$("#endsAt").data("DateTimePicker")) return false;
@@ -145,11 +159,13 @@ function silenceFormUpdateDuration(event) {
     silenceFormCalculateDuration();
 }
 
-function sendSilencePOST(url, payload) {
-    var elem = $(".silence-post-result[data-uri='" + url + "']");
+// silences are created using unsee silence proxy, so it can check if the user
+// is allowed to create them
+function sendSilencePOST(alertmanager, payload) {
+    var elem = $(".silence-post-result[data-uri='" + alertmanager.uri + "']");
     $.ajax({
         type: "POST",
-        url: alertmanagerSilencesAPIUrl(url),
+        url: "silences/" + encodeURIComponent(alertmanager.name),
         data: JSON.stringify(payload),
         error: function(xhr, textStatus) {
             var err = unsee.parseAJAXError(xhr, textStatus);
@@ -308,14 +324,15 @@ function setupSilenceForm() {
                         templates.renderTemplate("silenceFormResults", {alertmanagers: selectedAMs})
                     );
 
-                    $.each(selectedAMURIs, function(i, uri){
-                        sendSilencePOST(uri, payload);
+                    $.each(selectedAMs, function(i, am){
+                        sendSilencePOST(am, payload);
                     });
 
                     event.preventDefault();
                 });
                 silenceFormCalculateDuration();
                 silenceFormJSONRender();
+                silenceFormLoadAuthors();
             }
         });
 
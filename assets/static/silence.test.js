@@ -136,7 +136,7 @@ test("successful sendSilencePOST()", () => {
     ajaxServer.start();
 
     const silence = require("./silence");
-    silence.sendSilencePOST("http://localhost", {});
+    silence.sendSilencePOST({name: "default", uri: "http://localhost"}, {});
 
     let resultElem = $(".silence-post-result").html().trim();
     expect(resultElem).toMatchSnapshot();
@@ -164,7 +164,7 @@ test("failed sendSilencePOST()", () => {
     ajaxServer.start();
 
     const silence = require("./silence");
-    silence.sendSilencePOST("http://localhost", {});
+    silence.sendSilencePOST({name: "default", uri: "http://localhost"}, {});
 
     let resultElem = $(".silence-post-result").html().trim();
     expect(resultElem).toMatchSnapshot();
@@ -48,6 +48,7 @@ var templates = {},
         alertGroupElements: "#alert-group-elements",
         alertGroupSilence: "#alert-group-silence",
         alertGroupLabelMap: "#alert-group-label-map",
+        receiverHeader: "#receiver-header",
 
         // history dropdown
         historyMenu: "#history-menu",
@@ -79,6 +80,7 @@ function renderTemplate(name, context) {
     context["renderTemplate"] = renderTemplate;
     context["sortMapByKey"] = alerts.sortMapByKey;
     context["getLabelAttrs"] = alerts.getLabelAttrs;
+    context["labelText"] = alerts.labelText;
     var t = templates[name];
     if (t === undefined) {
         console.error("Unknown template " + name);
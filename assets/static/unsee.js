@@ -114,7 +114,7 @@ function renderError(template, context) {
     $(selectors.errors).show();
     counter.markUnknown();
     summary.update({});
-    document.title = "(◕ O ◕)";
+    counter.setTitle("(◕ O ◕)");
     updateCompleted();
 }
 
@@ -156,10 +156,21 @@ function upgrade() {
     }, 3000);
 }
 
+function alertsURL() {
+    // ask for shared annotations to be sent once per group, alerts module
+    // will merge those back into every alert
+    var url = "alerts.json?q=" + filters.getFilters().join(",") + "&annotations=shared";
+    var groupByReceiver = config.getOption("groupbyreceiver");
+    if (groupByReceiver !== undefined && groupByReceiver.Get()) {
+        url += "&grouping=receiver";
+    }
+    return url;
+}
+
 function triggerReload() {
     updateIsReady();
     $.ajax({
-        url: "alerts.json?q=" + filters.getFilters().join(","),
+        url: alertsURL(),
         success: function(resp) {
             counter.markSuccess();
             if (needsUpgrade(resp.version)) {
@@ -177,18 +188,19 @@ function triggerReload() {
                     resume();
                 } else if (resp.upstreams.counters.healthy > 0 ) {
                     // we have some healthy upstreams, check for failed ones
-                    if (resp.upstreams.counters.failed > 0) {
-                        var instances = [];
-                        resp.upstreams.instances.sort(function(a, b){
-                            if(a.name < b.name) return -1;
-                            if(a.name > b.name) return 1;
-                            return 0;
-                        });
-                        $.each(resp.upstreams.instances, function(i, instance){
-                            if (instance.error !== "") {
-                                instances.push(instance);
-                            }
-                        });
+                    // and those only having alerts restored from a snapshot
+                    var instances = [];
+                    resp.upstreams.instances.sort(function(a, b){
+                        if(a.name < b.name) return -1;
+                        if(a.name > b.name) return 1;
+                        return 0;
+                    });
+                    $.each(resp.upstreams.instances, function(i, instance){
+                        if (instance.error !== "" || instance.stale) {
+                            instances.push(instance);
+                        }
+                    });
+                    if (instances.length > 0) {
                         $(selectors.instanceErrors).html(
                             templates.renderTemplate("instanceError", {
                                 instances: instances
@@ -343,6 +355,7 @@ function onReady(localStore) {
         });
 
         colors.init($("#alerts").data("static-color-labels").split(" "));
+        alerts.setLabelNames($("#alerts").data("label-names"));
         templates.init();
         ui.setupModal();
         silence.setupSilenceForm();
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/cloudflare/unsee/internal/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// getUserName returns the name of the authenticated user, it's the user
+// authenticated using AUTH_HTPASSWD credentials or it's taken from the header
+// set by the authenticating reverse proxy, empty string is returned if there's
+// no authenticated user
+func getUserName(c *gin.Context) string {
+	if user := c.GetString(gin.AuthUserKey); user != "" {
+		return user
+	}
+	if config.Config.AuthUserHeader == "" {
+		return ""
+	}
+	return strings.TrimSpace(c.Request.Header.Get(config.Config.AuthUserHeader))
+}
+
+// getUserGroups returns the list of groups the authenticated user belongs to,
+// it's taken from the header set by the authenticating reverse proxy, which
+// should be a comma separated list of group names
+func getUserGroups(c *gin.Context) []string {
+	groups := []string{}
+	if config.Config.AuthGroupsHeader == "" {
+		return groups
+	}
+	for _, group := range strings.Split(c.Request.Header.Get(config.Config.AuthGroupsHeader), ",") {
+		if group = strings.TrimSpace(group); group != "" {
+			groups = append(groups, group)
+		}
+	}
+	return groups
+}
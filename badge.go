@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+	"text/template"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	badgeContentType = "image/svg+xml"
+	// approximate width of a single character rendered with 11px Verdana
+	badgeCharWidth = 7
+	badgePadding   = 10
+
+	badgeColorOK      = "#4c1"
+	badgeColorUnknown = "#dfb317"
+	badgeColorInvalid = "#9f9f9f"
+)
+
+// badgeSeverityColors maps severity label values to badge colors, the color of
+// the first severity with any matching alert is used, badgeColorUnknown is
+// used if none of those severities is present
+var badgeSeverityColors = []struct {
+	severity string
+	color    string
+}{
+	{severity: "critical", color: "#e05d44"},
+	{severity: "error", color: "#e05d44"},
+	{severity: "warning", color: "#fe7d37"},
+	{severity: "info", color: "#007ec6"},
+}
+
+var badgeTemplate = template.Must(template.New("badge").Funcs(template.FuncMap{"html": template.HTMLEscapeString}).Parse(
+	`<svg xmlns="http://www.w3.org/2000/svg" width="{{ .Width }}" height="20" role="img" aria-label="{{ html .Label }}: {{ html .Value }}">` +
+		`<title>{{ html .Label }}: {{ html .Value }}</title>` +
+		`<linearGradient id="s" x2="0" y2="100%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient>` +
+		`<clipPath id="r"><rect width="{{ .Width }}" height="20" rx="3" fill="#fff"/></clipPath>` +
+		`<g clip-path="url(#r)"><rect width="{{ .LabelWidth }}" height="20" fill="#555"/><rect x="{{ .LabelWidth }}" width="{{ .ValueWidth }}" height="20" fill="{{ .Color }}"/><rect width="{{ .Width }}" height="20" fill="url(#s)"/></g>` +
+		`<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">` +
+		`<text x="{{ .LabelX }}" y="14">{{ html .Label }}</text><text x="{{ .ValueX }}" y="14">{{ html .Value }}</text></g></svg>`,
+))
+
+type badgeData struct {
+	Label      string
+	Value      string
+	Color      string
+	Width      int
+	LabelWidth int
+	ValueWidth int
+	LabelX     int
+	ValueX     int
+}
+
+// renderBadge returns a shields style SVG badge with given label and value
+func renderBadge(label, value, color string) ([]byte, error) {
+	bd := badgeData{
+		Label:      label,
+		Value:      value,
+		Color:      color,
+		LabelWidth: len([]rune(label))*badgeCharWidth + badgePadding,
+		ValueWidth: len([]rune(value))*badgeCharWidth + badgePadding,
+	}
+	bd.Width = bd.LabelWidth + bd.ValueWidth
+	bd.LabelX = bd.LabelWidth / 2
+	bd.ValueX = bd.LabelWidth + bd.ValueWidth/2
+
+	var buf bytes.Buffer
+	if err := badgeTemplate.Execute(&buf, bd); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// badgeColor returns the color of the badge for alerts counted by severity
+func badgeColor(total int, severities map[string]int) string {
+	if total == 0 {
+		return badgeColorOK
+	}
+	for _, sc := range badgeSeverityColors {
+		if severities[sc.severity] > 0 {
+			return sc.color
+		}
+	}
+	return badgeColorUnknown
+}
+
+// SVG badge with the number of alerts matching the q argument, it can be
+// embedded in wiki pages, label argument sets the text on the left side
+func badge(c *gin.Context) {
+	noCache(c)
+	start := time.Now()
+	defer logView(c, start)
+
+	label := c.DefaultQuery("label", "alerts")
+	status := http.StatusOK
+	var value, color string
+
+	var err error
+	q, _ := expandFilterMacros(c.Query("q"))
+	if q != "" {
+		err = validateFilterQuery(q)
+	}
+	if err != nil {
+		// render an error badge so embedded images don't look broken
+		status = http.StatusBadRequest
+		value = "invalid filter"
+		color = badgeColorInvalid
+	} else {
+		summary := getAlertsSummary(getTenant(c), q, start)
+		value = strconv.Itoa(summary.Total)
+		color = badgeColor(summary.Total, summary.Severities)
+	}
+
+	data, err := renderBadge(label, value, color)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Data(status, badgeContentType, data)
+}
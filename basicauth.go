@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/cloudflare/unsee/internal/config"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// htpasswdSHAPrefix is the prefix of SHA1 hashes generated by htpasswd -s
+const htpasswdSHAPrefix = "{SHA}"
+
+// getHtpasswdUsers reads users from AUTH_HTPASSWD file
+func getHtpasswdUsers() (map[string]string, error) {
+	return readHtpasswd("AUTH_HTPASSWD", config.Config.AuthHtpasswd)
+}
+
+// getMetricsHtpasswdUsers reads users from METRICS_AUTH_HTPASSWD file
+func getMetricsHtpasswdUsers() (map[string]string, error) {
+	return readHtpasswd("METRICS_AUTH_HTPASSWD", config.Config.MetricsAuthHtpasswd)
+}
+
+// readHtpasswd reads users from the htpasswd file set using given option,
+// returned map is keyed by the user name with the password hash as the value,
+// only bcrypt and SHA1 hashes are supported
+func readHtpasswd(option, path string) (map[string]string, error) {
+	users := map[string]string{}
+	if path == "" {
+		return users, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s file: %s", option, err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	line := 0
+	for scanner.Scan() {
+		line++
+		s := strings.TrimSpace(scanner.Text())
+		if s == "" || strings.HasPrefix(s, "#") {
+			continue
+		}
+		z := strings.SplitN(s, ":", 2)
+		if len(z) != 2 || z[0] == "" || z[1] == "" {
+			return nil, fmt.Errorf("invalid %s entry at line %d, expected format 'user:hash'", option, line)
+		}
+		if _, found := users[z[0]]; found {
+			return nil, fmt.Errorf("duplicated %s user '%s'", option, z[0])
+		}
+		if !strings.HasPrefix(z[1], htpasswdSHAPrefix) {
+			if _, err := bcrypt.Cost([]byte(z[1])); err != nil {
+				return nil, fmt.Errorf("unsupported password hash for %s user '%s', only bcrypt and SHA1 hashes are supported", option, z[0])
+			}
+		}
+		users[z[0]] = z[1]
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s file: %s", option, err)
+	}
+	if len(users) == 0 {
+		return nil, fmt.Errorf("%s file doesn't have any users", option)
+	}
+	return users, nil
+}
+
+// checkPassword returns true if the password matches the htpasswd hash
+func checkPassword(hash, password string) bool {
+	if strings.HasPrefix(hash, htpasswdSHAPrefix) {
+		sum := sha1.Sum([]byte(password))
+		expected := htpasswdSHAPrefix + base64.StdEncoding.EncodeToString(sum[:])
+		return subtle.ConstantTimeCompare([]byte(hash), []byte(expected)) == 1
+	}
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
+
+// requireBasicAuth returns a middleware that will reject requests without
+// credentials of any user from the htpasswd file, health checks don't require
+// any credentials, it doesn't do anything if there are no users configured
+// Admin endpoints are skipped if they have their own METRICS_AUTH_HTPASSWD
+// users, since a request can't pass credentials for both
+func requireBasicAuth(users map[string]string) gin.HandlerFunc {
+	separateAdmin := config.Config.MetricsAuthHtpasswd != ""
+	return basicAuth(users, "unsee", func(p string) bool {
+		return isHealthPath(p) || (separateAdmin && isAdminPath(p))
+	})
+}
+
+// requireMetricsAuth returns a middleware that will reject requests for
+// metrics, debug and admin endpoints without credentials of any user from the
+// METRICS_AUTH_HTPASSWD file, it doesn't do anything if there are no users
+// configured
+func requireMetricsAuth(users map[string]string) gin.HandlerFunc {
+	return basicAuth(users, "unsee metrics", func(p string) bool {
+		return !isAdminPath(p)
+	})
+}
+
+// basicAuth returns a middleware checking credentials of requests, except for
+// those with a path that should be skipped
+func basicAuth(users map[string]string, realm string, skip func(string) bool) gin.HandlerFunc {
+	// bcrypt is slow by design and the UI sends many requests, so remember
+	// credentials that were already checked
+	verified := sync.Map{}
+	return func(c *gin.Context) {
+		if len(users) == 0 || skip(c.Request.URL.Path) {
+			c.Next()
+			return
+		}
+
+		user, password, ok := c.Request.BasicAuth()
+		if ok {
+			if hash, found := users[user]; found {
+				key := sha256.Sum256([]byte(hash + ":" + password))
+				if _, seen := verified.Load(key); seen {
+					c.Set(gin.AuthUserKey, user)
+					c.Next()
+					return
+				}
+				if checkPassword(hash, password) {
+					verified.Store(key, true)
+					c.Set(gin.AuthUserKey, user)
+					c.Next()
+					return
+				}
+			}
+			log.Warningf("[%s] Invalid credentials for user '%s' used for %s %s", c.ClientIP(), user, c.Request.Method, c.Request.URL.Path)
+		}
+
+		c.Header("WWW-Authenticate", fmt.Sprintf(`Basic realm="%s", charset="UTF-8"`, realm))
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "authentication required"})
+	}
+}
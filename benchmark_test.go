@@ -0,0 +1,50 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cloudflare/unsee/internal/mock"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+func benchmarkAlerts(b *testing.B, uri string, accept string) {
+	mockConfig()
+	mockAlerts(mock.ListAllMocks()[len(mock.ListAllMocks())-1])
+	r := ginTestEngine()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		// measure the full path rather than responses served from cache, but
+		// keep encoded alert groups as those are reused until next collection
+		apiCache.Delete(uri)
+		apiCache.Delete(accept + ":" + uri)
+		req, _ := http.NewRequest("GET", uri, nil)
+		req.RequestURI = uri
+		req.Header.Set("Accept", accept)
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+		if resp.Code != http.StatusOK {
+			b.Errorf("GET %s returned status %d", uri, resp.Code)
+		}
+	}
+}
+
+func BenchmarkAlerts(b *testing.B) {
+	benchmarkAlerts(b, "/alerts.json?q=", gin.MIMEJSON)
+}
+
+func BenchmarkAlertsFiltered(b *testing.B) {
+	benchmarkAlerts(b, "/alerts.json?q=alertname=Host_Down&q=@state=active", gin.MIMEJSON)
+}
+
+func BenchmarkAlertsMsgpack(b *testing.B) {
+	benchmarkAlerts(b, "/alerts.json?q=", binding.MIMEMSGPACK2)
+}
+
+func BenchmarkAlertsPaginated(b *testing.B) {
+	benchmarkAlerts(b, "/alerts.json?q=&limit=2", gin.MIMEJSON)
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"runtime"
+
+	"github.com/cloudflare/unsee/internal/models"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func getVersionInfo() models.VersionInfo {
+	return models.VersionInfo{
+		Version:   version,
+		Commit:    commit,
+		BuildDate: buildDate,
+		GoVersion: runtime.Version(),
+	}
+}
+
+func init() {
+	info := getVersionInfo()
+	buildInfo := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "unsee_build_info",
+			Help: "A metric with a constant '1' value labeled by version, commit, build date and Go version unsee was built with",
+		},
+		[]string{"version", "commit", "build_date", "goversion"},
+	)
+	buildInfo.WithLabelValues(info.Version, info.Commit, info.BuildDate, info.GoVersion).Set(1)
+	prometheus.MustRegister(buildInfo)
+}
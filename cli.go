@@ -0,0 +1,147 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/cloudflare/unsee/internal/config"
+)
+
+// command is a subcommand of the unsee binary
+type command struct {
+	name string
+	help string
+	run  func(args []string) int
+}
+
+// serveCommand is used when no command is passed, so flags can still be used
+// without any command
+const serveCommand = "serve"
+
+var commands = []command{
+	command{
+		name: serveCommand,
+		help: "Run the unsee server, this is the default command",
+		run: func(args []string) int {
+			serve(args)
+			return 0
+		},
+	},
+	command{
+		name: "version",
+		help: "Print version and build details",
+		run: func(args []string) int {
+			printVersion(os.Stdout)
+			return 0
+		},
+	},
+	command{
+		name: "silence",
+		help: "Manage silences using the API of a running unsee instance, run 'silence' for details",
+		run:  runSilence,
+	},
+	command{
+		name: "encrypt",
+		help: "Encrypt an option value read from stdin, so it can be stored in config files",
+		run: func(args []string) int {
+			if err := encryptValue(args, os.Stdin, os.Stdout); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+				return 1
+			}
+			return 0
+		},
+	},
+	command{
+		name: "mock-alertmanager",
+		help: "Run a mock Alertmanager serving synthetic alerts and silences, for load testing and demos",
+		run:  runMockAlertmanager,
+	},
+	command{
+		name: "check-config",
+		help: "Validate configuration and exit, exit code is non-zero if it's invalid",
+		run: func(args []string) int {
+			config.Config.ReadArgs(args)
+			if err := validateConfig(); err != nil {
+				fmt.Fprintf(os.Stderr, "Invalid configuration: %s\n", err)
+				return 1
+			}
+			fmt.Fprintln(os.Stdout, "Configuration is valid")
+			return 0
+		},
+	},
+}
+
+// encryptValue reads a plain text option value from r and writes the
+// encrypted value to w, the key is passed using the same flags or environment
+// variables that are used to decrypt values when running unsee
+func encryptValue(args []string, r io.Reader, w io.Writer) error {
+	flags := flag.NewFlagSet("encrypt", flag.ExitOnError)
+	key := flags.String("config.key", os.Getenv("CONFIG_KEY"), "Base64 encoded 32 byte key, defaults to CONFIG_KEY environment variable")
+	keyFile := flags.String("config.key.file", os.Getenv("CONFIG_KEY_FILE"), "Path to a file with the key, defaults to CONFIG_KEY_FILE environment variable")
+	flags.Parse(args)
+
+	k, err := config.ReadKey(*key, *keyFile)
+	if err != nil {
+		return err
+	}
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	value := strings.TrimRight(string(data), "\r\n")
+	if value == "" {
+		return fmt.Errorf("value to encrypt must be passed on stdin")
+	}
+	encrypted, err := config.EncryptValue(k, value)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(w, encrypted)
+	return nil
+}
+
+// parseCommand returns the command to run and arguments that should be
+// passed to it
+func parseCommand(args []string) (string, []string) {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return serveCommand, args
+	}
+	return args[0], args[1:]
+}
+
+func printVersion(w io.Writer) {
+	info := getVersionInfo()
+	fmt.Fprintf(w, "unsee %s (commit: %s, built: %s, %s)\n", info.Version, info.Commit, info.BuildDate, info.GoVersion)
+}
+
+func printCommands(w io.Writer) {
+	fmt.Fprintf(w, "Usage: %s [command] [flags]\n\nCommands:\n", os.Args[0])
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-18s %s\n", cmd.name, cmd.help)
+	}
+}
+
+func main() {
+	// flags are only registered once the config is read, so this is only
+	// called for serve and check-config commands
+	flag.Usage = func() {
+		printCommands(os.Stderr)
+		fmt.Fprintf(os.Stderr, "\nFlags for %s and check-config commands:\n", serveCommand)
+		flag.PrintDefaults()
+	}
+
+	name, args := parseCommand(os.Args[1:])
+	for _, cmd := range commands {
+		if cmd.name == name {
+			os.Exit(cmd.run(args))
+		}
+	}
+	fmt.Fprintf(os.Stderr, "Unknown command '%s'\n\n", name)
+	printCommands(os.Stderr)
+	fmt.Fprintf(os.Stderr, "\nRun '%s %s -h' to list all flags\n", os.Args[0], serveCommand)
+	os.Exit(2)
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+)
+
+// bufferedWriter will hold the response body in memory so we can decide if it
+// should be compressed once the full size is known
+type bufferedWriter struct {
+	gin.ResponseWriter
+	buffer bytes.Buffer
+}
+
+func (w *bufferedWriter) Write(data []byte) (int, error) {
+	return w.buffer.Write(data)
+}
+
+func (w *bufferedWriter) WriteString(s string) (int, error) {
+	return w.buffer.WriteString(s)
+}
+
+// WriteHeaderNow is delayed until the body is written after all handlers are
+// done, otherwise headers would be sent before we can set Content-Encoding
+func (w *bufferedWriter) WriteHeaderNow() {}
+
+// acceptedEncoding returns the best compression method supported by the
+// client, or an empty string if it doesn't accept any of them
+func acceptedEncoding(header string, brotliEnabled bool) string {
+	encodings := map[string]bool{}
+	for _, enc := range strings.Split(header, ",") {
+		parts := strings.Split(enc, ";")
+		accepted := true
+		for _, param := range parts[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if q, err := strconv.ParseFloat(param[2:], 64); err == nil && q <= 0 {
+					accepted = false
+				}
+			}
+		}
+		encodings[strings.TrimSpace(parts[0])] = accepted
+	}
+	if brotliEnabled && encodings["br"] {
+		return "br"
+	}
+	if encodings["gzip"] {
+		return "gzip"
+	}
+	return ""
+}
+
+// compressibleStatus returns false for responses that can't have a body and
+// for partial content, which needs to be sent as is
+func compressibleStatus(status int) bool {
+	switch {
+	case status < http.StatusOK:
+		return false
+	case status == http.StatusNoContent, status == http.StatusPartialContent, status == http.StatusNotModified:
+		return false
+	}
+	return true
+}
+
+// compressResponse returns a middleware that will compress all responses
+// larger than minSize using gzip or brotli if enabled and the client
+// supports it
+func compressResponse(minSize int, brotliEnabled bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		encoding := acceptedEncoding(c.Request.Header.Get("Accept-Encoding"), brotliEnabled)
+		if encoding == "" {
+			c.Next()
+			return
+		}
+
+		writer := &bufferedWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+		c.Writer = writer.ResponseWriter
+
+		header := c.Writer.Header()
+		if writer.buffer.Len() < minSize || !compressibleStatus(writer.Status()) || header.Get("Content-Encoding") != "" {
+			if writer.buffer.Len() > 0 {
+				c.Writer.Write(writer.buffer.Bytes())
+			}
+			return
+		}
+
+		var compressed bytes.Buffer
+		var cw io.WriteCloser
+		if encoding == "br" {
+			cw = brotli.NewWriterLevel(&compressed, brotli.DefaultCompression)
+		} else {
+			cw, _ = gzip.NewWriterLevel(&compressed, gzip.DefaultCompression)
+		}
+		cw.Write(writer.buffer.Bytes())
+		cw.Close()
+
+		header.Set("Content-Encoding", encoding)
+		header.Add("Vary", "Accept-Encoding")
+		header.Set("Content-Length", strconv.Itoa(compressed.Len()))
+		c.Writer.Write(compressed.Bytes())
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/cloudflare/unsee/internal/slices"
+
+	"github.com/gin-gonic/gin"
+)
+
+// corsHeaders returns a middleware that will set CORS headers for requests
+// from allowed origins, preflight requests are answered directly
+func corsHeaders(origins []string, methods []string, credentials bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		origin := c.Request.Header.Get("Origin")
+		if origin == "" || len(origins) == 0 || !(slices.StringInSlice(origins, "*") || slices.StringInSlice(origins, origin)) {
+			c.Next()
+			return
+		}
+
+		header := c.Writer.Header()
+		// wildcard origin can't be used with credentials, validateConfig()
+		// rejects such config, so there's no need to reflect the origin
+		if slices.StringInSlice(origins, "*") {
+			header.Set("Access-Control-Allow-Origin", "*")
+		} else {
+			header.Set("Access-Control-Allow-Origin", origin)
+			header.Add("Vary", "Origin")
+		}
+		if credentials {
+			header.Set("Access-Control-Allow-Credentials", "true")
+		}
+		header.Set("Access-Control-Expose-Headers", "ETag")
+
+		if c.Request.Method == http.MethodOptions && c.Request.Header.Get("Access-Control-Request-Method") != "" {
+			header.Set("Access-Control-Allow-Methods", strings.Join(methods, ", "))
+			if requestHeaders := c.Request.Header.Get("Access-Control-Request-Headers"); requestHeaders != "" {
+				header.Set("Access-Control-Allow-Headers", requestHeaders)
+			}
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+
+		c.Next()
+	}
+}
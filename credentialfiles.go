@@ -0,0 +1,205 @@
+package main
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/cloudflare/unsee/internal/alertmanager"
+	"github.com/cloudflare/unsee/internal/config"
+	"github.com/cloudflare/unsee/internal/transport"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// types of credentials files that can be used for Alertmanager upstreams
+const (
+	credentialsFileToken    = "token"
+	credentialsFilePassword = "password"
+	credentialsFileCert     = "cert"
+)
+
+// credentialsFile is a file with credentials of an Alertmanager upstream, cert
+// files have paths of the certificate and the key, which can be the same file
+type credentialsFile struct {
+	upstream string
+	kind     string
+	paths    []string
+}
+
+var credentialsFiles = struct {
+	sync.Mutex
+	files []credentialsFile
+	// content of every file when it was last loaded, keyed by the path
+	content map[string][]byte
+}{content: map[string][]byte{}}
+
+// getCredentialsFiles returns files from ALERTMANAGER_CREDENTIALS_FILES, every
+// upstream can have either a token or a password file, and a cert file
+func getCredentialsFiles() ([]credentialsFile, error) {
+	uris := map[string]string{}
+	for _, s := range config.Config.AlertmanagerURIs {
+		z := strings.SplitN(s, ":", 2)
+		if len(z) == 2 {
+			uris[z[0]] = z[1]
+		}
+	}
+	vaultSecrets := map[string]bool{}
+	for _, s := range config.Config.VaultSecrets {
+		vaultSecrets[strings.SplitN(s, ":", 2)[0]] = true
+	}
+
+	files := []credentialsFile{}
+	auth := map[string]string{}
+	certs := map[string]bool{}
+	for _, s := range config.Config.AlertmanagerCredentialsFiles {
+		if s == "" {
+			continue
+		}
+		z := strings.SplitN(s, ":", 3)
+		if len(z) != 3 || z[0] == "" || z[1] == "" || z[2] == "" {
+			return nil, fmt.Errorf("Invalid Alertmanager credentials file '%s', expected format 'name:type:path'", s)
+		}
+		f := credentialsFile{upstream: z[0], kind: z[1], paths: []string{z[2]}}
+		uri, found := uris[f.upstream]
+		if !found {
+			return nil, fmt.Errorf("Invalid Alertmanager credentials file '%s', there's no Alertmanager upstream named '%s'", s, f.upstream)
+		}
+		u, err := url.Parse(uri)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid Alertmanager URI '%s': %s", uri, err)
+		}
+		switch f.kind {
+		case credentialsFileToken, credentialsFilePassword:
+			if kind, found := auth[f.upstream]; found {
+				return nil, fmt.Errorf("Alertmanager upstream '%s' already has a %s file, only one token or password file can be used", f.upstream, kind)
+			}
+			if vaultSecrets[f.upstream] {
+				return nil, fmt.Errorf("Alertmanager upstream '%s' credentials are read from Vault, %s file can't be used", f.upstream, f.kind)
+			}
+			_, hasPassword := u.User.Password()
+			if f.kind == credentialsFileToken && u.User != nil {
+				return nil, fmt.Errorf("Alertmanager upstream '%s' URI already includes credentials, token file can't be used", f.upstream)
+			}
+			if f.kind == credentialsFilePassword && (u.User == nil || u.User.Username() == "" || hasPassword) {
+				return nil, fmt.Errorf("Alertmanager upstream '%s' URI must include a user name without a password to use a password file", f.upstream)
+			}
+			auth[f.upstream] = f.kind
+		case credentialsFileCert:
+			if certs[f.upstream] {
+				return nil, fmt.Errorf("Duplicated Alertmanager cert file for '%s'", f.upstream)
+			}
+			if u.Scheme != "https" {
+				return nil, fmt.Errorf("Alertmanager upstream '%s' doesn't use HTTPS, cert file can't be used", f.upstream)
+			}
+			// cert:cert.pem,key.pem or a single file with both
+			f.paths = strings.SplitN(z[2], ",", 2)
+			certs[f.upstream] = true
+		default:
+			return nil, fmt.Errorf("Invalid Alertmanager credentials file '%s', unsupported type '%s', supported types: %s, %s, %s", s, f.kind, credentialsFileToken, credentialsFilePassword, credentialsFileCert)
+		}
+		files = append(files, f)
+	}
+	return files, nil
+}
+
+// loadCredentialsFile reads the file and uses the credentials for all requests
+// sent to the upstream, it returns true if the file changed since it was last
+// loaded
+func loadCredentialsFile(am *alertmanager.Alertmanager, f credentialsFile) (bool, error) {
+	var content []byte
+	for _, path := range f.paths {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return false, err
+		}
+		content = append(content, data...)
+	}
+	key := strings.Join(f.paths, ",")
+	if bytes.Equal(credentialsFiles.content[key], content) {
+		return false, nil
+	}
+
+	switch f.kind {
+	case credentialsFileToken, credentialsFilePassword:
+		value := strings.TrimSpace(string(content))
+		if value == "" {
+			return false, fmt.Errorf("%s file '%s' is empty", f.kind, f.paths[0])
+		}
+		c := transport.Credentials{Token: value}
+		if f.kind == credentialsFilePassword {
+			u, _ := url.Parse(am.URI)
+			c = transport.Credentials{Username: u.User.Username(), Password: value}
+		}
+		transport.SetCredentials(am.URI, c)
+	case credentialsFileCert:
+		keyPath := f.paths[0]
+		if len(f.paths) > 1 {
+			keyPath = f.paths[1]
+		}
+		cert, err := tls.LoadX509KeyPair(f.paths[0], keyPath)
+		if err != nil {
+			return false, err
+		}
+		transport.SetClientCertificate(am.URI, cert)
+	}
+	changed := credentialsFiles.content[key] != nil
+	credentialsFiles.content[key] = content
+	return changed, nil
+}
+
+// refreshCredentialsFiles reads all credentials files again, so rotated
+// credentials are used without a restart, last valid credentials are used if
+// a file can't be read
+func refreshCredentialsFiles() {
+	credentialsFiles.Lock()
+	defer credentialsFiles.Unlock()
+	for _, f := range credentialsFiles.files {
+		am := alertmanager.GetAlertmanagerByName(f.upstream)
+		changed, err := loadCredentialsFile(am, f)
+		if err != nil {
+			log.Errorf("[%s] Failed to reload %s file: %s", f.upstream, f.kind, err)
+			continue
+		}
+		if changed {
+			log.Infof("[%s] Loaded rotated %s file '%s'", f.upstream, f.kind, f.paths[0])
+		}
+	}
+}
+
+// setupCredentialsFiles loads credentials files of Alertmanager upstreams,
+// upstreams can't be collected without those, so all files must be valid on
+// startup, they are reloaded on every collection and on SIGHUP after that
+func setupCredentialsFiles() error {
+	files, err := getCredentialsFiles()
+	if err != nil || len(files) == 0 {
+		return err
+	}
+	credentialsFiles.Lock()
+	defer credentialsFiles.Unlock()
+	for _, f := range files {
+		am := alertmanager.GetAlertmanagerByName(f.upstream)
+		if _, err := loadCredentialsFile(am, f); err != nil {
+			return fmt.Errorf("Failed to load %s file of Alertmanager '%s': %s", f.kind, f.upstream, err)
+		}
+		log.Infof("[%s] Using %s file '%s'", f.upstream, f.kind, f.paths[0])
+	}
+	credentialsFiles.files = files
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGHUP)
+	go func() {
+		for range sig {
+			log.Info("Received SIGHUP, reloading credentials files")
+			refreshCredentialsFiles()
+		}
+	}()
+	return nil
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/cloudflare/unsee/internal/alertmanager"
+	"github.com/cloudflare/unsee/internal/config"
+	"github.com/cloudflare/unsee/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// debugStateResponse is returned by /debug/state
+type debugStateResponse struct {
+	Version   models.VersionInfo           `json:"version"`
+	Time      time.Time                    `json:"time"`
+	Upstreams []alertmanager.UpstreamState `json:"upstreams"`
+	Config    map[string]string            `json:"config"`
+}
+
+// internal state of all Alertmanager upstreams and the config, json, it's
+// meant to be attached to bug reports, so secrets are hidden, upstream state
+// isn't filtered by tenant filters so users restricted by those can't use it
+func debugState(c *gin.Context) {
+	noCache(c)
+	start := time.Now()
+	defer logView(c, start)
+
+	if getTenant(c).restricted {
+		c.JSON(http.StatusForbidden, gin.H{"error": "debug state isn't available to users restricted by tenant filters"})
+		return
+	}
+
+	resp := debugStateResponse{
+		Version:   getVersionInfo(),
+		Time:      start,
+		Upstreams: []alertmanager.UpstreamState{},
+		Config:    config.Config.Values(),
+	}
+	for _, am := range alertmanager.GetAlertmanagers() {
+		state := am.State()
+		state.URI = config.HideURLPassword(state.URI)
+		resp.Upstreams = append(resp.Upstreams, state)
+	}
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// readOnly returns a middleware that will reject every request that could
+// change anything, like creating silences or saving filters, so the demo
+// mode dashboard can be shared without worrying about it, it doesn't do
+// anything if enabled is false
+func readOnly(enabled bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+		default:
+			if !enabled {
+				c.Next()
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "unsee is running in read-only demo mode"})
+		}
+	}
+}
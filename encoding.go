@@ -0,0 +1,176 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/cloudflare/unsee/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// encodeBuffers keeps buffers used to encode API responses that are written
+// directly to the client, so concurrent requests can reuse memory already
+// allocated for large responses instead of growing a new buffer every time
+var encodeBuffers = sync.Pool{
+	New: func() interface{} {
+		return &bytes.Buffer{}
+	},
+}
+
+// encodeWithBuffer passes a new buffer to the encode function and returns the
+// encoded data without copying it, encoded data is usually kept in the API
+// cache, so it can't use pooled buffers, the cache would keep all the memory
+// those have grown to
+func encodeWithBuffer(encode func(*bytes.Buffer) error) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	if err := encode(buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeWithBuffer passes a pooled buffer to the encode function and writes the
+// encoded data to the response, the buffer is only returned to the pool after
+// that, so the data doesn't need to be copied
+func writeWithBuffer(c *gin.Context, contentType string, encode func(*bytes.Buffer) error) error {
+	buf := encodeBuffers.Get().(*bytes.Buffer)
+	buf.Reset()
+	defer encodeBuffers.Put(buf)
+
+	if err := encode(buf); err != nil {
+		return err
+	}
+	c.Data(http.StatusOK, contentType, buf.Bytes())
+	return nil
+}
+
+// responseFormat returns the content type that should be used for the
+// response based on the format argument or the Accept header if it's not set,
+// JSON is used by default
+func responseFormat(c *gin.Context) (string, error) {
+	switch c.Query("format") {
+	case "":
+	case "json":
+		return gin.MIMEJSON, nil
+	case "msgpack":
+		return binding.MIMEMSGPACK2, nil
+	case "yaml":
+		return mimeYAML, nil
+	default:
+		return "", fmt.Errorf("Invalid format '%s', supported formats: json, msgpack, yaml", c.Query("format"))
+	}
+	switch c.NegotiateFormat(gin.MIMEJSON, binding.MIMEMSGPACK2, binding.MIMEMSGPACK, mimeYAML, mimeXYAML) {
+	case binding.MIMEMSGPACK2, binding.MIMEMSGPACK:
+		return binding.MIMEMSGPACK2, nil
+	case mimeYAML, mimeXYAML:
+		return mimeYAML, nil
+	default:
+		return gin.MIMEJSON, nil
+	}
+}
+
+// encodeResponse returns value encoded using given format
+func encodeResponse(format string, v interface{}) ([]byte, error) {
+	return encodeWithBuffer(func(buf *bytes.Buffer) error {
+		return writeResponse(buf, format, v)
+	})
+}
+
+// writeResponse writes value encoded using given format to the buffer
+func writeResponse(buf *bytes.Buffer, format string, v interface{}) error {
+	switch format {
+	case binding.MIMEMSGPACK2:
+		return writeMsgpack(buf, v)
+	case mimeYAML:
+		return writeYAML(buf, v)
+	default:
+		return writeJSON(buf, v)
+	}
+}
+
+// renderResponse writes value encoded using the format requested by the
+// client, errors are always returned as JSON
+func renderResponse(c *gin.Context, v interface{}) {
+	c.Writer.Header().Add("Vary", "Accept")
+	format, err := responseFormat(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	err = writeWithBuffer(c, format, func(buf *bytes.Buffer) error {
+		return writeResponse(buf, format, v)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// encodeJSON returns JSON encoded value, output is the same as json.Marshal
+func encodeJSON(v interface{}) ([]byte, error) {
+	return encodeWithBuffer(func(buf *bytes.Buffer) error {
+		return writeJSON(buf, v)
+	})
+}
+
+// writeJSON writes JSON encoded value to the buffer, output is the same as
+// json.Marshal
+func writeJSON(buf *bytes.Buffer, v interface{}) error {
+	if err := json.NewEncoder(buf).Encode(v); err != nil {
+		return err
+	}
+	// Encode() always adds a newline
+	buf.Truncate(buf.Len() - 1)
+	return nil
+}
+
+// alertsResponseJSON is used to encode alerts responses using alert groups
+// that were already encoded, AlertGroups hides the field with the same name
+// from the embedded response
+type alertsResponseJSON struct {
+	models.AlertsResponse
+	AlertGroups []json.RawMessage `json:"groups"`
+}
+
+// encodeAlertGroup returns JSON encoded alert group, encoded groups are kept
+// in the API cache and reused by all requests until the next collection
+// flushes it, so the cache key must cover everything that can make two copies
+// of a group differ within a single collection:
+//   - ID and hash, the hash changes if filters removed any alert
+//   - number of alerts, alertsPerGroup and ALERTS_PER_GROUP limit those
+//   - collapse hint, it depends on the request
+//   - annotations mode, shared annotations are moved out of alerts
+func encodeAlertGroup(ag models.AlertGroup, annotations string) (json.RawMessage, error) {
+	key := fmt.Sprintf("group:%s:%s:%d:%t:%s", ag.ID, ag.Hash, len(ag.Alerts), ag.Collapse, annotations)
+	if data, found := apiCache.Get(key); found {
+		return data.(json.RawMessage), nil
+	}
+	data, err := encodeJSON(ag)
+	if err != nil {
+		return nil, err
+	}
+	apiCache.Set(key, json.RawMessage(data), -1)
+	return data, nil
+}
+
+// encodeAlertsResponse returns JSON encoded alerts response, annotations is the
+// mode that was used to split group annotations
+func encodeAlertsResponse(resp models.AlertsResponse, annotations string) ([]byte, error) {
+	r := alertsResponseJSON{AlertsResponse: resp}
+	if resp.AlertGroups != nil {
+		r.AlertGroups = make([]json.RawMessage, 0, len(resp.AlertGroups))
+	}
+	for _, ag := range resp.AlertGroups {
+		data, err := encodeAlertGroup(ag, annotations)
+		if err != nil {
+			return nil, err
+		}
+		r.AlertGroups = append(r.AlertGroups, data)
+	}
+	r.AlertsResponse.AlertGroups = nil
+	return encodeJSON(r)
+}
@@ -0,0 +1,295 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cloudflare/unsee/api"
+	"github.com/cloudflare/unsee/internal/alertmanager"
+	"github.com/cloudflare/unsee/internal/models"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// grpcServer implements the gRPC API defined in api/unsee.proto
+type grpcServer struct {
+	api.UnimplementedUnseeServer
+}
+
+// grpcFilterQuery returns the filter query built from the list of filter
+// expressions passed in the request, it will return an error if any filter
+// is invalid
+func grpcFilterQuery(expressions []string) (string, error) {
+	q, _ := expandFilterMacros(strings.Join(expressions, ","))
+	if q != "" {
+		if err := validateFilterQuery(q); err != nil {
+			return "", status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+	return q, nil
+}
+
+// List returns all alert groups with alerts matching filters from the request
+func (s *grpcServer) List(ctx context.Context, req *api.ListRequest) (*api.ListResponse, error) {
+	q, err := grpcFilterQuery(req.Filters)
+	if err != nil {
+		return nil, err
+	}
+	countFilterTerms(q)
+	groups, err := filterAlertGroups(ctx, alertmanager.DedupAlerts(), q)
+	if err != nil {
+		return nil, status.FromContextError(err).Err()
+	}
+
+	resp := api.ListResponse{Groups: []*api.AlertGroup{}}
+	for _, ag := range groups {
+		resp.Groups = append(resp.Groups, alertGroupToProto(ag))
+	}
+	return &resp, nil
+}
+
+// Watch will stream every alert change matching filters from the request
+func (s *grpcServer) Watch(req *api.WatchRequest, stream api.Unsee_WatchServer) error {
+	start := time.Now()
+
+	q, err := grpcFilterQuery(req.Filters)
+	if err != nil {
+		return err
+	}
+
+	matchFilters, validFilters := getFiltersFromQuery(q)
+	ch := eventBroker.Subscribe()
+	defer eventBroker.Unsubscribe(ch)
+	log.Infof("gRPC client started watching alerts with filter '%s'", q)
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			log.Infof("gRPC client stopped watching alerts after %s", time.Since(start))
+			return nil
+		case changes, ok := <-ch:
+			if !ok {
+				log.Info("Closing gRPC watch stream, server is shutting down")
+				return nil
+			}
+			for _, change := range filterEvents(changes, matchFilters, validFilters) {
+				if err := stream.Send(alertEventToProto(change)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+func timestampToProto(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+func silenceToProto(silence models.Silence) *api.Silence {
+	s := api.Silence{
+		Id:        silence.ID,
+		Matchers:  []*api.SilenceMatcher{},
+		StartsAt:  timestampToProto(silence.StartsAt),
+		EndsAt:    timestampToProto(silence.EndsAt),
+		CreatedAt: timestampToProto(silence.CreatedAt),
+		CreatedBy: silence.CreatedBy,
+		Comment:   silence.Comment,
+		JiraId:    silence.JiraID,
+		JiraUrl:   silence.JiraURL,
+	}
+	for _, m := range silence.Matchers {
+		s.Matchers = append(s.Matchers, &api.SilenceMatcher{Name: m.Name, Value: m.Value, IsRegex: m.IsRegex})
+	}
+	return &s
+}
+
+func alertToProto(alert models.Alert) *api.Alert {
+	a := api.Alert{
+		Annotations:  []*api.Annotation{},
+		Labels:       alert.Labels,
+		StartsAt:     timestampToProto(alert.StartsAt),
+		EndsAt:       timestampToProto(alert.EndsAt),
+		State:        alert.State,
+		Fingerprint:  alert.Fingerprint,
+		Alertmanager: []*api.AlertmanagerInstance{},
+		Receiver:     alert.Receiver,
+	}
+	for _, annotation := range alert.Annotations {
+		a.Annotations = append(a.Annotations, &api.Annotation{
+			Name:    annotation.Name,
+			Value:   annotation.Value,
+			Visible: annotation.Visible,
+			IsLink:  annotation.IsLink,
+		})
+	}
+	for _, am := range alert.Alertmanager {
+		instance := api.AlertmanagerInstance{
+			Name:     am.Name,
+			Uri:      am.URI,
+			State:    am.State,
+			StartsAt: timestampToProto(am.StartsAt),
+			EndsAt:   timestampToProto(am.EndsAt),
+			Source:   am.Source,
+			Silences: []*api.Silence{},
+		}
+		silenceIDs := []string{}
+		for id := range am.Silences {
+			silenceIDs = append(silenceIDs, id)
+		}
+		sort.Strings(silenceIDs)
+		for _, id := range silenceIDs {
+			instance.Silences = append(instance.Silences, silenceToProto(am.Silences[id]))
+		}
+		a.Alertmanager = append(a.Alertmanager, &instance)
+	}
+	return &a
+}
+
+func alertGroupToProto(ag models.AlertGroup) *api.AlertGroup {
+	g := api.AlertGroup{
+		Id:         ag.ID,
+		Receiver:   ag.Receiver,
+		Labels:     ag.Labels,
+		Alerts:     []*api.Alert{},
+		Hash:       ag.Hash,
+		StateCount: map[string]int64{},
+	}
+	for _, alert := range ag.Alerts {
+		g.Alerts = append(g.Alerts, alertToProto(alert))
+	}
+	for state, count := range ag.StateCount {
+		g.StateCount[state] = int64(count)
+	}
+	return &g
+}
+
+func alertEventToProto(event models.AlertEvent) *api.AlertEvent {
+	return &api.AlertEvent{
+		Type:     event.Type,
+		GroupId:  event.GroupID,
+		Receiver: event.Receiver,
+		Labels:   event.Labels,
+		Alert:    alertToProto(event.Alert),
+	}
+}
+
+// newGRPCServer returns a gRPC server with the unsee API registered
+// grpcMetadataValue returns the first value of given metadata key
+func grpcMetadataValue(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// grpcAuthorize checks the client address, credentials and the API key of a
+// gRPC request the same way ALLOWED_NETWORKS, AUTH_HTPASSWD and API_KEYS are
+// checked for HTTP requests, credentials are passed using authorization and
+// the API key using x-api-key metadata, all gRPC methods are read-only
+func grpcAuthorize(ctx context.Context, keys map[string]string, users map[string]string, allowed []*net.IPNet) error {
+	client := "unknown"
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		client = p.Addr.String()
+	}
+	if len(allowed) > 0 {
+		host, _, err := net.SplitHostPort(client)
+		if err != nil {
+			host = client
+		}
+		ip := net.ParseIP(host)
+		if ip == nil || !networksContain(allowed, ip) {
+			log.Warningf("[%s] gRPC request rejected, client address is not allowed", client)
+			return status.Error(codes.PermissionDenied, "access from your address is not allowed")
+		}
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	if len(users) > 0 {
+		req := http.Request{Header: http.Header{"Authorization": []string{grpcMetadataValue(md, "authorization")}}}
+		user, password, ok := req.BasicAuth()
+		hash, found := users[user]
+		if !ok || !found || !checkPassword(hash, password) {
+			log.Warningf("[%s] gRPC request rejected, invalid credentials for user '%s'", client, user)
+			return status.Error(codes.Unauthenticated, "authentication required")
+		}
+	}
+	if len(keys) > 0 {
+		key := grpcMetadataValue(md, strings.ToLower(apiKeyHeader))
+		if key == "" {
+			return status.Errorf(codes.Unauthenticated, "missing API key, pass it using %s metadata", strings.ToLower(apiKeyHeader))
+		}
+		if _, found := keys[key]; !found {
+			log.Warningf("[%s] Invalid API key used for gRPC request", client)
+			return status.Error(codes.Unauthenticated, "invalid API key")
+		}
+	}
+	return nil
+}
+
+func newGRPCServer() *grpc.Server {
+	// all of those are validated on startup
+	keys, _ := getAPIKeys()
+	users, _ := getHtpasswdUsers()
+	allowed, _, _ := getAllowedNetworks()
+
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			if err := grpcAuthorize(ctx, keys, users, allowed); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.StreamInterceptor(func(srv interface{}, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if err := grpcAuthorize(ss.Context(), keys, users, allowed); err != nil {
+				return err
+			}
+			return handler(srv, ss)
+		}),
+	)
+	api.RegisterUnseeServer(server, &grpcServer{})
+	return server
+}
+
+// stopGRPCServer gracefully stops the gRPC server, in-flight requests can run
+// until ctx is done, remaining connections are closed after that
+func stopGRPCServer(ctx context.Context, server *grpc.Server) {
+	done := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		log.Warning("gRPC server didn't stop in time, closing remaining connections")
+		server.Stop()
+		<-done
+	}
+}
+
+// serveGRPC will start given gRPC API server listening on given port
+func serveGRPC(server *grpc.Server, port int, listener net.Listener) error {
+	if listener == nil {
+		var err error
+		if listener, err = net.Listen("tcp", fmt.Sprintf(":%d", port)); err != nil {
+			return err
+		}
+	}
+	log.Infof("Listening for gRPC requests on %s", listener.Addr())
+	return server.Serve(listener)
+}
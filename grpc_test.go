@@ -0,0 +1,303 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/cloudflare/unsee/api"
+	"github.com/cloudflare/unsee/internal/config"
+	"github.com/cloudflare/unsee/internal/events"
+	"github.com/cloudflare/unsee/internal/mock"
+	"github.com/cloudflare/unsee/internal/models"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func grpcTestClient(t *testing.T) (api.UnseeClient, func()) {
+	listener := bufconn.Listen(1024 * 1024)
+	server := newGRPCServer()
+	go server.Serve(listener)
+
+	conn, err := grpc.NewClient(
+		"passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to connect to gRPC server: %s", err)
+	}
+	return api.NewUnseeClient(conn), func() {
+		conn.Close()
+		server.Stop()
+	}
+}
+
+type grpcListTest struct {
+	filters []string
+	query   string
+}
+
+var grpcListTests = []grpcListTest{
+	{filters: []string{}, query: ""},
+	{filters: []string{"@state=active"}, query: "@state=active"},
+	{filters: []string{"@receiver=by-name", "cluster=dev"}, query: "@receiver=by-name,cluster=dev"},
+	{filters: []string{"@group_limit=1"}, query: "@group_limit=1"},
+}
+
+func TestGRPCList(t *testing.T) {
+	mockConfig()
+	client, stop := grpcTestClient(t)
+	defer stop()
+
+	for _, version := range mock.ListAllMocks() {
+		mockAlerts(version)
+		r := ginTestEngine()
+		for _, testCase := range grpcListTests {
+			apiCache.Flush()
+			req, _ := http.NewRequest("GET", "/alerts.json?q="+testCase.query, nil)
+			resp := httptest.NewRecorder()
+			r.ServeHTTP(resp, req)
+			ur := models.AlertsResponse{}
+			json.Unmarshal(resp.Body.Bytes(), &ur)
+
+			lr, err := client.List(context.Background(), &api.ListRequest{Filters: testCase.filters})
+			if err != nil {
+				t.Errorf("[%s] List(%v) failed: %s", version, testCase.filters, err)
+				continue
+			}
+			if len(lr.Groups) != len(ur.AlertGroups) {
+				t.Errorf("[%s] List(%v) returned %d groups, expected %d", version, testCase.filters, len(lr.Groups), len(ur.AlertGroups))
+				continue
+			}
+			for i, ag := range lr.Groups {
+				expected := ur.AlertGroups[i]
+				if ag.Id != expected.ID || ag.Hash != expected.Hash || len(ag.Alerts) != len(expected.Alerts) {
+					t.Errorf("[%s] List(%v) returned group %s with hash %s and %d alerts, expected %s with hash %s and %d alerts",
+						version, testCase.filters, ag.Id, ag.Hash, len(ag.Alerts), expected.ID, expected.Hash, len(expected.Alerts))
+				}
+			}
+		}
+	}
+
+	_, err := client.List(context.Background(), &api.ListRequest{Filters: []string{"@state=foo"}})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("List() with invalid filter returned %v, expected InvalidArgument", err)
+	}
+}
+
+func TestGRPCWatch(t *testing.T) {
+	mockConfig()
+	client, stop := grpcTestClient(t)
+	defer stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+
+	stream, err := client.Watch(ctx, &api.WatchRequest{Filters: []string{"@state=foo"}})
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Watch() with invalid filter returned %v, expected InvalidArgument", err)
+	}
+
+	subscribers := eventBroker.Subscribers()
+	stream, err = client.Watch(ctx, &api.WatchRequest{Filters: []string{"instance=web1"}})
+	if err != nil {
+		t.Fatalf("Watch() failed: %s", err)
+	}
+
+	// wait for the handler to subscribe
+	for i := 0; i < 100 && eventBroker.Subscribers() == subscribers; i++ {
+		time.Sleep(time.Millisecond * 10)
+	}
+
+	eventBroker.Publish([]models.AlertEvent{
+		models.AlertEvent{Type: "added", Alert: models.Alert{Labels: map[string]string{"instance": "web2"}}},
+		models.AlertEvent{Type: "resolved", GroupID: "foo", Alert: models.Alert{Labels: map[string]string{"instance": "web1"}}},
+	})
+
+	event, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("Failed to receive event: %s", err)
+	}
+	if event.Type != "resolved" || event.GroupId != "foo" || event.Alert.Labels["instance"] != "web1" {
+		t.Errorf("Got invalid event: %v", event)
+	}
+}
+
+// grpcWatchStream opens a Watch stream and waits for the handler to subscribe
+// to the event broker
+func grpcWatchStream(t *testing.T, ctx context.Context, client api.UnseeClient) api.Unsee_WatchClient {
+	subscribers := eventBroker.Subscribers()
+	stream, err := client.Watch(ctx, &api.WatchRequest{})
+	if err != nil {
+		t.Fatalf("Watch() failed: %s", err)
+	}
+	for i := 0; i < 100 && eventBroker.Subscribers() == subscribers; i++ {
+		time.Sleep(time.Millisecond * 10)
+	}
+	return stream
+}
+
+func TestGRPCWatchBrokerClosed(t *testing.T) {
+	mockConfig()
+	broker := eventBroker
+	eventBroker = events.NewBroker()
+	defer func() { eventBroker = broker }()
+
+	client, stop := grpcTestClient(t)
+	defer stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	stream := grpcWatchStream(t, ctx, client)
+
+	eventBroker.Close()
+	if _, err := stream.Recv(); err != io.EOF {
+		t.Errorf("Watch() stream returned %v after the broker was closed, expected EOF", err)
+	}
+}
+
+func TestStopGRPCServerTimeout(t *testing.T) {
+	mockConfig()
+	listener := bufconn.Listen(1024 * 1024)
+	server := newGRPCServer()
+	go server.Serve(listener)
+
+	conn, err := grpc.NewClient(
+		"passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to connect to gRPC server: %s", err)
+	}
+	defer conn.Close()
+
+	// an open Watch stream never finishes on its own
+	grpcWatchStream(t, context.Background(), api.NewUnseeClient(conn))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*100)
+	defer cancel()
+	stopped := make(chan struct{})
+	go func() {
+		stopGRPCServer(ctx, server)
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(time.Second * 5):
+		t.Fatal("stopGRPCServer() didn't return after the timeout")
+	}
+}
+
+func TestGRPCAPIKeys(t *testing.T) {
+	mockConfig()
+	mockAlerts(mock.ListAllMocks()[0])
+	defer func() { config.Config.ApiKeys = []string{} }()
+	config.Config.ApiKeys = []string{"ci:secret"}
+
+	client, stop := grpcTestClient(t)
+	defer stop()
+
+	type testCaseT struct {
+		key  string
+		code codes.Code
+	}
+	for _, testCase := range []testCaseT{
+		{key: "", code: codes.Unauthenticated},
+		{key: "invalid", code: codes.Unauthenticated},
+		{key: "secret", code: codes.OK},
+	} {
+		ctx := context.Background()
+		if testCase.key != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, "x-api-key", testCase.key)
+		}
+		if _, err := client.List(ctx, &api.ListRequest{}); status.Code(err) != testCase.code {
+			t.Errorf("[%s] List() returned %v, expected %s", testCase.key, err, testCase.code)
+		}
+	}
+
+	stream, err := client.Watch(context.Background(), &api.WatchRequest{})
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("Watch() without an API key returned %v, expected %s", err, codes.Unauthenticated)
+	}
+}
+
+func TestGRPCBasicAuth(t *testing.T) {
+	mockConfig()
+	mockAlerts(mock.ListAllMocks()[0])
+	dir, err := ioutil.TempDir("", "unsee")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer func() { config.Config.AuthHtpasswd = "" }()
+	config.Config.AuthHtpasswd = writeHtpasswd(t, dir)
+
+	client, stop := grpcTestClient(t)
+	defer stop()
+
+	type testCaseT struct {
+		user     string
+		password string
+		code     codes.Code
+	}
+	for _, testCase := range []testCaseT{
+		{code: codes.Unauthenticated},
+		{user: "alice", password: "bob", code: codes.Unauthenticated},
+		{user: "carol", password: "carol", code: codes.Unauthenticated},
+		{user: "alice", password: "alice", code: codes.OK},
+		{user: "bob", password: "bob", code: codes.OK},
+	} {
+		ctx := context.Background()
+		if testCase.user != "" {
+			credentials := base64.StdEncoding.EncodeToString([]byte(testCase.user + ":" + testCase.password))
+			ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Basic "+credentials)
+		}
+		if _, err := client.List(ctx, &api.ListRequest{}); status.Code(err) != testCase.code {
+			t.Errorf("[%s:%s] List() returned %v, expected %s", testCase.user, testCase.password, err, testCase.code)
+		}
+	}
+}
+
+func TestGRPCAllowedNetworks(t *testing.T) {
+	_, allowed, _ := net.ParseCIDR("10.0.0.0/8")
+	type testCaseT struct {
+		addr net.Addr
+		code codes.Code
+	}
+	for _, testCase := range []testCaseT{
+		{addr: &net.TCPAddr{IP: net.ParseIP("10.1.2.3"), Port: 1234}, code: codes.OK},
+		{addr: &net.TCPAddr{IP: net.ParseIP("192.168.1.1"), Port: 1234}, code: codes.PermissionDenied},
+		{addr: &net.UnixAddr{Name: "bufconn", Net: "unix"}, code: codes.PermissionDenied},
+	} {
+		ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: testCase.addr})
+		if err := grpcAuthorize(ctx, nil, nil, []*net.IPNet{allowed}); status.Code(err) != testCase.code {
+			t.Errorf("[%s] grpcAuthorize() returned %v, expected %s", testCase.addr, err, testCase.code)
+		}
+	}
+}
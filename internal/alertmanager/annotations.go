@@ -0,0 +1,98 @@
+package alertmanager
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/cloudflare/unsee/internal/config"
+	"github.com/cloudflare/unsee/internal/models"
+	"github.com/cloudflare/unsee/internal/slices"
+)
+
+const (
+	// AnnotationsMergeNewest uses annotations from the most recently updated
+	// copy of the alert
+	AnnotationsMergeNewest = "newest"
+	// AnnotationsMergePreferred uses annotations from the first upstream
+	// listed in ANNOTATIONS_MERGE_PREFERRED that has the alert
+	AnnotationsMergePreferred = "preferred"
+	// AnnotationsMergeUnion uses annotations from all copies of the alert,
+	// the most recently updated copy wins if values differ
+	AnnotationsMergeUnion = "union"
+)
+
+// ValidateAnnotationsMergePolicy returns an error if the annotations merge
+// policy isn't supported or preferred upstreams aren't in the list of
+// configured upstream names
+func ValidateAnnotationsMergePolicy(policy string, preferred []string, upstreams []string) error {
+	switch policy {
+	case AnnotationsMergeNewest, AnnotationsMergeUnion:
+		return nil
+	case AnnotationsMergePreferred:
+		if len(preferred) == 0 {
+			return fmt.Errorf("ANNOTATIONS_MERGE_PREFERRED needs to be set when ANNOTATIONS_MERGE_POLICY is %s", AnnotationsMergePreferred)
+		}
+		for _, name := range preferred {
+			if !slices.StringInSlice(upstreams, name) {
+				return fmt.Errorf("invalid ANNOTATIONS_MERGE_PREFERRED value '%s', there's no Alertmanager upstream with that name", name)
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("invalid ANNOTATIONS_MERGE_POLICY value '%s', supported values: %s, %s, %s", policy, AnnotationsMergeNewest, AnnotationsMergePreferred, AnnotationsMergeUnion)
+}
+
+// upstreamName returns the name of the upstream a copy of the alert was
+// collected from, copies aren't merged yet so there's only a single instance
+func upstreamName(alert models.Alert) string {
+	if len(alert.Alertmanager) == 0 {
+		return ""
+	}
+	return alert.Alertmanager[0].Name
+}
+
+// mergeAnnotations returns annotations for an alert merged from copies
+// collected from multiple upstreams, HA peers can have different annotations
+// for a while after a rule is updated, so the result shouldn't depend on the
+// order upstreams were collected in
+func mergeAnnotations(copies []models.Alert) models.Annotations {
+	if len(copies) == 1 {
+		return copies[0].Annotations
+	}
+
+	// Alertmanager moves endsAt forward every time an alert is received again,
+	// so the copy with the latest endsAt is the most recently updated one
+	sorted := make([]models.Alert, len(copies))
+	copy(sorted, copies)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if !sorted[i].EndsAt.Equal(sorted[j].EndsAt) {
+			return sorted[i].EndsAt.After(sorted[j].EndsAt)
+		}
+		return upstreamName(sorted[i]) < upstreamName(sorted[j])
+	})
+
+	switch config.Config.AnnotationsMergePolicy {
+	case AnnotationsMergePreferred:
+		for _, name := range config.Config.AnnotationsMergePreferred {
+			for _, alert := range sorted {
+				if upstreamName(alert) == name {
+					return alert.Annotations
+				}
+			}
+		}
+	case AnnotationsMergeUnion:
+		annotations := models.Annotations{}
+		seen := map[string]bool{}
+		for _, alert := range sorted {
+			for _, a := range alert.Annotations {
+				if !seen[a.Name] {
+					seen[a.Name] = true
+					annotations = append(annotations, a)
+				}
+			}
+		}
+		sort.Sort(annotations)
+		return annotations
+	}
+	return sorted[0].Annotations
+}
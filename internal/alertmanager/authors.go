@@ -0,0 +1,38 @@
+package alertmanager
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/cloudflare/unsee/internal/models"
+)
+
+// silenceAuthors tracks authors of all silences seen on any upstream, authors
+// are kept after their silences are deleted from Alertmanager
+var silenceAuthors = struct {
+	sync.RWMutex
+	seen map[string]bool
+}{seen: map[string]bool{}}
+
+func recordSilenceAuthors(silences map[string]models.Silence) {
+	silenceAuthors.Lock()
+	defer silenceAuthors.Unlock()
+	for _, silence := range silences {
+		if silence.CreatedBy != "" {
+			silenceAuthors.seen[silence.CreatedBy] = true
+		}
+	}
+}
+
+// SilenceAuthors returns a sorted list of authors of all silences collected
+// from any upstream since unsee was started
+func SilenceAuthors() []string {
+	silenceAuthors.RLock()
+	defer silenceAuthors.RUnlock()
+	authors := make([]string, 0, len(silenceAuthors.seen))
+	for author := range silenceAuthors.seen {
+		authors = append(authors, author)
+	}
+	sort.Strings(authors)
+	return authors
+}
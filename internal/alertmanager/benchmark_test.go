@@ -1,11 +1,16 @@
 package alertmanager_test
 
 import (
+	"context"
+	"fmt"
+	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/cloudflare/unsee/internal/alertmanager"
 	"github.com/cloudflare/unsee/internal/config"
+	"github.com/cloudflare/unsee/internal/mock/synthetic"
 )
 
 func BenchmarkDedupAlerts(b *testing.B) {
@@ -17,6 +22,14 @@ func BenchmarkDedupAlerts(b *testing.B) {
 	}
 }
 
+func BenchmarkPull(b *testing.B) {
+	for n := 0; n < b.N; n++ {
+		if err := pullAlerts(); err != nil {
+			b.Error(err)
+		}
+	}
+}
+
 func BenchmarkDedupAutocomplete(b *testing.B) {
 	if err := pullAlerts(); err != nil {
 		b.Error(err)
@@ -37,3 +50,49 @@ func BenchmarkDedupColors(b *testing.B) {
 		alertmanager.DedupColors()
 	}
 }
+
+// syntheticUpstreams returns HA pairs of upstreams serving the same generated
+// alerts, those are not registered so they don't affect other tests
+func syntheticUpstreams(b *testing.B, opts synthetic.Options) ([]*alertmanager.Alertmanager, func()) {
+	ams := []*alertmanager.Alertmanager{}
+	servers := []*httptest.Server{}
+	for i := 0; i < 2; i++ {
+		ts := httptest.NewServer(synthetic.NewServer(opts))
+		servers = append(servers, ts)
+		am := alertmanager.New(fmt.Sprintf("synthetic-%d", i), ts.URL, time.Second*30)
+		if err := am.Pull(context.Background()); err != nil {
+			b.Fatal(err)
+		}
+		ams = append(ams, am)
+	}
+	return ams, func() {
+		for _, ts := range servers {
+			ts.Close()
+		}
+	}
+}
+
+func BenchmarkDedupSyntheticAlerts(b *testing.B) {
+	ams, cleanup := syntheticUpstreams(b, synthetic.Options{Groups: 100, AlertsPerGroup: 50, Silences: 100})
+	defer cleanup()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		alertmanager.DedupUpstreamAlerts(ams)
+	}
+}
+
+func BenchmarkPullSyntheticAlerts(b *testing.B) {
+	opts := synthetic.Options{Groups: 100, AlertsPerGroup: 50, Silences: 100, ChurnRate: 0.1}
+	s := synthetic.NewServer(opts)
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	am := alertmanager.New("synthetic", ts.URL, time.Second*30)
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		// replace some alerts, so every pull has to process changed groups
+		s.Churn()
+		if err := am.Pull(context.Background()); err != nil {
+			b.Error(err)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package alertmanager
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/cloudflare/unsee/internal/filters"
+	"github.com/cloudflare/unsee/internal/models"
+)
+
+var blackhole = struct {
+	sync.RWMutex
+	queries []string
+}{}
+
+// SetBlackholeFilters sets filter queries used to drop alerts during
+// collection, every query is a comma separated list of filter expressions and
+// alerts matching all filters from any of the queries are dropped before
+// they're stored
+func SetBlackholeFilters(queries []string) {
+	blackhole.Lock()
+	defer blackhole.Unlock()
+	blackhole.queries = queries
+}
+
+// newBlackholeFilters parses blackhole queries, filters track hits, so every
+// pull needs its own set
+func newBlackholeFilters() [][]filters.FilterT {
+	blackhole.RLock()
+	defer blackhole.RUnlock()
+	parsed := [][]filters.FilterT{}
+	for _, q := range blackhole.queries {
+		matchFilters := []filters.FilterT{}
+		for _, expression := range strings.Split(q, ",") {
+			if f := filters.NewFilter(expression); f.GetIsValid() {
+				matchFilters = append(matchFilters, f)
+			}
+		}
+		if len(matchFilters) > 0 {
+			parsed = append(parsed, matchFilters)
+		}
+	}
+	return parsed
+}
+
+// isBlackholed returns true if the alert matches all filters from any of the
+// blackhole queries, the alert is matched with details of this upstream and
+// its silences, so it looks the same as alerts returned by the API
+func (am *Alertmanager) isBlackholed(alert models.Alert, silences map[string]models.Silence, blackholeFilters [][]filters.FilterT) bool {
+	if len(blackholeFilters) == 0 {
+		return false
+	}
+	alertSilences := map[string]models.Silence{}
+	for _, silenceID := range alert.SilencedBy {
+		if silence, found := silences[silenceID]; found {
+			alertSilences[silenceID] = silence
+		}
+	}
+	alert.Alertmanager = []models.AlertmanagerInstance{
+		models.AlertmanagerInstance{
+			Name:     am.Name,
+			URI:      am.URI,
+			State:    alert.State,
+			StartsAt: alert.StartsAt,
+			EndsAt:   alert.EndsAt,
+			Source:   alert.GeneratorURL,
+			Silences: alertSilences,
+		},
+	}
+	for _, matchFilters := range blackholeFilters {
+		matched := true
+		for _, f := range matchFilters {
+			if !f.Match(&alert, 0) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,67 @@
+package alertmanager
+
+import (
+	"time"
+
+	"github.com/cloudflare/unsee/internal/config"
+	"github.com/cloudflare/unsee/internal/models"
+)
+
+// Date header only has full seconds
+const dateResolution = time.Second
+
+// clockSkewFromDate estimates how far the upstream clock is ahead of the local
+// one using the Date header of a response to a request that was sent at start
+// and received at end, it returns 0 if the difference is within the error of
+// the estimate
+func clockSkewFromDate(date, start, end time.Time) time.Duration {
+	remote := date.Add(dateResolution / 2)
+	local := start.Add(end.Sub(start) / 2)
+	skew := remote.Sub(local)
+	precision := end.Sub(start)/2 + dateResolution/2
+	if skew <= precision && skew >= -precision {
+		return 0
+	}
+	return skew.Round(time.Millisecond)
+}
+
+// clockSkewFromSilences is used for upstreams that don't send the Date header,
+// silences can't be created in the future, so if any silence was created
+// after now then the upstream clock is ahead by at least that much, it
+// returns false if the skew can't be detected this way
+func clockSkewFromSilences(silences map[string]models.Silence, now time.Time) (time.Duration, bool) {
+	var latest time.Time
+	for _, silence := range silences {
+		if silence.CreatedAt.After(latest) {
+			latest = silence.CreatedAt
+		}
+	}
+	if !latest.After(now) {
+		return 0, false
+	}
+	return latest.Sub(now).Round(time.Millisecond), true
+}
+
+func (am *Alertmanager) setClockSkew(skew time.Duration, known bool) {
+	am.lock.Lock()
+	am.clockSkew = skew
+	am.clockSkewKnown = known
+	am.lock.Unlock()
+}
+
+// ClockSkew returns how far the upstream clock was ahead of the local one
+// during the last pull, it's negative if the upstream clock is behind, false
+// is returned if it couldn't be detected
+func (am *Alertmanager) ClockSkew() (time.Duration, bool) {
+	am.lock.RLock()
+	defer am.lock.RUnlock()
+	return am.clockSkew, am.clockSkewKnown
+}
+
+// IsClockSkewed returns true if the upstream clock was off by more than
+// ALERTMANAGER_MAX_CLOCK_SKEW during the last pull
+func (am *Alertmanager) IsClockSkewed() bool {
+	skew, known := am.ClockSkew()
+	limit := config.Config.AlertmanagerMaxClockSkew
+	return known && limit > 0 && (skew > limit || skew < -limit)
+}
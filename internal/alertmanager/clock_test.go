@@ -0,0 +1,81 @@
+package alertmanager_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/cloudflare/unsee/internal/alertmanager"
+	"github.com/cloudflare/unsee/internal/config"
+	"github.com/cloudflare/unsee/internal/mock"
+
+	httpmock "gopkg.in/jarcoal/httpmock.v1"
+)
+
+type clockSkewTest struct {
+	offset  time.Duration
+	skewed  bool
+	minSkew time.Duration
+	maxSkew time.Duration
+}
+
+var clockSkewTests = []clockSkewTest{
+	clockSkewTest{},
+	clockSkewTest{offset: time.Minute * 5, skewed: true, minSkew: time.Minute*5 - time.Second*2, maxSkew: time.Minute*5 + time.Second*2},
+	clockSkewTest{offset: -time.Minute * 5, skewed: true, minSkew: -time.Minute*5 - time.Second*2, maxSkew: -time.Minute*5 + time.Second*2},
+	clockSkewTest{offset: time.Second * 10, minSkew: time.Second * 8, maxSkew: time.Second * 12},
+}
+
+func TestClockSkew(t *testing.T) {
+	config.Config.AlertmanagerMaxClockSkew = time.Second * 30
+	defer func() { config.Config.AlertmanagerMaxClockSkew = 0 }()
+
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	status, err := ioutil.ReadFile(mock.GetAbsoluteMockPath("status", "0.9.1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, testCase := range clockSkewTests {
+		httpmock.Reset()
+		httpmock.RegisterResponder("GET", "http://localhost/api/v1/status", func(req *http.Request) (*http.Response, error) {
+			resp := httpmock.NewBytesResponse(200, status)
+			resp.Header.Set("Date", time.Now().Add(testCase.offset).UTC().Format(http.TimeFormat))
+			return resp, nil
+		})
+		mock.RegisterURL("http://localhost/api/v1/silences", "0.9.1", "silences")
+		mock.RegisterURL("http://localhost/api/v1/alerts/groups", "0.9.1", "alerts/groups")
+
+		am := alertmanager.New("skew", "http://localhost", time.Second)
+		if err := am.Pull(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		skew, known := am.ClockSkew()
+		if !known {
+			t.Errorf("[%s] Clock skew wasn't detected", testCase.offset)
+			continue
+		}
+		if skew < testCase.minSkew || skew > testCase.maxSkew {
+			t.Errorf("[%s] Invalid clock skew %s, expected between %s and %s", testCase.offset, skew, testCase.minSkew, testCase.maxSkew)
+		}
+		if am.IsClockSkewed() != testCase.skewed {
+			t.Errorf("[%s] IsClockSkewed() returned %v, expected %v", testCase.offset, am.IsClockSkewed(), testCase.skewed)
+		}
+	}
+}
+
+func TestClockSkewFromSilences(t *testing.T) {
+	for _, uri := range mock.ListAllMockURIs() {
+		am := alertmanager.New("skew", uri, time.Second)
+		if err := am.Pull(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		// all mock silences were created in the past
+		if skew, known := am.ClockSkew(); known {
+			t.Errorf("[%s] Clock skew %s detected without Date header", uri, skew)
+		}
+	}
+}
@@ -1,98 +1,291 @@
 package alertmanager
 
 import (
+	"fmt"
 	"sort"
+	"strings"
+	"sync"
 
 	"github.com/cloudflare/unsee/internal/config"
+	"github.com/cloudflare/unsee/internal/filters"
+	"github.com/cloudflare/unsee/internal/flapping"
 	"github.com/cloudflare/unsee/internal/models"
 	"github.com/cloudflare/unsee/internal/slices"
 	"github.com/cloudflare/unsee/internal/transform"
+
+	"github.com/cnf/structhash"
 )
 
+// dedupedGroup is an alert group merged from all upstreams
+type dedupedGroup struct {
+	// checksum of upstream groups this group was merged from
+	checksum string
+	group    models.AlertGroup
+}
+
+// dedupCache keeps alert groups merged by the last DedupAlerts call, those are
+// reused for as long as upstream groups they were merged from don't change,
+// if no upstream was pulled since the last call then the whole result is
+// reused
+var dedupCache = struct {
+	sync.Mutex
+	groups map[string]dedupedGroup
+	// upstream snapshots and labels config used for the last result
+	snapshots    map[string]*upstreamData
+	labelsConfig string
+	alertGroups  []models.AlertGroup
+}{groups: map[string]dedupedGroup{}}
+
+// snapshotsEqual returns true if both maps have exactly the same snapshots
+func snapshotsEqual(a, b map[string]*upstreamData) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for name, data := range a {
+		if b[name] != data {
+			return false
+		}
+	}
+	return true
+}
+
+func getSnapshots(ams []*Alertmanager) map[string]*upstreamData {
+	snapshots := map[string]*upstreamData{}
+	for _, am := range ams {
+		snapshots[am.Name] = am.snapshot()
+	}
+	return snapshots
+}
+
+// labels are stripped, alerts deduplicated, annotations picked, timestamps
+// formatted and flapping alerts marked when merging, so the config and the
+// list of flapping alerts are part of the checksum
+func getLabelsConfig() string {
+	return fmt.Sprintf("%q %q %q %q %q %q %s", config.Config.KeepLabels, config.Config.StripLabels, config.Config.DedupIgnoredLabels, config.Config.AnnotationsMergePolicy, config.Config.AnnotationsMergePreferred, transform.Timezone(), flapping.Version())
+}
+
+// dedupKey returns the identity of the alert used when merging alerts, alerts
+// with the same labels, ignoring labels from DEDUP_IGNORED_LABELS, are merged
+// into a single alert
+func dedupKey(alert *models.Alert) string {
+	if len(config.Config.DedupIgnoredLabels) == 0 {
+		return alert.LabelsFingerprint()
+	}
+	labels := transform.StripLables([]string{}, config.Config.DedupIgnoredLabels, alert.Labels)
+	return fmt.Sprintf("%x", structhash.Sha1(labels, 1))
+}
+
+// DedupUpstreamAlerts deduplicates alert groups from given upstreams, unlike
+// DedupAlerts it doesn't cache merged groups, so it can be used with upstreams
+// that are not registered
+func DedupUpstreamAlerts(ams []*Alertmanager) []models.AlertGroup {
+	groups, _ := dedupGroups(getSnapshots(ams), getLabelsConfig(), map[string]dedupedGroup{})
+	return groups
+}
+
 // DedupAlerts will collect alert groups from all defined Alertmanager
 // upstreams and deduplicate them, so we only return unique alerts
 func DedupAlerts() []models.AlertGroup {
+	snapshots := getSnapshots(GetAlertmanagers())
+	labelsConfig := getLabelsConfig()
+
+	dedupCache.Lock()
+	defer dedupCache.Unlock()
+
+	if dedupCache.alertGroups == nil || dedupCache.labelsConfig != labelsConfig || !snapshotsEqual(dedupCache.snapshots, snapshots) {
+		dedupCache.alertGroups, dedupCache.groups = dedupGroups(snapshots, labelsConfig, dedupCache.groups)
+		dedupCache.snapshots = snapshots
+		dedupCache.labelsConfig = labelsConfig
+	}
+
+	dedupedGroups := make([]models.AlertGroup, len(dedupCache.alertGroups))
+	copy(dedupedGroups, dedupCache.alertGroups)
+	return dedupedGroups
+}
+
+// dedupGroups merges alert groups from all upstream snapshots, groups merged
+// by previous calls are reused if they are unchanged, it returns merged groups
+// and the cache that should be passed to the next call
+func dedupGroups(snapshots map[string]*upstreamData, labelsConfig string, previous map[string]dedupedGroup) ([]models.AlertGroup, map[string]dedupedGroup) {
 	uniqueGroups := map[string][]models.AlertGroup{}
+	upstreamHashes := map[string][]string{}
 
-	upstreams := GetAlertmanagers()
-	for _, am := range upstreams {
-		groups := am.Alerts()
-		for _, ag := range groups {
+	for name, data := range snapshots {
+		for _, ag := range data.alertGroups {
 			if _, found := uniqueGroups[ag.ID]; !found {
 				uniqueGroups[ag.ID] = []models.AlertGroup{}
 			}
 			uniqueGroups[ag.ID] = append(uniqueGroups[ag.ID], ag)
+			upstreamHashes[ag.ID] = append(upstreamHashes[ag.ID], name+":"+ag.Hash)
+		}
+	}
+
+	agIDs := make([]string, 0, len(uniqueGroups))
+	for agID := range uniqueGroups {
+		agIDs = append(agIDs, agID)
+	}
+	// groups are sorted by ID, so they are always returned in the same order
+	sort.Strings(agIDs)
+
+	merged := make([]dedupedGroup, len(agIDs))
+	transform.ForEach(len(agIDs), func(i int) {
+		agID := agIDs[i]
+		// upstreams are stored in a map, so the order isn't stable
+		sort.Strings(upstreamHashes[agID])
+		checksum := labelsConfig + " " + strings.Join(upstreamHashes[agID], " ")
+		dg, found := previous[agID]
+		if !found || dg.checksum != checksum {
+			dg = dedupedGroup{checksum: checksum, group: mergeGroups(uniqueGroups[agID])}
 		}
+		merged[i] = dg
+	})
+
+	dedupedGroups := make([]models.AlertGroup, 0, len(merged))
+	cache := make(map[string]dedupedGroup, len(merged))
+	for _, dg := range merged {
+		cache[dg.group.ID] = dg
+		dedupedGroups = append(dedupedGroups, dg.group)
 	}
+	return dedupedGroups, cache
+}
 
-	dedupedGroups := []models.AlertGroup{}
+// mergeGroups merges copies of the same alert group collected from multiple
+// upstreams into a single group with unique alerts
+func mergeGroups(agList []models.AlertGroup) models.AlertGroup {
+	allAlerts := []models.Alert{}
+	for _, ag := range agList {
+		allAlerts = append(allAlerts, ag.Alerts...)
+	}
+	if len(config.Config.DedupIgnoredLabels) > 0 {
+		// alerts with different labels can be merged when some labels are
+		// ignored, sort them so the same alert is always used as the base
+		sort.SliceStable(allAlerts, func(i, j int) bool {
+			return allAlerts[i].LabelsFingerprint() < allAlerts[j].LabelsFingerprint()
+		})
+	}
+
+	alerts := map[string]models.Alert{}
 	alertStates := map[string][]string{}
-	for _, agList := range uniqueGroups {
-		alerts := map[string]models.Alert{}
-		for _, ag := range agList {
-			for _, alert := range ag.Alerts {
-				alertLFP := alert.LabelsFingerprint()
-				a, found := alerts[alertLFP]
-				if found {
-					// if we already have an alert with the same fp then just append
-					// alertmanager instances to it, this way we end up with all instances
-					// for each unique alert merged into a single alert with all
-					// alertmanager instances attached to it
-					for _, am := range alert.Alertmanager {
-						a.Alertmanager = append(a.Alertmanager, am)
-					}
-					// set startsAt to the earliest value we have
-					if alert.StartsAt.Before(a.StartsAt) {
-						a.StartsAt = alert.StartsAt
-					}
-					// set endsAt to the oldest value we have
-					if alert.EndsAt.After(a.EndsAt) {
-						a.EndsAt = alert.EndsAt
-					}
-					// update map
-					alerts[alertLFP] = a
-					// and append alert state to the slice
-					alertStates[alertLFP] = append(alertStates[alertLFP], alert.State)
-				} else {
-					alerts[alertLFP] = models.Alert(alert)
-					// seed alert state slice
-					alertStates[alertLFP] = []string{alert.State}
+	alertCopies := map[string][]models.Alert{}
+	for _, alert := range allAlerts {
+		alertLFP := dedupKey(&alert)
+		alertCopies[alertLFP] = append(alertCopies[alertLFP], alert)
+		a, found := alerts[alertLFP]
+		if found {
+			// if we already have an alert with the same fp then just append
+			// alertmanager instances to it, this way we end up with all instances
+			// for each unique alert merged into a single alert with all
+			// alertmanager instances attached to it, alerts that only differ
+			// by ignored labels can be collected from the same instance
+			for _, am := range alert.Alertmanager {
+				if !hasAlertmanagerInstance(a.Alertmanager, am.Name) {
+					a.Alertmanager = append(a.Alertmanager, am)
 				}
 			}
+			// set startsAt to the earliest value we have
+			if alert.StartsAt.Before(a.StartsAt) {
+				a.StartsAt = alert.StartsAt
+			}
+			// set endsAt to the oldest value we have
+			if alert.EndsAt.After(a.EndsAt) {
+				a.EndsAt = alert.EndsAt
+			}
+			// update map
+			alerts[alertLFP] = a
+			// and append alert state to the slice
+			alertStates[alertLFP] = append(alertStates[alertLFP], alert.State)
+		} else {
+			alerts[alertLFP] = models.Alert(alert)
+			// seed alert state slice
+			alertStates[alertLFP] = []string{alert.State}
 		}
-		ag := models.AlertGroup(agList[0])
-		ag.Alerts = models.AlertList{}
-		for _, alert := range alerts {
-			// strip labels user doesn't want to see in the UI
-			alert.Labels = transform.StripLables(config.Config.KeepLabels, config.Config.StripLabels, alert.Labels)
-			// calculate final alert state based on the most important value found
-			// in the list of states from all instances
-			alertLFP := alert.LabelsFingerprint()
-			if slices.StringInSlice(alertStates[alertLFP], models.AlertStateActive) {
-				alert.State = models.AlertStateActive
-			} else if slices.StringInSlice(alertStates[alertLFP], models.AlertStateSuppressed) {
-				alert.State = models.AlertStateSuppressed
+	}
+	strippedLabels := append(append([]string{}, config.Config.StripLabels...), config.Config.DedupIgnoredLabels...)
+	ag := models.AlertGroup(agList[0])
+	ag.Alerts = models.AlertList{}
+	for alertLFP, alert := range alerts {
+		// strip labels user doesn't want to see in the UI, ignored labels
+		// can have a different value on every merged alert so those are
+		// stripped too
+		alert.Labels = transform.StripLables(config.Config.KeepLabels, strippedLabels, alert.Labels)
+		// upstreams can have different annotations for the same alert, pick
+		// them using ANNOTATIONS_MERGE_POLICY
+		alert.Annotations = mergeAnnotations(alertCopies[alertLFP])
+		// calculate final alert state based on the most important value found
+		// in the list of states from all instances
+		if slices.StringInSlice(alertStates[alertLFP], models.AlertStateActive) {
+			alert.State = models.AlertStateActive
+		} else if slices.StringInSlice(alertStates[alertLFP], models.AlertStateSuppressed) {
+			alert.State = models.AlertStateSuppressed
+		} else {
+			alert.State = models.AlertStateUnprocessed
+		}
+		// sort Alertmanager instances for every alert
+		sort.Slice(alert.Alertmanager, func(i, j int) bool {
+			return alert.Alertmanager[i].Name < alert.Alertmanager[j].Name
+		})
+		// timestamps are only final once all instances are merged
+		alert.StartsAtDisplay = transform.FormatTime(alert.StartsAt)
+		alert.EndsAtDisplay = transform.FormatTime(alert.EndsAt)
+		// fingerprints need to be updated since labels, state and instances
+		// might have changed
+		alert.UpdateFingerprints()
+		if flapping.IsFlapping(&alert) {
+			alert.Flapping = true
+			alert.UpdateFingerprints()
+		}
+		ag.Alerts = append(ag.Alerts, alert)
+	}
+	sort.Sort(ag.Alerts)
+	ag.Hash = ag.ContentFingerprint()
+	return ag
+}
+
+// hasAlertmanagerInstance returns true if the list has an instance with given
+// name
+func hasAlertmanagerInstance(instances []models.AlertmanagerInstance, name string) bool {
+	for _, am := range instances {
+		if am.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// DedupSilences returns a list of unique silences from all Alertmanager
+// upstreams, with names of all upstreams each silence was found on
+func DedupSilences() []models.ManagedSilence {
+	return DedupUpstreamSilences(GetAlertmanagers())
+}
+
+// DedupUpstreamSilences returns a list of unique silences from given
+// upstreams, with names of all upstreams each silence was found on
+func DedupUpstreamSilences(ams []*Alertmanager) []models.ManagedSilence {
+	uniqueSilences := map[string]*models.ManagedSilence{}
+
+	for _, am := range ams {
+		for id, silence := range am.Silences() {
+			if ms, found := uniqueSilences[id]; found {
+				ms.Alertmanagers = append(ms.Alertmanagers, am.Name)
 			} else {
-				alert.State = models.AlertStateUnprocessed
+				uniqueSilences[id] = &models.ManagedSilence{
+					Silence:       silence,
+					Alertmanagers: []string{am.Name},
+				}
 			}
-			// sort Alertmanager instances for every alert
-			sort.Slice(alert.Alertmanager, func(i, j int) bool {
-				return alert.Alertmanager[i].Name < alert.Alertmanager[j].Name
-			})
-			ag.Alerts = append(ag.Alerts, alert)
 		}
-		sort.Sort(ag.Alerts)
-		ag.Hash = ag.ContentFingerprint()
-		dedupedGroups = append(dedupedGroups, ag)
 	}
 
-	// sort alert groups so they are always returned in the same order
-	// use group ID which is unique and immutable
-	sort.Slice(dedupedGroups, func(i, j int) bool {
-		return dedupedGroups[i].ID < dedupedGroups[j].ID
+	dedupedSilences := []models.ManagedSilence{}
+	for _, ms := range uniqueSilences {
+		sort.Strings(ms.Alertmanagers)
+		dedupedSilences = append(dedupedSilences, *ms)
+	}
+	sort.Slice(dedupedSilences, func(i, j int) bool {
+		return dedupedSilences[i].ID < dedupedSilences[j].ID
 	})
 
-	return dedupedGroups
+	return dedupedSilences
 }
 
 // DedupColors returns a color map merged from all Alertmanager upstream color
@@ -138,18 +331,47 @@ func DedupAutocomplete() []models.Autocomplete {
 						h.Tokens = append(h.Tokens, token)
 					}
 				}
+				// upstreams might be HA pairs with the same alerts, so don't
+				// sum weights as that would double count those alerts
+				if hint.Weight > h.Weight {
+					h.Weight = hint.Weight
+				}
 			} else {
 				uniqueAutocomplete[hint.Value] = &models.Autocomplete{
 					Value:  hint.Value,
 					Tokens: hint.Tokens,
+					Weight: hint.Weight,
 				}
 			}
 		}
 	}
 
+	// add hints for every configured Alertmanager instance, so it's possible
+	// to filter by instances that currently have no alerts or are failing
+	for _, am := range upstreams {
+		for _, hint := range filters.AlertmanagerInstanceAutocomplete(am.Name) {
+			if _, found := uniqueAutocomplete[hint.Value]; !found {
+				h := hint
+				uniqueAutocomplete[hint.Value] = &h
+			}
+		}
+	}
+
+	// add hints for every silence author seen so far, authors are remembered
+	// after their silences are gone, so filtering and creating silences
+	// doesn't require typing their full name
+	for _, author := range SilenceAuthors() {
+		for _, hint := range filters.SilenceAuthorAutocomplete(author) {
+			if _, found := uniqueAutocomplete[hint.Value]; !found {
+				h := hint
+				uniqueAutocomplete[hint.Value] = &h
+			}
+		}
+	}
+
 	for _, hint := range uniqueAutocomplete {
 		dedupedAutocomplete = append(dedupedAutocomplete, *hint)
 	}
 
-	return dedupedAutocomplete
+	return transform.LimitAutocomplete(dedupedAutocomplete)
 }
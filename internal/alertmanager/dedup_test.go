@@ -1,16 +1,29 @@
 package alertmanager_test
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
+	"unsafe"
 
 	"github.com/cloudflare/unsee/internal/alertmanager"
 	"github.com/cloudflare/unsee/internal/config"
 	"github.com/cloudflare/unsee/internal/mock"
+	"github.com/cloudflare/unsee/internal/models"
 
+	"github.com/prometheus/client_golang/prometheus"
 	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
 )
 
 func init() {
@@ -22,7 +35,7 @@ func init() {
 
 func pullAlerts() error {
 	for _, am := range alertmanager.GetAlertmanagers() {
-		err := am.Pull()
+		err := am.Pull(context.Background())
 		if err != nil {
 			return err
 		}
@@ -70,6 +83,129 @@ func TestDedupAlertsWithoutLabels(t *testing.T) {
 	}
 }
 
+func TestDedupAlertsCached(t *testing.T) {
+	if err := pullAlerts(); err != nil {
+		t.Error(err)
+	}
+	first := alertmanager.DedupAlerts()
+	// nothing changed, so groups should be reused after another pull
+	if err := pullAlerts(); err != nil {
+		t.Error(err)
+	}
+	second := alertmanager.DedupAlerts()
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("Alert groups changed after pulling the same alerts again")
+	}
+
+	// labels are stripped after merging, cached groups can't be used if that
+	// config changes
+	config.Config.KeepLabels = []string{"xyz"}
+	stripped := alertmanager.DedupAlerts()
+	config.Config.KeepLabels = []string{}
+	for _, ag := range stripped {
+		for _, alert := range ag.Alerts {
+			if len(alert.Labels) != 0 {
+				t.Errorf("Expected all labels to be stripped, got %v", alert.Labels)
+			}
+		}
+	}
+	if len(alertmanager.DedupAlerts()[0].Alerts[0].Labels) == 0 {
+		t.Errorf("Expected labels to be restored after KeepLabels was reset")
+	}
+}
+
+func TestPullReusedGroupsInterned(t *testing.T) {
+	am := alertmanager.GetAlertmanagers()[0]
+	// every collection rotates interned strings, groups reused on the second
+	// pull must keep their strings in the pool
+	for i := 0; i < 2; i++ {
+		if err := am.Pull(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		models.RotateInterned()
+	}
+	for _, ag := range am.Alerts() {
+		for _, alert := range ag.Alerts {
+			for _, v := range alert.Labels {
+				if interned := models.Intern(strings.Clone(v)); unsafe.StringData(interned) != unsafe.StringData(v) {
+					t.Errorf("[%s] Label value %q of a reused group isn't shared with the string pool", am.Name, v)
+				}
+			}
+		}
+	}
+}
+
+func TestDedupAlertsIgnoredLabels(t *testing.T) {
+	if err := pullAlerts(); err != nil {
+		t.Error(err)
+	}
+	// count alerts that should remain once the instance label is ignored
+	expected := map[string]bool{}
+	for _, ag := range alertmanager.DedupAlerts() {
+		for _, alert := range ag.Alerts {
+			labels := map[string]string{}
+			for k, v := range alert.Labels {
+				if k != "instance" {
+					labels[k] = v
+				}
+			}
+			expected[fmt.Sprintf("%s %v", ag.ID, labels)] = true
+		}
+	}
+
+	config.Config.DedupIgnoredLabels = []string{"instance"}
+	alertGroups := alertmanager.DedupAlerts()
+	config.Config.DedupIgnoredLabels = []string{}
+
+	totalAlerts := 0
+	for _, ag := range alertGroups {
+		for _, alert := range ag.Alerts {
+			totalAlerts++
+			if _, found := alert.Labels["instance"]; found {
+				t.Errorf("Ignored label instance wasn't stripped from %v", alert.Labels)
+			}
+			names := map[string]bool{}
+			for _, am := range alert.Alertmanager {
+				if names[am.Name] {
+					t.Errorf("Alertmanager instance %s is listed more than once on %v", am.Name, alert.Labels)
+				}
+				names[am.Name] = true
+			}
+		}
+	}
+	if totalAlerts != len(expected) || totalAlerts >= 24 {
+		t.Errorf("Expected %d total alerts, got %d", len(expected), totalAlerts)
+	}
+}
+
+func TestPullConcurrentReads(t *testing.T) {
+	done := make(chan bool)
+	go func() {
+		defer close(done)
+		for i := 0; i < 5; i++ {
+			if err := pullAlerts(); err != nil {
+				t.Error(err)
+			}
+		}
+	}()
+
+	// readers should always see complete data from one of the pulls
+	for {
+		select {
+		case <-done:
+			return
+		default:
+			alertGroups := alertmanager.DedupAlerts()
+			if len(alertGroups) != 0 && len(alertGroups) != 10 {
+				t.Errorf("Expected %d alert groups, got %d", 10, len(alertGroups))
+			}
+			alertmanager.DedupSilences()
+			alertmanager.DedupColors()
+			alertmanager.DedupAutocomplete()
+		}
+	}
+}
+
 func TestDedupAutocomplete(t *testing.T) {
 	if err := pullAlerts(); err != nil {
 		t.Error(err)
@@ -77,15 +213,348 @@ func TestDedupAutocomplete(t *testing.T) {
 	ac := alertmanager.DedupAutocomplete()
 	// since we have alertmanager instance per mock adding new mocks will increase
 	// the number of hints, so we need to calculate the expected value here
-	// there should be 56 hints excluding @alertmanager ones, use that as our base
+	// there should be 65 hints excluding @alertmanager ones, use that as our base
 	// and add 2 hints per alertmanager instance (= and != hints)
 	mockCount := len(mock.ListAllMockURIs())
-	expected := 56 + mockCount*2
+	expected := 65 + mockCount*2
 	if len(ac) != expected {
 		t.Errorf("Expected %d autocomplete hints, got %d", expected, len(ac))
 	}
 }
 
+func TestDedupAutocompleteWeight(t *testing.T) {
+	if err := pullAlerts(); err != nil {
+		t.Error(err)
+	}
+	weights := map[string]int{}
+	for _, hint := range alertmanager.DedupAutocomplete() {
+		weights[hint.Value] = hint.Weight
+	}
+	// there are more Host_Down alerts than Memory_Usage_Too_High ones
+	if weights["alertname=Host_Down"] <= weights["alertname=Memory_Usage_Too_High"] {
+		t.Errorf("Expected alertname=Host_Down hint to have higher weight than alertname=Memory_Usage_Too_High, got %d <= %d",
+			weights["alertname=Host_Down"], weights["alertname=Memory_Usage_Too_High"])
+	}
+}
+
+func TestMaxAlerts(t *testing.T) {
+	config.Config.AlertmanagerMaxAlerts = 5
+	defer func() {
+		config.Config.AlertmanagerMaxAlerts = 0
+		if err := pullAlerts(); err != nil {
+			t.Error(err)
+		}
+	}()
+	if err := pullAlerts(); err != nil {
+		t.Error(err)
+	}
+
+	first := alertmanager.DedupAlerts()
+	for _, am := range alertmanager.GetAlertmanagers() {
+		total := 0
+		for _, ag := range am.Alerts() {
+			if len(ag.Alerts) == 0 {
+				t.Errorf("[%s] Group %s has no alerts", am.Name, ag.ID)
+			}
+			total += len(ag.Alerts)
+		}
+		if total != 5 {
+			t.Errorf("[%s] Expected %d alerts, got %d", am.Name, 5, total)
+		}
+	}
+
+	// the same alerts should be kept on every pull
+	if err := pullAlerts(); err != nil {
+		t.Error(err)
+	}
+	if !reflect.DeepEqual(first, alertmanager.DedupAlerts()) {
+		t.Errorf("Different alerts were kept after pulling the same alerts again")
+	}
+
+	families, err := prometheus.DefaultGatherer.Gather()
+	if err != nil {
+		t.Error(err)
+	}
+	found := map[string]bool{}
+	for _, family := range families {
+		for _, m := range family.GetMetric() {
+			switch family.GetName() {
+			case "unsee_dropped_alerts_count", "unsee_store_size_bytes":
+				if m.GetGauge().GetValue() <= 0 {
+					t.Errorf("Expected %s to be positive, got %v", family.GetName(), m.GetGauge().GetValue())
+				}
+				found[family.GetName()] = true
+			case "unsee_collected_silences_count":
+				found[family.GetName()] = true
+			}
+		}
+	}
+	for _, name := range []string{"unsee_collected_silences_count", "unsee_dropped_alerts_count", "unsee_store_size_bytes"} {
+		if !found[name] {
+			t.Errorf("Metric %s not found", name)
+		}
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	if err := pullAlerts(); err != nil {
+		t.Error(err)
+	}
+	expected := map[string]string{}
+	for _, ag := range alertmanager.DedupAlerts() {
+		expected[ag.ID] = ag.Hash
+	}
+
+	dir, err := ioutil.TempDir("", "unsee-snapshot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "snapshot.json")
+
+	if restored, err := alertmanager.LoadSnapshot(path); restored != 0 || err != nil {
+		t.Errorf("LoadSnapshot() on missing file returned %d, %v", restored, err)
+	}
+
+	if err = alertmanager.SaveSnapshot(path); err != nil {
+		t.Fatal(err)
+	}
+	restored, err := alertmanager.LoadSnapshot(path)
+	if err != nil {
+		t.Error(err)
+	}
+	if restored != len(alertmanager.GetAlertmanagers()) {
+		t.Errorf("Expected %d upstreams to be restored, got %d", len(alertmanager.GetAlertmanagers()), restored)
+	}
+	for _, am := range alertmanager.GetAlertmanagers() {
+		if !am.IsStale() {
+			t.Errorf("[%s] Expected upstream to be stale after restoring snapshot", am.Name)
+		}
+	}
+
+	alertGroups := alertmanager.DedupAlerts()
+	if len(alertGroups) != len(expected) {
+		t.Errorf("Expected %d alert groups after restoring snapshot, got %d", len(expected), len(alertGroups))
+	}
+	for _, ag := range alertGroups {
+		if ag.Hash != expected[ag.ID] {
+			t.Errorf("Alert group %s has hash %s after restoring snapshot, expected %s", ag.ID, ag.Hash, expected[ag.ID])
+		}
+	}
+
+	if err := pullAlerts(); err != nil {
+		t.Error(err)
+	}
+	for _, am := range alertmanager.GetAlertmanagers() {
+		if am.IsStale() {
+			t.Errorf("[%s] Expected upstream to not be stale after pulling", am.Name)
+		}
+	}
+
+	if err = ioutil.WriteFile(path, []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = alertmanager.LoadSnapshot(path); err == nil {
+		t.Errorf("LoadSnapshot() on invalid file didn't return any error")
+	}
+}
+
+func TestDedupAnnotationsMergePolicy(t *testing.T) {
+	defer func() {
+		config.Config.AnnotationsMergePolicy = alertmanager.AnnotationsMergeNewest
+		config.Config.AnnotationsMergePreferred = []string{}
+		if err := pullAlerts(); err != nil {
+			t.Error(err)
+		}
+	}()
+	if err := pullAlerts(); err != nil {
+		t.Error(err)
+	}
+
+	dir, err := ioutil.TempDir("", "unsee-annotations")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "snapshot.json")
+	if err = alertmanager.SaveSnapshot(path); err != nil {
+		t.Fatal(err)
+	}
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// give the same alert different annotations on the first two upstreams,
+	// the second one was updated more recently
+	snapshots := []map[string]interface{}{}
+	if err = json.Unmarshal(content, &snapshots); err != nil {
+		t.Fatal(err)
+	}
+	type annotation struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	}
+	overrides := []struct {
+		endsAt      string
+		annotations []annotation
+	}{
+		{endsAt: "2017-10-02T17:00:00Z", annotations: []annotation{{Name: "alert", Value: "old"}}},
+		{endsAt: "2017-10-02T18:00:00Z", annotations: []annotation{{Name: "alert", Value: "new"}, {Name: "summary", Value: "disk"}}},
+	}
+	for i, override := range overrides {
+		for _, ag := range snapshots[i]["alertGroups"].([]interface{}) {
+			for _, a := range ag.(map[string]interface{})["alerts"].([]interface{}) {
+				alert := a.(map[string]interface{})
+				labels := alert["labels"].(map[string]interface{})
+				if labels["alertname"] == "Free_Disk_Space_Too_Low" && labels["instance"] == "server5" {
+					alert["endsAt"] = override.endsAt
+					alert["annotations"] = override.annotations
+				}
+			}
+		}
+	}
+	if content, err = json.Marshal(snapshots); err != nil {
+		t.Fatal(err)
+	}
+	if err = ioutil.WriteFile(path, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = alertmanager.LoadSnapshot(path); err != nil {
+		t.Fatal(err)
+	}
+
+	getAnnotations := func(alertGroups []models.AlertGroup) map[string]string {
+		for _, ag := range alertGroups {
+			for _, alert := range ag.Alerts {
+				if alert.Labels["alertname"] == "Free_Disk_Space_Too_Low" && alert.Labels["instance"] == "server5" {
+					annotations := map[string]string{}
+					for _, a := range alert.Annotations {
+						annotations[a.Name] = a.Value
+					}
+					return annotations
+				}
+			}
+		}
+		return nil
+	}
+
+	for _, testCase := range []struct {
+		policy    string
+		preferred []string
+		expected  map[string]string
+	}{
+		{
+			policy:    alertmanager.AnnotationsMergeNewest,
+			preferred: []string{},
+			expected:  map[string]string{"alert": "new", "summary": "disk"},
+		},
+		{
+			policy:    alertmanager.AnnotationsMergePreferred,
+			preferred: []string{"unknown", snapshots[0]["name"].(string)},
+			expected:  map[string]string{"alert": "old"},
+		},
+		{
+			policy:    alertmanager.AnnotationsMergePreferred,
+			preferred: []string{"unknown"},
+			expected:  map[string]string{"alert": "new", "summary": "disk"},
+		},
+		{
+			policy:    alertmanager.AnnotationsMergeUnion,
+			preferred: []string{},
+			expected:  map[string]string{"alert": "new", "summary": "disk", "dashboard": "http://localhost/dashboard.html"},
+		},
+	} {
+		config.Config.AnnotationsMergePolicy = testCase.policy
+		config.Config.AnnotationsMergePreferred = testCase.preferred
+		if annotations := getAnnotations(alertmanager.DedupAlerts()); !reflect.DeepEqual(annotations, testCase.expected) {
+			t.Errorf("[%s %v] Expected annotations %v, got %v", testCase.policy, testCase.preferred, testCase.expected, annotations)
+		}
+		// the result must not depend on the order upstreams are merged in,
+		// groups merged by DedupUpstreamAlerts are never cached
+		for i := 0; i < 10; i++ {
+			if annotations := getAnnotations(alertmanager.DedupUpstreamAlerts(alertmanager.GetAlertmanagers())); !reflect.DeepEqual(annotations, testCase.expected) {
+				t.Errorf("[%s %v] Expected annotations %v on merge #%d, got %v", testCase.policy, testCase.preferred, testCase.expected, i, annotations)
+				break
+			}
+		}
+	}
+}
+
+func TestValidateAnnotationsMergePolicy(t *testing.T) {
+	for _, testCase := range []struct {
+		policy    string
+		preferred []string
+		valid     bool
+	}{
+		{policy: alertmanager.AnnotationsMergeNewest, valid: true},
+		{policy: alertmanager.AnnotationsMergeUnion, valid: true},
+		{policy: alertmanager.AnnotationsMergePreferred, preferred: []string{"am1"}, valid: true},
+		{policy: alertmanager.AnnotationsMergePreferred, preferred: []string{"am2", "am1"}, valid: true},
+		{policy: alertmanager.AnnotationsMergePreferred, preferred: []string{"am1", "am3"}},
+		{policy: alertmanager.AnnotationsMergePreferred},
+		{policy: "oldest"},
+		{policy: ""},
+	} {
+		err := alertmanager.ValidateAnnotationsMergePolicy(testCase.policy, testCase.preferred, []string{"am1", "am2"})
+		if (err == nil) != testCase.valid {
+			t.Errorf("ValidateAnnotationsMergePolicy(%q, %v) returned %v", testCase.policy, testCase.preferred, err)
+		}
+	}
+}
+
+func TestUpstreamDownAlert(t *testing.T) {
+	am := alertmanager.GetAlertmanagers()[0]
+	uri := am.URI
+	defer func() {
+		am.URI = uri
+		config.Config.AlertmanagerDownAlertAfter = 0
+		if err := pullAlerts(); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	findDownAlerts := func() []string {
+		names := []string{}
+		for _, ag := range alertmanager.DedupAlerts() {
+			if ag.Labels["alertname"] != alertmanager.UpstreamDownAlertName {
+				continue
+			}
+			for _, alert := range ag.Alerts {
+				names = append(names, alert.Labels["alertmanager"])
+			}
+		}
+		return names
+	}
+
+	am.URI = "file:///non-existing-file.abcdef"
+	for _, testCase := range []struct {
+		after    time.Duration
+		expected []string
+	}{
+		{after: 0, expected: []string{}},
+		{after: time.Hour, expected: []string{}},
+		{after: time.Nanosecond, expected: []string{am.Name}},
+	} {
+		config.Config.AlertmanagerDownAlertAfter = testCase.after
+		if err := am.Pull(context.Background()); err == nil {
+			t.Errorf("[%s] Pull from missing file didn't fail", testCase.after)
+		}
+		if names := findDownAlerts(); !reflect.DeepEqual(names, testCase.expected) {
+			t.Errorf("[%s] Expected %s alerts for %v, got %v", testCase.after, alertmanager.UpstreamDownAlertName, testCase.expected, names)
+		}
+	}
+
+	// recovered upstream should only have real alerts again
+	am.URI = uri
+	if err := am.Pull(context.Background()); err != nil {
+		t.Error(err)
+	}
+	if names := findDownAlerts(); len(names) != 0 {
+		t.Errorf("Got %s alerts after successful pull: %v", alertmanager.UpstreamDownAlertName, names)
+	}
+}
+
 func TestDedupColors(t *testing.T) {
 	os.Setenv("COLOR_LABELS_UNIQUE", "cluster instance @receiver")
 	os.Setenv("ALERTMANAGER_URIS", "default:http://localhost")
@@ -99,3 +568,31 @@ func TestDedupColors(t *testing.T) {
 		t.Errorf("Expected %d color keys, got %d", expected, len(colors))
 	}
 }
+
+func TestPullTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(noop.NewTracerProvider())
+
+	am := alertmanager.GetAlertmanagers()[0]
+	if err := am.Pull(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	parents := map[string]string{}
+	names := map[string]string{}
+	for _, span := range recorder.Ended() {
+		names[span.SpanContext().SpanID().String()] = span.Name()
+		parents[span.Name()] = span.Parent().SpanID().String()
+	}
+	for _, name := range []string{"detect version", "fetch silences", "detect JIRA links", "fetch alerts", "deduplicate alerts", "process alert groups"} {
+		parent, found := parents[name]
+		if !found {
+			t.Errorf("Span '%s' wasn't recorded", name)
+			continue
+		}
+		if names[parent] != "pull" {
+			t.Errorf("Span '%s' has parent '%s', expected 'pull'", name, names[parent])
+		}
+	}
+}
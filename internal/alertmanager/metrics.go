@@ -3,14 +3,32 @@ package alertmanager
 import "github.com/prometheus/client_golang/prometheus"
 
 type unseeCollector struct {
-	collectedAlerts *prometheus.Desc
-	collectedGroups *prometheus.Desc
-	cyclesTotal     *prometheus.Desc
-	errorsTotal     *prometheus.Desc
+	blackholedAlerts  *prometheus.Desc
+	clockSkew         *prometheus.Desc
+	collectedAlerts   *prometheus.Desc
+	collectedGroups   *prometheus.Desc
+	collectedSilences *prometheus.Desc
+	cyclesTotal       *prometheus.Desc
+	droppedAlerts     *prometheus.Desc
+	errorsTotal       *prometheus.Desc
+	paused            *prometheus.Desc
+	storeSize         *prometheus.Desc
 }
 
 func newUnseeCollector() *unseeCollector {
 	return &unseeCollector{
+		blackholedAlerts: prometheus.NewDesc(
+			"unsee_blackholed_alerts_count",
+			"Number of alerts dropped during the last collection because they matched ALERTS_BLACKHOLE_FILTERS",
+			[]string{"alertmanager"},
+			prometheus.Labels{},
+		),
+		clockSkew: prometheus.NewDesc(
+			"unsee_alertmanager_clock_skew_seconds",
+			"Number of seconds the Alertmanager clock is ahead of the local clock, only set for instances where clock skew could be detected",
+			[]string{"alertmanager"},
+			prometheus.Labels{},
+		),
 		collectedAlerts: prometheus.NewDesc(
 			"unsee_collected_alerts_count",
 			"Total number of alerts collected from Alertmanager API",
@@ -23,32 +41,108 @@ func newUnseeCollector() *unseeCollector {
 			[]string{"alertmanager", "receiver"},
 			prometheus.Labels{},
 		),
+		collectedSilences: prometheus.NewDesc(
+			"unsee_collected_silences_count",
+			"Total number of silences collected from Alertmanager API",
+			[]string{"alertmanager"},
+			prometheus.Labels{},
+		),
 		cyclesTotal: prometheus.NewDesc(
 			"unsee_collect_cycles_total",
 			"Total number of alert collection cycles run",
 			[]string{"alertmanager"},
 			prometheus.Labels{},
 		),
+		droppedAlerts: prometheus.NewDesc(
+			"unsee_dropped_alerts_count",
+			"Number of alerts dropped during the last collection because of ALERTMANAGER_MAX_ALERTS limit",
+			[]string{"alertmanager"},
+			prometheus.Labels{},
+		),
 		errorsTotal: prometheus.NewDesc(
 			"unsee_alertmanager_errors_total",
 			"Total number of errors encounter when requesting data from Alertmanager API",
-			[]string{"alertmanager", "endpoint"},
+			[]string{"alertmanager", "endpoint", "class"},
+			prometheus.Labels{},
+		),
+		paused: prometheus.NewDesc(
+			"unsee_alertmanager_paused",
+			"1 if collection from the Alertmanager instance is paused, 0 otherwise",
+			[]string{"alertmanager"},
+			prometheus.Labels{},
+		),
+		storeSize: prometheus.NewDesc(
+			"unsee_store_size_bytes",
+			"Approximate number of bytes used to store alerts and silences collected from Alertmanager API",
+			[]string{"alertmanager"},
 			prometheus.Labels{},
 		),
 	}
 }
 
 func (c *unseeCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.blackholedAlerts
+	ch <- c.clockSkew
 	ch <- c.collectedAlerts
 	ch <- c.collectedGroups
+	ch <- c.collectedSilences
 	ch <- c.cyclesTotal
+	ch <- c.droppedAlerts
 	ch <- c.errorsTotal
+	ch <- c.paused
+	ch <- c.storeSize
 }
 
 func (c *unseeCollector) Collect(ch chan<- prometheus.Metric) {
 	upstreams := GetAlertmanagers()
 
 	for _, am := range upstreams {
+		data := am.snapshot()
+
+		ch <- prometheus.MustNewConstMetric(
+			c.collectedSilences,
+			prometheus.GaugeValue,
+			float64(len(data.silences)),
+			am.Name,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			c.blackholedAlerts,
+			prometheus.GaugeValue,
+			float64(data.blackholedAlerts),
+			am.Name,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			c.droppedAlerts,
+			prometheus.GaugeValue,
+			float64(data.droppedAlerts),
+			am.Name,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			c.storeSize,
+			prometheus.GaugeValue,
+			float64(data.size),
+			am.Name,
+		)
+
+		paused := 0.0
+		if am.IsPaused() {
+			paused = 1
+		}
+		ch <- prometheus.MustNewConstMetric(
+			c.paused,
+			prometheus.GaugeValue,
+			paused,
+			am.Name,
+		)
+
+		if skew, known := am.ClockSkew(); known {
+			ch <- prometheus.MustNewConstMetric(
+				c.clockSkew,
+				prometheus.GaugeValue,
+				skew.Seconds(),
+				am.Name,
+			)
+		}
 
 		ch <- prometheus.MustNewConstMetric(
 			c.cyclesTotal,
@@ -56,13 +150,14 @@ func (c *unseeCollector) Collect(ch chan<- prometheus.Metric) {
 			am.metrics.cycles,
 			am.Name,
 		)
-		for key, val := range am.metrics.errors {
+		for key, val := range am.errorCounts() {
 			ch <- prometheus.MustNewConstMetric(
 				c.errorsTotal,
 				prometheus.CounterValue,
 				val,
 				am.Name,
-				key,
+				key.endpoint,
+				key.class,
 			)
 		}
 
@@ -72,7 +167,7 @@ func (c *unseeCollector) Collect(ch chan<- prometheus.Metric) {
 		alertsByReceiverByState := map[string]map[string]float64{}
 
 		// iterate all alert groups this instance stores
-		for _, group := range am.Alerts() {
+		for _, group := range data.alertGroups {
 			// count all groups per receiver
 			if _, found := groupsByReceiver[group.Receiver]; !found {
 				groupsByReceiver[group.Receiver] = 0
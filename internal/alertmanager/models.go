@@ -1,17 +1,27 @@
 package alertmanager
 
 import (
+	"context"
+	"crypto/sha1"
 	"fmt"
+	"io"
 	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/cloudflare/unsee/internal/config"
+	"github.com/cloudflare/unsee/internal/incidents"
 	"github.com/cloudflare/unsee/internal/mapper"
 	"github.com/cloudflare/unsee/internal/models"
+	"github.com/cloudflare/unsee/internal/tracing"
 	"github.com/cloudflare/unsee/internal/transform"
 	"github.com/cloudflare/unsee/internal/transport"
 
+	"github.com/cnf/structhash"
 	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 const (
@@ -19,9 +29,127 @@ const (
 	labelValueErrorsSilences = "silences"
 )
 
+// errorClasses lists all classes of errors tracked by the errors metric
+var errorClasses = []string{
+	transport.ErrorClassTimeout,
+	transport.ErrorClassDNS,
+	transport.ErrorClassConnection,
+	transport.ErrorClassHTTP,
+	transport.ErrorClassDecode,
+	transport.ErrorClassOther,
+}
+
+// errorsKey identifies a single series of the errors metric
+type errorsKey struct {
+	endpoint string
+	class    string
+}
+
 type alertmanagerMetrics struct {
 	cycles float64
-	errors map[string]float64
+	errors map[errorsKey]float64
+}
+
+func newAlertmanagerMetrics() alertmanagerMetrics {
+	m := alertmanagerMetrics{errors: map[errorsKey]float64{}}
+	for _, endpoint := range []string{labelValueErrorsAlerts, labelValueErrorsSilences} {
+		for _, class := range errorClasses {
+			m.errors[errorsKey{endpoint: endpoint, class: class}] = 0
+		}
+	}
+	return m
+}
+
+// processedGroup is an alert group generated during the last pull, together
+// with colors and autocomplete hints for its alerts, groups that didn't change
+// are reused on the next pull rather than processed again
+type processedGroup struct {
+	// checksum of raw alerts and silences the group was generated from
+	checksum     string
+	group        models.AlertGroup
+	colors       models.LabelsColorMap
+	autocomplete []models.Autocomplete
+}
+
+// getSilenceChecksums returns checksums of all silences keyed by silence ID
+func getSilenceChecksums(silences map[string]models.Silence) map[string]string {
+	silenceIDs := make([]string, 0, len(silences))
+	for silenceID := range silences {
+		silenceIDs = append(silenceIDs, silenceID)
+	}
+	checksums := make([]string, len(silenceIDs))
+	transform.ForEach(len(silenceIDs), func(i int) {
+		checksums[i] = fmt.Sprintf("%x", structhash.Sha1(silences[silenceIDs[i]], 1))
+	})
+
+	silenceChecksums := make(map[string]string, len(silenceIDs))
+	for i, silenceID := range silenceIDs {
+		silenceChecksums[silenceID] = checksums[i]
+	}
+	return silenceChecksums
+}
+
+// groupChecksum returns a checksum of raw alerts in a group and all silences
+// referenced by those alerts
+func groupChecksum(alerts map[string]models.Alert, silenceChecksums map[string]string) string {
+	alertCFPs := make([]string, 0, len(alerts))
+	for alertCFP := range alerts {
+		alertCFPs = append(alertCFPs, alertCFP)
+	}
+	sort.Strings(alertCFPs)
+
+	h := sha1.New()
+	// unique colors depend on the config, so include it
+	io.WriteString(h, strings.Join(config.Config.ColorLabelsUnique, " "))
+	// and so do scrubbed labels
+	io.WriteString(h, strings.Join(config.Config.DemoScrubLabels, " "))
+	for _, alertCFP := range alertCFPs {
+		alert := alerts[alertCFP]
+		io.WriteString(h, alertCFP)
+		// silence, inhibition and source aren't part of alert fingerprints
+		io.WriteString(h, alert.GeneratorURL)
+		io.WriteString(h, strings.Join(alert.InhibitedBy, " "))
+		for _, silenceID := range alert.SilencedBy {
+			io.WriteString(h, silenceID)
+			io.WriteString(h, silenceChecksums[silenceID])
+		}
+		// incidents are fetched separately, so they can change without any
+		// change to alerts
+		if incident := incidents.Lookup(&alert); incident != nil {
+			fmt.Fprintf(h, "%s %s %s", incident.ID, incident.Status, incident.URL)
+		}
+	}
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
+
+// upstreamData is a snapshot of all data pulled from an Alertmanager upstream,
+// it's never modified once stored, every pull builds a new snapshot and swaps
+// it with the old one, so readers never have to wait for a pull to finish
+type upstreamData struct {
+	alertGroups  []models.AlertGroup
+	groupCache   map[string]processedGroup
+	silences     map[string]models.Silence
+	colors       models.LabelsColorMap
+	autocomplete []models.Autocomplete
+	// number of alerts dropped because there were more than configured limit
+	droppedAlerts int
+	// number of alerts dropped because they matched blackhole filters
+	blackholedAlerts int
+	// approximate number of bytes used to store all alerts and silences
+	size int
+	// upstreamDown is true if the only alert stored is the synthetic one
+	// added when the upstream is failing
+	upstreamDown bool
+}
+
+func newUpstreamData() *upstreamData {
+	return &upstreamData{
+		alertGroups:  []models.AlertGroup{},
+		groupCache:   map[string]processedGroup{},
+		silences:     map[string]models.Silence{},
+		colors:       models.LabelsColorMap{},
+		autocomplete: []models.Autocomplete{},
+	}
 }
 
 // Alertmanager represents Alertmanager upstream instance
@@ -29,110 +157,185 @@ type Alertmanager struct {
 	URI     string        `json:"uri"`
 	Timeout time.Duration `json:"timeout"`
 	Name    string        `json:"name"`
-	// lock protects data access while updating
+	// lock protects error and collection time access while updating
 	lock sync.RWMutex
-	// fields for storing pulled data
-	alertGroups  []models.AlertGroup
-	silences     map[string]models.Silence
-	colors       models.LabelsColorMap
-	autocomplete []models.Autocomplete
-	lastError    string
+	// data holds the *upstreamData snapshot from the last pull
+	data      atomic.Value
+	lastError string
+	// stale is true if data was restored from a snapshot and wasn't pulled yet
+	stale bool
+	// lastPull is the time the last pull was started at
+	lastPull time.Time
+	// lastCollected is the time of the last successful pull
+	lastCollected time.Time
+	// firstCollected is the time of the first successful pull since startup,
+	// restoring data from a snapshot doesn't set it
+	firstCollected time.Time
+	// version is the Alertmanager version detected during the last pull
+	version string
+	// status is what the status API returned during the last pull that
+	// detected the version, nil if it never did
+	status *models.AlertmanagerStatus
+	// failingSince is the time of the first pull that failed since the last
+	// successful one, zero if the last pull was successful
+	failingSince time.Time
+	// clockSkew is how far the upstream clock was ahead of the local one
+	// during the last pull, clockSkewKnown is false if it couldn't be detected
+	clockSkew      time.Duration
+	clockSkewKnown bool
 	// metrics tracked per alertmanager instance
 	metrics alertmanagerMetrics
+	// paused is 1 if collection was paused, it's only accessed atomically
+	paused int32
 }
 
-func (am *Alertmanager) detectVersion() string {
+// detectVersion returns the version of Alertmanager and the difference between
+// its clock and the local one if the response included the Date header
+func (am *Alertmanager) detectVersion() (string, time.Duration, bool) {
 	// if everything fails assume Alertmanager is at latest possible version
 	defaultVersion := "999.0.0"
 
 	url, err := transport.JoinURL(am.URI, "api/v1/status")
 	if err != nil {
 		log.Errorf("Failed to join url '%s' and path 'api/v1/status': %s", am.URI, err)
-		return defaultVersion
+		return defaultVersion, 0, false
 	}
 	ver := alertmanagerVersion{}
-	err = transport.ReadJSON(url, am.Timeout, &ver)
+	start := time.Now()
+	date, err := transport.ReadJSONWithDate(url, am.Timeout, &ver)
+	skew, skewKnown := time.Duration(0), !date.IsZero()
+	if skewKnown {
+		skew = clockSkewFromDate(date, start, time.Now())
+	}
 	if err != nil {
 		log.Errorf("[%s] %s request failed: %s", am.Name, url, err.Error())
-		return defaultVersion
+		return defaultVersion, skew, skewKnown
 	}
 
 	if ver.Status != "success" {
 		log.Errorf("[%s] Request to %s returned status %s", am.Name, url, ver.Status)
-		return defaultVersion
+		return defaultVersion, skew, skewKnown
 	}
 
-	if ver.Data.VersionInfo.Version == "" {
+	if ver.Data.VersionInfo["version"] == "" {
 		log.Errorf("[%s] No version information in Alertmanager API at %s", am.Name, url)
-		return defaultVersion
+		return defaultVersion, skew, skewKnown
 	}
 
-	log.Infof("[%s] Remote Alertmanager version: %s", am.Name, ver.Data.VersionInfo.Version)
-	return ver.Data.VersionInfo.Version
+	am.setStatus(ver, time.Now())
+
+	log.Infof("[%s] Remote Alertmanager version: %s", am.Name, ver.Data.VersionInfo["version"])
+	return ver.Data.VersionInfo["version"], skew, skewKnown
+}
+
+// snapshot returns data from the last pull, it must not be modified
+func (am *Alertmanager) snapshot() *upstreamData {
+	return am.data.Load().(*upstreamData)
 }
 
 func (am *Alertmanager) clearData() {
-	am.lock.Lock()
-	am.alertGroups = []models.AlertGroup{}
-	am.silences = map[string]models.Silence{}
-	am.colors = models.LabelsColorMap{}
-	am.autocomplete = []models.Autocomplete{}
-	am.lock.Unlock()
+	am.data.Store(newUpstreamData())
 }
 
-func (am *Alertmanager) pullSilences(version string) error {
+func (am *Alertmanager) pullSilences(ctx context.Context, version string) (map[string]models.Silence, error) {
 	mapper, err := mapper.GetSilenceMapper(version)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	start := time.Now()
+	_, span := tracing.Start(ctx, "fetch silences")
 	silences, err := mapper.GetSilences(am.URI, am.Timeout)
 	if err != nil {
-		return err
+		tracing.Fail(span, err)
+		span.End()
+		return nil, err
 	}
+	span.SetAttributes(attribute.Int("silences", len(silences)))
+	span.End()
 	log.Infof("[%s] Got %d silences(s) in %s", am.Name, len(silences), time.Since(start))
 
 	log.Infof("[%s] Detecting JIRA links in silences (%d)", am.Name, len(silences))
+	_, span = tracing.Start(ctx, "detect JIRA links")
+	transform.ForEach(len(silences), func(i int) {
+		transform.ScrubSilence(&silences[i])
+		silences[i].JiraID, silences[i].JiraURL = transform.DetectJIRAs(&silences[i])
+		silences[i].StartsAtDisplay = transform.FormatTime(silences[i].StartsAt)
+		silences[i].EndsAtDisplay = transform.FormatTime(silences[i].EndsAt)
+		silences[i].CreatedAtDisplay = transform.FormatTime(silences[i].CreatedAt)
+	})
+	span.End()
 	silenceMap := map[string]models.Silence{}
 	for _, silence := range silences {
-		silence.JiraID, silence.JiraURL = transform.DetectJIRAs(&silence)
 		silenceMap[silence.ID] = silence
 	}
 
-	am.lock.Lock()
-	am.silences = silenceMap
-	am.lock.Unlock()
+	return silenceMap, nil
+}
 
-	return nil
+// retainExpiredSilences copies silences from the previous pull that are no
+// longer returned by Alertmanager, but expired within
+// SILENCE_EXPIRED_RETENTION, so recently expired silences can still be listed,
+// it returns the number of retained silences
+func retainExpiredSilences(silences, previous map[string]models.Silence, now time.Time) int {
+	if config.Config.SilenceExpiredRetention <= 0 {
+		return 0
+	}
+	retained := 0
+	for id, silence := range previous {
+		if _, found := silences[id]; found {
+			continue
+		}
+		if silence.StateAt(now) == models.SilenceStateExpired && now.Sub(silence.EndsAt) <= config.Config.SilenceExpiredRetention {
+			silences[id] = silence
+			retained++
+		}
+	}
+	return retained
 }
 
-func (am *Alertmanager) pullAlerts(version string) error {
+// pullAlerts fetches alerts and builds a new data snapshot from those and
+// silences pulled before
+func (am *Alertmanager) pullAlerts(ctx context.Context, version string, silences map[string]models.Silence) (*upstreamData, error) {
 	mapper, err := mapper.GetAlertMapper(version)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	start := time.Now()
+	_, span := tracing.Start(ctx, "fetch alerts")
 	groups, err := mapper.GetAlerts(am.URI, am.Timeout)
 	if err != nil {
-		return err
+		tracing.Fail(span, err)
+		span.End()
+		return nil, err
 	}
+	span.SetAttributes(attribute.Int("groups", len(groups)))
+	span.End()
 	log.Infof("[%s] Got %d alert group(s) in %s", am.Name, len(groups), time.Since(start))
 
 	log.Infof("[%s] Deduplicating alert groups (%d)", am.Name, len(groups))
+	_, span = tracing.Start(ctx, "deduplicate alerts")
 	uniqueGroups := map[string]models.AlertGroup{}
 	uniqueAlerts := map[string]map[string]models.Alert{}
+	blackholeFilters := newBlackholeFilters()
+	blackholed := 0
 	for _, ag := range groups {
 		agID := ag.LabelsFingerprint()
-		if _, found := uniqueGroups[agID]; !found {
-			uniqueGroups[agID] = models.AlertGroup{
-				Receiver: ag.Receiver,
-				Labels:   ag.Labels,
-				ID:       agID,
-			}
-		}
 		for _, alert := range ag.Alerts {
+			if am.isBlackholed(alert, silences, blackholeFilters) {
+				blackholed++
+				continue
+			}
+			// groups are only created for alerts that weren't dropped, so
+			// there are no empty groups
+			if _, found := uniqueGroups[agID]; !found {
+				uniqueGroups[agID] = models.AlertGroup{
+					Receiver: models.Intern(ag.Receiver),
+					Labels:   models.InternLabels(transform.ScrubLabels(ag.Labels)),
+					ID:       agID,
+				}
+			}
 			if _, found := uniqueAlerts[agID]; !found {
 				uniqueAlerts[agID] = map[string]models.Alert{}
 			}
@@ -144,126 +347,311 @@ func (am *Alertmanager) pullAlerts(version string) error {
 
 	}
 
+	if blackholed > 0 {
+		log.Infof("[%s] Dropped %d alert(s) matching blackhole filters", am.Name, blackholed)
+	}
+
+	dropped := 0
+	if config.Config.AlertmanagerMaxAlerts > 0 {
+		dropped = truncateAlerts(uniqueGroups, uniqueAlerts, config.Config.AlertmanagerMaxAlerts)
+		if dropped > 0 {
+			log.Warningf("[%s] Collected more than %d alerts, %d alert(s) were dropped", am.Name, config.Config.AlertmanagerMaxAlerts, dropped)
+		}
+	}
+
+	span.SetAttributes(attribute.Int("groups", len(uniqueGroups)), attribute.Int("dropped", dropped), attribute.Int("blackholed", blackholed))
+	span.End()
+
+	_, span = tracing.Start(ctx, "process alert groups")
+	silenceChecksums := getSilenceChecksums(silences)
+	previous := am.snapshot().groupCache
+
 	dedupedGroups := []models.AlertGroup{}
+	groupCache := map[string]processedGroup{}
 	colors := models.LabelsColorMap{}
 	autocompleteMap := map[string]models.Autocomplete{}
 
 	log.Infof("[%s] Processing unique alert groups (%d)", am.Name, len(uniqueGroups))
+	agList := make([]models.AlertGroup, 0, len(uniqueGroups))
 	for _, ag := range uniqueGroups {
-		alerts := models.AlertList{}
-		for _, alert := range uniqueAlerts[ag.ID] {
-
-			silences := map[string]models.Silence{}
-			for _, silenceID := range alert.SilencedBy {
-				silence, err := am.SilenceByID(silenceID)
-				if err == nil {
-					silences[silenceID] = silence
-				}
-			}
-			alert.Alertmanager = []models.AlertmanagerInstance{
-				models.AlertmanagerInstance{
-					Name:     am.Name,
-					URI:      am.URI,
-					State:    alert.State,
-					StartsAt: alert.StartsAt,
-					EndsAt:   alert.EndsAt,
-					Source:   alert.GeneratorURL,
-					Silences: silences,
-				},
-			}
+		agList = append(agList, ag)
+	}
+	processed := make([]processedGroup, len(agList))
+	var reused int64
+	transform.ForEach(len(agList), func(i int) {
+		ag := agList[i]
+		checksum := groupChecksum(uniqueAlerts[ag.ID], silenceChecksums)
+		pg, found := previous[ag.ID]
+		if found && pg.checksum == checksum {
+			atomic.AddInt64(&reused, 1)
+			reinternGroup(pg.group)
+		} else {
+			pg = am.processGroup(ag, uniqueAlerts[ag.ID], silences)
+			pg.checksum = checksum
+		}
+		processed[i] = pg
+	})
 
-			transform.ColorLabel(colors, "@receiver", alert.Receiver)
-			for k, v := range alert.Labels {
-				transform.ColorLabel(colors, k, v)
-			}
+	for _, pg := range processed {
+		groupCache[pg.group.ID] = pg
 
-			alert.UpdateFingerprints()
-			alerts = append(alerts, alert)
+		for labelName, valueMap := range pg.colors {
+			if _, found := colors[labelName]; !found {
+				colors[labelName] = map[string]models.LabelColors{}
+			}
+			for labelVal, labelColors := range valueMap {
+				colors[labelName][labelVal] = labelColors
+			}
 		}
 
-		for _, hint := range transform.BuildAutocomplete(alerts) {
+		for _, hint := range pg.autocomplete {
+			if h, found := autocompleteMap[hint.Value]; found {
+				hint.Weight += h.Weight
+			}
 			autocompleteMap[hint.Value] = hint
 		}
 
-		sort.Sort(&alerts)
-		ag.Alerts = alerts
-
-		// Hash is a checksum of all alerts, used to tell when any alert in the group changed
-		ag.Hash = ag.ContentFingerprint()
-
-		dedupedGroups = append(dedupedGroups, ag)
+		dedupedGroups = append(dedupedGroups, pg.group)
 	}
+	log.Infof("[%s] Reused %d unchanged alert group(s)", am.Name, atomic.LoadInt64(&reused))
+	span.SetAttributes(attribute.Int("groups", len(agList)), attribute.Int64("reused", atomic.LoadInt64(&reused)))
+	span.End()
 
 	log.Infof("[%s] Merging autocomplete data (%d)", am.Name, len(autocompleteMap))
 	autocomplete := []models.Autocomplete{}
 	for _, hint := range autocompleteMap {
 		autocomplete = append(autocomplete, hint)
 	}
+	// every group is limited separately, so limit merged hints again
+	autocomplete = transform.LimitAutocomplete(autocomplete)
+
+	data := &upstreamData{
+		alertGroups:      dedupedGroups,
+		groupCache:       groupCache,
+		silences:         silences,
+		colors:           colors,
+		autocomplete:     autocomplete,
+		droppedAlerts:    dropped,
+		blackholedAlerts: blackholed,
+	}
+	data.size = data.approximateSize()
+	return data, nil
+}
 
-	am.lock.Lock()
-	am.alertGroups = dedupedGroups
-	am.colors = colors
-	am.autocomplete = autocomplete
-	am.lock.Unlock()
+// truncateAlerts removes alerts from unique groups so there's no more than
+// maxAlerts left, groups are sorted by ID and alerts by labels fingerprint
+// first, so the same alerts are kept on every pull, it returns the number of
+// alerts removed
+func truncateAlerts(uniqueGroups map[string]models.AlertGroup, uniqueAlerts map[string]map[string]models.Alert, maxAlerts int) int {
+	agIDs := make([]string, 0, len(uniqueGroups))
+	for agID := range uniqueGroups {
+		agIDs = append(agIDs, agID)
+	}
+	sort.Strings(agIDs)
+
+	kept := 0
+	dropped := 0
+	for _, agID := range agIDs {
+		alerts := uniqueAlerts[agID]
+		if kept+len(alerts) <= maxAlerts {
+			kept += len(alerts)
+			continue
+		}
 
-	return nil
+		alertCFPs := make([]string, 0, len(alerts))
+		for alertCFP := range alerts {
+			alertCFPs = append(alertCFPs, alertCFP)
+		}
+		sort.Slice(alertCFPs, func(i, j int) bool {
+			a, b := alerts[alertCFPs[i]], alerts[alertCFPs[j]]
+			if a.LabelsFingerprint() != b.LabelsFingerprint() {
+				return a.LabelsFingerprint() < b.LabelsFingerprint()
+			}
+			return alertCFPs[i] < alertCFPs[j]
+		})
+		for _, alertCFP := range alertCFPs[maxAlerts-kept:] {
+			delete(alerts, alertCFP)
+			dropped++
+		}
+		kept = maxAlerts
+		if len(alerts) == 0 {
+			delete(uniqueGroups, agID)
+			delete(uniqueAlerts, agID)
+		}
+	}
+	return dropped
+}
+
+// reinternGroup passes all interned strings of a group reused from the previous
+// pull back to the string pool, otherwise those would be dropped from it by
+// models.RotateInterned() and any new alert with the same labels would get
+// another copy of them
+func reinternGroup(ag models.AlertGroup) {
+	for _, alert := range ag.Alerts {
+		for k, v := range alert.Labels {
+			models.Intern(k)
+			models.Intern(v)
+		}
+		models.Intern(alert.Receiver)
+	}
+}
+
+// processGroup generates an alert group from a set of unique raw alerts,
+// it will attach Alertmanager instance details to every alert and generate
+// colors and autocomplete hints
+func (am *Alertmanager) processGroup(ag models.AlertGroup, rawAlerts map[string]models.Alert, silences map[string]models.Silence) processedGroup {
+	alerts := models.AlertList{}
+	for _, alert := range rawAlerts {
+		transform.ScrubAlert(&alert)
+		alertSilences := map[string]models.Silence{}
+		for _, silenceID := range alert.SilencedBy {
+			if silence, found := silences[silenceID]; found {
+				alertSilences[silenceID] = silence
+			}
+		}
+		alert.Alertmanager = []models.AlertmanagerInstance{
+			models.AlertmanagerInstance{
+				Name:     am.Name,
+				URI:      am.URI,
+				State:    alert.State,
+				StartsAt: alert.StartsAt,
+				EndsAt:   alert.EndsAt,
+				Source:   alert.GeneratorURL,
+				Silences: alertSilences,
+			},
+		}
+		transform.NormalizeSeverity(&alert)
+		transform.InjectRunbook(&alert)
+		transform.InjectAnnotations(&alert)
+		alert.Links = transform.GrafanaLinks(&alert)
+		alert.Incident = incidents.Lookup(&alert)
+		alerts = append(alerts, alert)
+	}
+
+	// extra transforms can modify labels, so those are interned afterwards
+	alerts = transform.ApplyTransforms(alerts)
+
+	colors := models.LabelsColorMap{}
+	for i := range alerts {
+		// label names and values are repeated across many alerts
+		alerts[i].Labels = models.InternLabels(alerts[i].Labels)
+		alerts[i].Receiver = models.Intern(alerts[i].Receiver)
+
+		transform.ColorLabel(colors, "@receiver", alerts[i].Receiver)
+		for k, v := range alerts[i].Labels {
+			transform.ColorLabel(colors, k, v)
+		}
+
+		alerts[i].UpdateFingerprints()
+	}
+
+	sort.Sort(&alerts)
+	ag.Alerts = alerts
+
+	// Hash is a checksum of all alerts, used to tell when any alert in the group changed
+	ag.Hash = ag.ContentFingerprint()
+
+	return processedGroup{
+		group:        ag,
+		colors:       colors,
+		autocomplete: transform.BuildAutocomplete(alerts),
+	}
 }
 
 // Pull data from upstream Alertmanager instance
-func (am *Alertmanager) Pull() error {
+func (am *Alertmanager) Pull(ctx context.Context) error {
+	am.lock.Lock()
 	am.metrics.cycles++
+	am.lastPull = time.Now()
+	am.lock.Unlock()
+	defer func() {
+		am.lock.Lock()
+		am.stale = false
+		am.lock.Unlock()
+	}()
+
+	ctx, span := tracing.Start(ctx, "pull", attribute.String("alertmanager", am.Name))
+	defer span.End()
+
+	_, versionSpan := tracing.Start(ctx, "detect version")
+	version, skew, skewKnown := am.detectVersion()
+	versionSpan.SetAttributes(attribute.String("version", version))
+	versionSpan.End()
+	am.lock.Lock()
+	am.version = version
+	am.lock.Unlock()
 
-	version := am.detectVersion()
-
-	err := am.pullSilences(version)
+	silences, err := am.pullSilences(ctx, version)
 	if err != nil {
-		am.clearData()
-		am.setError(err.Error())
-		am.metrics.errors[labelValueErrorsSilences]++
+		am.setClockSkew(skew, skewKnown)
+		am.pullFailed(err)
+		am.countError(labelValueErrorsSilences, err)
+		tracing.Fail(span, err)
 		return err
 	}
+	recordSilenceAuthors(silences)
+	if !skewKnown {
+		skew, skewKnown = clockSkewFromSilences(silences, time.Now())
+	}
+	am.setClockSkew(skew, skewKnown)
+	if am.IsClockSkewed() {
+		log.Warningf("[%s] Upstream clock is %s off from the local clock", am.Name, skew)
+	}
+	if retained := retainExpiredSilences(silences, am.snapshot().silences, time.Now()); retained > 0 {
+		log.Infof("[%s] Retained %d expired silence(s) no longer returned by Alertmanager", am.Name, retained)
+	}
 
-	err = am.pullAlerts(version)
+	data, err := am.pullAlerts(ctx, version, silences)
 	if err != nil {
-		am.clearData()
-		am.setError(err.Error())
-		am.metrics.errors[labelValueErrorsAlerts]++
+		am.pullFailed(err)
+		am.countError(labelValueErrorsAlerts, err)
+		tracing.Fail(span, err)
 		return err
 	}
 
+	am.data.Store(data)
+	am.lock.Lock()
 	am.lastError = ""
+	am.lastCollected = time.Now()
+	if am.firstCollected.IsZero() {
+		am.firstCollected = am.lastCollected
+	}
+	am.failingSince = time.Time{}
+	am.lock.Unlock()
 	return nil
 }
 
 // Alerts returns a copy of all alert groups
 func (am *Alertmanager) Alerts() []models.AlertGroup {
-	am.lock.RLock()
-	defer am.lock.RUnlock()
+	data := am.snapshot()
 
-	alerts := make([]models.AlertGroup, len(am.alertGroups))
-	copy(alerts, am.alertGroups)
+	alerts := make([]models.AlertGroup, len(data.alertGroups))
+	copy(alerts, data.alertGroups)
 	return alerts
 }
 
 // SilenceByID allows to query for a silence by it's ID, returns error if not found
 func (am *Alertmanager) SilenceByID(id string) (models.Silence, error) {
-	am.lock.RLock()
-	defer am.lock.RUnlock()
-
-	s, found := am.silences[id]
+	s, found := am.snapshot().silences[id]
 	if !found {
 		return models.Silence{}, fmt.Errorf("Silence '%s' not found", id)
 	}
 	return s, nil
 }
 
+// Silences returns a copy of all silences
+func (am *Alertmanager) Silences() map[string]models.Silence {
+	silences := map[string]models.Silence{}
+	for id, silence := range am.snapshot().silences {
+		silences[id] = silence
+	}
+	return silences
+}
+
 // Colors returns a copy of all color maps
 func (am *Alertmanager) Colors() models.LabelsColorMap {
-	am.lock.RLock()
-	defer am.lock.RUnlock()
-
 	colors := models.LabelsColorMap{}
-	for k, v := range am.colors {
+	for k, v := range am.snapshot().colors {
 		colors[k] = map[string]models.LabelColors{}
 		for nk, nv := range v {
 			colors[k][nk] = nv
@@ -274,19 +662,32 @@ func (am *Alertmanager) Colors() models.LabelsColorMap {
 
 // Autocomplete returns a copy of all autocomplete data
 func (am *Alertmanager) Autocomplete() []models.Autocomplete {
-	am.lock.RLock()
-	defer am.lock.RUnlock()
+	data := am.snapshot()
 
-	autocomplete := make([]models.Autocomplete, len(am.autocomplete))
-	copy(autocomplete, am.autocomplete)
+	autocomplete := make([]models.Autocomplete, len(data.autocomplete))
+	copy(autocomplete, data.autocomplete)
 	return autocomplete
 }
 
-func (am *Alertmanager) setError(err string) {
+// countError increments the errors metric for given endpoint and the class
+// of err
+func (am *Alertmanager) countError(endpoint string, err error) {
 	am.lock.Lock()
 	defer am.lock.Unlock()
 
-	am.lastError = err
+	am.metrics.errors[errorsKey{endpoint: endpoint, class: transport.ErrorClass(err)}]++
+}
+
+// errorCounts returns a copy of the errors metric values
+func (am *Alertmanager) errorCounts() map[errorsKey]float64 {
+	am.lock.RLock()
+	defer am.lock.RUnlock()
+
+	counts := make(map[errorsKey]float64, len(am.metrics.errors))
+	for key, val := range am.metrics.errors {
+		counts[key] = val
+	}
+	return counts
 }
 
 func (am *Alertmanager) Error() string {
@@ -295,3 +696,31 @@ func (am *Alertmanager) Error() string {
 
 	return am.lastError
 }
+
+// IsStale returns true if data was restored from a snapshot and upstream
+// wasn't pulled since
+func (am *Alertmanager) IsStale() bool {
+	am.lock.RLock()
+	defer am.lock.RUnlock()
+
+	return am.stale
+}
+
+// LastCollected returns the time of the last successful pull, it will be zero
+// if data was never pulled
+func (am *Alertmanager) LastCollected() time.Time {
+	am.lock.RLock()
+	defer am.lock.RUnlock()
+
+	return am.lastCollected
+}
+
+// FirstCollected returns the time of the first successful pull since startup,
+// it will be zero if data was never pulled, even if it was restored from a
+// snapshot
+func (am *Alertmanager) FirstCollected() time.Time {
+	am.lock.RLock()
+	defer am.lock.RUnlock()
+
+	return am.firstCollected
+}
@@ -0,0 +1,21 @@
+package alertmanager
+
+import "sync/atomic"
+
+// Pause stops collection from the upstream until Resume is called, alerts and
+// silences from the last collection are kept, it returns false if collection
+// was already paused
+func (am *Alertmanager) Pause() bool {
+	return atomic.CompareAndSwapInt32(&am.paused, 0, 1)
+}
+
+// Resume starts collection from the upstream again after Pause, it returns
+// false if collection wasn't paused
+func (am *Alertmanager) Resume() bool {
+	return atomic.CompareAndSwapInt32(&am.paused, 1, 0)
+}
+
+// IsPaused returns true if collection from the upstream is paused
+func (am *Alertmanager) IsPaused() bool {
+	return atomic.LoadInt32(&am.paused) == 1
+}
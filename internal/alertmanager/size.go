@@ -0,0 +1,57 @@
+package alertmanager
+
+import (
+	"unsafe"
+
+	"github.com/cloudflare/unsee/internal/models"
+)
+
+var (
+	alertGroupSize           = int(unsafe.Sizeof(models.AlertGroup{}))
+	alertSize                = int(unsafe.Sizeof(models.Alert{}))
+	alertmanagerInstanceSize = int(unsafe.Sizeof(models.AlertmanagerInstance{}))
+	annotationSize           = int(unsafe.Sizeof(models.Annotation{}))
+	silenceSize              = int(unsafe.Sizeof(models.Silence{}))
+)
+
+func labelsSize(labels map[string]string) int {
+	size := 0
+	for k, v := range labels {
+		size += len(k) + len(v)
+	}
+	return size
+}
+
+func silenceDataSize(silence models.Silence) int {
+	size := silenceSize + len(silence.ID) + len(silence.CreatedBy) + len(silence.Comment) + len(silence.JiraID) + len(silence.JiraURL)
+	for _, m := range silence.Matchers {
+		size += len(m.Name) + len(m.Value)
+	}
+	return size
+}
+
+// approximateSize returns the number of bytes used to store alerts and
+// silences, it only counts struct sizes and string lengths, so shared strings
+// are counted multiple times and map overhead isn't counted at all, which
+// makes it good enough to compare upstreams or track growth, but it won't
+// match the actual memory usage
+func (d *upstreamData) approximateSize() int {
+	size := 0
+	for _, ag := range d.alertGroups {
+		size += alertGroupSize + len(ag.Receiver) + len(ag.ID) + len(ag.Hash) + labelsSize(ag.Labels)
+		for _, alert := range ag.Alerts {
+			size += alertSize + len(alert.Receiver) + len(alert.State) + len(alert.Fingerprint) + len(alert.GeneratorURL) + labelsSize(alert.Labels)
+			for _, a := range alert.Annotations {
+				size += annotationSize + len(a.Name) + len(a.Value)
+			}
+			for _, am := range alert.Alertmanager {
+				// silences are counted only once below
+				size += alertmanagerInstanceSize + len(am.Name) + len(am.URI) + len(am.State) + len(am.Source)
+			}
+		}
+	}
+	for _, silence := range d.silences {
+		size += silenceDataSize(silence)
+	}
+	return size
+}
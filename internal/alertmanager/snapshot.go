@@ -0,0 +1,164 @@
+package alertmanager
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/cloudflare/unsee/internal/models"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// snapshotAlert is an alert with fields that are never exposed in the API
+// but are needed to restore it
+type snapshotAlert struct {
+	models.Alert
+	GeneratorURL string   `json:"generatorURL"`
+	SilencedBy   []string `json:"silencedBy"`
+	InhibitedBy  []string `json:"inhibitedBy"`
+}
+
+type snapshotGroup struct {
+	models.AlertGroup
+	Alerts []snapshotAlert `json:"alerts"`
+}
+
+// upstreamSnapshot is the data collected from an Alertmanager upstream
+// written to disk, so it can be restored after restart
+type upstreamSnapshot struct {
+	Name          string                    `json:"name"`
+	URI           string                    `json:"uri"`
+	AlertGroups   []snapshotGroup           `json:"alertGroups"`
+	Silences      map[string]models.Silence `json:"silences"`
+	Colors        models.LabelsColorMap     `json:"colors"`
+	Autocomplete  []models.Autocomplete     `json:"autocomplete"`
+	LastCollected time.Time                 `json:"lastCollected"`
+}
+
+// SaveSnapshot writes data collected from all Alertmanager upstreams to a
+// file, it's written to a temporary file first and then renamed, so a
+// partially written snapshot is never left behind
+func SaveSnapshot(path string) error {
+	snapshots := []upstreamSnapshot{}
+	for _, am := range GetAlertmanagers() {
+		if am.LastCollected().IsZero() {
+			// nothing was ever collected, don't overwrite data from previous runs
+			// with an empty snapshot
+			continue
+		}
+		data := am.snapshot()
+		if data.upstreamDown {
+			// synthetic alerts would be restored as if they were collected
+			continue
+		}
+		groups := make([]snapshotGroup, 0, len(data.alertGroups))
+		for _, ag := range data.alertGroups {
+			sg := snapshotGroup{AlertGroup: ag, Alerts: make([]snapshotAlert, 0, len(ag.Alerts))}
+			for _, alert := range ag.Alerts {
+				sg.Alerts = append(sg.Alerts, snapshotAlert{
+					Alert:        alert,
+					GeneratorURL: alert.GeneratorURL,
+					SilencedBy:   alert.SilencedBy,
+					InhibitedBy:  alert.InhibitedBy,
+				})
+			}
+			groups = append(groups, sg)
+		}
+		snapshots = append(snapshots, upstreamSnapshot{
+			Name:          am.Name,
+			URI:           am.URI,
+			AlertGroups:   groups,
+			Silences:      data.silences,
+			Colors:        data.colors,
+			Autocomplete:  data.autocomplete,
+			LastCollected: am.LastCollected(),
+		})
+	}
+
+	content, err := json.Marshal(snapshots)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path))
+	if err != nil {
+		return err
+	}
+	if _, err = tmp.Write(content); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// LoadSnapshot restores data saved with SaveSnapshot, only upstreams with the
+// same name and URI as in the snapshot are restored and are marked as stale
+// until they're pulled again, it returns the number of restored upstreams,
+// missing snapshot file isn't an error
+func LoadSnapshot(path string) (int, error) {
+	content, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	snapshots := []upstreamSnapshot{}
+	if err = json.Unmarshal(content, &snapshots); err != nil {
+		return 0, err
+	}
+
+	restored := 0
+	for _, s := range snapshots {
+		am := GetAlertmanagerByName(s.Name)
+		if am == nil || am.URI != s.URI {
+			log.Warningf("[%s] Alertmanager upstream from the snapshot isn't configured, skipping", s.Name)
+			continue
+		}
+
+		data := newUpstreamData()
+		for _, sg := range s.AlertGroups {
+			ag := sg.AlertGroup
+			ag.Alerts = make(models.AlertList, 0, len(sg.Alerts))
+			for _, sa := range sg.Alerts {
+				alert := sa.Alert
+				alert.GeneratorURL = sa.GeneratorURL
+				alert.SilencedBy = sa.SilencedBy
+				alert.InhibitedBy = sa.InhibitedBy
+				// fingerprints aren't stored
+				alert.UpdateFingerprints()
+				ag.Alerts = append(ag.Alerts, alert)
+			}
+			data.alertGroups = append(data.alertGroups, ag)
+		}
+		if s.Silences != nil {
+			data.silences = s.Silences
+		}
+		if s.Colors != nil {
+			data.colors = s.Colors
+		}
+		if s.Autocomplete != nil {
+			data.autocomplete = s.Autocomplete
+		}
+		data.size = data.approximateSize()
+
+		am.data.Store(data)
+		am.lock.Lock()
+		am.lastCollected = s.LastCollected
+		am.stale = true
+		am.lock.Unlock()
+
+		log.Infof("[%s] Restored %d alert group(s) and %d silence(s) collected at %s", am.Name, len(data.alertGroups), len(data.silences), s.LastCollected)
+		restored++
+	}
+	return restored, nil
+}
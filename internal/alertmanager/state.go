@@ -0,0 +1,81 @@
+package alertmanager
+
+import "time"
+
+// UpstreamCounts tracks the number of objects stored for an upstream
+type UpstreamCounts struct {
+	AlertGroups      int `json:"alertGroups"`
+	Alerts           int `json:"alerts"`
+	Silences         int `json:"silences"`
+	Colors           int `json:"colors"`
+	Autocomplete     int `json:"autocomplete"`
+	CachedGroups     int `json:"cachedGroups"`
+	DroppedAlerts    int `json:"droppedAlerts"`
+	BlackholedAlerts int `json:"blackholedAlerts"`
+	SizeBytes        int `json:"sizeBytes"`
+}
+
+// UpstreamState is a dump of everything unsee knows about an upstream, it's
+// only meant for debugging and its format can change at any time
+type UpstreamState struct {
+	Name          string    `json:"name"`
+	URI           string    `json:"uri"`
+	Timeout       string    `json:"timeout"`
+	Version       string    `json:"version"`
+	Error         string    `json:"error"`
+	Stale         bool      `json:"stale"`
+	LastPull      time.Time `json:"lastPull"`
+	LastCollected time.Time `json:"lastCollected"`
+	Cycles        float64   `json:"cycles"`
+	// ClockSkew is empty if it couldn't be detected
+	ClockSkew string `json:"clockSkew"`
+	// endpoint -> error class -> number of errors
+	Errors map[string]map[string]float64 `json:"errors"`
+	Counts UpstreamCounts                `json:"counts"`
+}
+
+// State returns the internal state of this upstream
+func (am *Alertmanager) State() UpstreamState {
+	data := am.snapshot()
+
+	am.lock.RLock()
+	state := UpstreamState{
+		Name:          am.Name,
+		URI:           am.URI,
+		Timeout:       am.Timeout.String(),
+		Version:       am.version,
+		Error:         am.lastError,
+		Stale:         am.stale,
+		LastPull:      am.lastPull,
+		LastCollected: am.lastCollected,
+		Cycles:        am.metrics.cycles,
+		Errors:        map[string]map[string]float64{},
+	}
+	if am.clockSkewKnown {
+		state.ClockSkew = am.clockSkew.String()
+	}
+	for key, val := range am.metrics.errors {
+		if _, found := state.Errors[key.endpoint]; !found {
+			state.Errors[key.endpoint] = map[string]float64{}
+		}
+		state.Errors[key.endpoint][key.class] = val
+	}
+	am.lock.RUnlock()
+
+	state.Counts = UpstreamCounts{
+		AlertGroups:      len(data.alertGroups),
+		Silences:         len(data.silences),
+		Autocomplete:     len(data.autocomplete),
+		CachedGroups:     len(data.groupCache),
+		DroppedAlerts:    data.droppedAlerts,
+		BlackholedAlerts: data.blackholedAlerts,
+		SizeBytes:        data.size,
+	}
+	for _, ag := range data.alertGroups {
+		state.Counts.Alerts += len(ag.Alerts)
+	}
+	for _, values := range data.colors {
+		state.Counts.Colors += len(values)
+	}
+	return state
+}
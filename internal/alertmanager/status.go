@@ -0,0 +1,133 @@
+package alertmanager
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/cloudflare/unsee/internal/models"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// statusDuration is a duration from the configJSON key of the status API, it
+// can be encoded as nanoseconds or as a duration string
+type statusDuration time.Duration
+
+func (d *statusDuration) UnmarshalJSON(b []byte) error {
+	if ns, err := strconv.ParseInt(string(b), 10, 64); err == nil {
+		*d = statusDuration(ns)
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = statusDuration(parsed)
+	return nil
+}
+
+// statusRoute is a single route from the configJSON key of the status API,
+// regexp matchers are encoded as empty objects by older Alertmanager versions,
+// so only string values are used
+type statusRoute struct {
+	Receiver       string                 `json:"receiver"`
+	GroupBy        []string               `json:"group_by"`
+	GroupWait      statusDuration         `json:"group_wait"`
+	GroupInterval  statusDuration         `json:"group_interval"`
+	RepeatInterval statusDuration         `json:"repeat_interval"`
+	Match          map[string]string      `json:"match"`
+	MatchRE        map[string]interface{} `json:"match_re"`
+	Continue       bool                   `json:"continue"`
+	Routes         []statusRoute          `json:"routes"`
+}
+
+// statusConfig is the part of the configJSON key of the status API we expose,
+// receivers are reduced to names, so integration secrets are never exposed
+type statusConfig struct {
+	Route     *statusRoute `json:"route"`
+	Receivers []struct {
+		Name string `json:"name"`
+	} `json:"receivers"`
+}
+
+func (r *statusRoute) toModel() models.AlertmanagerRoute {
+	route := models.AlertmanagerRoute{
+		Receiver:       r.Receiver,
+		GroupBy:        r.GroupBy,
+		GroupWait:      time.Duration(r.GroupWait).String(),
+		GroupInterval:  time.Duration(r.GroupInterval).String(),
+		RepeatInterval: time.Duration(r.RepeatInterval).String(),
+		Match:          map[string]string{},
+		MatchRE:        map[string]string{},
+		Continue:       r.Continue,
+		Routes:         []models.AlertmanagerRoute{},
+	}
+	if route.GroupBy == nil {
+		route.GroupBy = []string{}
+	}
+	for k, v := range r.Match {
+		route.Match[k] = v
+	}
+	for k, v := range r.MatchRE {
+		if s, ok := v.(string); ok {
+			route.MatchRE[k] = s
+		}
+	}
+	for i := range r.Routes {
+		route.Routes = append(route.Routes, r.Routes[i].toModel())
+	}
+	return route
+}
+
+// setStatus stores the response from the status API
+func (am *Alertmanager) setStatus(ver alertmanagerVersion, now time.Time) {
+	status := models.AlertmanagerStatus{
+		Name:        am.Name,
+		URI:         am.URI,
+		Version:     ver.Data.VersionInfo["version"],
+		VersionInfo: ver.Data.VersionInfo,
+		StartedAt:   ver.Data.Uptime,
+		Receivers:   []string{},
+		CollectedAt: now,
+	}
+
+	// some versions only export the configuration as YAML, which we don't parse
+	if len(ver.Data.ConfigJSON) > 0 {
+		cfg := statusConfig{}
+		if err := json.Unmarshal(ver.Data.ConfigJSON, &cfg); err != nil {
+			log.Errorf("[%s] Failed to decode Alertmanager configuration: %s", am.Name, err)
+		} else {
+			if cfg.Route != nil {
+				route := cfg.Route.toModel()
+				status.Route = &route
+			}
+			for _, receiver := range cfg.Receivers {
+				status.Receivers = append(status.Receivers, receiver.Name)
+			}
+		}
+	}
+
+	am.lock.Lock()
+	am.status = &status
+	am.lock.Unlock()
+}
+
+// Status returns the status of the Alertmanager instance reported during the
+// last pull that reached its status API, false is returned if no pull did
+func (am *Alertmanager) Status() (models.AlertmanagerStatus, bool) {
+	am.lock.RLock()
+	defer am.lock.RUnlock()
+	if am.status == nil {
+		return models.AlertmanagerStatus{}, false
+	}
+	status := *am.status
+	if !status.StartedAt.IsZero() {
+		status.Uptime = time.Since(status.StartedAt).Seconds()
+	}
+	return status, true
+}
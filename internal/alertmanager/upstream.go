@@ -5,8 +5,6 @@ import (
 	"sync"
 	"time"
 
-	"github.com/cloudflare/unsee/internal/models"
-
 	log "github.com/sirupsen/logrus"
 )
 
@@ -26,28 +24,28 @@ func NewAlertmanager(name, uri string, timeout time.Duration) error {
 		}
 	}
 
-	upstreams[name] = &Alertmanager{
-		URI:          uri,
-		Timeout:      timeout,
-		Name:         name,
-		lock:         sync.RWMutex{},
-		alertGroups:  []models.AlertGroup{},
-		silences:     map[string]models.Silence{},
-		colors:       models.LabelsColorMap{},
-		autocomplete: []models.Autocomplete{},
-		metrics: alertmanagerMetrics{
-			errors: map[string]float64{
-				labelValueErrorsAlerts:   0,
-				labelValueErrorsSilences: 0,
-			},
-		},
-	}
+	am := New(name, uri, timeout)
+	upstreams[name] = am
 
 	log.Infof("[%s] Configured Alertmanager source at %s", name, uri)
 
 	return nil
 }
 
+// New creates a new Alertmanager instance that isn't registered as an
+// upstream, it's only collected and deduplicated by callers holding it
+func New(name, uri string, timeout time.Duration) *Alertmanager {
+	am := &Alertmanager{
+		URI:     uri,
+		Timeout: timeout,
+		Name:    name,
+		lock:    sync.RWMutex{},
+		metrics: newAlertmanagerMetrics(),
+	}
+	am.clearData()
+	return am
+}
+
 // GetAlertmanagers returns a list of all defined Alertmanager instances
 func GetAlertmanagers() []*Alertmanager {
 	ams := []*Alertmanager{}
@@ -0,0 +1,72 @@
+package alertmanager
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cloudflare/unsee/internal/config"
+	"github.com/cloudflare/unsee/internal/models"
+)
+
+const (
+	// UpstreamDownAlertName is the alertname of synthetic alerts shown for
+	// upstreams that can't be collected
+	UpstreamDownAlertName = "UnseeUpstreamDown"
+	// UpstreamDownReceiver is the receiver of synthetic alerts
+	UpstreamDownReceiver = "unsee"
+)
+
+// pullFailed clears all data collected from this upstream after a failed
+// pull, if it's been failing for longer than ALERTMANAGER_DOWN_ALERT_AFTER
+// a synthetic alert is stored instead
+func (am *Alertmanager) pullFailed(err error) {
+	am.lock.Lock()
+	am.lastError = err.Error()
+	if am.failingSince.IsZero() {
+		am.failingSince = time.Now()
+	}
+	failingSince := am.failingSince
+	am.lock.Unlock()
+
+	threshold := config.Config.AlertmanagerDownAlertAfter
+	if threshold > 0 && time.Since(failingSince) >= threshold {
+		am.data.Store(am.upstreamDownData(failingSince, err))
+	} else {
+		am.clearData()
+	}
+}
+
+// upstreamDownData returns data with a single UnseeUpstreamDown alert, all
+// upstreams put those alerts in the same group, so there's a single group
+// listing every failing upstream
+func (am *Alertmanager) upstreamDownData(failingSince time.Time, err error) *upstreamData {
+	alert := models.Alert{
+		Annotations: models.AnnotationsFromMap(map[string]string{
+			"summary": fmt.Sprintf("Alerts can't be collected from Alertmanager %s, data shown might be incomplete", am.Name),
+			"error":   err.Error(),
+		}),
+		Labels: map[string]string{
+			"alertname":    UpstreamDownAlertName,
+			"alertmanager": am.Name,
+		},
+		StartsAt: failingSince,
+		State:    models.AlertStateActive,
+		Receiver: UpstreamDownReceiver,
+	}
+	alert.UpdateFingerprints()
+
+	ag := models.AlertGroup{
+		Receiver: UpstreamDownReceiver,
+		Labels:   map[string]string{"alertname": UpstreamDownAlertName},
+	}
+	ag.ID = ag.LabelsFingerprint()
+	pg := am.processGroup(ag, map[string]models.Alert{alert.ContentFingerprint(): alert}, map[string]models.Silence{})
+
+	data := newUpstreamData()
+	data.alertGroups = []models.AlertGroup{pg.group}
+	data.colors = pg.colors
+	data.autocomplete = pg.autocomplete
+	data.upstreamDown = true
+	data.size = data.approximateSize()
+	return data
+}
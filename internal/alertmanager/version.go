@@ -1,6 +1,7 @@
 package alertmanager
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/cloudflare/unsee/internal/transport"
@@ -8,14 +9,15 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
-// AlertmanagerVersion is what api/v1/status returns, we only use it to check
-// version, so we skip all other keys (except for status)
+// AlertmanagerVersion is what api/v1/status returns, configJSON is decoded
+// separately, so a configuration we don't understand won't break version
+// detection
 type alertmanagerVersion struct {
 	Status string `json:"status"`
 	Data   struct {
-		VersionInfo struct {
-			Version string `json:"version"`
-		} `json:"versionInfo"`
+		Uptime      time.Time         `json:"uptime"`
+		VersionInfo map[string]string `json:"versionInfo"`
+		ConfigJSON  json.RawMessage   `json:"configJSON"`
 	} `json:"data"`
 }
 
@@ -41,11 +43,11 @@ func GetVersion(uri string, timeout time.Duration) string {
 		return defaultVersion
 	}
 
-	if ver.Data.VersionInfo.Version == "" {
+	if ver.Data.VersionInfo["version"] == "" {
 		log.Error("No version information in Alertmanager API")
 		return defaultVersion
 	}
 
-	log.Infof("Remote Alertmanager version: %s", ver.Data.VersionInfo.Version)
-	return ver.Data.VersionInfo.Version
+	log.Infof("Remote Alertmanager version: %s", ver.Data.VersionInfo["version"])
+	return ver.Data.VersionInfo["version"]
 }
@@ -7,6 +7,7 @@ import (
 	"net/url"
 	"os"
 	"reflect"
+	"strconv"
 	"strings"
 	"time"
 	"unicode"
@@ -24,23 +25,152 @@ func (mvd *spaceSeparatedList) Decode(value string) error {
 }
 
 type configEnvs struct {
-	AlertmanagerTimeout      time.Duration      `envconfig:"ALERTMANAGER_TIMEOUT" default:"40s" help:"Timeout for all request send to Alertmanager"`
-	AlertmanagerTTL          time.Duration      `envconfig:"ALERTMANAGER_TTL" default:"1m" help:"TTL for Alertmanager alerts and silences"`
-	AlertmanagerURIs         spaceSeparatedList `envconfig:"ALERTMANAGER_URIS" required:"true" help:"List of Alertmanager URIs (name:uri)"`
-	AnnotationsHidden        spaceSeparatedList `envconfig:"ANNOTATIONS_HIDDEN" help:"List of annotations that are hidden by default"`
-	AnnotationsDefaultHidden bool               `envconfig:"ANNOTATIONS_DEFAULT_HIDDEN" default:"false" help:"Hide all annotations by default unless listed in ANNOTATIONS_VISIBLE"`
-	AnnotationsVisible       spaceSeparatedList `envconfig:"ANNOTATIONS_VISIBLE" help:"List of annotations that are visible by default"`
-	ColorLabelsStatic        spaceSeparatedList `envconfig:"COLOR_LABELS_STATIC" help:"List of label names that should have the same (but distinct) color"`
-	ColorLabelsUnique        spaceSeparatedList `envconfig:"COLOR_LABELS_UNIQUE" help:"List of label names that should have unique color"`
-	Debug                    bool               `envconfig:"DEBUG" default:"false" help:"Enable debug mode"`
-	FilterDefault            string             `envconfig:"FILTER_DEFAULT" help:"Default filter string"`
-	JiraRegexp               spaceSeparatedList `envconfig:"JIRA_REGEX" help:"List of JIRA regex rules"`
-	Port                     int                `envconfig:"PORT" default:"8080" help:"HTTP port to listen on"`
-	SentryDSN                string             `envconfig:"SENTRY_DSN" help:"Sentry DSN for Go exceptions"`
-	SentryPublicDSN          string             `envconfig:"SENTRY_PUBLIC_DSN" help:"Sentry DSN for javascript exceptions"`
-	StripLabels              spaceSeparatedList `envconfig:"STRIP_LABELS" help:"List of labels to ignore"`
-	KeepLabels               spaceSeparatedList `envconfig:"KEEP_LABELS" help:"List of labels to keep, all other labels will be stripped"`
-	WebPrefix                string             `envconfig:"WEB_PREFIX" default:"/" help:"URL prefix"`
+	AccessLog                       string             `envconfig:"ACCESS_LOG" help:"Format of the access log written to stdout (common, combined or json), access log is disabled if not set"`
+	AllowedNetworks                 spaceSeparatedList `envconfig:"ALLOWED_NETWORKS" help:"List of networks (CIDR) allowed to access unsee, access is not restricted if not set"`
+	AlertmanagerClusters            spaceSeparatedList `envconfig:"ALERTMANAGER_CLUSTERS" help:"List of Alertmanager HA clusters (name:upstream,upstream,...), silences are checked for consistency between cluster members"`
+	AlertmanagerCredentialsFiles    spaceSeparatedList `envconfig:"ALERTMANAGER_CREDENTIALS_FILES" help:"List of files with credentials of Alertmanager upstreams (name:type:path), type is token, password or cert, files are read again on every collection and on SIGHUP"`
+	AlertmanagerDisableHttp2        bool               `envconfig:"ALERTMANAGER_DISABLE_HTTP2" default:"false" help:"Disable HTTP/2 for connections to HTTPS Alertmanager upstreams"`
+	AlertmanagerDnsRefresh          time.Duration      `envconfig:"ALERTMANAGER_DNS_REFRESH" default:"0" help:"Re-resolve host names of Alertmanager upstreams this often and reconnect if their addresses changed, 0 disables it"`
+	AlertmanagerDownAlertAfter      time.Duration      `envconfig:"ALERTMANAGER_DOWN_ALERT_AFTER" default:"0" help:"Show a synthetic UnseeUpstreamDown alert for Alertmanager upstreams that couldn't be collected for longer than this, 0 disables it"`
+	AlertmanagerIdleConnTimeout     time.Duration      `envconfig:"ALERTMANAGER_IDLE_CONN_TIMEOUT" default:"90s" help:"How long idle connections to Alertmanager upstreams are kept open for reuse, 0 keeps them open until closed by the upstream"`
+	AlertmanagerKeepalive           time.Duration      `envconfig:"ALERTMANAGER_KEEPALIVE" default:"30s" help:"Interval of TCP keepalive probes sent on connections to Alertmanager upstreams, 0 disables them"`
+	AlertmanagerMaxAlerts           int                `envconfig:"ALERTMANAGER_MAX_ALERTS" default:"0" help:"Maximum number of alerts collected from every Alertmanager upstream, alerts above the limit are dropped, there's no limit if set to 0"`
+	AlertmanagerMaxClockSkew        time.Duration      `envconfig:"ALERTMANAGER_MAX_CLOCK_SKEW" default:"30s" help:"Report Alertmanager upstreams with clocks that differ from the local clock by more than this, 0 disables it"`
+	AlertmanagerMaxIdleConnsPerHost int                `envconfig:"ALERTMANAGER_MAX_IDLE_CONNS_PER_HOST" default:"2" help:"Maximum number of idle connections kept open for every Alertmanager upstream host"`
+	AlertmanagerProxy               bool               `envconfig:"ALERTMANAGER_PROXY" default:"false" help:"Proxy requests to Alertmanager upstreams under /proxy/<name>/"`
+	AlertmanagerProxyURLs           spaceSeparatedList `envconfig:"ALERTMANAGER_PROXY_URLS" help:"List of proxy servers used to connect to Alertmanager upstreams (name:proxy_url), supported schemes are socks5, socks5h, http and https"`
+	AlertmanagerStartupCheck        bool               `envconfig:"ALERTMANAGER_STARTUP_CHECK" default:"false" help:"Exit on startup if no Alertmanager upstream could be collected"`
+	AlertmanagerTimeout             time.Duration      `envconfig:"ALERTMANAGER_TIMEOUT" default:"40s" help:"Timeout for all request send to Alertmanager"`
+	AlertmanagerTLSServerNames      spaceSeparatedList `envconfig:"ALERTMANAGER_TLS_SERVER_NAMES" help:"List of TLS server names used to verify certificates of HTTPS Alertmanager upstreams (name:server_name), host from the upstream URI is used if not set"`
+	AlertmanagerTTL                 time.Duration      `envconfig:"ALERTMANAGER_TTL" default:"1m" help:"TTL for Alertmanager alerts and silences"`
+	AlertmanagerURIs                spaceSeparatedList `envconfig:"ALERTMANAGER_URIS" required:"true" help:"List of Alertmanager URIs (name:uri)"`
+	AlertsBlackholeFilters          spaceSeparatedList `envconfig:"ALERTS_BLACKHOLE_FILTERS" help:"List of filters, alerts matching any of them are dropped during collection and never stored"`
+	AlertsCollapseLabels            spaceSeparatedList `envconfig:"ALERTS_COLLAPSE_LABELS" help:"List of label names, alerts in the same group that only differ by values of those labels are returned as a single alert listing all values"`
+	AlertsPerGroup                  int                `envconfig:"ALERTS_PER_GROUP" default:"0" help:"Maximum number of alerts included in every alert group returned by the API, there's no limit if set to 0"`
+	AnnotationsHidden               spaceSeparatedList `envconfig:"ANNOTATIONS_HIDDEN" help:"List of annotations that are hidden by default"`
+	AnnotationsDefaultHidden        bool               `envconfig:"ANNOTATIONS_DEFAULT_HIDDEN" default:"false" help:"Hide all annotations by default unless listed in ANNOTATIONS_VISIBLE"`
+	AnnotationsMergePolicy          string             `envconfig:"ANNOTATIONS_MERGE_POLICY" default:"newest" help:"How annotations of the same alert collected from multiple upstreams are merged (newest, preferred or union)"`
+	AnnotationsMergePreferred       spaceSeparatedList `envconfig:"ANNOTATIONS_MERGE_PREFERRED" help:"List of upstream names in order of preference, annotations are taken from the first one with the alert when ANNOTATIONS_MERGE_POLICY is preferred"`
+	AnnotationsHiddenRegex          spaceSeparatedList `envconfig:"ANNOTATIONS_HIDDEN_REGEX" help:"List of regexps matching names of annotations that are hidden by default"`
+	AnnotationsTemplates            spaceSeparatedList `envconfig:"ANNOTATIONS_TEMPLATES" help:"List of annotations rendered from templates over alert labels (name:template), added to alerts without an annotation with that name"`
+	AnnotationsVisible              spaceSeparatedList `envconfig:"ANNOTATIONS_VISIBLE" help:"List of annotations that are visible by default"`
+	ApiKeys                         spaceSeparatedList `envconfig:"API_KEYS" secret:"true" help:"List of API keys required to query JSON endpoints (name:key), API keys are not required if not set"`
+	AssetsPath                      string             `envconfig:"ASSETS_PATH" help:"Path to a directory with templates and static assets overriding the embedded ones"`
+	AuthGroupsHeader                string             `envconfig:"AUTH_GROUPS_HEADER" help:"Name of the header with a comma separated list of groups of the user authenticated by a reverse proxy"`
+	AuthHtpasswd                    string             `envconfig:"AUTH_HTPASSWD" help:"Path to a htpasswd file with users allowed to access unsee using HTTP basic authentication, only bcrypt and SHA1 hashes are supported"`
+	AuthUserHeader                  string             `envconfig:"AUTH_USER_HEADER" help:"Name of the header with the name of the user authenticated by a reverse proxy"`
+	AutocompleteIgnoredLabels       spaceSeparatedList `envconfig:"AUTOCOMPLETE_IGNORED_LABELS" help:"List of label names that won't be included in autocomplete hints"`
+	AutocompleteMaxValues           int                `envconfig:"AUTOCOMPLETE_MAX_VALUES" default:"0" help:"Maximum number of values of a single label included in autocomplete hints, values used by most alerts are kept, there's no limit if set to 0"`
+	ColorLabelsStatic               spaceSeparatedList `envconfig:"COLOR_LABELS_STATIC" help:"List of label names that should have the same (but distinct) color"`
+	ColorLabelsUnique               spaceSeparatedList `envconfig:"COLOR_LABELS_UNIQUE" help:"List of label names that should have unique color"`
+	CompressionBrotli               bool               `envconfig:"COMPRESSION_BROTLI" default:"false" help:"Use brotli compression for clients that support it"`
+	CompressionMinSize              int                `envconfig:"COMPRESSION_MIN_SIZE" default:"1024" help:"Minimum response size in bytes that will be compressed"`
+	ConfigKey                       string             `envconfig:"CONFIG_KEY" secret:"true" help:"Base64 encoded 32 byte key used to decrypt encrypted option values"`
+	ConfigKeyFile                   string             `envconfig:"CONFIG_KEY_FILE" help:"Path to a file with the base64 encoded 32 byte key used to decrypt encrypted option values"`
+	CorsAllowCredentials            bool               `envconfig:"CORS_ALLOW_CREDENTIALS" default:"false" help:"Allow cross-origin requests to include credentials"`
+	CorsAllowedMethods              spaceSeparatedList `envconfig:"CORS_ALLOWED_METHODS" default:"GET HEAD" help:"List of HTTP methods allowed in cross-origin requests"`
+	CorsAllowedOrigins              spaceSeparatedList `envconfig:"CORS_ALLOWED_ORIGINS" help:"List of origins allowed to make cross-origin requests, use * to allow any origin"`
+	Debug                           bool               `envconfig:"DEBUG" default:"false" help:"Enable debug mode"`
+	DebugState                      bool               `envconfig:"DEBUG_STATE" default:"false" help:"Enable /debug/state endpoint returning internal state of all Alertmanager upstreams"`
+	DedupIgnoredLabels              spaceSeparatedList `envconfig:"DEDUP_IGNORED_LABELS" help:"List of labels ignored when deduplicating alerts, alerts with labels that only differ by those are merged"`
+	DemoMode                        bool               `envconfig:"DEMO_MODE" default:"false" help:"Run in read-only demo mode, all changes are rejected and values of DEMO_SCRUB_LABELS are anonymized"`
+	DemoScrubLabels                 spaceSeparatedList `envconfig:"DEMO_SCRUB_LABELS" help:"List of label names with values that are replaced with a hash in demo mode, like hostnames or customer IDs"`
+	FilterDefault                   string             `envconfig:"FILTER_DEFAULT" help:"Default filter string"`
+	FilterMacros                    spaceSeparatedList `envconfig:"FILTER_MACROS" help:"List of filter macros that can be referenced as $name in filters (name:filter)"`
+	FilterPresets                   spaceSeparatedList `envconfig:"FILTER_PRESETS" help:"List of named filter presets (name:filter)"`
+	FlappingThreshold               int                `envconfig:"FLAPPING_THRESHOLD" default:"0" help:"Mark alerts that were added or resolved at least this many times within FLAPPING_WINDOW as flapping, 0 disables flapping detection"`
+	FlappingWindow                  time.Duration      `envconfig:"FLAPPING_WINDOW" default:"10m" help:"Time window used for flapping detection"`
+	GrafanaLinks                    spaceSeparatedList `envconfig:"GRAFANA_LINKS" help:"List of rules generating Grafana links for alerts (name:dashboard:uid:variable=label,... or name:explore:datasource:label=label,...)"`
+	GrafanaURL                      string             `envconfig:"GRAFANA_URL" help:"Grafana URL used for links generated by GRAFANA_LINKS rules"`
+	GroupCollapseFilter             string             `envconfig:"GROUP_COLLAPSE_FILTER" help:"Alert groups with any alert matching this filter are returned with a hint to collapse them in the UI"`
+	GroupCollapseSize               int                `envconfig:"GROUP_COLLAPSE_SIZE" default:"0" help:"Alert groups with more alerts than this are returned with a hint to collapse them in the UI, 0 disables it"`
+	GrpcPort                        int                `envconfig:"GRPC_PORT" default:"0" help:"Port to listen on for gRPC API requests, gRPC API is disabled if not set"`
+	HandlerTimeout                  time.Duration      `envconfig:"HANDLER_TIMEOUT" default:"30s" help:"Maximum time API requests can take before they're cancelled, 0 disables the timeout"`
+	HistoryPath                     string             `envconfig:"HISTORY_PATH" help:"Path to a file used to save alert counts history on shutdown, it's restored on startup"`
+	HistoryRetention                time.Duration      `envconfig:"HISTORY_RETENTION" default:"24h" help:"How long alert counts recorded on every collection are kept for the history endpoint"`
+	Hooks                           spaceSeparatedList `envconfig:"HOOKS" help:"List of commands run for alert events (event:command), supported events are added, resolved, silenced and all"`
+	HooksConcurrency                int                `envconfig:"HOOKS_CONCURRENCY" default:"4" help:"Maximum number of hook commands running at the same time"`
+	HooksTimeout                    time.Duration      `envconfig:"HOOKS_TIMEOUT" default:"30s" help:"Hook commands still running after this long are killed, 0 disables the timeout"`
+	HttpIdleTimeout                 time.Duration      `envconfig:"HTTP_IDLE_TIMEOUT" default:"2m" help:"Maximum time to wait for the next request on keep-alive connections"`
+	HttpReadTimeout                 time.Duration      `envconfig:"HTTP_READ_TIMEOUT" default:"30s" help:"Maximum time for reading the entire request, including the body"`
+	HttpWriteTimeout                time.Duration      `envconfig:"HTTP_WRITE_TIMEOUT" default:"0s" help:"Maximum time for writing the response, 0 disables the timeout, live update streams are also closed once it passes"`
+	IncidentsApiKey                 string             `envconfig:"INCIDENTS_API_KEY" secret:"true" help:"API key used to look up open incidents"`
+	IncidentsApiUrl                 string             `envconfig:"INCIDENTS_API_URL" help:"URL of the incidents provider API, default API URL of the provider is used if not set"`
+	IncidentsDedupLabel             string             `envconfig:"INCIDENTS_DEDUP_LABEL" help:"Name of the label with the dedup key of the incident created for an alert, alert fingerprint is used if not set"`
+	IncidentsProvider               string             `envconfig:"INCIDENTS_PROVIDER" help:"Incident management service used to look up open incidents for alerts (pagerduty or opsgenie), incidents are not looked up if not set"`
+	IncidentsServices               spaceSeparatedList `envconfig:"INCIDENTS_SERVICES" help:"List of receivers mapped to PagerDuty service IDs or Opsgenie team names (receiver:service)"`
+	JiraRegexp                      spaceSeparatedList `envconfig:"JIRA_REGEX" help:"List of JIRA regex rules"`
+	LabelDisplayNames               spaceSeparatedList `envconfig:"LABEL_DISPLAY_NAMES" help:"List of label names mapped to names displayed in the UI (label:name)"`
+	Listen                          spaceSeparatedList `envconfig:"LISTEN" help:"List of addresses to listen on for HTTP requests (address or scope:address, scope is all, public or admin), all endpoints are served on PORT if not set"`
+	LogFile                         string             `envconfig:"LOG_FILE" help:"Path to a file where logs are written in addition to stderr, logs are only written to stderr if not set"`
+	LogFileMaxAge                   time.Duration      `envconfig:"LOG_FILE_MAX_AGE" default:"0s" help:"Rotate LOG_FILE after it was written to for this long, 0 disables age based rotation"`
+	LogFileMaxBackups               int                `envconfig:"LOG_FILE_MAX_BACKUPS" default:"5" help:"Number of rotated log files to keep"`
+	LogFileMaxSize                  int                `envconfig:"LOG_FILE_MAX_SIZE" default:"100" help:"Rotate LOG_FILE once it's bigger than this many megabytes, 0 disables size based rotation"`
+	MetricsAllowedNetworks          spaceSeparatedList `envconfig:"METRICS_ALLOWED_NETWORKS" help:"List of networks (CIDR) allowed to access only metrics and health check endpoints when ALLOWED_NETWORKS is set"`
+	MetricsAuthHtpasswd             string             `envconfig:"METRICS_AUTH_HTPASSWD" help:"Path to a htpasswd file with users allowed to access metrics, debug and admin endpoints"`
+	Port                            int                `envconfig:"PORT" default:"8080" help:"HTTP port to listen on"`
+	Pprof                           bool               `envconfig:"PPROF" default:"false" help:"Enable pprof profiling endpoints under /debug/pprof/"`
+	PrometheusRules                 bool               `envconfig:"PROMETHEUS_RULES" default:"false" help:"Look up alerting rules on Prometheus servers linked from alert generatorURL and return them in alert group details"`
+	RateLimitBurst                  int                `envconfig:"RATE_LIMIT_BURST" default:"10" help:"Maximum number of API requests a single client can burst above the rate limit"`
+	RateLimitRps                    float64            `envconfig:"RATE_LIMIT_RPS" default:"0" help:"Maximum number of API requests per second for a single client IP, requests are not limited if not set"`
+	ReadinessDelayUi                bool               `envconfig:"READINESS_DELAY_UI" default:"false" help:"Respond to UI requests with 503 until READINESS_UPSTREAMS completed their first collection"`
+	ReadinessUpstreams              string             `envconfig:"READINESS_UPSTREAMS" default:"any" help:"Number of Alertmanager upstreams that must complete their first collection before the readiness check succeeds (any, all or a number), any doesn't wait for collections after startup"`
+	RefreshMinInterval              time.Duration      `envconfig:"REFRESH_MIN_INTERVAL" default:"30s" help:"Minimum interval between collections requested using the refresh endpoint, 0 disables the limit"`
+	RobotsNoindex                   bool               `envconfig:"ROBOTS_NOINDEX" default:"false" help:"Send X-Robots-Tag header asking search engines not to index any page"`
+	RobotsTxt                       string             `envconfig:"ROBOTS_TXT" help:"Content of robots.txt, crawling is disallowed if not set"`
+	RunbookUrls                     spaceSeparatedList `envconfig:"RUNBOOK_URLS" help:"List of rules injecting runbook annotation into alerts without one (matcher@url), matcher is an alertname or a label matcher (name=value or name=~regex)"`
+	SecurityAllowFraming            bool               `envconfig:"SECURITY_ALLOW_FRAMING" default:"false" help:"Allow embedding the UI in frames on other pages"`
+	SecurityCsp                     string             `envconfig:"SECURITY_CSP" default:"default-src 'self'; script-src 'self' 'unsafe-eval'; style-src 'self' 'unsafe-inline'; img-src 'self' data: https:; font-src 'self' data:; connect-src 'self' https:; frame-ancestors 'none'" help:"Content-Security-Policy header value for HTML pages, header is not sent if empty"`
+	SecurityFrameOptions            string             `envconfig:"SECURITY_FRAME_OPTIONS" default:"DENY" help:"X-Frame-Options header value for HTML pages, header is not sent if empty"`
+	SecurityHstsMaxAge              time.Duration      `envconfig:"SECURITY_HSTS_MAX_AGE" default:"0s" help:"max-age of the Strict-Transport-Security header for HTML pages, header is not sent if not set"`
+	SeverityLabel                   string             `envconfig:"SEVERITY_LABEL" default:"severity" help:"Name of the label with alert severity, values of this label are normalized using SEVERITY_MAP"`
+	SeverityMap                     spaceSeparatedList `envconfig:"SEVERITY_MAP" help:"List of rules mapping severity values to canonical severities (severity:value,value,...), values are matched case insensitively"`
+	SeverityNormalizedLabel         string             `envconfig:"SEVERITY_NORMALIZED_LABEL" default:"severity_normalized" help:"Name of the label canonical severity is stored in, it's added alongside the original severity label"`
+	ShutdownTimeout                 time.Duration      `envconfig:"SHUTDOWN_TIMEOUT" default:"30s" help:"Maximum time to wait for in-flight requests to finish when shutting down"`
+	SilenceACL                      spaceSeparatedList `envconfig:"SILENCE_ACL" help:"List of rules for users and groups allowed to create silences (user:name:matchers or group:name:matchers)"`
+	SilenceAuthor                   string             `envconfig:"SILENCE_AUTHOR" help:"Author of silences created using unsee, used when SILENCE_AUTHOR_SOURCE is fixed or if the author isn't otherwise known"`
+	SilenceAuthorSource             string             `envconfig:"SILENCE_AUTHOR_SOURCE" default:"user" help:"Where the author of silences created using unsee is taken from (user or fixed)"`
+	SilenceCommentTemplate          string             `envconfig:"SILENCE_COMMENT_TEMPLATE" help:"Template used to generate comments of silences created using unsee, it can reference the original .Comment, .Author and .Labels from matchers"`
+	SilenceDefaultDuration          time.Duration      `envconfig:"SILENCE_DEFAULT_DURATION" default:"0" help:"Duration of silences created using unsee without endsAt, silences without endsAt are passed to Alertmanager as they are if set to 0"`
+	SilenceExpiredRetention         time.Duration      `envconfig:"SILENCE_EXPIRED_RETENTION" default:"0" help:"Keep silences that expired within this window even if Alertmanager no longer returns them, silences that expired earlier are not listed, all expired silences returned by Alertmanager are listed if set to 0"`
+	SentryDSN                       string             `envconfig:"SENTRY_DSN" help:"Sentry DSN for Go exceptions"`
+	SentryEnvironment               string             `envconfig:"SENTRY_ENVIRONMENT" help:"Environment name reported with all Sentry events, like production or staging"`
+	SentryPublicDSN                 string             `envconfig:"SENTRY_PUBLIC_DSN" help:"Sentry DSN for javascript exceptions"`
+	SentryRelease                   string             `envconfig:"SENTRY_RELEASE" help:"Release reported with all Sentry events, unsee version is used if not set"`
+	SentrySampleRate                float64            `envconfig:"SENTRY_SAMPLE_RATE" default:"1" help:"Fraction of Sentry events that are sent, between 0 and 1"`
+	SentrySensitiveLabels           spaceSeparatedList `envconfig:"SENTRY_SENSITIVE_LABELS" help:"List of label names with values that are removed from Sentry events"`
+	SnapshotPath                    string             `envconfig:"SNAPSHOT_PATH" help:"Path to a file used to save collected alerts and silences on shutdown, those are restored on startup"`
+	StatsExportAddress              string             `envconfig:"STATS_EXPORT_ADDRESS" help:"Address (host:port) of a StatsD or Graphite server alert counts are pushed to, counts are not pushed if not set"`
+	StatsExportInterval             time.Duration      `envconfig:"STATS_EXPORT_INTERVAL" default:"1m" help:"How often alert counts are pushed to STATS_EXPORT_ADDRESS"`
+	StatsExportPrefix               string             `envconfig:"STATS_EXPORT_PREFIX" default:"unsee" help:"Prefix added to names of all metrics pushed to STATS_EXPORT_ADDRESS"`
+	StatsExportProtocol             string             `envconfig:"STATS_EXPORT_PROTOCOL" default:"statsd" help:"Protocol used to push alert counts to STATS_EXPORT_ADDRESS (statsd or graphite)"`
+	StorePath                       string             `envconfig:"STORE_PATH" help:"Path to a file used to persist saved filters, data is only kept in memory if not set"`
+	StripLabels                     spaceSeparatedList `envconfig:"STRIP_LABELS" help:"List of labels to ignore"`
+	KeepLabels                      spaceSeparatedList `envconfig:"KEEP_LABELS" help:"List of labels to keep, all other labels will be stripped"`
+	TenantFilters                   spaceSeparatedList `envconfig:"TENANT_FILTERS" help:"List of filters restricting alerts visible to members of each group (group:filter), alerts are not restricted if not set"`
+	Timezone                        string             `envconfig:"TIMEZONE" help:"Timezone (like Europe/London) used for timestamps formatted for display, UTC is used if not set"`
+	TlsCert                         string             `envconfig:"TLS_CERT" help:"Path to a TLS certificate file, HTTPS is used if set"`
+	TlsKey                          string             `envconfig:"TLS_KEY" help:"Path to a TLS key file, required if TLS_CERT is set"`
+	TracingEndpoint                 string             `envconfig:"TRACING_ENDPOINT" help:"OTLP/HTTP endpoint (host:port) OpenTelemetry traces are sent to, tracing is disabled if not set"`
+	TracingInsecure                 bool               `envconfig:"TRACING_INSECURE" default:"false" help:"Send traces using plain HTTP instead of HTTPS"`
+	TracingSampleRatio              float64            `envconfig:"TRACING_SAMPLE_RATIO" default:"1" help:"Fraction of collections and API requests that are traced, between 0 and 1"`
+	TransformCommands               spaceSeparatedList `envconfig:"TRANSFORM_COMMANDS" help:"List of commands used as extra transforms for collected alerts, alerts are passed as JSON on stdin and modified alerts are read from stdout"`
+	TransformPlugins                spaceSeparatedList `envconfig:"TRANSFORM_PLUGINS" help:"List of Go plugins with extra transforms for collected alerts"`
+	TransformTimeout                time.Duration      `envconfig:"TRANSFORM_TIMEOUT" default:"10s" help:"Transform commands still running after this long are killed, 0 disables the timeout"`
+	TransformWorkers                int                `envconfig:"TRANSFORM_WORKERS" default:"0" help:"Number of goroutines used to process collected alerts and silences, number of CPUs is used if set to 0"`
+	UiAppendTop                     bool               `envconfig:"UI_APPEND_TOP" default:"true" help:"Default value of the UI option showing new alerts on top"`
+	UiAutoRefresh                   bool               `envconfig:"UI_AUTO_REFRESH" default:"true" help:"Default value of the UI option refreshing alerts automatically"`
+	UiBanner                        string             `envconfig:"UI_BANNER" help:"Announcement message shown at the top of the UI"`
+	UiFlash                         bool               `envconfig:"UI_FLASH" default:"true" help:"Default value of the UI option flashing the screen when alerts change"`
+	UiGroupByReceiver               bool               `envconfig:"UI_GROUP_BY_RECEIVER" default:"false" help:"Default value of the UI option grouping alert groups by receiver"`
+	UiLogoUrl                       string             `envconfig:"UI_LOGO_URL" help:"URL of the logo image shown in the UI navigation bar"`
+	UiRefreshInterval               time.Duration      `envconfig:"UI_REFRESH_INTERVAL" default:"15s" help:"Default interval between alert refreshes in the UI"`
+	UiTitle                         string             `envconfig:"UI_TITLE" help:"Title of this unsee instance, shown in the UI navigation bar and page title"`
+	VaultAddr                       string             `envconfig:"VAULT_ADDR" help:"Address of the HashiCorp Vault server used to read credentials of Alertmanager upstreams"`
+	VaultRefresh                    time.Duration      `envconfig:"VAULT_REFRESH" default:"5m" help:"How often credentials are read again from Vault if their secrets don't have a lease"`
+	VaultSecrets                    spaceSeparatedList `envconfig:"VAULT_SECRETS" help:"List of Vault secret paths with credentials of Alertmanager upstreams (name:path), secrets must have username and password or token keys"`
+	VaultToken                      string             `envconfig:"VAULT_TOKEN" secret:"true" help:"Token used to authenticate with Vault"`
+	WebPrefix                       string             `envconfig:"WEB_PREFIX" default:"/" help:"URL prefix"`
 }
 
 // Config exposes all options required to run
@@ -65,12 +195,13 @@ func makeFlagName(s string) string {
 // Next parse those flags and for each set flag inject env variable which will
 // be read by envconfig later on.
 type flagMapper struct {
+	name      string
 	isBool    bool
 	stringVal *string
 	boolVal   *bool
 }
 
-func mapEnvConfigToFlags() {
+func mapEnvConfigToFlags(args []string) {
 	flags := make(map[string]flagMapper)
 	s := reflect.ValueOf(Config)
 	typeOfSpec := s.Type()
@@ -90,7 +221,7 @@ func mapEnvConfigToFlags() {
 			helpMsg = fmt.Sprintf("%s This option is required.", helpMsg)
 		}
 
-		mapper := flagMapper{}
+		mapper := flagMapper{name: flagName}
 		if s.Field(i).Kind() == reflect.Bool {
 			mapper.isBool = true
 			mapper.boolVal = flag.Bool(flagName, false, helpMsg)
@@ -99,11 +230,19 @@ func mapEnvConfigToFlags() {
 		}
 		flags[envName] = mapper
 	}
-	flag.Parse()
+	if err := flag.CommandLine.Parse(args); err != nil {
+		log.Fatal(err)
+	}
+	// bool flags are only passed if set, so options enabled by default can be
+	// disabled with -flag=false
+	passed := map[string]bool{}
+	flag.CommandLine.Visit(func(f *flag.Flag) {
+		passed[f.Name] = true
+	})
 	for envName, mapper := range flags {
 		if mapper.isBool {
-			if *mapper.boolVal == true {
-				err := os.Setenv(envName, "true")
+			if passed[mapper.name] {
+				err := os.Setenv(envName, strconv.FormatBool(*mapper.boolVal))
 				if err != nil {
 					log.Fatal(err)
 				}
@@ -119,16 +258,37 @@ func mapEnvConfigToFlags() {
 	}
 }
 
+// Read reads the config from flags passed on the command line and from
+// environment variables
 func (config *configEnvs) Read() {
-	mapEnvConfigToFlags()
+	config.ReadArgs(os.Args[1:])
+}
+
+// ReadArgs reads the config from given flags and from environment variables
+func (config *configEnvs) ReadArgs(args []string) {
+	mapEnvConfigToFlags(args)
+
+	if err := decryptEnvs(); err != nil {
+		log.Fatal(err)
+	}
 
 	err := envconfig.Process("", config)
 	if err != nil {
 		log.Fatal(err)
 	}
+
+	// WEB_PREFIX always starts and ends with a slash, so it can be used to
+	// build URLs in templates
+	if !strings.HasPrefix(config.WebPrefix, "/") {
+		config.WebPrefix = "/" + config.WebPrefix
+	}
+	if !strings.HasSuffix(config.WebPrefix, "/") {
+		config.WebPrefix += "/"
+	}
 }
 
-func hideURLPassword(s string) string {
+// HideURLPassword replaces the password in given URL with xxx
+func HideURLPassword(s string) string {
 	u, err := url.Parse(s)
 	if err != nil {
 		return s
@@ -142,13 +302,57 @@ func hideURLPassword(s string) string {
 	return s
 }
 
-func (config *configEnvs) LogValues() {
+// Values returns all config options as they are logged on startup, keyed by
+// the environment variable name, secrets and URL passwords are replaced with
+// xxx
+func (config *configEnvs) Values() map[string]string {
+	values := map[string]string{}
+	config.eachValue(func(env, val string) {
+		values[env] = val
+	})
+	return values
+}
+
+// hideValuePassword replaces the password in a URL, which can be prefixed
+// with the upstream name, like in name:uri list values
+func hideValuePassword(s string) string {
+	if hidden := HideURLPassword(s); hidden != s {
+		return hidden
+	}
+	if z := strings.SplitN(s, ":", 2); len(z) == 2 {
+		if hidden := HideURLPassword(z[1]); hidden != z[1] {
+			return z[0] + ":" + hidden
+		}
+	}
+	return s
+}
+
+func (config *configEnvs) eachValue(fn func(env, val string)) {
 	s := reflect.ValueOf(config).Elem()
 	typeOfT := s.Type()
 	for i := 0; i < s.NumField(); i++ {
 		env := typeOfT.Field(i).Tag.Get("envconfig")
-		val := fmt.Sprintf("%v", s.Field(i).Interface())
-		log.Infof("%20s => %v", env, hideURLPassword(val))
+		var val string
+		if list, ok := s.Field(i).Interface().(spaceSeparatedList); ok {
+			// every element needs to be checked, the whole list won't parse
+			// as a URL
+			hidden := make([]string, 0, len(list))
+			for _, v := range list {
+				hidden = append(hidden, hideValuePassword(v))
+			}
+			val = fmt.Sprintf("%v", hidden)
+		} else {
+			val = hideValuePassword(fmt.Sprintf("%v", s.Field(i).Interface()))
+		}
+		if typeOfT.Field(i).Tag.Get("secret") == "true" && val != "[]" && val != "" {
+			val = "xxx"
+		}
+		fn(env, val)
 	}
+}
 
+func (config *configEnvs) LogValues() {
+	config.eachValue(func(env, val string) {
+		log.Infof("%20s => %v", env, val)
+	})
 }
@@ -0,0 +1,118 @@
+package config
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// EncryptedPrefix is the prefix of encrypted option values, the rest of the
+// value is base64 encoded nonce and AES-GCM encrypted value
+const EncryptedPrefix = "enc:"
+
+// ReadKey returns the key used to encrypt option values, it's passed directly
+// or read from the file, the key is base64 encoded and must be 32 bytes long
+func ReadKey(key string, keyFile string) ([]byte, error) {
+	if key != "" && keyFile != "" {
+		return nil, fmt.Errorf("CONFIG_KEY and CONFIG_KEY_FILE can't be both set")
+	}
+	if keyFile != "" {
+		data, err := ioutil.ReadFile(keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CONFIG_KEY_FILE: %s", err)
+		}
+		key = strings.TrimSpace(string(data))
+	}
+	if key == "" {
+		return nil, fmt.Errorf("CONFIG_KEY or CONFIG_KEY_FILE must be set to use encrypted values")
+	}
+	decoded, err := base64.StdEncoding.DecodeString(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config key, it must be base64 encoded: %s", err)
+	}
+	if len(decoded) != 32 {
+		return nil, fmt.Errorf("invalid config key, it must be 32 bytes long, got %d", len(decoded))
+	}
+	return decoded, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// EncryptValue returns encrypted option value that can be used in place of
+// the plain text value
+func EncryptValue(key []byte, value string) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+	sealed := gcm.Seal(nonce, nonce, []byte(value), nil)
+	return EncryptedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// DecryptValue returns the plain text value of an encrypted option value
+func DecryptValue(key []byte, value string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, EncryptedPrefix))
+	if err != nil {
+		return "", fmt.Errorf("invalid encrypted value: %s", err)
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	if len(data) < gcm.NonceSize() {
+		return "", fmt.Errorf("invalid encrypted value, it's too short")
+	}
+	plain, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt value, wrong key was used or it was modified")
+	}
+	return string(plain), nil
+}
+
+// decryptEnvs replaces encrypted values of all environment variables used
+// for options with their plain text values, flags are already mapped to
+// environment variables, so those are covered too, the key is only read if
+// any value is encrypted
+func decryptEnvs() error {
+	var key []byte
+	s := reflect.ValueOf(Config)
+	typeOfSpec := s.Type()
+	for i := 0; i < s.NumField(); i++ {
+		envName := typeOfSpec.Field(i).Tag.Get("envconfig")
+		value := os.Getenv(envName)
+		if !strings.HasPrefix(value, EncryptedPrefix) {
+			continue
+		}
+		if key == nil {
+			var err error
+			if key, err = ReadKey(os.Getenv("CONFIG_KEY"), os.Getenv("CONFIG_KEY_FILE")); err != nil {
+				return err
+			}
+		}
+		plain, err := DecryptValue(key, value)
+		if err != nil {
+			return fmt.Errorf("failed to decrypt %s: %s", envName, err)
+		}
+		if err = os.Setenv(envName, plain); err != nil {
+			return err
+		}
+	}
+	return nil
+}
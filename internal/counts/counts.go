@@ -0,0 +1,161 @@
+// Package counts keeps the number of alerts seen on every collection, split by
+// state and severity, so it's possible to tell how alert volume changed over
+// time, samples can be persisted to a JSON file on disk
+package counts
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/cloudflare/unsee/internal/models"
+)
+
+// Sample holds alert counts from a single collection
+type Sample struct {
+	Timestamp  time.Time      `json:"timestamp"`
+	Total      int            `json:"total"`
+	States     map[string]int `json:"states"`
+	Severities map[string]int `json:"severities"`
+}
+
+// Buffer keeps up to size most recent samples, oldest samples are dropped
+// when a new one is added to a full buffer
+type Buffer struct {
+	lock    sync.RWMutex
+	size    int
+	samples []Sample
+}
+
+// NewBuffer creates a new Buffer that will keep up to size samples
+func NewBuffer(size int) *Buffer {
+	return &Buffer{size: size, samples: []Sample{}}
+}
+
+// Add stores a new sample, samples must be added in chronological order
+func (b *Buffer) Add(s Sample) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.samples = append(b.samples, s)
+	if len(b.samples) > b.size {
+		b.samples = append([]Sample{}, b.samples[len(b.samples)-b.size:]...)
+	}
+}
+
+// Prune removes all samples older than given time
+func (b *Buffer) Prune(before time.Time) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	i := 0
+	for i < len(b.samples) && b.samples[i].Timestamp.Before(before) {
+		i++
+	}
+	if i > 0 {
+		b.samples = append([]Sample{}, b.samples[i:]...)
+	}
+}
+
+// Len returns the number of stored samples
+func (b *Buffer) Len() int {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+	return len(b.samples)
+}
+
+// Buckets splits the time starting at start into count buckets of given
+// resolution, every bucket reports counts from the sample with the highest
+// number of alerts in it, so short spikes are still visible with a low
+// resolution, buckets without any sample have Samples set to 0
+func (b *Buffer) Buckets(start time.Time, resolution time.Duration, count int) []models.AlertCountsBucket {
+	buckets := make([]models.AlertCountsBucket, count)
+	for i := range buckets {
+		ts, _ := start.Add(resolution * time.Duration(i)).UTC().MarshalText()
+		buckets[i] = models.AlertCountsBucket{
+			Timestamp:  string(ts),
+			States:     map[string]int{},
+			Severities: map[string]int{},
+		}
+		for _, state := range models.AlertStateList {
+			buckets[i].States[state] = 0
+		}
+	}
+
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+	for _, s := range b.samples {
+		if s.Timestamp.Before(start) {
+			continue
+		}
+		i := int(s.Timestamp.Sub(start) / resolution)
+		if i >= count {
+			break
+		}
+		buckets[i].Samples++
+		if buckets[i].Samples > 1 && s.Total < buckets[i].Total {
+			continue
+		}
+		buckets[i].Total = s.Total
+		for state := range buckets[i].States {
+			buckets[i].States[state] = s.States[state]
+		}
+		buckets[i].Severities = map[string]int{}
+		for severity, n := range s.Severities {
+			buckets[i].Severities[severity] = n
+		}
+	}
+	return buckets
+}
+
+// Save writes all samples to given path, a temporary file is written first
+// and then renamed, so we never leave a partially written file behind
+func (b *Buffer) Save(path string) error {
+	b.lock.RLock()
+	content, err := json.Marshal(b.samples)
+	b.lock.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path))
+	if err != nil {
+		return err
+	}
+	if _, err = tmp.Write(content); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Load replaces stored samples with samples saved to given path, it does
+// nothing if the file doesn't exist
+func (b *Buffer) Load(path string) error {
+	content, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	samples := []Sample{}
+	if err = json.Unmarshal(content, &samples); err != nil {
+		return err
+	}
+	if len(samples) > b.size {
+		samples = samples[len(samples)-b.size:]
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.samples = samples
+	return nil
+}
@@ -0,0 +1,119 @@
+package counts_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cloudflare/unsee/internal/counts"
+)
+
+var start = time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+
+func sample(offset time.Duration, total int) counts.Sample {
+	return counts.Sample{
+		Timestamp:  start.Add(offset),
+		Total:      total,
+		States:     map[string]int{"active": total},
+		Severities: map[string]int{"critical": total},
+	}
+}
+
+func TestBufferSize(t *testing.T) {
+	b := counts.NewBuffer(3)
+	for i := 0; i < 5; i++ {
+		b.Add(sample(time.Minute*time.Duration(i), i))
+	}
+	if b.Len() != 3 {
+		t.Errorf("Buffer has %d samples, expected 3", b.Len())
+	}
+	buckets := b.Buckets(start, time.Minute, 5)
+	for i, bucket := range buckets {
+		if (i >= 2) != (bucket.Samples == 1) {
+			t.Errorf("Bucket %d has %d samples, only the last 3 samples should be kept", i, bucket.Samples)
+		}
+	}
+
+	b.Prune(start.Add(time.Minute * 4))
+	if b.Len() != 1 {
+		t.Errorf("Buffer has %d samples after pruning, expected 1", b.Len())
+	}
+}
+
+func TestBuckets(t *testing.T) {
+	b := counts.NewBuffer(100)
+	b.Add(sample(-time.Second, 100))
+	b.Add(sample(time.Second*10, 3))
+	b.Add(sample(time.Second*20, 5))
+	b.Add(sample(time.Second*30, 4))
+	b.Add(sample(time.Minute*2, 1))
+	b.Add(sample(time.Minute*3, 100))
+
+	buckets := b.Buckets(start, time.Minute, 3)
+	if len(buckets) != 3 {
+		t.Fatalf("Got %d buckets, expected 3", len(buckets))
+	}
+	for i, expected := range []struct {
+		timestamp string
+		samples   int
+		total     int
+	}{
+		{timestamp: "2026-10-14T12:00:00Z", samples: 3, total: 5},
+		{timestamp: "2026-10-14T12:01:00Z", samples: 0, total: 0},
+		{timestamp: "2026-10-14T12:02:00Z", samples: 1, total: 1},
+	} {
+		bucket := buckets[i]
+		if bucket.Timestamp != expected.timestamp || bucket.Samples != expected.samples || bucket.Total != expected.total {
+			t.Errorf("Bucket %d is %v, expected %v", i, bucket, expected)
+		}
+		if bucket.Severities["critical"] != expected.total || bucket.States["active"] != expected.total {
+			t.Errorf("Bucket %d has states=%v severities=%v, expected counts from the highest sample", i, bucket.States, bucket.Severities)
+		}
+	}
+	if buckets[1].States["suppressed"] != 0 || len(buckets[1].States) != 3 {
+		t.Errorf("Empty bucket should have all states set to 0, got %v", buckets[1].States)
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "unsee-counts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "history.json")
+
+	empty := counts.NewBuffer(2)
+	if err = empty.Load(path); err != nil {
+		t.Errorf("Load() failed for a missing file: %s", err)
+	}
+
+	b := counts.NewBuffer(10)
+	for i := 0; i < 3; i++ {
+		b.Add(sample(time.Minute*time.Duration(i), i))
+	}
+	if err = b.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := counts.NewBuffer(2)
+	if err = loaded.Load(path); err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Len() != 2 {
+		t.Errorf("Loaded %d samples, expected only the last 2", loaded.Len())
+	}
+	if !reflect.DeepEqual(loaded.Buckets(start, time.Minute, 3)[1:], b.Buckets(start, time.Minute, 3)[1:]) {
+		t.Errorf("Loaded samples don't match saved ones")
+	}
+
+	if err = ioutil.WriteFile(path, []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err = loaded.Load(path); err == nil {
+		t.Errorf("Load() didn't fail on invalid JSON")
+	}
+}
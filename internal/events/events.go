@@ -0,0 +1,167 @@
+// Package events implements detection of alert changes between collections
+// and a broker used to deliver those changes to subscribed clients
+package events
+
+import (
+	"sync"
+
+	"github.com/cloudflare/unsee/internal/models"
+)
+
+// types of alert events
+const (
+	// EventAdded is used for alerts that weren't present in the previous
+	// collection
+	EventAdded = "added"
+	// EventResolved is used for alerts that are no longer present
+	EventResolved = "resolved"
+	// EventSilenced is used for alerts that were silenced since the previous
+	// collection
+	EventSilenced = "silenced"
+	// EventChanged is used for alerts with any other change
+	EventChanged = "changed"
+)
+
+// size of the per subscriber buffer, if subscriber doesn't read events fast
+// enough and the buffer is full then new events will be dropped for it
+const subscriberBufferSize = 16
+
+type alertRef struct {
+	group models.AlertGroup
+	alert models.Alert
+}
+
+func indexAlerts(groups []models.AlertGroup) map[string]alertRef {
+	index := map[string]alertRef{}
+	for _, ag := range groups {
+		for _, alert := range ag.Alerts {
+			alert.UpdateFingerprints()
+			index[ag.ID+"/"+alert.LabelsFingerprint()] = alertRef{group: ag, alert: alert}
+		}
+	}
+	return index
+}
+
+func newEvent(eventType string, ref alertRef) models.AlertEvent {
+	return models.AlertEvent{
+		Type:     eventType,
+		GroupID:  ref.group.ID,
+		Receiver: ref.group.Receiver,
+		Labels:   ref.group.Labels,
+		Alert:    ref.alert,
+	}
+}
+
+// Diff compares two lists of alert groups and returns a list of events for
+// every alert that was added, resolved or modified
+func Diff(oldGroups, newGroups []models.AlertGroup) []models.AlertEvent {
+	changes := []models.AlertEvent{}
+
+	oldAlerts := indexAlerts(oldGroups)
+	newAlerts := indexAlerts(newGroups)
+
+	// iterate groups rather than the index so events are always in the same
+	// order
+	for _, ag := range newGroups {
+		for _, alert := range ag.Alerts {
+			alert.UpdateFingerprints()
+			key := ag.ID + "/" + alert.LabelsFingerprint()
+			ref := newAlerts[key]
+			old, found := oldAlerts[key]
+			switch {
+			case !found:
+				changes = append(changes, newEvent(EventAdded, ref))
+			case !old.alert.IsSilenced() && ref.alert.IsSilenced():
+				changes = append(changes, newEvent(EventSilenced, ref))
+			case old.alert.ContentFingerprint() != ref.alert.ContentFingerprint():
+				changes = append(changes, newEvent(EventChanged, ref))
+			}
+		}
+	}
+
+	for _, ag := range oldGroups {
+		for _, alert := range ag.Alerts {
+			alert.UpdateFingerprints()
+			key := ag.ID + "/" + alert.LabelsFingerprint()
+			if _, found := newAlerts[key]; !found {
+				changes = append(changes, newEvent(EventResolved, oldAlerts[key]))
+			}
+		}
+	}
+
+	return changes
+}
+
+// Broker delivers published events to all subscribers
+type Broker struct {
+	lock        sync.RWMutex
+	subscribers map[chan []models.AlertEvent]bool
+	closed      bool
+}
+
+// NewBroker creates a new Broker instance without any subscribers
+func NewBroker() *Broker {
+	return &Broker{
+		subscribers: map[chan []models.AlertEvent]bool{},
+	}
+}
+
+// Subscribe returns a new channel that will receive all published events,
+// Unsubscribe() must be called once it's no longer used, the channel is
+// closed if the broker is closed
+func (b *Broker) Subscribe() chan []models.AlertEvent {
+	ch := make(chan []models.AlertEvent, subscriberBufferSize)
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.closed {
+		close(ch)
+		return ch
+	}
+	b.subscribers[ch] = true
+	return ch
+}
+
+// Unsubscribe removes the subscriber and closes its channel
+func (b *Broker) Unsubscribe(ch chan []models.AlertEvent) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if _, found := b.subscribers[ch]; found {
+		delete(b.subscribers, ch)
+		close(ch)
+	}
+}
+
+// Close removes all subscribers and closes their channels, any channel
+// returned by Subscribe() after that will be closed right away
+func (b *Broker) Close() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.closed = true
+	for ch := range b.subscribers {
+		delete(b.subscribers, ch)
+		close(ch)
+	}
+}
+
+// Subscribers returns the number of active subscribers
+func (b *Broker) Subscribers() int {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+	return len(b.subscribers)
+}
+
+// Publish sends events to all subscribers, it never blocks, events will be
+// dropped for subscribers with full buffers
+func (b *Broker) Publish(changes []models.AlertEvent) {
+	if len(changes) == 0 {
+		return
+	}
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+	for ch := range b.subscribers {
+		select {
+		case ch <- changes:
+		default:
+		}
+	}
+}
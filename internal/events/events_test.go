@@ -0,0 +1,273 @@
+package events_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cloudflare/unsee/internal/events"
+	"github.com/cloudflare/unsee/internal/models"
+)
+
+func newGroup(id string, alerts ...models.Alert) models.AlertGroup {
+	return models.AlertGroup{
+		ID:       id,
+		Receiver: "default",
+		Labels:   map[string]string{"alertname": "Fake"},
+		Alerts:   alerts,
+	}
+}
+
+func newAlert(instance string, state string, silencedBy ...string) models.Alert {
+	return models.Alert{
+		Labels:     map[string]string{"alertname": "Fake", "instance": instance},
+		State:      state,
+		SilencedBy: silencedBy,
+	}
+}
+
+type diffTest struct {
+	name     string
+	old      []models.AlertGroup
+	new      []models.AlertGroup
+	expected []string
+}
+
+var diffTests = []diffTest{
+	diffTest{
+		name:     "no changes",
+		old:      []models.AlertGroup{newGroup("1", newAlert("a", "active"))},
+		new:      []models.AlertGroup{newGroup("1", newAlert("a", "active"))},
+		expected: []string{},
+	},
+	diffTest{
+		name:     "alert added",
+		old:      []models.AlertGroup{newGroup("1", newAlert("a", "active"))},
+		new:      []models.AlertGroup{newGroup("1", newAlert("a", "active"), newAlert("b", "active"))},
+		expected: []string{"added:b"},
+	},
+	diffTest{
+		name:     "group added",
+		old:      []models.AlertGroup{},
+		new:      []models.AlertGroup{newGroup("1", newAlert("a", "active"))},
+		expected: []string{"added:a"},
+	},
+	diffTest{
+		name:     "alert resolved",
+		old:      []models.AlertGroup{newGroup("1", newAlert("a", "active"), newAlert("b", "active"))},
+		new:      []models.AlertGroup{newGroup("1", newAlert("b", "active"))},
+		expected: []string{"resolved:a"},
+	},
+	diffTest{
+		name:     "alert silenced",
+		old:      []models.AlertGroup{newGroup("1", newAlert("a", "active"))},
+		new:      []models.AlertGroup{newGroup("1", newAlert("a", "suppressed", "123"))},
+		expected: []string{"silenced:a"},
+	},
+	diffTest{
+		name:     "alert unsilenced",
+		old:      []models.AlertGroup{newGroup("1", newAlert("a", "suppressed", "123"))},
+		new:      []models.AlertGroup{newGroup("1", newAlert("a", "active"))},
+		expected: []string{"changed:a"},
+	},
+	diffTest{
+		name:     "alert moved between groups",
+		old:      []models.AlertGroup{newGroup("1", newAlert("a", "active"))},
+		new:      []models.AlertGroup{newGroup("2", newAlert("a", "active"))},
+		expected: []string{"added:a", "resolved:a"},
+	},
+}
+
+func TestDiff(t *testing.T) {
+	for _, dt := range diffTests {
+		changes := events.Diff(dt.old, dt.new)
+		if len(changes) != len(dt.expected) {
+			t.Errorf("[%s] Got %d event(s), expected %d: %v", dt.name, len(changes), len(dt.expected), changes)
+			continue
+		}
+		for i, change := range changes {
+			got := change.Type + ":" + change.Alert.Labels["instance"]
+			if got != dt.expected[i] {
+				t.Errorf("[%s] Got event '%s', expected '%s'", dt.name, got, dt.expected[i])
+			}
+		}
+	}
+}
+
+func TestBroker(t *testing.T) {
+	b := events.NewBroker()
+	ch1 := b.Subscribe()
+	ch2 := b.Subscribe()
+	if b.Subscribers() != 2 {
+		t.Errorf("Got %d subscribers, expected 2", b.Subscribers())
+	}
+
+	// empty event lists are never sent
+	b.Publish([]models.AlertEvent{})
+
+	changes := []models.AlertEvent{models.AlertEvent{Type: events.EventAdded}}
+	b.Publish(changes)
+	for _, ch := range []chan []models.AlertEvent{ch1, ch2} {
+		select {
+		case got := <-ch:
+			if len(got) != 1 || got[0].Type != events.EventAdded {
+				t.Errorf("Got invalid events: %v", got)
+			}
+		default:
+			t.Error("No events received")
+		}
+	}
+
+	b.Unsubscribe(ch1)
+	if _, ok := <-ch1; ok {
+		t.Error("Channel wasn't closed after Unsubscribe()")
+	}
+	if b.Subscribers() != 1 {
+		t.Errorf("Got %d subscribers, expected 1", b.Subscribers())
+	}
+
+	// slow subscriber shouldn't block publishing
+	for i := 0; i < 100; i++ {
+		b.Publish(changes)
+	}
+	b.Unsubscribe(ch2)
+}
+
+func TestBrokerClose(t *testing.T) {
+	b := events.NewBroker()
+	ch1 := b.Subscribe()
+	b.Close()
+	if _, ok := <-ch1; ok {
+		t.Error("Channel wasn't closed after Close()")
+	}
+	if b.Subscribers() != 0 {
+		t.Errorf("Got %d subscribers after Close(), expected 0", b.Subscribers())
+	}
+	// it's safe to unsubscribe after closing
+	b.Unsubscribe(ch1)
+
+	ch2 := b.Subscribe()
+	if _, ok := <-ch2; ok {
+		t.Error("Channel returned after Close() isn't closed")
+	}
+	b.Publish([]models.AlertEvent{models.AlertEvent{Type: events.EventAdded}})
+}
+
+func TestHistory(t *testing.T) {
+	h := events.NewHistory(2)
+	if h.Version() != 0 {
+		t.Errorf("Got version %d from empty history", h.Version())
+	}
+	if _, _, ok := h.Changes(0); ok {
+		t.Error("Changes() returned ok for empty history")
+	}
+
+	v1 := h.Add([]models.AlertGroup{
+		newGroup("1", newAlert("a", "active")),
+		newGroup("2", newAlert("b", "active")),
+	})
+	hash := h.Hash()
+	h2 := events.NewHistory(1)
+	h2.Add([]models.AlertGroup{
+		newGroup("2", newAlert("b", "active")),
+		newGroup("1", newAlert("a", "active")),
+	})
+	if h2.Hash() != hash {
+		t.Errorf("Hash is different for identical alert groups: %s != %s", h2.Hash(), hash)
+	}
+
+	v2 := h.Add([]models.AlertGroup{
+		newGroup("1", newAlert("a", "active")),
+		newGroup("3", newAlert("c", "active")),
+	})
+	if h.Hash() == hash {
+		t.Error("Hash didn't change after alert groups were modified")
+	}
+	if v2 <= v1 || h.Version() != v2 {
+		t.Errorf("Versions are not increasing: %d, %d", v1, v2)
+	}
+
+	changed, removed, ok := h.Changes(v1)
+	if !ok {
+		t.Fatalf("Changes(%d) returned false", v1)
+	}
+	if len(changed) != 1 || !changed["3"] {
+		t.Errorf("Invalid changed groups: %v", changed)
+	}
+	if len(removed) != 1 || removed[0] != "2" {
+		t.Errorf("Invalid removed groups: %v", removed)
+	}
+
+	changed, removed, ok = h.Changes(v2)
+	if !ok || len(changed) != 0 || len(removed) != 0 {
+		t.Errorf("Changes(%d) returned changes: %v %v %v", v2, changed, removed, ok)
+	}
+
+	// only 2 versions are kept
+	h.Add([]models.AlertGroup{newGroup("1", newAlert("a", "suppressed", "1"))})
+	if _, _, ok := h.Changes(v1); ok {
+		t.Errorf("Changes(%d) returned ok for expired version", v1)
+	}
+	changed, _, ok = h.Changes(v2)
+	if !ok || len(changed) != 1 || !changed["1"] {
+		t.Errorf("Invalid changed groups: %v", changed)
+	}
+}
+
+func transitionTypes(transitions []models.AlertTransition) []string {
+	types := []string{}
+	for _, tr := range transitions {
+		types = append(types, tr.Type)
+	}
+	return types
+}
+
+func TestTimeline(t *testing.T) {
+	timeline := events.NewTimeline(4)
+	now := time.Now()
+	a := newAlert("a", "active")
+	a.UpdateFingerprints()
+
+	for i, collection := range [][]models.AlertGroup{
+		[]models.AlertGroup{newGroup("1", newAlert("a", "active"))},
+		// same alert sent to two receivers is only silenced once both copies
+		// are silenced
+		[]models.AlertGroup{newGroup("1", newAlert("a", "suppressed", "1")), newGroup("2", newAlert("a", "active"))},
+		[]models.AlertGroup{newGroup("1", newAlert("a", "suppressed", "1")), newGroup("2", newAlert("a", "suppressed", "1"))},
+		[]models.AlertGroup{newGroup("1", newAlert("a", "active"))},
+		[]models.AlertGroup{},
+		[]models.AlertGroup{},
+		[]models.AlertGroup{newGroup("1", newAlert("a", "active"))},
+	} {
+		timeline.Record(collection, now.Add(time.Minute*time.Duration(i)))
+	}
+
+	// only the last 4 transitions are kept
+	transitions := timeline.Transitions(&a)
+	expected := []string{"silenced", "unsilenced", "resolved", "reappeared"}
+	if !reflect.DeepEqual(transitionTypes(transitions), expected) {
+		t.Fatalf("Got transitions %v, expected %v", transitionTypes(transitions), expected)
+	}
+	for i, minutes := range []int{2, 3, 4, 6} {
+		if !transitions[i].Timestamp.Equal(now.Add(time.Minute * time.Duration(minutes))) {
+			t.Errorf("Transition %s has timestamp %s, expected %s", transitions[i].Type, transitions[i].Timestamp, now.Add(time.Minute*time.Duration(minutes)))
+		}
+	}
+
+	b := newAlert("b", "suppressed", "1")
+	b.UpdateFingerprints()
+	timeline.Record([]models.AlertGroup{newGroup("1", b)}, now.Add(time.Minute*7))
+	if types := transitionTypes(timeline.Transitions(&b)); !reflect.DeepEqual(types, []string{"appeared", "silenced"}) {
+		t.Errorf("Got transitions %v for an alert that appeared silenced", types)
+	}
+
+	// resolved alerts are forgotten once pruned
+	timeline.Record([]models.AlertGroup{newGroup("1", newAlert("a", "active"))}, now.Add(time.Minute*8))
+	timeline.Prune(now.Add(time.Minute * 9))
+	if transitions := timeline.Transitions(&b); len(transitions) != 0 {
+		t.Errorf("Got transitions %v for a pruned alert", transitionTypes(transitions))
+	}
+	if transitions := timeline.Transitions(&a); len(transitions) != 4 {
+		t.Errorf("Transitions of an alert that's still present were pruned")
+	}
+}
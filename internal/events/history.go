@@ -0,0 +1,120 @@
+package events
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cloudflare/unsee/internal/models"
+)
+
+// History keeps alert group hashes for recent collections, so it's possible
+// to tell which groups changed since given version. Versions are timestamps
+// in milliseconds, so they will keep increasing after a restart.
+type History struct {
+	lock      sync.RWMutex
+	size      int
+	version   int64
+	hash      string
+	versions  []int64
+	snapshots map[int64]map[string]string
+}
+
+// NewHistory creates a new History that will keep up to size versions
+func NewHistory(size int) *History {
+	return &History{
+		size:      size,
+		versions:  []int64{},
+		snapshots: map[int64]map[string]string{},
+	}
+}
+
+func groupHash(ag models.AlertGroup) string {
+	// fingerprints of deduplicated alerts might be stale
+	alerts := models.AlertList{}
+	for _, alert := range ag.Alerts {
+		alert.UpdateFingerprints()
+		alerts = append(alerts, alert)
+	}
+	ag.Alerts = alerts
+	return ag.ContentFingerprint()
+}
+
+// Add stores hashes of given alert groups as a new version and returns it
+func (h *History) Add(groups []models.AlertGroup) int64 {
+	snapshot := map[string]string{}
+	ids := []string{}
+	for _, ag := range groups {
+		snapshot[ag.ID] = groupHash(ag)
+		ids = append(ids, ag.ID)
+	}
+	sort.Strings(ids)
+	hasher := sha1.New()
+	for _, id := range ids {
+		io.WriteString(hasher, id)
+		io.WriteString(hasher, snapshot[id])
+	}
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	version := time.Now().UnixNano() / int64(time.Millisecond)
+	if version <= h.version {
+		version = h.version + 1
+	}
+	h.version = version
+	h.hash = fmt.Sprintf("%x", hasher.Sum(nil))
+	h.versions = append(h.versions, version)
+	h.snapshots[version] = snapshot
+	for len(h.versions) > h.size {
+		delete(h.snapshots, h.versions[0])
+		h.versions = h.versions[1:]
+	}
+	return version
+}
+
+// Version returns the latest version, or 0 if nothing was added yet
+func (h *History) Version() int64 {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+	return h.version
+}
+
+// Hash returns a checksum of all alert groups from the latest version, it
+// will be the same for versions with identical alert groups
+func (h *History) Hash() string {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+	return h.hash
+}
+
+// Changes returns IDs of groups that were added or modified and IDs of groups
+// that were removed since given version, ok will be false if given version is
+// no longer (or was never) stored
+func (h *History) Changes(since int64) (changed map[string]bool, removed []string, ok bool) {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	old, found := h.snapshots[since]
+	if !found {
+		return nil, nil, false
+	}
+	current := h.snapshots[h.version]
+
+	changed = map[string]bool{}
+	removed = []string{}
+	for id, hash := range current {
+		if oldHash, found := old[id]; !found || oldHash != hash {
+			changed[id] = true
+		}
+	}
+	for id := range old {
+		if _, found := current[id]; !found {
+			removed = append(removed, id)
+		}
+	}
+	return changed, removed, true
+}
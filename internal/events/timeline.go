@@ -0,0 +1,134 @@
+package events
+
+import (
+	"sync"
+	"time"
+
+	"github.com/cloudflare/unsee/internal/models"
+)
+
+// types of alert transitions recorded by the Timeline
+const (
+	// TransitionAppeared is used when the alert is seen for the first time
+	TransitionAppeared = "appeared"
+	// TransitionSilenced is used when a silence started muting the alert
+	TransitionSilenced = "silenced"
+	// TransitionUnsilenced is used when the alert is no longer muted by any
+	// silence
+	TransitionUnsilenced = "unsilenced"
+	// TransitionResolved is used when the alert is no longer collected
+	TransitionResolved = "resolved"
+	// TransitionReappeared is used when a resolved alert is collected again
+	TransitionReappeared = "reappeared"
+)
+
+type alertTimeline struct {
+	present     bool
+	silenced    bool
+	resolvedAt  time.Time
+	transitions []models.AlertTransition
+}
+
+// Timeline records state transitions of every alert, alerts are identified by
+// the fingerprint, so the same alert sent to multiple receivers has a single
+// timeline
+type Timeline struct {
+	lock   sync.RWMutex
+	size   int
+	alerts map[string]*alertTimeline
+}
+
+// NewTimeline creates a new Timeline that will keep up to size most recent
+// transitions for every alert
+func NewTimeline(size int) *Timeline {
+	return &Timeline{
+		size:   size,
+		alerts: map[string]*alertTimeline{},
+	}
+}
+
+func timelineKey(alert *models.Alert) string {
+	if alert.Fingerprint != "" {
+		return alert.Fingerprint
+	}
+	return alert.LabelsFingerprint()
+}
+
+func (at *alertTimeline) add(transitionType string, now time.Time, size int) {
+	at.transitions = append(at.transitions, models.AlertTransition{Type: transitionType, Timestamp: now})
+	if len(at.transitions) > size {
+		at.transitions = append([]models.AlertTransition{}, at.transitions[len(at.transitions)-size:]...)
+	}
+}
+
+// Record compares alerts from a new collection with alerts from previous
+// collections and records all transitions, an alert is only silenced if all
+// its copies are silenced
+func (t *Timeline) Record(groups []models.AlertGroup, now time.Time) {
+	silenced := map[string]bool{}
+	for _, ag := range groups {
+		for _, alert := range ag.Alerts {
+			alert.UpdateFingerprints()
+			key := timelineKey(&alert)
+			if s, found := silenced[key]; found {
+				silenced[key] = s && alert.IsSilenced()
+			} else {
+				silenced[key] = alert.IsSilenced()
+			}
+		}
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	for key, isSilenced := range silenced {
+		at, found := t.alerts[key]
+		switch {
+		case !found:
+			at = &alertTimeline{}
+			t.alerts[key] = at
+			at.add(TransitionAppeared, now, t.size)
+		case !at.present:
+			at.add(TransitionReappeared, now, t.size)
+		}
+		switch {
+		case isSilenced && (!at.present || !at.silenced):
+			at.add(TransitionSilenced, now, t.size)
+		case !isSilenced && at.present && at.silenced:
+			at.add(TransitionUnsilenced, now, t.size)
+		}
+		at.present = true
+		at.silenced = isSilenced
+	}
+
+	for key, at := range t.alerts {
+		if _, found := silenced[key]; !found && at.present {
+			at.add(TransitionResolved, now, t.size)
+			at.present = false
+			at.silenced = false
+			at.resolvedAt = now
+		}
+	}
+}
+
+// Prune forgets alerts that were resolved before given time
+func (t *Timeline) Prune(before time.Time) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	for key, at := range t.alerts {
+		if !at.present && at.resolvedAt.Before(before) {
+			delete(t.alerts, key)
+		}
+	}
+}
+
+// Transitions returns all recorded transitions for given alert, oldest first
+func (t *Timeline) Transitions(alert *models.Alert) []models.AlertTransition {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+	at, found := t.alerts[timelineKey(alert)]
+	if !found {
+		return []models.AlertTransition{}
+	}
+	return append([]models.AlertTransition{}, at.transitions...)
+}
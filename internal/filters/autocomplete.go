@@ -10,7 +10,19 @@ func makeAC(value string, tokens []string) models.Autocomplete {
 	acHint := models.Autocomplete{
 		Value:  value,
 		Tokens: tokens,
+		Weight: 1,
 	}
 	acHint.Tokens = append(acHint.Tokens, value)
 	return acHint
 }
+
+// addWeightedAC adds a hint to the map, if there's already a hint with the
+// same value it will have its weight increased instead
+func addWeightedAC(hints map[string]models.Autocomplete, hint models.Autocomplete) {
+	if h, found := hints[hint.Value]; found {
+		h.Weight += hint.Weight
+		hints[hint.Value] = h
+		return
+	}
+	hints[hint.Value] = hint
+}
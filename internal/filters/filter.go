@@ -1,11 +1,7 @@
 package filters
 
 import (
-	"fmt"
-	"regexp"
-
 	"github.com/cloudflare/unsee/internal/models"
-	"github.com/cloudflare/unsee/internal/slices"
 )
 
 // FilterT provides methods for interacting with alert filters
@@ -17,6 +13,20 @@ type FilterT interface {
 	GetIsValid() bool
 }
 
+// GroupFilterT is implemented by filters that match alerts based on the alert
+// group they belong to, SetGroup() must be called with every group before
+// calling Match() on alerts from that group
+type GroupFilterT interface {
+	SetGroup(group *models.AlertGroup)
+}
+
+// GroupLimitFilterT is implemented by filters that limit the number of alert
+// groups, LimitGroups() must be called with the list of groups left after
+// filtering, it will return the truncated list
+type GroupLimitFilterT interface {
+	LimitGroups(groups []models.AlertGroup) []models.AlertGroup
+}
+
 type alertFilter struct {
 	FilterT
 	Matched string
@@ -58,21 +68,12 @@ func NewFilter(expression string) FilterT {
 	invalid := alwaysInvalidFilter{}
 	invalid.init("", nil, expression, false, expression)
 
-	reExp := fmt.Sprintf("^(?P<matched>(%s))(?P<operator>(%s))(?P<value>(.*))", filterRegex, matcherRegex)
-	re := regexp.MustCompile(reExp)
-	match := re.FindStringSubmatch(expression)
-	result := make(map[string]string)
-	for i, name := range re.SubexpNames() {
-		if name != "" && i > 0 && i <= len(match) {
-			result[name] = match[i]
-		}
+	e, fc, parseErr := parse(expression)
+	if parseErr != nil {
+		return &invalid
 	}
 
-	matched, _ := result["matched"]
-	operator, _ := result["operator"]
-	value, _ := result["value"]
-
-	if matched == "" && operator == "" && value == "" {
+	if e.Fuzzy {
 		// no "filter=" part, just the value, use fuzzy filter
 		f := newFuzzyFilter()
 		matcher, err := newMatcher(regexpOperator)
@@ -84,37 +85,12 @@ func NewFilter(expression string) FilterT {
 		return f
 	}
 
-	if value == "" {
-		// there's no value, so it's always invalid
+	matcher, err := newMatcher(e.Operator)
+	if err != nil {
 		return &invalid
 	}
-
-	if operator == "" {
-		// no operator, no valid filter here
-		return &invalid
-	}
-
-	// we have "filter=" part, lookup filter that matches
-	for _, fc := range AllFilters {
-		f := fc.Factory()
-		if !fc.LabelRe.MatchString(matched) {
-			// filter name doesn't match, keep searching
-			continue
-		}
-		if !slices.StringInSlice(fc.SupportedOperators, operator) {
-			return &invalid
-		}
-		matcher, err := newMatcher(operator)
-		if err != nil {
-			f.init(matched, nil, expression, false, "")
-		} else {
-			if value != "" {
-				f.init(matched, &matcher, expression, true, value)
-				return f
-			}
-			f.init(matched, &matcher, expression, false, "")
-		}
-	}
-
-	return &invalid
+	// we have "filter=" part, init the filter that matched its name
+	f := fc.Factory()
+	f.init(e.Name, &matcher, expression, true, e.Value)
+	return f
 }
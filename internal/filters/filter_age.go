@@ -2,12 +2,41 @@ package filters
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/cloudflare/unsee/internal/models"
 )
 
+// parseAge works like time.ParseDuration but also accepts days as the leading
+// unit (2d, 1d12h), this is useful for alerts that are firing for a long time
+func parseAge(value string) (time.Duration, error) {
+	i := strings.Index(value, "d")
+	if i < 0 {
+		return time.ParseDuration(value)
+	}
+
+	days, err := strconv.Atoi(value[:i])
+	if err != nil {
+		return 0, fmt.Errorf("Invalid number of days in '%s'", value)
+	}
+	dur := time.Duration(days) * time.Hour * 24
+
+	if rest := value[i+1:]; rest != "" {
+		extra, err := time.ParseDuration(rest)
+		if err != nil {
+			return 0, err
+		}
+		if days < 0 || strings.HasPrefix(value, "-") {
+			dur -= extra
+		} else {
+			dur += extra
+		}
+	}
+	return dur, nil
+}
+
 type ageFilter struct {
 	alertFilter
 }
@@ -20,7 +49,7 @@ func (filter *ageFilter) init(name string, matcher *matcherT, rawText string, is
 	filter.RawText = rawText
 	filter.IsValid = isValid
 
-	dur, err := time.ParseDuration(value)
+	dur, err := parseAge(value)
 	if err != nil {
 		filter.IsValid = false
 	}
@@ -33,23 +33,31 @@ func newAlertmanagerInstanceFilter() FilterT {
 	return &f
 }
 
+func alertmanagerInstanceHints(name string, operators []string, instance string) []models.Autocomplete {
+	tokens := []models.Autocomplete{}
+	for _, operator := range operators {
+		switch operator {
+		case equalOperator, notEqualOperator:
+			token := fmt.Sprintf("%s%s%s", name, operator, instance)
+			tokens = append(tokens, makeAC(
+				token,
+				[]string{
+					name,
+					strings.TrimPrefix(name, "@"),
+					name + operator,
+				},
+			))
+		}
+	}
+	return tokens
+}
+
 func alertmanagerInstanceAutocomplete(name string, operators []string, alerts []models.Alert) []models.Autocomplete {
 	tokens := map[string]models.Autocomplete{}
 	for _, alert := range alerts {
 		for _, am := range alert.Alertmanager {
-			for _, operator := range operators {
-				switch operator {
-				case equalOperator, notEqualOperator:
-					token := fmt.Sprintf("%s%s%s", name, operator, am.Name)
-					tokens[token] = makeAC(
-						token,
-						[]string{
-							name,
-							strings.TrimPrefix(name, "@"),
-							name + operator,
-						},
-					)
-				}
+			for _, hint := range alertmanagerInstanceHints(name, operators, am.Name) {
+				tokens[hint.Value] = hint
 			}
 		}
 	}
@@ -59,3 +67,16 @@ func alertmanagerInstanceAutocomplete(name string, operators []string, alerts []
 	}
 	return acData
 }
+
+// AlertmanagerInstanceAutocomplete returns autocomplete hints for the
+// @alertmanager filter and given Alertmanager instance name, it's used to
+// provide hints for all configured instances, even if there are no alerts
+// collected from them
+func AlertmanagerInstanceAutocomplete(instance string) []models.Autocomplete {
+	for _, fc := range AllFilters {
+		if fc.Label == "@alertmanager" {
+			return alertmanagerInstanceHints(fc.Label, fc.SupportedOperators, instance)
+		}
+	}
+	return []models.Autocomplete{}
+}
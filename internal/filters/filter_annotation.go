@@ -0,0 +1,51 @@
+package filters
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cloudflare/unsee/internal/models"
+)
+
+const annotationFilterPrefix = "@annotation:"
+
+type annotationFilter struct {
+	alertFilter
+	Annotation string
+}
+
+func (filter *annotationFilter) init(name string, matcher *matcherT, rawText string, isValid bool, value string) {
+	filter.Matched = name
+	if matcher != nil {
+		filter.Matcher = *matcher
+	}
+	filter.RawText = rawText
+	filter.IsValid = isValid
+	filter.Value = value
+	filter.Annotation = strings.TrimPrefix(name, annotationFilterPrefix)
+}
+
+func (filter *annotationFilter) Match(alert *models.Alert, matches int) bool {
+	if filter.IsValid {
+		// missing annotation is compared as an empty string, same as labels
+		var val string
+		for _, annotation := range alert.Annotations {
+			if annotation.Name == filter.Annotation {
+				val = annotation.Value
+				break
+			}
+		}
+		isMatch := filter.Matcher.Compare(val, filter.Value)
+		if isMatch {
+			filter.Hits++
+		}
+		return isMatch
+	}
+	e := fmt.Sprintf("Match() called on invalid filter %#v", filter)
+	panic(e)
+}
+
+func newAnnotationFilter() FilterT {
+	f := annotationFilter{}
+	return &f
+}
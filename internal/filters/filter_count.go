@@ -0,0 +1,73 @@
+package filters
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/cloudflare/unsee/internal/models"
+)
+
+type countFilter struct {
+	alertFilter
+	group *models.AlertGroup
+}
+
+func (filter *countFilter) init(name string, matcher *matcherT, rawText string, isValid bool, value string) {
+	filter.Matched = name
+	if matcher != nil {
+		filter.Matcher = *matcher
+	}
+	filter.RawText = rawText
+	filter.IsValid = isValid
+	if filter.IsValid {
+		val, err := strconv.Atoi(value)
+		if err != nil || val < 0 {
+			filter.IsValid = false
+		} else {
+			filter.Value = val
+		}
+	}
+}
+
+func (filter *countFilter) SetGroup(group *models.AlertGroup) {
+	filter.group = group
+}
+
+func (filter *countFilter) Match(alert *models.Alert, matches int) bool {
+	if filter.IsValid {
+		var size int
+		if filter.group != nil {
+			size = len(filter.group.Alerts)
+		}
+		isMatch := filter.Matcher.Compare(size, filter.Value)
+		if isMatch {
+			filter.Hits++
+		}
+		return isMatch
+	}
+	e := fmt.Sprintf("Match() called on invalid filter %#v", filter)
+	panic(e)
+}
+
+func newCountFilter() FilterT {
+	f := countFilter{}
+	return &f
+}
+
+func countAutocomplete(name string, operators []string, alerts []models.Alert) []models.Autocomplete {
+	tokens := []models.Autocomplete{}
+	for _, operator := range operators {
+		for _, val := range []string{"10", "100"} {
+			tokens = append(tokens, makeAC(
+				fmt.Sprintf("%s%s%s", name, operator, val),
+				[]string{
+					name,
+					strings.TrimPrefix(name, "@"),
+					fmt.Sprintf("%s%s", name, operator),
+				},
+			))
+		}
+	}
+	return tokens
+}
@@ -0,0 +1,28 @@
+package filters
+
+import (
+	"fmt"
+
+	"github.com/cloudflare/unsee/internal/models"
+)
+
+type fingerprintFilter struct {
+	alertFilter
+}
+
+func (filter *fingerprintFilter) Match(alert *models.Alert, matches int) bool {
+	if filter.IsValid {
+		isMatch := filter.Matcher.Compare(alert.Fingerprint, filter.Value)
+		if isMatch {
+			filter.Hits++
+		}
+		return isMatch
+	}
+	e := fmt.Sprintf("Match() called on invalid filter %#v", filter)
+	panic(e)
+}
+
+func newFingerprintFilter() FilterT {
+	f := fingerprintFilter{}
+	return &f
+}
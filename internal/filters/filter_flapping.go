@@ -0,0 +1,64 @@
+package filters
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/cloudflare/unsee/internal/models"
+)
+
+type flappingFilter struct {
+	alertFilter
+}
+
+func (filter *flappingFilter) init(name string, matcher *matcherT, rawText string, isValid bool, value string) {
+	filter.Matched = name
+	if matcher != nil {
+		filter.Matcher = *matcher
+	}
+	filter.RawText = rawText
+	filter.IsValid = isValid
+	filter.Value = value
+	if value != "true" && value != "false" {
+		filter.IsValid = false
+	}
+}
+
+func (filter *flappingFilter) Match(alert *models.Alert, matches int) bool {
+	if filter.IsValid {
+		isMatch := filter.Matcher.Compare(strconv.FormatBool(alert.Flapping), filter.Value)
+		if isMatch {
+			filter.Hits++
+		}
+		return isMatch
+	}
+	e := fmt.Sprintf("Match() called on invalid filter %#v", filter)
+	panic(e)
+}
+
+func newFlappingFilter() FilterT {
+	f := flappingFilter{}
+	return &f
+}
+
+// hints are only returned if there are any flapping alerts
+func flappingAutocomplete(name string, operators []string, alerts []models.Alert) []models.Autocomplete {
+	tokens := []models.Autocomplete{}
+	for _, operator := range operators {
+		for _, alert := range alerts {
+			if !alert.Flapping {
+				continue
+			}
+			tokens = append(tokens, makeAC(
+				name+operator+"true",
+				[]string{
+					name,
+					strings.TrimPrefix(name, "@"),
+					name + operator,
+				},
+			))
+		}
+	}
+	return tokens
+}
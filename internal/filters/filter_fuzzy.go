@@ -3,12 +3,17 @@ package filters
 import (
 	"fmt"
 	"regexp"
+	"strings"
 
 	"github.com/cloudflare/unsee/internal/models"
 )
 
+// fuzzyFilter is used when the filter expression has no "key=" part, value is
+// split into whitespace separated tokens and alert will match only if every
+// token is found in any of the alert labels, annotations or silence comments
 type fuzzyFilter struct {
 	alertFilter
+	tokens []string
 }
 
 func (filter *fuzzyFilter) init(name string, matcher *matcherT, rawText string, isValid bool, value string) {
@@ -19,42 +24,51 @@ func (filter *fuzzyFilter) init(name string, matcher *matcherT, rawText string,
 	filter.RawText = rawText
 	filter.IsValid = isValid
 	filter.Value = value
-	if _, err := regexp.Compile(value); err != nil {
-		filter.IsValid = false
+	filter.tokens = strings.Fields(value)
+	if len(filter.tokens) == 0 {
+		filter.tokens = []string{value}
+	}
+	for _, token := range filter.tokens {
+		if _, err := regexp.Compile(token); err != nil {
+			filter.IsValid = false
+		}
 	}
 }
 
-func (filter *fuzzyFilter) Match(alert *models.Alert, matches int) bool {
-	if filter.IsValid {
-		for _, val := range alert.Annotations {
-			if filter.Matcher.Compare(val.Value, filter.Value) {
-				filter.Hits++
-				return true
-			}
+func (filter *fuzzyFilter) matchToken(alert *models.Alert, token string) bool {
+	for _, val := range alert.Annotations {
+		if filter.Matcher.Compare(val.Value, token) {
+			return true
 		}
+	}
 
-		for _, val := range alert.Labels {
-			if filter.Matcher.Compare(val, filter.Value) {
-				filter.Hits++
-				return true
-			}
+	for _, val := range alert.Labels {
+		if filter.Matcher.Compare(val, token) {
+			return true
 		}
+	}
 
-		for _, silenceID := range alert.SilencedBy {
-			for _, am := range alert.Alertmanager {
-				silence, found := am.Silences[silenceID]
-				if found {
-					m := filter.Matcher.Compare(silence.Comment, filter.Value)
-					if m {
-						filter.Hits++
-						return true
-					}
-				}
+	for _, silenceID := range alert.SilencedBy {
+		for _, am := range alert.Alertmanager {
+			silence, found := am.Silences[silenceID]
+			if found && filter.Matcher.Compare(silence.Comment, token) {
+				return true
 			}
 		}
+	}
 
-		return false
+	return false
+}
 
+func (filter *fuzzyFilter) Match(alert *models.Alert, matches int) bool {
+	if filter.IsValid {
+		for _, token := range filter.tokens {
+			if !filter.matchToken(alert, token) {
+				return false
+			}
+		}
+		filter.Hits++
+		return true
 	}
 	e := fmt.Sprintf("Match() called on invalid filter %#v", filter)
 	panic(e)
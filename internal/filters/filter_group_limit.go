@@ -0,0 +1,57 @@
+package filters
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/cloudflare/unsee/internal/models"
+)
+
+type groupLimitFilter struct {
+	alertFilter
+}
+
+func (filter *groupLimitFilter) init(name string, matcher *matcherT, rawText string, isValid bool, value string) {
+	filter.Matched = name
+	if matcher != nil {
+		filter.Matcher = *matcher
+	}
+	filter.RawText = rawText
+	filter.IsValid = isValid
+	if filter.IsValid {
+		val, err := strconv.Atoi(value)
+		if err != nil || val < 1 {
+			filter.IsValid = false
+		} else {
+			filter.Value = val
+		}
+	}
+}
+
+// Match will always return true, groups are limited after all alerts are
+// filtered using LimitGroups()
+func (filter *groupLimitFilter) Match(alert *models.Alert, matches int) bool {
+	if filter.IsValid {
+		return true
+	}
+	e := fmt.Sprintf("Match() called on invalid filter %#v", filter)
+	panic(e)
+}
+
+func (filter *groupLimitFilter) LimitGroups(groups []models.AlertGroup) []models.AlertGroup {
+	if !filter.IsValid {
+		e := fmt.Sprintf("LimitGroups() called on invalid filter %#v", filter)
+		panic(e)
+	}
+	limit := filter.Value.(int)
+	if len(groups) <= limit {
+		return groups
+	}
+	filter.Hits += len(groups) - limit
+	return groups[:limit]
+}
+
+func newGroupLimitFilter() FilterT {
+	f := groupLimitFilter{}
+	return &f
+}
@@ -37,20 +37,20 @@ func labelAutocomplete(name string, operators []string, alerts []models.Alert) [
 				switch operator {
 				case equalOperator, notEqualOperator:
 					token := fmt.Sprintf("%s%s%s", key, operator, value)
-					tokens[token] = makeAC(
+					addWeightedAC(tokens, makeAC(
 						token,
 						[]string{
 							key,
 							fmt.Sprintf("%s%s", key, operator),
 							value,
 						},
-					)
+					))
 				case regexpOperator, negativeRegexOperator:
 					substrings := strings.Split(value, " ")
 					if len(substrings) > 1 {
 						for _, substring := range substrings {
 							token := fmt.Sprintf("%s%s%s", key, operator, substring)
-							tokens[token] = makeAC(
+							addWeightedAC(tokens, makeAC(
 								token,
 								[]string{
 									key,
@@ -58,20 +58,35 @@ func labelAutocomplete(name string, operators []string, alerts []models.Alert) [
 									value,
 									substring,
 								},
-							)
+							))
 						}
 					}
+				case caseInsensitiveEqualOperator:
+					// only suggest it for values with upper case characters, so
+					// it's easier to find those while typing in lower case
+					if strings.ToLower(value) != value {
+						token := fmt.Sprintf("%s%s%s", key, operator, strings.ToLower(value))
+						addWeightedAC(tokens, makeAC(
+							token,
+							[]string{
+								key,
+								fmt.Sprintf("%s%s", key, operator),
+								value,
+								strings.ToLower(value),
+							},
+						))
+					}
 				case moreThanOperator, lessThanOperator:
 					if _, err := strconv.Atoi(value); err == nil {
 						token := fmt.Sprintf("%s%s%s", key, operator, value)
-						tokens[token] = makeAC(
+						addWeightedAC(tokens, makeAC(
 							token,
 							[]string{
 								key,
 								fmt.Sprintf("%s%s", key, operator),
 								value,
 							},
-						)
+						))
 					}
 				}
 			}
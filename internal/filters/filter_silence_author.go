@@ -51,14 +51,8 @@ func sinceAuthorAutocomplete(name string, operators []string, alerts []models.Al
 				for _, am := range alert.Alertmanager {
 					silence, found := am.Silences[silenceID]
 					if found {
-						for _, operator := range operators {
-							token := fmt.Sprintf("%s%s%s", name, operator, silence.CreatedBy)
-							tokens[token] = makeAC(token, []string{
-								name,
-								strings.TrimPrefix(name, "@"),
-								fmt.Sprintf("%s%s", name, operator),
-								silence.CreatedBy,
-							})
+						for _, hint := range silenceAuthorHints(name, operators, silence.CreatedBy) {
+							tokens[hint.Value] = hint
 						}
 					}
 				}
@@ -71,3 +65,29 @@ func sinceAuthorAutocomplete(name string, operators []string, alerts []models.Al
 	}
 	return acData
 }
+
+func silenceAuthorHints(name string, operators []string, author string) []models.Autocomplete {
+	hints := []models.Autocomplete{}
+	for _, operator := range operators {
+		token := fmt.Sprintf("%s%s%s", name, operator, author)
+		hints = append(hints, makeAC(token, []string{
+			name,
+			strings.TrimPrefix(name, "@"),
+			fmt.Sprintf("%s%s", name, operator),
+			author,
+		}))
+	}
+	return hints
+}
+
+// SilenceAuthorAutocomplete returns autocomplete hints for the @silence_author
+// filter and given author, it's used to provide hints for all authors seen on
+// any upstream, even if none of their silences currently match any alert
+func SilenceAuthorAutocomplete(author string) []models.Autocomplete {
+	for _, fc := range AllFilters {
+		if fc.Label == "@silence_author" {
+			return silenceAuthorHints(fc.Label, fc.SupportedOperators, author)
+		}
+	}
+	return []models.Autocomplete{}
+}
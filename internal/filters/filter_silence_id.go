@@ -0,0 +1,41 @@
+package filters
+
+import (
+	"fmt"
+
+	"github.com/cloudflare/unsee/internal/models"
+)
+
+type silenceIDFilter struct {
+	alertFilter
+}
+
+func (filter *silenceIDFilter) Match(alert *models.Alert, matches int) bool {
+	if filter.IsValid {
+		var isMatch bool
+		if alert.IsSilenced() {
+			// check silences from every Alertmanager instance, deduplicated alert
+			// will only have SilencedBy from the first instance it was found at
+			for _, am := range alert.Alertmanager {
+				for silenceID := range am.Silences {
+					if filter.Matcher.Compare(silenceID, filter.Value) {
+						isMatch = true
+					}
+				}
+			}
+		} else {
+			isMatch = filter.Matcher.Compare("", filter.Value)
+		}
+		if isMatch {
+			filter.Hits++
+		}
+		return isMatch
+	}
+	e := fmt.Sprintf("Match() called on invalid filter %#v", filter)
+	panic(e)
+}
+
+func newSilenceIDFilter() FilterT {
+	f := silenceIDFilter{}
+	return &f
+}
@@ -212,6 +212,71 @@ var tests = []filterTest{
 		IsMatch:    false,
 	},
 
+	filterTest{
+		Expression: "@silenced_by_author=john",
+		IsValid:    true,
+		Alert:      models.Alert{State: "suppressed", SilencedBy: []string{"1"}},
+		Silence:    models.Silence{ID: "1", CreatedBy: "john"},
+		IsMatch:    true,
+	},
+	filterTest{
+		Expression: "@silenced_by_author=~^jo",
+		IsValid:    true,
+		Alert:      models.Alert{State: "suppressed", SilencedBy: []string{"1"}},
+		Silence:    models.Silence{ID: "1", CreatedBy: "bob"},
+		IsMatch:    false,
+	},
+	filterTest{
+		Expression: "@silenced_by_author!~john",
+		IsValid:    true,
+		Alert:      models.Alert{State: "suppressed", SilencedBy: []string{"1"}},
+		Silence:    models.Silence{ID: "1", CreatedBy: "bob"},
+		IsMatch:    true,
+	},
+	filterTest{
+		Expression: "@silenced_by=john",
+		IsValid:    false,
+	},
+
+	filterTest{
+		Expression: "@silence_id=1",
+		IsValid:    true,
+		Alert:      models.Alert{State: "suppressed", SilencedBy: []string{"1"}},
+		Silence:    models.Silence{ID: "1"},
+		IsMatch:    true,
+	},
+	filterTest{
+		Expression: "@silence_id=2",
+		IsValid:    true,
+		Alert:      models.Alert{State: "suppressed", SilencedBy: []string{"1"}},
+		Silence:    models.Silence{ID: "1"},
+		IsMatch:    false,
+	},
+	filterTest{
+		Expression: "@silence_id!=2",
+		IsValid:    true,
+		Alert:      models.Alert{State: "suppressed", SilencedBy: []string{"1"}},
+		Silence:    models.Silence{ID: "1"},
+		IsMatch:    true,
+	},
+	filterTest{
+		Expression: "@silence_id!=1",
+		IsValid:    true,
+		Alert:      models.Alert{State: "suppressed", SilencedBy: []string{"1"}},
+		Silence:    models.Silence{ID: "1"},
+		IsMatch:    false,
+	},
+	filterTest{
+		Expression: "@silence_id!=1",
+		IsValid:    true,
+		Alert:      models.Alert{State: "active"},
+		IsMatch:    true,
+	},
+	filterTest{
+		Expression: "@silence_id=~1",
+		IsValid:    false,
+	},
+
 	filterTest{
 		Expression: "@age<1h",
 		IsValid:    true,
@@ -236,6 +301,38 @@ var tests = []filterTest{
 		Alert:      models.Alert{StartsAt: time.Now().Add(time.Hour * -2)},
 		IsMatch:    true,
 	},
+	filterTest{
+		Expression: "@age>1d",
+		IsValid:    true,
+		Alert:      models.Alert{StartsAt: time.Now().Add(time.Hour * -49)},
+		IsMatch:    true,
+	},
+	filterTest{
+		Expression: "@age<2d",
+		IsValid:    true,
+		Alert:      models.Alert{StartsAt: time.Now().Add(time.Hour * -49)},
+		IsMatch:    false,
+	},
+	filterTest{
+		Expression: "@age>1d12h",
+		IsValid:    true,
+		Alert:      models.Alert{StartsAt: time.Now().Add(time.Hour * -35)},
+		IsMatch:    false,
+	},
+	filterTest{
+		Expression: "@age<1d12h",
+		IsValid:    true,
+		Alert:      models.Alert{StartsAt: time.Now().Add(time.Hour * -35)},
+		IsMatch:    true,
+	},
+	filterTest{
+		Expression: "@age>xd",
+		IsValid:    false,
+	},
+	filterTest{
+		Expression: "@age>1d1x",
+		IsValid:    false,
+	},
 	filterTest{
 		Expression: "@age=1h",
 		IsValid:    false,
@@ -334,6 +431,85 @@ var tests = []filterTest{
 		IsMatch:    false,
 	},
 
+	filterTest{
+		Expression: "@annotation:summary=~timeout",
+		IsValid:    true,
+		Alert: models.Alert{
+			Annotations: models.Annotations{
+				models.Annotation{Name: "summary", Value: "Connection timeout to db1"},
+			},
+		},
+		IsMatch: true,
+	},
+	filterTest{
+		Expression: "@annotation:summary=~timeout",
+		IsValid:    true,
+		Alert: models.Alert{
+			Annotations: models.Annotations{
+				models.Annotation{Name: "description", Value: "Connection timeout to db1"},
+			},
+		},
+		IsMatch: false,
+	},
+	filterTest{
+		Expression: "@annotation:runbook=disk_full",
+		IsValid:    true,
+		Alert: models.Alert{
+			Annotations: models.Annotations{
+				models.Annotation{Name: "runbook", Value: "disk_full"},
+			},
+		},
+		IsMatch: true,
+	},
+	filterTest{
+		Expression: "@annotation:runbook!=disk_full",
+		IsValid:    true,
+		Alert:      models.Alert{},
+		IsMatch:    true,
+	},
+	filterTest{
+		Expression: "@annotation:runbook!~disk",
+		IsValid:    true,
+		Alert: models.Alert{
+			Annotations: models.Annotations{
+				models.Annotation{Name: "runbook", Value: "disk_full"},
+			},
+		},
+		IsMatch: false,
+	},
+	filterTest{
+		Expression: "@annotation:summary~Timeout",
+		IsValid:    true,
+		Alert: models.Alert{
+			Annotations: models.Annotations{
+				models.Annotation{Name: "summary", Value: "Connection timeout to db1"},
+			},
+		},
+		IsMatch: true,
+	},
+	filterTest{
+		Expression: "@annotation:summary~refused",
+		IsValid:    true,
+		Alert: models.Alert{
+			Annotations: models.Annotations{
+				models.Annotation{Name: "summary", Value: "Connection timeout to db1"},
+			},
+		},
+		IsMatch: false,
+	},
+	filterTest{
+		Expression: "node~vps",
+		IsValid:    false,
+	},
+	filterTest{
+		Expression: "@annotation=foo",
+		IsValid:    false,
+	},
+	filterTest{
+		Expression: "@annotation:summary>foo",
+		IsValid:    false,
+	},
+
 	filterTest{
 		Expression: "abc",
 		IsValid:    true,
@@ -416,10 +592,127 @@ var tests = []filterTest{
 		Alert:      models.Alert{},
 		IsMatch:    false,
 	},
+	filterTest{
+		Expression: "severity=*critical",
+		IsValid:    true,
+		Alert:      models.Alert{Labels: map[string]string{"severity": "CRITICAL"}},
+		IsMatch:    true,
+	},
+	filterTest{
+		Expression: "severity=*critical",
+		IsValid:    true,
+		Alert:      models.Alert{Labels: map[string]string{"severity": "Critical"}},
+		IsMatch:    true,
+	},
+	filterTest{
+		Expression: "severity=*critical",
+		IsValid:    true,
+		Alert:      models.Alert{Labels: map[string]string{"severity": "warning"}},
+		IsMatch:    false,
+	},
+	filterTest{
+		Expression: "@flapping=true",
+		IsValid:    true,
+		Alert:      models.Alert{Flapping: true},
+		IsMatch:    true,
+	},
+	filterTest{
+		Expression: "@flapping=true",
+		IsValid:    true,
+		Alert:      models.Alert{},
+		IsMatch:    false,
+	},
+	filterTest{
+		Expression: "@flapping!=true",
+		IsValid:    true,
+		Alert:      models.Alert{},
+		IsMatch:    true,
+	},
+	filterTest{
+		Expression: "@flapping=false",
+		IsValid:    true,
+		Alert:      models.Alert{Flapping: true},
+		IsMatch:    false,
+	},
+	filterTest{
+		Expression: "@flapping=yes",
+		IsValid:    false,
+	},
+	filterTest{
+		Expression: "@flapping=~true",
+		IsValid:    false,
+	},
+	filterTest{
+		Expression: "@receiver=*By-Name",
+		IsValid:    true,
+		Alert:      models.Alert{Receiver: "by-name"},
+		IsMatch:    true,
+	},
+	filterTest{
+		Expression: "@state=*active",
+		IsValid:    false,
+	},
+	filterTest{
+		Expression: "@fingerprint=f87343c11c74a3f4",
+		IsValid:    true,
+		Alert:      models.Alert{Fingerprint: "f87343c11c74a3f4"},
+		IsMatch:    true,
+	},
+	filterTest{
+		Expression: "@fingerprint=f87343c11c74a3f4",
+		IsValid:    true,
+		Alert:      models.Alert{Fingerprint: "54c2f185e49cfccb"},
+		IsMatch:    false,
+	},
+	filterTest{
+		Expression: "@fingerprint!=f87343c11c74a3f4",
+		IsValid:    true,
+		Alert:      models.Alert{Fingerprint: "54c2f185e49cfccb"},
+		IsMatch:    true,
+	},
+	filterTest{
+		Expression: "@fingerprint=~f87343c11c74a3f4",
+		IsValid:    false,
+	},
+	filterTest{
+		Expression: "redis timeout",
+		IsValid:    true,
+		Alert:      models.Alert{Labels: map[string]string{"job": "redis", "error": "connection TIMEOUT"}},
+		IsMatch:    true,
+	},
+	filterTest{
+		Expression: "redis timeout",
+		IsValid:    true,
+		Alert: models.Alert{
+			Labels: map[string]string{"job": "redis-exporter"},
+			Annotations: models.Annotations{
+				models.Annotation{Name: "summary", Value: "Connection timeout"},
+			},
+		},
+		IsMatch: true,
+	},
+	filterTest{
+		Expression: "redis timeout",
+		IsValid:    true,
+		Alert:      models.Alert{Labels: map[string]string{"job": "redis"}},
+		IsMatch:    false,
+	},
+	filterTest{
+		Expression: "redis [****",
+		IsValid:    false,
+	},
 	filterTest{
 		Expression: "^abb[****].*****",
 		IsValid:    false,
 	},
+	filterTest{
+		Expression: "@state",
+		IsValid:    false,
+	},
+	filterTest{
+		Expression: "@state active",
+		IsValid:    false,
+	},
 	filterTest{
 		Expression: "@silenced=true",
 		IsValid:    false,
@@ -613,3 +906,129 @@ func TestLimitFilter(t *testing.T) {
 		}
 	}
 }
+
+type countFilterTest struct {
+	Expression string
+	IsValid    bool
+	GroupSize  int
+	IsMatch    bool
+}
+
+var countTests = []countFilterTest{
+	countFilterTest{
+		Expression: "@count>2",
+		IsValid:    true,
+		GroupSize:  3,
+		IsMatch:    true,
+	},
+	countFilterTest{
+		Expression: "@count>3",
+		IsValid:    true,
+		GroupSize:  3,
+		IsMatch:    false,
+	},
+	countFilterTest{
+		Expression: "@count<3",
+		IsValid:    true,
+		GroupSize:  1,
+		IsMatch:    true,
+	},
+	countFilterTest{
+		Expression: "@count<3",
+		IsValid:    true,
+		GroupSize:  10,
+		IsMatch:    false,
+	},
+	countFilterTest{
+		Expression: "@count=3",
+		IsValid:    false,
+	},
+	countFilterTest{
+		Expression: "@count>abc",
+		IsValid:    false,
+	},
+	countFilterTest{
+		Expression: "@count>-1",
+		IsValid:    false,
+	},
+}
+
+func TestCountFilter(t *testing.T) {
+	for _, ft := range countTests {
+		f := filters.NewFilter(ft.Expression)
+		if f.GetIsValid() != ft.IsValid {
+			t.Errorf("[%s] GetIsValid() returned %#v while %#v was expected", ft.Expression, f.GetIsValid(), ft.IsValid)
+		}
+		if f.GetIsValid() {
+			gf, ok := f.(filters.GroupFilterT)
+			if !ok {
+				t.Errorf("[%s] Filter doesn't implement GroupFilterT", ft.Expression)
+				continue
+			}
+			ag := models.AlertGroup{}
+			for i := 0; i < ft.GroupSize; i++ {
+				ag.Alerts = append(ag.Alerts, models.Alert{})
+			}
+			gf.SetGroup(&ag)
+			m := f.Match(&ag.Alerts[0], 0)
+			if m != ft.IsMatch {
+				t.Errorf("[%s] Match() returned %#v while %#v was expected for group with %d alert(s)", ft.Expression, m, ft.IsMatch, ft.GroupSize)
+			}
+		}
+	}
+}
+
+type groupLimitTest struct {
+	Expression string
+	IsValid    bool
+	Groups     int
+	Expected   int
+	Hits       int
+}
+
+var groupLimitTests = []groupLimitTest{
+	groupLimitTest{Expression: "@group_limit=3", IsValid: true, Groups: 10, Expected: 3, Hits: 7},
+	groupLimitTest{Expression: "@group_limit=10", IsValid: true, Groups: 10, Expected: 10, Hits: 0},
+	groupLimitTest{Expression: "@group_limit=50", IsValid: true, Groups: 1, Expected: 1, Hits: 0},
+	groupLimitTest{Expression: "@group_limit=0", IsValid: false},
+	groupLimitTest{Expression: "@group_limit=-1", IsValid: false},
+	groupLimitTest{Expression: "@group_limit=abc", IsValid: false},
+	groupLimitTest{Expression: "@group_limit>5", IsValid: false},
+}
+
+func TestGroupLimitFilter(t *testing.T) {
+	for _, ft := range groupLimitTests {
+		f := filters.NewFilter(ft.Expression)
+		if f.GetIsValid() != ft.IsValid {
+			t.Errorf("[%s] GetIsValid() returned %#v while %#v was expected", ft.Expression, f.GetIsValid(), ft.IsValid)
+		}
+		if f.GetIsValid() {
+			lf, ok := f.(filters.GroupLimitFilterT)
+			if !ok {
+				t.Errorf("[%s] Filter doesn't implement GroupLimitFilterT", ft.Expression)
+				continue
+			}
+			groups := make([]models.AlertGroup, ft.Groups)
+			limited := lf.LimitGroups(groups)
+			if len(limited) != ft.Expected {
+				t.Errorf("[%s] LimitGroups() returned %d groups while %d was expected", ft.Expression, len(limited), ft.Expected)
+			}
+			if f.GetHits() != ft.Hits {
+				t.Errorf("[%s] GetHits() returned %d while %d was expected", ft.Expression, f.GetHits(), ft.Hits)
+			}
+		}
+	}
+}
+
+func TestAlertmanagerInstanceAutocomplete(t *testing.T) {
+	hints := filters.AlertmanagerInstanceAutocomplete("prod")
+	expected := []string{"@alertmanager=prod", "@alertmanager!=prod"}
+	if len(hints) != len(expected) {
+		t.Fatalf("Got %d hints, expected %d: %v", len(hints), len(expected), hints)
+	}
+	for i, hint := range hints {
+		if hint.Value != expected[i] {
+			t.Errorf("Got hint '%s', expected '%s'", hint.Value, expected[i])
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"regexp"
 	"strconv"
+	"strings"
 	"time"
 
 	cache "github.com/patrickmn/go-cache"
@@ -35,6 +36,32 @@ func (matcher *equalMatcher) Compare(valA, valB interface{}) bool {
 	return valA == valB
 }
 
+type caseInsensitiveEqualMatcher struct {
+	abstractMatcher
+}
+
+func (matcher *caseInsensitiveEqualMatcher) Compare(valA, valB interface{}) bool {
+	strA, okA := valA.(string)
+	strB, okB := valB.(string)
+	if okA && okB {
+		return strings.EqualFold(strA, strB)
+	}
+	return valA == valB
+}
+
+type containsMatcher struct {
+	abstractMatcher
+}
+
+func (matcher *containsMatcher) Compare(valA, valB interface{}) bool {
+	strA, okA := valA.(string)
+	strB, okB := valB.(string)
+	if okA && okB {
+		return strings.Contains(strings.ToLower(strA), strings.ToLower(strB))
+	}
+	return false
+}
+
 type notEqualMatcher struct {
 	abstractMatcher
 }
@@ -32,6 +32,42 @@ func TestEqualMatcher(t *testing.T) {
 	}
 }
 
+func TestCaseInsensitiveEqualMatcher(t *testing.T) {
+	tests := []matchTest{
+		matchTest{"abc", "abc", true, true},
+		matchTest{"Critical", "critical", true, true},
+		matchTest{"CRITICAL", "critical", true, true},
+		matchTest{"critical", "CriTicaL", true, true},
+		matchTest{"critical", "critica", true, false},
+		matchTest{"", "critical", true, false},
+		matchTest{1, 1, true, true},
+	}
+	for _, mt := range tests {
+		m := caseInsensitiveEqualMatcher{}
+		if result := m.Compare(mt.ValA, mt.ValB); result != mt.Expacted {
+			t.Errorf("CaseInsensitiveEqualMatcher(%#v, %#v) returned %v when %v was expected", mt.ValA, mt.ValB, result, mt.Expacted)
+		}
+	}
+}
+
+func TestContainsMatcher(t *testing.T) {
+	tests := []matchTest{
+		matchTest{"Connection timeout to db1", "timeout", true, true},
+		matchTest{"Connection TIMEOUT to db1", "timeout", true, true},
+		matchTest{"timeout", "Timeout", true, true},
+		matchTest{"abc", "", true, true},
+		matchTest{"Connection refused", "timeout", true, false},
+		matchTest{"", "timeout", true, false},
+		matchTest{1, 1, true, false},
+	}
+	for _, mt := range tests {
+		m := containsMatcher{}
+		if result := m.Compare(mt.ValA, mt.ValB); result != mt.Expacted {
+			t.Errorf("ContainsMatcher(%#v, %#v) returned %v when %v was expected", mt.ValA, mt.ValB, result, mt.Expacted)
+		}
+	}
+}
+
 func TestNotEqualMatcher(t *testing.T) {
 	now := time.Now()
 	tests := []matchTest{
@@ -141,6 +177,7 @@ func TestNewMatcher(t *testing.T) {
 		moreThanOperator,
 		lessThanOperator,
 		regexpOperator,
+		caseInsensitiveEqualOperator,
 	}
 	for _, operator := range operators {
 		m, err := newMatcher(operator)
@@ -0,0 +1,180 @@
+package filters
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cloudflare/unsee/internal/models"
+	"github.com/cloudflare/unsee/internal/slices"
+)
+
+// TokenValue is returned as the expected token if the filter value is
+// missing, other errors list filter names or operators that were expected
+const TokenValue = "value"
+
+// operatorChars are all characters that can be used in operators, any of
+// them is considered part of the operator, so a===b is an invalid operator
+// rather than an "a" filter with "==b" value
+const operatorChars = "=!<>~*"
+
+// expression is a filter expression split into tokens, with positions of
+// every token in the expression
+type expression struct {
+	Name     string
+	Operator string
+	Value    string
+	// Fuzzy is true if the expression doesn't start with "name<operator>",
+	// in which case it's matched against all labels and annotations
+	Fuzzy bool
+}
+
+func (e expression) operatorPosition() int {
+	return len(e.Name)
+}
+
+func (e expression) valuePosition() int {
+	return len(e.Name) + len(e.Operator)
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isIdentChar(c byte) bool {
+	return isIdentStart(c) || (c >= '0' && c <= '9')
+}
+
+// scanIdent returns the index right after the identifier starting at i, or i
+// if there's no identifier there
+func scanIdent(s string, i int) int {
+	if i >= len(s) || !isIdentStart(s[i]) {
+		return i
+	}
+	i++
+	for i < len(s) && isIdentChar(s[i]) {
+		i++
+	}
+	return i
+}
+
+// scanName returns the index right after the filter name at the start of s,
+// names are label names or special filters starting with @, special filters
+// can also take an argument after a colon, like @annotation:summary
+func scanName(s string) int {
+	start := 0
+	if strings.HasPrefix(s, "@") {
+		start = 1
+	}
+	end := scanIdent(s, start)
+	if end == start {
+		return 0
+	}
+	if start == 1 && end < len(s) && s[end] == ':' {
+		if argEnd := scanIdent(s, end+1); argEnd > end+1 {
+			return argEnd
+		}
+	}
+	return end
+}
+
+// scanOperator returns the index right after the run of operator characters
+// starting at i
+func scanOperator(s string, i int) int {
+	for i < len(s) && strings.IndexByte(operatorChars, s[i]) >= 0 {
+		i++
+	}
+	return i
+}
+
+// lex splits the expression into name, operator and value tokens, it never
+// fails, expressions without a name followed by an operator are fuzzy
+func lex(s string) expression {
+	nameEnd := scanName(s)
+	if nameEnd == 0 {
+		return expression{Value: s, Fuzzy: true}
+	}
+	operatorEnd := scanOperator(s, nameEnd)
+	if operatorEnd == nameEnd {
+		return expression{Value: s, Fuzzy: true}
+	}
+	return expression{
+		Name:     s[:nameEnd],
+		Operator: s[nameEnd:operatorEnd],
+		Value:    s[operatorEnd:],
+	}
+}
+
+// lookupFilter returns the config of the filter with given name, or nil if
+// there's no such filter
+func lookupFilter(name string) *filterConfig {
+	for i := range AllFilters {
+		if AllFilters[i].LabelRe.MatchString(name) {
+			return &AllFilters[i]
+		}
+	}
+	return nil
+}
+
+// parse splits the expression into tokens and checks that they form a valid
+// filter, it returns the parsed expression and the config of the filter it
+// uses, fuzzy expressions don't have any config, errors point to the first
+// invalid token and list tokens that were expected there
+func parse(s string) (expression, *filterConfig, *models.FilterValidationError) {
+	e := lex(s)
+	if e.Fuzzy && strings.HasPrefix(s, "@") {
+		// only special filters start with @, so this is a filter with a
+		// missing or mistyped operator rather than a fuzzy search
+		name := s[:scanName(s)]
+		fc := lookupFilter(name)
+		if name == "" || fc == nil {
+			if name == "" {
+				name = "@"
+			}
+			return e, nil, &models.FilterValidationError{
+				Reason:   ReasonUnknownFilter,
+				Message:  fmt.Sprintf("unknown filter '%s'", name),
+				Position: 0,
+				Expected: Keywords(),
+			}
+		}
+		return e, fc, &models.FilterValidationError{
+			Reason:   ReasonMissingOperator,
+			Message:  fmt.Sprintf("missing operator after '%s' filter, supported operators: %s", name, strings.Join(fc.SupportedOperators, " ")),
+			Position: len(name),
+			Expected: fc.SupportedOperators,
+		}
+	}
+	if e.Fuzzy {
+		return e, nil, nil
+	}
+
+	fc := lookupFilter(e.Name)
+	if fc == nil {
+		return e, nil, &models.FilterValidationError{
+			Reason:   ReasonUnknownFilter,
+			Message:  fmt.Sprintf("unknown filter '%s'", e.Name),
+			Position: 0,
+			Expected: Keywords(),
+		}
+	}
+
+	if !slices.StringInSlice(fc.SupportedOperators, e.Operator) {
+		return e, fc, &models.FilterValidationError{
+			Reason:   ReasonInvalidOperator,
+			Message:  fmt.Sprintf("operator '%s' is not supported by '%s' filter, supported operators: %s", e.Operator, e.Name, strings.Join(fc.SupportedOperators, " ")),
+			Position: e.operatorPosition(),
+			Expected: fc.SupportedOperators,
+		}
+	}
+
+	if e.Value == "" {
+		return e, fc, &models.FilterValidationError{
+			Reason:   ReasonMissingValue,
+			Message:  "missing filter value",
+			Position: e.valuePosition(),
+			Expected: []string{TokenValue},
+		}
+	}
+
+	return e, fc, nil
+}
@@ -9,24 +9,22 @@ const (
 	lessThanOperator      string = "<"
 	regexpOperator        string = "=~"
 	negativeRegexOperator string = "!~"
+	// case insensitive version of equalOperator, regex operators are always
+	// case insensitive
+	caseInsensitiveEqualOperator string = "=*"
+	// case insensitive substring match
+	containsOperator string = "~"
 )
 
-// this needs to be hand crafted because any of the supported operator chars
-// should be considered part of the operator expression
-// this is needed to catch errors in operators, for example:
-// a===b should yield an error
-var matcherRegex = "[=!<>~]+"
-
-// same as matcherRegex but for the filter name part
-var filterRegex = "^(@)?[a-zA-Z_][a-zA-Z0-9_]*"
-
 var matcherConfig = map[string]matcherT{
-	equalOperator:         &equalMatcher{abstractMatcher{Operator: equalOperator}},
-	notEqualOperator:      &notEqualMatcher{abstractMatcher{Operator: notEqualOperator}},
-	moreThanOperator:      &moreThanMatcher{abstractMatcher{Operator: moreThanOperator}},
-	lessThanOperator:      &lessThanMatcher{abstractMatcher{Operator: lessThanOperator}},
-	regexpOperator:        &regexpMatcher{abstractMatcher{Operator: regexpOperator}},
-	negativeRegexOperator: &negativeRegexMatcher{abstractMatcher{Operator: negativeRegexOperator}},
+	equalOperator:                &equalMatcher{abstractMatcher{Operator: equalOperator}},
+	notEqualOperator:             &notEqualMatcher{abstractMatcher{Operator: notEqualOperator}},
+	moreThanOperator:             &moreThanMatcher{abstractMatcher{Operator: moreThanOperator}},
+	lessThanOperator:             &lessThanMatcher{abstractMatcher{Operator: lessThanOperator}},
+	regexpOperator:               &regexpMatcher{abstractMatcher{Operator: regexpOperator}},
+	negativeRegexOperator:        &negativeRegexMatcher{abstractMatcher{Operator: negativeRegexOperator}},
+	caseInsensitiveEqualOperator: &caseInsensitiveEqualMatcher{abstractMatcher{Operator: caseInsensitiveEqualOperator}},
+	containsOperator:             &containsMatcher{abstractMatcher{Operator: containsOperator}},
 }
 
 type filterConfig struct {
@@ -43,7 +41,7 @@ var AllFilters = []filterConfig{
 	filterConfig{
 		Label:              "@alertmanager",
 		LabelRe:            regexp.MustCompile("^@alertmanager$"),
-		SupportedOperators: []string{regexpOperator, negativeRegexOperator, equalOperator, notEqualOperator},
+		SupportedOperators: []string{regexpOperator, negativeRegexOperator, equalOperator, notEqualOperator, caseInsensitiveEqualOperator},
 		Factory:            newAlertmanagerInstanceFilter,
 		Autocomplete:       alertmanagerInstanceAutocomplete,
 	},
@@ -54,10 +52,17 @@ var AllFilters = []filterConfig{
 		Factory:            newStateFilter,
 		Autocomplete:       stateAutocomplete,
 	},
+	filterConfig{
+		Label:              "@flapping",
+		LabelRe:            regexp.MustCompile("^@flapping$"),
+		SupportedOperators: []string{equalOperator, notEqualOperator},
+		Factory:            newFlappingFilter,
+		Autocomplete:       flappingAutocomplete,
+	},
 	filterConfig{
 		Label:              "@receiver",
 		LabelRe:            regexp.MustCompile("^@receiver$"),
-		SupportedOperators: []string{regexpOperator, negativeRegexOperator, equalOperator, notEqualOperator},
+		SupportedOperators: []string{regexpOperator, negativeRegexOperator, equalOperator, notEqualOperator, caseInsensitiveEqualOperator},
 		Factory:            newreceiverFilter,
 		Autocomplete:       receiverAutocomplete,
 	},
@@ -71,17 +76,42 @@ var AllFilters = []filterConfig{
 	filterConfig{
 		Label:              "@silence_jira",
 		LabelRe:            regexp.MustCompile("^@silence_jira$"),
-		SupportedOperators: []string{regexpOperator, negativeRegexOperator, equalOperator, notEqualOperator},
+		SupportedOperators: []string{regexpOperator, negativeRegexOperator, equalOperator, notEqualOperator, caseInsensitiveEqualOperator},
 		Factory:            newSilenceJiraFilter,
 		Autocomplete:       sinceJiraIDAutocomplete,
 	},
 	filterConfig{
 		Label:              "@silence_author",
-		LabelRe:            regexp.MustCompile("^@silence_author$"),
-		SupportedOperators: []string{regexpOperator, negativeRegexOperator, equalOperator, notEqualOperator},
+		LabelRe:            regexp.MustCompile("^@silence(d_by)?_author$"),
+		SupportedOperators: []string{regexpOperator, negativeRegexOperator, equalOperator, notEqualOperator, caseInsensitiveEqualOperator},
 		Factory:            newSilenceAuthorFilter,
 		Autocomplete:       sinceAuthorAutocomplete,
 	},
+	filterConfig{
+		Label:              "@silence_id",
+		LabelRe:            regexp.MustCompile("^@silence_id$"),
+		SupportedOperators: []string{equalOperator, notEqualOperator},
+		Factory:            newSilenceIDFilter,
+	},
+	filterConfig{
+		Label:              "@fingerprint",
+		LabelRe:            regexp.MustCompile("^@fingerprint$"),
+		SupportedOperators: []string{equalOperator, notEqualOperator},
+		Factory:            newFingerprintFilter,
+	},
+	filterConfig{
+		Label:              "@annotation",
+		LabelRe:            regexp.MustCompile("^@annotation:[a-zA-Z_][a-zA-Z0-9_]*$"),
+		SupportedOperators: []string{regexpOperator, negativeRegexOperator, equalOperator, notEqualOperator, caseInsensitiveEqualOperator, containsOperator},
+		Factory:            newAnnotationFilter,
+	},
+	filterConfig{
+		Label:              "@count",
+		LabelRe:            regexp.MustCompile("^@count$"),
+		SupportedOperators: []string{lessThanOperator, moreThanOperator},
+		Factory:            newCountFilter,
+		Autocomplete:       countAutocomplete,
+	},
 	filterConfig{
 		Label:              "@limit",
 		LabelRe:            regexp.MustCompile("^@limit$"),
@@ -89,10 +119,16 @@ var AllFilters = []filterConfig{
 		Factory:            newLimitFilter,
 		Autocomplete:       limitAutocomplete,
 	},
+	filterConfig{
+		Label:              "@group_limit",
+		LabelRe:            regexp.MustCompile("^@group_limit$"),
+		SupportedOperators: []string{equalOperator},
+		Factory:            newGroupLimitFilter,
+	},
 	filterConfig{
 		Label:              "[a-zA-Z_][a-zA-Z0-9_]*",
 		LabelRe:            regexp.MustCompile("^[a-zA-Z_][a-zA-Z0-9_]*$"),
-		SupportedOperators: []string{regexpOperator, negativeRegexOperator, equalOperator, notEqualOperator, lessThanOperator, moreThanOperator},
+		SupportedOperators: []string{regexpOperator, negativeRegexOperator, equalOperator, notEqualOperator, lessThanOperator, moreThanOperator, caseInsensitiveEqualOperator},
 		Factory:            newLabelFilter,
 		Autocomplete:       labelAutocomplete,
 	},
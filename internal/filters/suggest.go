@@ -0,0 +1,39 @@
+package filters
+
+import "strings"
+
+// SplitExpression splits filter expression into the filter name, operator and
+// value, it's used to tell which part of the filter is being typed
+func SplitExpression(expression string) (name, operator, value string) {
+	e := lex(expression)
+	if e.Fuzzy {
+		return "", "", ""
+	}
+	return e.Name, e.Operator, e.Value
+}
+
+// SupportedOperators returns the list of operators supported by the filter
+// with given name, it will be empty if there's no filter for this name
+func SupportedOperators(name string) []string {
+	if fc := lookupFilter(name); fc != nil {
+		return fc.SupportedOperators
+	}
+	return []string{}
+}
+
+// Keywords returns names of all special filters, like @state, filters which
+// require an argument (like @annotation:name) will have a trailing colon
+func Keywords() []string {
+	keywords := []string{}
+	for _, fc := range AllFilters {
+		if !strings.HasPrefix(fc.Label, "@") {
+			continue
+		}
+		if fc.LabelRe.MatchString(fc.Label) {
+			keywords = append(keywords, fc.Label)
+		} else {
+			keywords = append(keywords, fc.Label+":")
+		}
+	}
+	return keywords
+}
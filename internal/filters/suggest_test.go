@@ -0,0 +1,71 @@
+package filters_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/cloudflare/unsee/internal/filters"
+	"github.com/cloudflare/unsee/internal/slices"
+)
+
+type splitExpressionTest struct {
+	expression string
+	name       string
+	operator   string
+	value      string
+}
+
+var splitExpressionTests = []splitExpressionTest{
+	{expression: "", name: "", operator: "", value: ""},
+	{expression: "clu", name: "", operator: "", value: ""},
+	{expression: "cluster!", name: "cluster", operator: "!", value: ""},
+	{expression: "cluster=~", name: "cluster", operator: "=~", value: ""},
+	{expression: "cluster=pr", name: "cluster", operator: "=", value: "pr"},
+	{expression: "@state!=active", name: "@state", operator: "!=", value: "active"},
+	{expression: "@annotation:summary=~foo", name: "@annotation:summary", operator: "=~", value: "foo"},
+}
+
+func TestSplitExpression(t *testing.T) {
+	for _, testCase := range splitExpressionTests {
+		name, operator, value := filters.SplitExpression(testCase.expression)
+		if name != testCase.name || operator != testCase.operator || value != testCase.value {
+			t.Errorf("SplitExpression(%q) returned (%q, %q, %q), expected (%q, %q, %q)",
+				testCase.expression, name, operator, value, testCase.name, testCase.operator, testCase.value)
+		}
+	}
+}
+
+type supportedOperatorsTest struct {
+	name      string
+	operators []string
+}
+
+var supportedOperatorsTests = []supportedOperatorsTest{
+	{name: "@state", operators: []string{"=", "!="}},
+	{name: "@limit", operators: []string{"="}},
+	{name: "cluster", operators: []string{"=~", "!~", "=", "!=", "<", ">", "=*"}},
+	{name: "@foo", operators: []string{}},
+}
+
+func TestSupportedOperators(t *testing.T) {
+	for _, testCase := range supportedOperatorsTests {
+		operators := filters.SupportedOperators(testCase.name)
+		if !reflect.DeepEqual(operators, testCase.operators) {
+			t.Errorf("SupportedOperators(%q) returned %v, expected %v", testCase.name, operators, testCase.operators)
+		}
+	}
+}
+
+func TestKeywords(t *testing.T) {
+	keywords := filters.Keywords()
+	for _, keyword := range []string{"@state", "@receiver", "@annotation:", "@group_limit"} {
+		if !slices.StringInSlice(keywords, keyword) {
+			t.Errorf("Keyword %s missing in %v", keyword, keywords)
+		}
+	}
+	for _, keyword := range keywords {
+		if keyword[0] != '@' {
+			t.Errorf("Invalid keyword %s", keyword)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package filters
+
+import (
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+
+	"github.com/cloudflare/unsee/internal/models"
+)
+
+// reasons returned in validation errors
+const (
+	ReasonMissingValue    = "missing_value"
+	ReasonMissingOperator = "missing_operator"
+	ReasonUnknownFilter   = "unknown_filter"
+	ReasonInvalidOperator = "invalid_operator"
+	ReasonInvalidRegex    = "invalid_regex"
+	ReasonInvalidValue    = "invalid_value"
+)
+
+// regexValidationError returns validation error for a regex that failed to
+// compile, position will point to the invalid part of the regex if it can be
+// found, or to the start of the regex otherwise
+func regexValidationError(value string, offset int, err error) *models.FilterValidationError {
+	position := offset
+	if se, ok := err.(*syntax.Error); ok {
+		if i := strings.Index(value, se.Expr); se.Expr != "" && i >= 0 {
+			position += i
+		}
+	}
+	return &models.FilterValidationError{
+		Reason:   ReasonInvalidRegex,
+		Message:  err.Error(),
+		Position: position,
+	}
+}
+
+// Validate checks given filter expression and returns an error describing
+// why it's invalid, or nil if it's valid. Unlike NewFilter it will also check
+// if the value of regex filters is a valid regex, since invalid regex filters
+// won't fail to parse but they will never match any alert
+func Validate(expression string) *models.FilterValidationError {
+	e, _, err := parse(expression)
+	if err != nil {
+		return err
+	}
+
+	if e.Fuzzy {
+		// fuzzy filter, every word is a regex
+		offset := 0
+		for _, token := range strings.Fields(expression) {
+			i := strings.Index(expression[offset:], token) + offset
+			if _, err := regexp.Compile(token); err != nil {
+				return regexValidationError(token, i, err)
+			}
+			offset = i + len(token)
+		}
+		return nil
+	}
+
+	if e.Operator == regexpOperator || e.Operator == negativeRegexOperator {
+		if _, err := regexp.Compile(e.Value); err != nil {
+			return regexValidationError(e.Value, e.valuePosition(), err)
+		}
+	}
+
+	if !NewFilter(expression).GetIsValid() {
+		return &models.FilterValidationError{
+			Reason:   ReasonInvalidValue,
+			Message:  fmt.Sprintf("invalid value '%s' for '%s' filter", e.Value, e.Name),
+			Position: e.valuePosition(),
+		}
+	}
+
+	return nil
+}
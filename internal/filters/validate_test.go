@@ -0,0 +1,103 @@
+package filters_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/cloudflare/unsee/internal/filters"
+)
+
+type validateTest struct {
+	Expression string
+	Reason     string
+	Position   int
+	Expected   []string
+}
+
+var validateTests = []validateTest{
+	validateTest{Expression: "cluster=prod"},
+	validateTest{Expression: "cluster=~^prod-.*"},
+	validateTest{Expression: "@state=active"},
+	validateTest{Expression: "@age>1h"},
+	validateTest{Expression: "redis timeout"},
+	validateTest{Expression: "@foo=bar", Reason: filters.ReasonUnknownFilter, Position: 0},
+	validateTest{Expression: "cluster==prod", Reason: filters.ReasonInvalidOperator, Position: 7},
+	validateTest{Expression: "@age=1h", Reason: filters.ReasonInvalidOperator, Position: 4, Expected: []string{"<", ">"}},
+	validateTest{Expression: "cluster=", Reason: filters.ReasonMissingValue, Position: 8, Expected: []string{filters.TokenValue}},
+	validateTest{Expression: "@state", Reason: filters.ReasonMissingOperator, Position: 6, Expected: []string{"=", "!="}},
+	validateTest{Expression: "@state active", Reason: filters.ReasonMissingOperator, Position: 6, Expected: []string{"=", "!="}},
+	validateTest{Expression: "@annotation:summary", Reason: filters.ReasonMissingOperator, Position: 19},
+	validateTest{Expression: "@", Reason: filters.ReasonUnknownFilter, Position: 0},
+	validateTest{Expression: "@annotation:=foo", Reason: filters.ReasonUnknownFilter, Position: 0},
+	validateTest{Expression: "@foo", Reason: filters.ReasonUnknownFilter, Position: 0},
+	validateTest{Expression: "@limit=", Reason: filters.ReasonMissingValue, Position: 7, Expected: []string{filters.TokenValue}},
+	validateTest{Expression: "=foo"},
+	validateTest{Expression: "cluster prod=x"},
+	validateTest{Expression: "cluster=~prod-(", Reason: filters.ReasonInvalidRegex, Position: 9},
+	validateTest{Expression: "cluster!~prod-[a", Reason: filters.ReasonInvalidRegex, Position: 14},
+	validateTest{Expression: "redis [a", Reason: filters.ReasonInvalidRegex, Position: 6},
+	validateTest{Expression: "@state=foo", Reason: filters.ReasonInvalidValue, Position: 7},
+	validateTest{Expression: "@age>foo", Reason: filters.ReasonInvalidValue, Position: 5},
+}
+
+func TestValidate(t *testing.T) {
+	for _, vt := range validateTests {
+		err := filters.Validate(vt.Expression)
+		if vt.Reason == "" {
+			if err != nil {
+				t.Errorf("[%s] Expected no error, got %v", vt.Expression, err)
+			}
+			continue
+		}
+		if err == nil {
+			t.Errorf("[%s] Expected '%s' error, got nil", vt.Expression, vt.Reason)
+			continue
+		}
+		if err.Reason != vt.Reason {
+			t.Errorf("[%s] Expected '%s' error, got '%s': %s", vt.Expression, vt.Reason, err.Reason, err.Message)
+		}
+		if err.Position != vt.Position {
+			t.Errorf("[%s] Expected error at position %d, got %d", vt.Expression, vt.Position, err.Position)
+		}
+		if vt.Expected != nil && !reflect.DeepEqual(err.Expected, vt.Expected) {
+			t.Errorf("[%s] Expected tokens %v, got %v", vt.Expression, vt.Expected, err.Expected)
+		}
+	}
+}
+
+func TestValidateUnknownFilterExpected(t *testing.T) {
+	err := filters.Validate("@foo=bar")
+	if err == nil {
+		t.Fatal("Validate() didn't return any error")
+	}
+	if !reflect.DeepEqual(err.Expected, filters.Keywords()) {
+		t.Errorf("Expected tokens %v, got %v", filters.Keywords(), err.Expected)
+	}
+}
+
+func FuzzValidate(f *testing.F) {
+	for _, vt := range validateTests {
+		f.Add(vt.Expression)
+	}
+	f.Add("@annotation:summary=~^foo.*")
+	f.Add("@silenced_by_author!~john")
+	f.Add("a===b")
+	f.Fuzz(func(t *testing.T, expression string) {
+		err := filters.Validate(expression)
+		filter := filters.NewFilter(expression)
+		if err == nil && !filter.GetIsValid() {
+			t.Errorf("[%q] Validate() returned no error but NewFilter() returned an invalid filter", expression)
+		}
+		if err != nil {
+			if err.Position < 0 || err.Position > len(expression) {
+				t.Errorf("[%q] Error position %d is outside of the expression", expression, err.Position)
+			}
+			if err.Reason == "" || err.Message == "" {
+				t.Errorf("[%q] Error without reason or message: %v", expression, err)
+			}
+		}
+		if name, operator, value := filters.SplitExpression(expression); name != "" && name+operator+value != expression {
+			t.Errorf("[%q] SplitExpression() returned (%q, %q, %q)", expression, name, operator, value)
+		}
+	})
+}
@@ -0,0 +1,146 @@
+// Package flapping detects alerts that keep firing and resolving, every time
+// an alert is added or resolved the change is recorded for its fingerprint and
+// alerts with too many changes within the window are reported as flapping
+package flapping
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cloudflare/unsee/internal/events"
+	"github.com/cloudflare/unsee/internal/models"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var tracker = struct {
+	sync.RWMutex
+	window    time.Duration
+	threshold int
+	// timestamps of recent changes keyed by alert fingerprint
+	changes  map[string][]time.Time
+	flapping map[string]bool
+	version  string
+}{changes: map[string][]time.Time{}, flapping: map[string]bool{}}
+
+var flappingAlerts = prometheus.NewGaugeFunc(
+	prometheus.GaugeOpts{
+		Name: "unsee_flapping_alerts",
+		Help: "Number of alerts that were added or resolved too many times within the flapping window",
+	},
+	func() float64 { return float64(Count()) },
+)
+
+func init() {
+	prometheus.MustRegister(flappingAlerts)
+}
+
+// Setup configures flapping detection, alerts added or resolved at least
+// threshold times within the window are flapping, detection is disabled if
+// threshold is 0, recorded changes are cleared
+func Setup(window time.Duration, threshold int) error {
+	if threshold < 0 {
+		return fmt.Errorf("Invalid flapping threshold %d, it can't be negative", threshold)
+	}
+	if threshold > 0 && window <= 0 {
+		return fmt.Errorf("Invalid flapping window '%s', it must be positive", window)
+	}
+	tracker.Lock()
+	defer tracker.Unlock()
+	tracker.window = window
+	tracker.threshold = threshold
+	tracker.changes = map[string][]time.Time{}
+	tracker.flapping = map[string]bool{}
+	tracker.version = ""
+	return nil
+}
+
+// alertKey returns the key changes of the alert are recorded under
+func alertKey(alert *models.Alert) string {
+	if alert.Fingerprint != "" {
+		return alert.Fingerprint
+	}
+	return alert.LabelsFingerprint()
+}
+
+// Update records all added and resolved alerts from the list of changes and
+// refreshes the list of flapping alerts, it returns true if that list changed
+func Update(changes []models.AlertEvent, now time.Time) bool {
+	tracker.Lock()
+	defer tracker.Unlock()
+	if tracker.threshold == 0 {
+		return false
+	}
+
+	for _, change := range changes {
+		if change.Type != events.EventAdded && change.Type != events.EventResolved {
+			continue
+		}
+		key := alertKey(&change.Alert)
+		tracker.changes[key] = append(tracker.changes[key], now)
+	}
+
+	since := now.Add(-tracker.window)
+	flapping := map[string]bool{}
+	for key, timestamps := range tracker.changes {
+		recent := timestamps[:0]
+		for _, ts := range timestamps {
+			if ts.After(since) {
+				recent = append(recent, ts)
+			}
+		}
+		if len(recent) == 0 {
+			delete(tracker.changes, key)
+			continue
+		}
+		tracker.changes[key] = recent
+		if len(recent) >= tracker.threshold {
+			flapping[key] = true
+		}
+	}
+
+	keys := make([]string, 0, len(flapping))
+	for key := range flapping {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	hasher := sha1.New()
+	for _, key := range keys {
+		io.WriteString(hasher, key+" ")
+	}
+	version := fmt.Sprintf("%x", hasher.Sum(nil))
+	if len(keys) == 0 {
+		version = ""
+	}
+
+	changed := version != tracker.version
+	tracker.flapping = flapping
+	tracker.version = version
+	return changed
+}
+
+// IsFlapping returns true if the alert is flapping
+func IsFlapping(alert *models.Alert) bool {
+	tracker.RLock()
+	defer tracker.RUnlock()
+	return tracker.flapping[alertKey(alert)]
+}
+
+// Version returns a checksum of fingerprints of all flapping alerts, it's
+// empty if no alert is flapping
+func Version() string {
+	tracker.RLock()
+	defer tracker.RUnlock()
+	return tracker.version
+}
+
+// Count returns the number of flapping alerts
+func Count() int {
+	tracker.RLock()
+	defer tracker.RUnlock()
+	return len(tracker.flapping)
+}
@@ -0,0 +1,101 @@
+package flapping_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cloudflare/unsee/internal/events"
+	"github.com/cloudflare/unsee/internal/flapping"
+	"github.com/cloudflare/unsee/internal/models"
+)
+
+type setupTest struct {
+	window    time.Duration
+	threshold int
+	valid     bool
+}
+
+var setupTests = []setupTest{
+	setupTest{window: time.Minute * 10, threshold: 0, valid: true},
+	setupTest{window: 0, threshold: 0, valid: true},
+	setupTest{window: time.Minute, threshold: 3, valid: true},
+	setupTest{window: 0, threshold: 3, valid: false},
+	setupTest{window: -time.Minute, threshold: 3, valid: false},
+	setupTest{window: time.Minute, threshold: -1, valid: false},
+}
+
+func TestSetup(t *testing.T) {
+	for _, st := range setupTests {
+		err := flapping.Setup(st.window, st.threshold)
+		if (err == nil) != st.valid {
+			t.Errorf("Setup(%s, %d) returned error=%v, expected valid=%v", st.window, st.threshold, err, st.valid)
+		}
+	}
+}
+
+func change(eventType, fingerprint string) models.AlertEvent {
+	return models.AlertEvent{Type: eventType, Alert: models.Alert{Fingerprint: fingerprint}}
+}
+
+func TestUpdate(t *testing.T) {
+	if err := flapping.Setup(time.Minute*10, 3); err != nil {
+		t.Fatal(err)
+	}
+	defer flapping.Setup(0, 0)
+
+	noisy := models.Alert{Fingerprint: "noisy"}
+	quiet := models.Alert{Fingerprint: "quiet"}
+	now := time.Now()
+
+	flapping.Update([]models.AlertEvent{change(events.EventAdded, "noisy"), change(events.EventAdded, "quiet")}, now)
+	flapping.Update([]models.AlertEvent{change(events.EventResolved, "noisy"), change(events.EventSilenced, "quiet")}, now.Add(time.Minute))
+	if flapping.IsFlapping(&noisy) || flapping.Count() != 0 || flapping.Version() != "" {
+		t.Errorf("No alert should be flapping before reaching the threshold")
+	}
+
+	if changed := flapping.Update([]models.AlertEvent{change(events.EventAdded, "noisy"), change(events.EventChanged, "quiet")}, now.Add(time.Minute*2)); !changed {
+		t.Errorf("Update() returned false after an alert started flapping")
+	}
+	if !flapping.IsFlapping(&noisy) {
+		t.Errorf("Alert %s isn't flapping after 3 changes", noisy.Fingerprint)
+	}
+	if flapping.IsFlapping(&quiet) {
+		t.Errorf("Alert %s is flapping, only added and resolved events should be counted", quiet.Fingerprint)
+	}
+	if flapping.Count() != 1 {
+		t.Errorf("Count() returned %d, expected 1", flapping.Count())
+	}
+	version := flapping.Version()
+	if version == "" {
+		t.Errorf("Version() is empty with a flapping alert")
+	}
+
+	if changed := flapping.Update([]models.AlertEvent{}, now.Add(time.Minute*3)); changed {
+		t.Errorf("Update() returned true without any change to flapping alerts")
+	}
+	if flapping.Version() != version {
+		t.Errorf("Version() changed from '%s' to '%s' without any change to flapping alerts", version, flapping.Version())
+	}
+
+	// first change is outside of the window now
+	if changed := flapping.Update([]models.AlertEvent{}, now.Add(time.Minute*10)); !changed {
+		t.Errorf("Update() returned false after an alert stopped flapping")
+	}
+	if flapping.IsFlapping(&noisy) || flapping.Count() != 0 || flapping.Version() != "" {
+		t.Errorf("Alert %s is still flapping after old changes expired", noisy.Fingerprint)
+	}
+}
+
+func TestUpdateDisabled(t *testing.T) {
+	if err := flapping.Setup(time.Minute, 0); err != nil {
+		t.Fatal(err)
+	}
+	noisy := models.Alert{Fingerprint: "noisy"}
+	now := time.Now()
+	for i := 0; i < 10; i++ {
+		flapping.Update([]models.AlertEvent{change(events.EventAdded, "noisy")}, now)
+	}
+	if flapping.IsFlapping(&noisy) {
+		t.Errorf("Alert %s is flapping with flapping detection disabled", noisy.Fingerprint)
+	}
+}
@@ -0,0 +1,173 @@
+// Package hooks runs external commands for alert events detected after every
+// collection, so site specific automations can be attached to alerts
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudflare/unsee/internal/events"
+	"github.com/cloudflare/unsee/internal/models"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// EventAll can be used in hook rules to run the command for every supported
+// event
+const EventAll = "all"
+
+// maximum number of hook runs waiting for a free worker, if commands can't
+// keep up then new runs will be dropped
+const queueSize = 1000
+
+// events hooks can be attached to
+var supportedEvents = []string{events.EventAdded, events.EventResolved, events.EventSilenced}
+
+type hook struct {
+	event   string
+	command string
+}
+
+type job struct {
+	command string
+	event   models.AlertEvent
+}
+
+type runner struct {
+	hooks   []hook
+	timeout time.Duration
+	queue   chan job
+	wg      sync.WaitGroup
+}
+
+var current *runner
+
+func isSupported(event string) bool {
+	for _, e := range supportedEvents {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Setup parses the list of hook rules and starts workers that will run hook
+// commands, hooks are disabled if there are no rules
+// Each rule is in the event:command format, where event is one of added,
+// resolved, silenced or all and command is the path to an executable, every
+// command will be killed if it's still running after timeout and there will
+// be no more than concurrency commands running at the same time
+func Setup(rules []string, timeout time.Duration, concurrency int) error {
+	Stop()
+
+	hooks := []hook{}
+	for _, s := range rules {
+		if s == "" {
+			continue
+		}
+		ss := strings.SplitN(s, ":", 2)
+		if len(ss) != 2 || ss[1] == "" {
+			return fmt.Errorf("Invalid hook rule '%s', expected format 'event:command'", s)
+		}
+		if ss[0] != EventAll && !isSupported(ss[0]) {
+			return fmt.Errorf("Invalid hook rule '%s', supported events: %s, %s", s, strings.Join(supportedEvents, ", "), EventAll)
+		}
+		hooks = append(hooks, hook{event: ss[0], command: ss[1]})
+	}
+	if len(hooks) == 0 {
+		return nil
+	}
+	if concurrency < 1 {
+		return fmt.Errorf("Invalid hooks concurrency %d, it must be at least 1", concurrency)
+	}
+
+	r := &runner{
+		hooks:   hooks,
+		timeout: timeout,
+		queue:   make(chan job, queueSize),
+	}
+	r.wg.Add(concurrency)
+	for i := 0; i < concurrency; i++ {
+		go r.worker()
+	}
+	current = r
+	return nil
+}
+
+// Stop waits for all queued hook commands to finish and disables hooks
+func Stop() {
+	if current == nil {
+		return
+	}
+	close(current.queue)
+	current.wg.Wait()
+	current = nil
+}
+
+// Run queues hook commands for all events matching configured rules, it
+// never blocks, runs will be dropped if the queue is full
+func Run(changes []models.AlertEvent) {
+	if current == nil {
+		return
+	}
+	for _, change := range changes {
+		for _, h := range current.hooks {
+			if h.event != change.Type && (h.event != EventAll || !isSupported(change.Type)) {
+				continue
+			}
+			select {
+			case current.queue <- job{command: h.command, event: change}:
+			default:
+				log.Warningf("Hook queue is full, dropping '%s' run for %s event", h.command, change.Type)
+			}
+		}
+	}
+}
+
+func (r *runner) worker() {
+	defer r.wg.Done()
+	for j := range r.queue {
+		if err := r.execute(j); err != nil {
+			log.Errorf("Hook '%s' failed for %s event: %s", j.command, j.event.Type, err)
+		}
+	}
+}
+
+// execute runs the command passing the event as JSON on stdin
+func (r *runner) execute(j job) error {
+	payload, err := json.Marshal(j.event)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	if r.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.timeout)
+		defer cancel()
+	}
+
+	cmd := exec.CommandContext(ctx, j.command)
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Env = append(os.Environ(),
+		"UNSEE_EVENT="+j.event.Type,
+		"UNSEE_GROUP_ID="+j.event.GroupID,
+		"UNSEE_ALERTNAME="+j.event.Alert.Labels["alertname"],
+	)
+	output, err := cmd.CombinedOutput()
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("command timed out after %s", r.timeout)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %s", err, strings.TrimSpace(string(output)))
+	}
+	log.Debugf("Hook '%s' completed for %s event", j.command, j.event.Type)
+	return nil
+}
@@ -0,0 +1,111 @@
+package hooks_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cloudflare/unsee/internal/events"
+	"github.com/cloudflare/unsee/internal/hooks"
+	"github.com/cloudflare/unsee/internal/models"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// writeScript creates an executable shell script in dir and returns its path
+func writeScript(t *testing.T, dir, name, body string) string {
+	path := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(path, []byte("#!/bin/sh\n"+body+"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRun(t *testing.T) {
+	dir, err := ioutil.TempDir("", "unsee-hooks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// every run writes stdin to a file named after the event and alert
+	script := writeScript(t, dir, "hook.sh", `cat > "$(dirname "$0")/$UNSEE_EVENT-$UNSEE_ALERTNAME.json"`)
+	if err := hooks.Setup([]string{"added:" + script, "all:" + script}, time.Second*5, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	hooks.Run([]models.AlertEvent{
+		{Type: events.EventAdded, GroupID: "1", Alert: models.Alert{Labels: map[string]string{"alertname": "Foo"}}},
+		{Type: events.EventResolved, GroupID: "2", Alert: models.Alert{Labels: map[string]string{"alertname": "Bar"}}},
+		{Type: events.EventChanged, GroupID: "3", Alert: models.Alert{Labels: map[string]string{"alertname": "Baz"}}},
+	})
+	hooks.Stop()
+
+	for _, name := range []string{"added-Foo.json", "resolved-Bar.json"} {
+		raw, err := ioutil.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Errorf("Hook wasn't run: %s", err)
+			continue
+		}
+		event := models.AlertEvent{}
+		if err := json.Unmarshal(raw, &event); err != nil {
+			t.Errorf("Invalid event JSON passed to hook: %s", err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "changed-Baz.json")); err == nil {
+		t.Error("Hook was run for changed event")
+	}
+
+	// once stopped hooks are no-op
+	hooks.Run([]models.AlertEvent{
+		{Type: events.EventSilenced, Alert: models.Alert{Labels: map[string]string{"alertname": "Foo"}}},
+	})
+	if _, err := os.Stat(filepath.Join(dir, "silenced-Foo.json")); err == nil {
+		t.Error("Hook was run after Stop()")
+	}
+}
+
+func TestRunTimeout(t *testing.T) {
+	log.SetLevel(log.FatalLevel)
+	defer log.SetLevel(log.InfoLevel)
+
+	dir, err := ioutil.TempDir("", "unsee-hooks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	script := writeScript(t, dir, "sleep.sh", "exec sleep 10")
+	if err := hooks.Setup([]string{"added:" + script}, time.Millisecond*100, 1); err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	hooks.Run([]models.AlertEvent{{Type: events.EventAdded}})
+	hooks.Stop()
+	if time.Since(start) > time.Second*5 {
+		t.Errorf("Hook wasn't killed after timeout, took %s", time.Since(start))
+	}
+}
+
+func TestSetup(t *testing.T) {
+	defer hooks.Stop()
+	for _, testCase := range []struct {
+		rules       []string
+		concurrency int
+	}{
+		{rules: []string{"/bin/true"}, concurrency: 1},
+		{rules: []string{"added:"}, concurrency: 1},
+		{rules: []string{"changed:/bin/true"}, concurrency: 1},
+		{rules: []string{"added:/bin/true"}, concurrency: 0},
+	} {
+		if err := hooks.Setup(testCase.rules, time.Second, testCase.concurrency); err == nil {
+			t.Errorf("Setup(%v, %d) didn't return any error", testCase.rules, testCase.concurrency)
+		}
+	}
+	if err := hooks.Setup([]string{}, time.Second, 0); err != nil {
+		t.Errorf("Setup() returned an error with hooks disabled: %s", err)
+	}
+}
@@ -0,0 +1,173 @@
+// Package incidents looks up open PagerDuty incidents or Opsgenie alerts
+// created for alerts collected from Alertmanager, so the UI can link to them
+package incidents
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudflare/unsee/internal/models"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// ProviderPagerDuty looks up incidents using the PagerDuty REST API
+	ProviderPagerDuty = "pagerduty"
+	// ProviderOpsgenie looks up alerts using the Opsgenie Alert API
+	ProviderOpsgenie = "opsgenie"
+)
+
+// number of incidents requested in a single API call
+const pageSize = 100
+
+// provider fetches all open incidents for a single service, keyed by their
+// dedup key
+type provider interface {
+	openIncidents(ctx context.Context, service string) (map[string]models.AlertIncident, error)
+}
+
+type lookup struct {
+	provider provider
+	// receiver -> service
+	services   map[string]string
+	dedupLabel string
+	timeout    time.Duration
+
+	lock sync.RWMutex
+	// service -> dedup key -> incident
+	incidents map[string]map[string]models.AlertIncident
+}
+
+var current *lookup
+
+// Setup parses incident lookup configuration, lookups are disabled if the
+// provider isn't set, services is a list of receiver:service pairs mapping
+// receivers to PagerDuty service IDs or Opsgenie team names
+func Setup(providerName, apiURL, apiKey string, services []string, dedupLabel string, timeout time.Duration) error {
+	if providerName == "" {
+		current = nil
+		return nil
+	}
+
+	l := &lookup{
+		services:   map[string]string{},
+		dedupLabel: dedupLabel,
+		timeout:    timeout,
+		incidents:  map[string]map[string]models.AlertIncident{},
+	}
+	switch providerName {
+	case ProviderPagerDuty:
+		if apiURL == "" {
+			apiURL = "https://api.pagerduty.com"
+		}
+		l.provider = &pagerDuty{apiURL: strings.TrimSuffix(apiURL, "/"), apiKey: apiKey, timeout: timeout}
+	case ProviderOpsgenie:
+		if apiURL == "" {
+			apiURL = "https://api.opsgenie.com"
+		}
+		l.provider = &opsgenie{apiURL: strings.TrimSuffix(apiURL, "/"), apiKey: apiKey, timeout: timeout}
+	default:
+		return fmt.Errorf("Invalid incidents provider '%s', supported providers: %s, %s", providerName, ProviderPagerDuty, ProviderOpsgenie)
+	}
+	if apiKey == "" {
+		return fmt.Errorf("API key is required to look up %s incidents", providerName)
+	}
+	for _, s := range services {
+		if s == "" {
+			continue
+		}
+		z := strings.SplitN(s, ":", 2)
+		if len(z) != 2 || z[0] == "" || z[1] == "" {
+			return fmt.Errorf("Invalid incidents service '%s', expected format 'receiver:service'", s)
+		}
+		l.services[z[0]] = z[1]
+	}
+	if len(l.services) == 0 {
+		return fmt.Errorf("At least one receiver must be mapped to a %s service", providerName)
+	}
+	current = l
+	return nil
+}
+
+// Refresh fetches open incidents for all configured services, incidents of
+// services that couldn't be fetched are kept from the previous refresh, so
+// links don't disappear on transient API errors
+func Refresh(ctx context.Context) {
+	l := current
+	if l == nil {
+		return
+	}
+
+	seen := map[string]bool{}
+	for _, service := range l.services {
+		if seen[service] {
+			continue
+		}
+		seen[service] = true
+
+		incidents, err := l.provider.openIncidents(ctx, service)
+		if err != nil {
+			log.Errorf("Failed to fetch open incidents for service '%s': %s", service, err)
+			continue
+		}
+		log.Infof("Fetched %d open incident(s) for service '%s'", len(incidents), service)
+		l.lock.Lock()
+		l.incidents[service] = incidents
+		l.lock.Unlock()
+	}
+}
+
+// Lookup returns the open incident created for given alert, or nil if there
+// is none
+func Lookup(alert *models.Alert) *models.AlertIncident {
+	l := current
+	if l == nil {
+		return nil
+	}
+	service, found := l.services[alert.Receiver]
+	if !found {
+		return nil
+	}
+	key := alert.Fingerprint
+	if l.dedupLabel != "" {
+		key = alert.Labels[l.dedupLabel]
+	}
+	if key == "" {
+		return nil
+	}
+
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+	if incident, found := l.incidents[service][key]; found {
+		return &incident
+	}
+	return nil
+}
+
+// getJSON sends a GET request to given URL and decodes the JSON response
+func getJSON(ctx context.Context, uri string, timeout time.Duration, headers map[string]string, target interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, uri, nil)
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	client := &http.Client{Timeout: timeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Request to %s returned status %s", req.URL.Path, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(target)
+}
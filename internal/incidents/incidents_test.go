@@ -0,0 +1,144 @@
+package incidents_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cloudflare/unsee/internal/incidents"
+	"github.com/cloudflare/unsee/internal/models"
+
+	"gopkg.in/jarcoal/httpmock.v1"
+)
+
+type lookupTest struct {
+	alert    models.Alert
+	incident *models.AlertIncident
+}
+
+func TestPagerDuty(t *testing.T) {
+	defer incidents.Setup("", "", "", nil, "", 0)
+	if err := incidents.Setup(incidents.ProviderPagerDuty, "", "secret", []string{"by-cluster:PABC123", "by-name:PABC123", "db:PDB"}, "dedup_key", time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	requests := 0
+	httpmock.RegisterResponder("GET", "https://api.pagerduty.com/incidents", func(req *http.Request) (*http.Response, error) {
+		requests++
+		if req.Header.Get("Authorization") != "Token token=secret" {
+			return httpmock.NewStringResponse(401, "{}"), nil
+		}
+		switch req.URL.Query().Get("service_ids[]") + " " + req.URL.Query().Get("offset") {
+		case "PABC123 0":
+			return httpmock.NewStringResponse(200, `{"incidents": [{"id": "Q1", "incident_key": "foo", "status": "triggered", "html_url": "https://example.pagerduty.com/incidents/Q1"}], "more": true}`), nil
+		case "PABC123 100":
+			return httpmock.NewStringResponse(200, `{"incidents": [{"id": "Q2", "incident_key": "bar", "status": "acknowledged", "html_url": "https://example.pagerduty.com/incidents/Q2"}], "more": false}`), nil
+		default:
+			return httpmock.NewStringResponse(500, "{}"), nil
+		}
+	})
+	incidents.Refresh(context.Background())
+	// PABC123 is fetched once using 2 pages, PDB fails
+	if requests != 3 {
+		t.Errorf("Sent %d request(s), expected 3", requests)
+	}
+
+	for _, testCase := range []lookupTest{
+		{
+			alert: models.Alert{Receiver: "by-cluster", Labels: map[string]string{"dedup_key": "foo"}},
+			incident: &models.AlertIncident{
+				Provider: incidents.ProviderPagerDuty,
+				ID:       "Q1",
+				Status:   "triggered",
+				URL:      "https://example.pagerduty.com/incidents/Q1",
+			},
+		},
+		{
+			alert: models.Alert{Receiver: "by-name", Labels: map[string]string{"dedup_key": "bar"}},
+			incident: &models.AlertIncident{
+				Provider: incidents.ProviderPagerDuty,
+				ID:       "Q2",
+				Status:   "acknowledged",
+				URL:      "https://example.pagerduty.com/incidents/Q2",
+			},
+		},
+		{alert: models.Alert{Receiver: "by-cluster", Labels: map[string]string{"dedup_key": "baz"}}},
+		{alert: models.Alert{Receiver: "by-cluster", Labels: map[string]string{}, Fingerprint: "foo"}},
+		{alert: models.Alert{Receiver: "db", Labels: map[string]string{"dedup_key": "foo"}}},
+		{alert: models.Alert{Receiver: "web", Labels: map[string]string{"dedup_key": "foo"}}},
+	} {
+		incident := incidents.Lookup(&testCase.alert)
+		if !reflect.DeepEqual(incident, testCase.incident) {
+			t.Errorf("Lookup(%v) returned %v, expected %v", testCase.alert, incident, testCase.incident)
+		}
+	}
+
+	// incidents are kept if the API fails
+	httpmock.Reset()
+	httpmock.RegisterResponder("GET", "https://api.pagerduty.com/incidents", httpmock.NewStringResponder(500, "{}"))
+	incidents.Refresh(context.Background())
+	if incident := incidents.Lookup(&models.Alert{Receiver: "by-cluster", Labels: map[string]string{"dedup_key": "foo"}}); incident == nil {
+		t.Error("Incident was removed after failed refresh")
+	}
+}
+
+func TestOpsgenie(t *testing.T) {
+	defer incidents.Setup("", "", "", nil, "", 0)
+	if err := incidents.Setup(incidents.ProviderOpsgenie, "https://api.eu.opsgenie.com/", "secret", []string{"by-cluster:SRE"}, "", time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("GET", "https://api.eu.opsgenie.com/v2/alerts", func(req *http.Request) (*http.Response, error) {
+		if req.Header.Get("Authorization") != "GenieKey secret" || req.URL.Query().Get("query") != `status:open AND teams:"SRE"` {
+			return httpmock.NewStringResponse(400, "{}"), nil
+		}
+		return httpmock.NewStringResponse(200, `{"data": [
+			{"id": "a1", "alias": "fp1", "status": "open", "acknowledged": false},
+			{"id": "a2", "alias": "fp2", "status": "open", "acknowledged": true}
+		]}`), nil
+	})
+	incidents.Refresh(context.Background())
+
+	for alias, status := range map[string]string{"fp1": "open", "fp2": "acknowledged"} {
+		incident := incidents.Lookup(&models.Alert{Receiver: "by-cluster", Fingerprint: alias})
+		if incident == nil {
+			t.Errorf("No incident found for %s", alias)
+			continue
+		}
+		if incident.Status != status || incident.Provider != incidents.ProviderOpsgenie {
+			t.Errorf("Invalid incident for %s: %v", alias, incident)
+		}
+		if incident.URL != fmt.Sprintf("https://app.opsgenie.com/alert/detail/%s/details", incident.ID) {
+			t.Errorf("Invalid incident URL for %s: %s", alias, incident.URL)
+		}
+	}
+}
+
+func TestSetup(t *testing.T) {
+	defer incidents.Setup("", "", "", nil, "", 0)
+	for _, testCase := range []struct {
+		provider string
+		apiKey   string
+		services []string
+	}{
+		{provider: "foo", apiKey: "secret", services: []string{"foo:bar"}},
+		{provider: incidents.ProviderPagerDuty, services: []string{"foo:bar"}},
+		{provider: incidents.ProviderPagerDuty, apiKey: "secret"},
+		{provider: incidents.ProviderPagerDuty, apiKey: "secret", services: []string{"foo"}},
+		{provider: incidents.ProviderOpsgenie, apiKey: "secret", services: []string{":bar"}},
+	} {
+		if err := incidents.Setup(testCase.provider, "", testCase.apiKey, testCase.services, "", time.Second); err == nil {
+			t.Errorf("Setup(%v) didn't return any error", testCase)
+		}
+	}
+	if err := incidents.Setup("", "", "", nil, "", 0); err != nil {
+		t.Errorf("Setup() returned an error with lookups disabled: %s", err)
+	}
+}
@@ -0,0 +1,62 @@
+package incidents
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/cloudflare/unsee/internal/models"
+)
+
+// opsgenieAlertURL is the web UI URL of an Opsgenie alert, API responses
+// don't include it
+const opsgenieAlertURL = "https://app.opsgenie.com/alert/detail/%s/details"
+
+type opsgenie struct {
+	apiURL  string
+	apiKey  string
+	timeout time.Duration
+}
+
+type opsgenieAlerts struct {
+	Data []struct {
+		ID           string `json:"id"`
+		Alias        string `json:"alias"`
+		Status       string `json:"status"`
+		Acknowledged bool   `json:"acknowledged"`
+	} `json:"data"`
+}
+
+// openIncidents returns open alerts of given team, alias is the dedup key
+// sent by Alertmanager
+func (og *opsgenie) openIncidents(ctx context.Context, team string) (map[string]models.AlertIncident, error) {
+	headers := map[string]string{"Authorization": "GenieKey " + og.apiKey}
+	incidents := map[string]models.AlertIncident{}
+	for offset := 0; ; offset += pageSize {
+		query := url.Values{
+			"query":  []string{fmt.Sprintf(`status:open AND teams:"%s"`, team)},
+			"limit":  []string{fmt.Sprintf("%d", pageSize)},
+			"offset": []string{fmt.Sprintf("%d", offset)},
+		}
+		resp := opsgenieAlerts{}
+		if err := getJSON(ctx, og.apiURL+"/v2/alerts?"+query.Encode(), og.timeout, headers, &resp); err != nil {
+			return nil, err
+		}
+		for _, alert := range resp.Data {
+			status := alert.Status
+			if alert.Acknowledged {
+				status = "acknowledged"
+			}
+			incidents[alert.Alias] = models.AlertIncident{
+				Provider: ProviderOpsgenie,
+				ID:       alert.ID,
+				Status:   status,
+				URL:      fmt.Sprintf(opsgenieAlertURL, url.PathEscape(alert.ID)),
+			}
+		}
+		if len(resp.Data) < pageSize {
+			return incidents, nil
+		}
+	}
+}
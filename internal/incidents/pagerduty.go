@@ -0,0 +1,59 @@
+package incidents
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/cloudflare/unsee/internal/models"
+)
+
+type pagerDuty struct {
+	apiURL  string
+	apiKey  string
+	timeout time.Duration
+}
+
+type pagerDutyIncidents struct {
+	Incidents []struct {
+		ID          string `json:"id"`
+		IncidentKey string `json:"incident_key"`
+		Status      string `json:"status"`
+		HTMLURL     string `json:"html_url"`
+	} `json:"incidents"`
+	More bool `json:"more"`
+}
+
+// openIncidents returns triggered and acknowledged incidents of given
+// service, incident_key is the dedup_key sent by Alertmanager
+func (pd *pagerDuty) openIncidents(ctx context.Context, service string) (map[string]models.AlertIncident, error) {
+	headers := map[string]string{
+		"Accept":        "application/vnd.pagerduty+json;version=2",
+		"Authorization": "Token token=" + pd.apiKey,
+	}
+	incidents := map[string]models.AlertIncident{}
+	for offset := 0; ; offset += pageSize {
+		query := url.Values{
+			"service_ids[]": []string{service},
+			"statuses[]":    []string{"triggered", "acknowledged"},
+			"limit":         []string{fmt.Sprintf("%d", pageSize)},
+			"offset":        []string{fmt.Sprintf("%d", offset)},
+		}
+		resp := pagerDutyIncidents{}
+		if err := getJSON(ctx, pd.apiURL+"/incidents?"+query.Encode(), pd.timeout, headers, &resp); err != nil {
+			return nil, err
+		}
+		for _, incident := range resp.Incidents {
+			incidents[incident.IncidentKey] = models.AlertIncident{
+				Provider: ProviderPagerDuty,
+				ID:       incident.ID,
+				Status:   incident.Status,
+				URL:      incident.HTMLURL,
+			}
+		}
+		if !resp.More || len(resp.Incidents) == 0 {
+			return incidents, nil
+		}
+	}
+}
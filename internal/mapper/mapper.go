@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/cloudflare/unsee/internal/models"
+	"github.com/prometheus/common/model"
 )
 
 var (
@@ -58,3 +59,14 @@ func GetSilenceMapper(version string) (SilenceMapper, error) {
 	}
 	return nil, fmt.Errorf("Can't find silence mapper for Alertmanager %s", version)
 }
+
+// AlertFingerprint returns the fingerprint of an alert with given labels, it
+// uses the same algorithm as Alertmanager, so it can be used with Alertmanager
+// versions that don't include fingerprints in the API responses
+func AlertFingerprint(labels map[string]string) string {
+	ls := model.LabelSet{}
+	for k, v := range labels {
+		ls[model.LabelName(k)] = model.LabelValue(v)
+	}
+	return ls.Fingerprint().String()
+}
@@ -5,6 +5,7 @@
 package v05
 
 import (
+	"encoding/json"
 	"errors"
 	"sort"
 	"time"
@@ -35,10 +36,11 @@ type alertsGroups struct {
 	} `json:"blocks"`
 }
 
+// alertsGroupsAPISchema is the response without alert groups stored under
+// data, those are decoded one by one while the response is read
 type alertsGroupsAPISchema struct {
-	Status string         `json:"status"`
-	Data   []alertsGroups `json:"data"`
-	Error  string         `json:"error"`
+	Status string `json:"status"`
+	Error  string `json:"error"`
 }
 
 type alertsGroupReceiver struct {
@@ -69,16 +71,11 @@ func (m AlertMapper) GetAlerts(uri string, timeout time.Duration) ([]models.Aler
 		return groups, err
 	}
 
-	err = transport.ReadJSON(url, timeout, &resp)
-	if err != nil {
-		return groups, err
-	}
-
-	if resp.Status != "success" {
-		return groups, errors.New(resp.Error)
-	}
-
-	for _, d := range resp.Data {
+	err = transport.StreamJSON(url, timeout, "data", func(dec *json.Decoder) error {
+		d := alertsGroups{}
+		if err := dec.Decode(&d); err != nil {
+			return err
+		}
 		for _, b := range d.Blocks {
 			rcv, found := receivers[b.RouteOps.Receiver]
 			if !found {
@@ -104,6 +101,7 @@ func (m AlertMapper) GetAlerts(uri string, timeout time.Duration) ([]models.Aler
 					Receiver:     rcv.Name,
 					Annotations:  models.AnnotationsFromMap(a.Annotations),
 					Labels:       a.Labels,
+					Fingerprint:  mapper.AlertFingerprint(a.Labels),
 					StartsAt:     a.StartsAt,
 					EndsAt:       a.EndsAt,
 					GeneratorURL: a.GeneratorURL,
@@ -124,7 +122,16 @@ func (m AlertMapper) GetAlerts(uri string, timeout time.Duration) ([]models.Aler
 			rcv.Groups = append(rcv.Groups, ug)
 			receivers[rcv.Name] = rcv
 		}
+		return nil
+	}, &resp)
+	if err != nil {
+		return groups, err
+	}
+
+	if resp.Status != "success" {
+		return groups, errors.New(resp.Error)
 	}
+
 	for _, rcv := range receivers {
 		for _, ag := range rcv.Groups {
 			groups = append(groups, ag)
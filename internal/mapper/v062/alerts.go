@@ -6,6 +6,7 @@
 package v062
 
 import (
+	"encoding/json"
 	"errors"
 	"sort"
 	"time"
@@ -29,6 +30,7 @@ type alert struct {
 	EndsAt       time.Time         `json:"endsAt"`
 	GeneratorURL string            `json:"generatorURL"`
 	Status       alertStatus       `json:"status"`
+	Fingerprint  string            `json:"fingerprint"`
 }
 
 type alertsGroups struct {
@@ -41,10 +43,11 @@ type alertsGroups struct {
 	} `json:"blocks"`
 }
 
+// alertsGroupsAPISchema is the response without alert groups stored under
+// data, those are decoded one by one while the response is read
 type alertsGroupsAPISchema struct {
-	Status string         `json:"status"`
-	Data   []alertsGroups `json:"data"`
-	Error  string         `json:"error"`
+	Status string `json:"status"`
+	Error  string `json:"error"`
 }
 
 type alertsGroupReceiver struct {
@@ -75,16 +78,11 @@ func (m AlertMapper) GetAlerts(uri string, timeout time.Duration) ([]models.Aler
 		return groups, err
 	}
 
-	err = transport.ReadJSON(url, timeout, &resp)
-	if err != nil {
-		return groups, err
-	}
-
-	if resp.Status != "success" {
-		return groups, errors.New(resp.Error)
-	}
-
-	for _, d := range resp.Data {
+	err = transport.StreamJSON(url, timeout, "data", func(dec *json.Decoder) error {
+		d := alertsGroups{}
+		if err := dec.Decode(&d); err != nil {
+			return err
+		}
 		for _, b := range d.Blocks {
 			rcv, found := receivers[b.RouteOps.Receiver]
 			if !found {
@@ -103,10 +101,16 @@ func (m AlertMapper) GetAlerts(uri string, timeout time.Duration) ([]models.Aler
 				if a.Status.SilencedBy != nil {
 					silencedBy = a.Status.SilencedBy
 				}
+				fingerprint := a.Fingerprint
+				if fingerprint == "" {
+					// fingerprint is only included in responses since 0.9.0
+					fingerprint = mapper.AlertFingerprint(a.Labels)
+				}
 				a := models.Alert{
 					Receiver:     rcv.Name,
 					Annotations:  models.AnnotationsFromMap(a.Annotations),
 					Labels:       a.Labels,
+					Fingerprint:  fingerprint,
 					StartsAt:     a.StartsAt,
 					EndsAt:       a.EndsAt,
 					GeneratorURL: a.GeneratorURL,
@@ -127,7 +131,16 @@ func (m AlertMapper) GetAlerts(uri string, timeout time.Duration) ([]models.Aler
 			rcv.Groups = append(rcv.Groups, ug)
 			receivers[rcv.Name] = rcv
 		}
+		return nil
+	}, &resp)
+	if err != nil {
+		return groups, err
+	}
+
+	if resp.Status != "success" {
+		return groups, errors.New(resp.Error)
 	}
+
 	for _, rcv := range receivers {
 		for _, ag := range rcv.Groups {
 			groups = append(groups, ag)
@@ -0,0 +1,304 @@
+// Package synthetic implements a fake Alertmanager API serving generated
+// alerts and silences, it's used to load test unsee and to run demos without
+// pointing it at a real Alertmanager
+package synthetic
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Version is the Alertmanager version reported by the status endpoint, the
+// API schema used to return alerts and silences matches this version
+const Version = "0.9.1"
+
+// Receiver is the name of the receiver all alert groups are routed to
+const Receiver = "default"
+
+var (
+	clusters   = []string{"prod", "staging", "dev"}
+	severities = []string{"critical", "warning", "info"}
+)
+
+// Options control how many alerts and silences are generated and how many of
+// them are replaced on every churn
+type Options struct {
+	// Groups is the number of alert groups, every group has its own alertname
+	Groups int
+	// AlertsPerGroup is the number of alerts in every group
+	AlertsPerGroup int
+	// Silences is the number of silences, each one silences a single alert
+	Silences int
+	// ChurnRate is the fraction of alerts and silences replaced with new ones
+	// every time Churn is called
+	ChurnRate float64
+	// Seed for the random generator, the same options will always generate
+	// alerts and silences with the same labels and matchers
+	Seed int64
+}
+
+type alert struct {
+	labels      map[string]string
+	annotations map[string]string
+	startsAt    time.Time
+	fingerprint string
+}
+
+type silence struct {
+	id        string
+	instance  string
+	createdBy string
+	comment   string
+	startsAt  time.Time
+	endsAt    time.Time
+}
+
+// Server generates alerts and silences and serves them using the Alertmanager
+// API
+type Server struct {
+	lock     sync.RWMutex
+	opts     Options
+	random   *rand.Rand
+	seq      int
+	started  time.Time
+	groups   [][]alert
+	silences []silence
+}
+
+// NewServer returns a Server with alerts and silences generated from given
+// options
+func NewServer(opts Options) *Server {
+	s := &Server{
+		opts:    opts,
+		random:  rand.New(rand.NewSource(opts.Seed)),
+		started: time.Now(),
+	}
+	s.groups = make([][]alert, opts.Groups)
+	for i := range s.groups {
+		s.groups[i] = make([]alert, opts.AlertsPerGroup)
+		for j := range s.groups[i] {
+			s.groups[i][j] = s.newAlert(i)
+		}
+	}
+	s.silences = make([]silence, 0, opts.Silences)
+	for i := 0; i < opts.Silences; i++ {
+		s.silences = append(s.silences, s.newSilence())
+	}
+	return s
+}
+
+func (s *Server) newAlert(group int) alert {
+	s.seq++
+	return alert{
+		labels: map[string]string{
+			"alertname": groupName(group),
+			"cluster":   clusters[s.random.Intn(len(clusters))],
+			"instance":  fmt.Sprintf("server%d", s.seq),
+			"job":       "node_exporter",
+			"severity":  severities[s.random.Intn(len(severities))],
+		},
+		annotations: map[string]string{
+			"summary":   fmt.Sprintf("Synthetic alert #%d", s.seq),
+			"dashboard": "http://localhost/dashboard.html",
+		},
+		startsAt:    time.Now(),
+		fingerprint: fmt.Sprintf("%016x", s.seq),
+	}
+}
+
+// newSilence returns a silence matching the instance label of a random alert,
+// instance values are unique so it only silences that alert
+func (s *Server) newSilence() silence {
+	s.seq++
+	sl := silence{
+		id:        fmt.Sprintf("synthetic-%d", s.seq),
+		createdBy: "synthetic@example.com",
+		comment:   fmt.Sprintf("Synthetic silence #%d", s.seq),
+		startsAt:  time.Now(),
+		endsAt:    time.Now().Add(time.Hour * 24),
+	}
+	if s.opts.Groups > 0 && s.opts.AlertsPerGroup > 0 {
+		a := s.groups[s.random.Intn(s.opts.Groups)][s.random.Intn(s.opts.AlertsPerGroup)]
+		sl.instance = a.labels["instance"]
+	}
+	return sl
+}
+
+func groupName(group int) string {
+	return fmt.Sprintf("Synthetic_Alert_%d", group)
+}
+
+// churnCount returns how many of total items should be replaced
+func (s *Server) churnCount(total int) int {
+	n := int(float64(total)*s.opts.ChurnRate + 0.5)
+	if n > total {
+		return total
+	}
+	return n
+}
+
+// Churn replaces a fraction of alerts and silences with new ones, as set by
+// ChurnRate, it returns the number of replaced alerts and silences
+func (s *Server) Churn() (int, int) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	alerts := s.churnCount(s.opts.Groups * s.opts.AlertsPerGroup)
+	for _, n := range s.random.Perm(s.opts.Groups * s.opts.AlertsPerGroup)[:alerts] {
+		group := n / s.opts.AlertsPerGroup
+		s.groups[group][n%s.opts.AlertsPerGroup] = s.newAlert(group)
+	}
+
+	silences := s.churnCount(len(s.silences))
+	for _, n := range s.random.Perm(len(s.silences))[:silences] {
+		s.silences[n] = s.newSilence()
+	}
+	return alerts, silences
+}
+
+type apiResponse struct {
+	Status string      `json:"status"`
+	Data   interface{} `json:"data"`
+}
+
+type apiStatus struct {
+	Uptime      time.Time         `json:"uptime"`
+	VersionInfo map[string]string `json:"versionInfo"`
+}
+
+type apiAlertStatus struct {
+	State       string   `json:"state"`
+	SilencedBy  []string `json:"silencedBy"`
+	InhibitedBy []string `json:"inhibitedBy"`
+}
+
+type apiAlert struct {
+	Annotations  map[string]string `json:"annotations"`
+	Labels       map[string]string `json:"labels"`
+	StartsAt     time.Time         `json:"startsAt"`
+	EndsAt       time.Time         `json:"endsAt"`
+	GeneratorURL string            `json:"generatorURL"`
+	Status       apiAlertStatus    `json:"status"`
+	Fingerprint  string            `json:"fingerprint"`
+}
+
+type apiRouteOpts struct {
+	Receiver string   `json:"receiver"`
+	GroupBy  []string `json:"groupBy"`
+}
+
+type apiBlock struct {
+	Alerts    []apiAlert   `json:"alerts"`
+	RouteOpts apiRouteOpts `json:"routeOpts"`
+}
+
+type apiAlertGroup struct {
+	Labels map[string]string `json:"labels"`
+	Blocks []apiBlock        `json:"blocks"`
+}
+
+type apiMatcher struct {
+	Name    string `json:"name"`
+	Value   string `json:"value"`
+	IsRegex bool   `json:"isRegex"`
+}
+
+type apiSilence struct {
+	ID        string       `json:"id"`
+	Matchers  []apiMatcher `json:"matchers"`
+	StartsAt  time.Time    `json:"startsAt"`
+	EndsAt    time.Time    `json:"endsAt"`
+	CreatedAt time.Time    `json:"createdAt"`
+	CreatedBy string       `json:"createdBy"`
+	Comment   string       `json:"comment"`
+}
+
+func (s *Server) status() apiStatus {
+	return apiStatus{
+		Uptime:      s.started,
+		VersionInfo: map[string]string{"version": Version},
+	}
+}
+
+func (s *Server) alertGroups() []apiAlertGroup {
+	silencedBy := map[string][]string{}
+	for _, sl := range s.silences {
+		silencedBy[sl.instance] = append(silencedBy[sl.instance], sl.id)
+	}
+
+	groups := make([]apiAlertGroup, 0, len(s.groups))
+	for i, alerts := range s.groups {
+		block := apiBlock{
+			Alerts:    make([]apiAlert, 0, len(alerts)),
+			RouteOpts: apiRouteOpts{Receiver: Receiver, GroupBy: []string{"alertname"}},
+		}
+		for _, a := range alerts {
+			status := apiAlertStatus{State: "active", SilencedBy: []string{}, InhibitedBy: []string{}}
+			if ids, found := silencedBy[a.labels["instance"]]; found {
+				status.State = "suppressed"
+				status.SilencedBy = ids
+			}
+			block.Alerts = append(block.Alerts, apiAlert{
+				Annotations:  a.annotations,
+				Labels:       a.labels,
+				StartsAt:     a.startsAt,
+				GeneratorURL: "http://localhost/prometheus",
+				Status:       status,
+				Fingerprint:  a.fingerprint,
+			})
+		}
+		groups = append(groups, apiAlertGroup{
+			Labels: map[string]string{"alertname": groupName(i)},
+			Blocks: []apiBlock{block},
+		})
+	}
+	return groups
+}
+
+func (s *Server) apiSilences() []apiSilence {
+	silences := make([]apiSilence, 0, len(s.silences))
+	for _, sl := range s.silences {
+		silences = append(silences, apiSilence{
+			ID:        sl.id,
+			Matchers:  []apiMatcher{{Name: "instance", Value: sl.instance}},
+			StartsAt:  sl.startsAt,
+			EndsAt:    sl.endsAt,
+			CreatedAt: sl.startsAt,
+			CreatedBy: sl.createdBy,
+			Comment:   sl.comment,
+		})
+	}
+	return silences
+}
+
+// ServeHTTP implements the http.Handler interface, only endpoints used by
+// unsee to collect alerts and silences are supported
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	var data interface{}
+	switch strings.TrimSuffix(r.URL.Path, "/") {
+	case "/api/v1/status":
+		data = s.status()
+	case "/api/v1/alerts/groups":
+		data = s.alertGroups()
+	case "/api/v1/silences":
+		data = s.apiSilences()
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(apiResponse{Status: "success", Data: data})
+}
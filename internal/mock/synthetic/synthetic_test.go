@@ -0,0 +1,147 @@
+package synthetic_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cloudflare/unsee/internal/alertmanager"
+	"github.com/cloudflare/unsee/internal/mapper/v05"
+	"github.com/cloudflare/unsee/internal/mapper/v062"
+	"github.com/cloudflare/unsee/internal/mock/synthetic"
+	"github.com/cloudflare/unsee/internal/models"
+)
+
+func collect(t *testing.T, uri string) ([]models.AlertGroup, []models.Silence) {
+	groups, err := v062.AlertMapper{}.GetAlerts(uri, time.Second*5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	silences, err := v05.SilenceMapper{}.GetSilences(uri, time.Second*5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return groups, silences
+}
+
+func fingerprints(groups []models.AlertGroup) map[string]bool {
+	fps := map[string]bool{}
+	for _, ag := range groups {
+		for _, alert := range ag.Alerts {
+			fps[alert.Fingerprint] = true
+		}
+	}
+	return fps
+}
+
+func TestSyntheticServer(t *testing.T) {
+	s := synthetic.NewServer(synthetic.Options{Groups: 5, AlertsPerGroup: 20, Silences: 10, ChurnRate: 0.1})
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	if version := alertmanager.GetVersion(ts.URL, time.Second*5); version != synthetic.Version {
+		t.Errorf("Got version %s, expected %s", version, synthetic.Version)
+	}
+
+	groups, silences := collect(t, ts.URL)
+	if len(groups) != 5 {
+		t.Errorf("Got %d alert groups, expected %d", len(groups), 5)
+	}
+	suppressed := 0
+	for _, ag := range groups {
+		if ag.Receiver != synthetic.Receiver {
+			t.Errorf("Alert group %v has receiver %s, expected %s", ag.Labels, ag.Receiver, synthetic.Receiver)
+		}
+		if len(ag.Alerts) != 20 {
+			t.Errorf("Alert group %v has %d alerts, expected %d", ag.Labels, len(ag.Alerts), 20)
+		}
+		for _, alert := range ag.Alerts {
+			if alert.State == models.AlertStateSuppressed {
+				suppressed++
+			}
+		}
+	}
+	if len(silences) != 10 {
+		t.Errorf("Got %d silences, expected %d", len(silences), 10)
+	}
+	if suppressed == 0 || suppressed > 10 {
+		t.Errorf("Got %d suppressed alerts, expected 1-10", suppressed)
+	}
+
+	before := fingerprints(groups)
+	if alerts, silenced := s.Churn(); alerts != 10 || silenced != 1 {
+		t.Errorf("Churn() replaced %d alerts and %d silences, expected 10 and 1", alerts, silenced)
+	}
+	groups, silences = collect(t, ts.URL)
+	after := fingerprints(groups)
+	if len(after) != 100 || len(silences) != 10 {
+		t.Errorf("Got %d alerts and %d silences after churn, expected 100 and 10", len(after), len(silences))
+	}
+	kept := 0
+	for fp := range after {
+		if before[fp] {
+			kept++
+		}
+	}
+	if kept != 90 {
+		t.Errorf("%d alerts were kept after churn, expected %d", kept, 90)
+	}
+}
+
+func TestSyntheticServerSeed(t *testing.T) {
+	labels := func(seed int64) []map[string]string {
+		s := synthetic.NewServer(synthetic.Options{Groups: 2, AlertsPerGroup: 5, Seed: seed})
+		ts := httptest.NewServer(s)
+		defer ts.Close()
+		groups, _ := collect(t, ts.URL)
+		l := []map[string]string{}
+		for _, ag := range groups {
+			for _, alert := range ag.Alerts {
+				l = append(l, alert.Labels)
+			}
+		}
+		return l
+	}
+	a, b := labels(1), labels(1)
+	if len(a) != 10 || len(a) != len(b) {
+		t.Fatalf("Got %d and %d alerts, expected %d", len(a), len(b), 10)
+	}
+	for i := range a {
+		for name, value := range a[i] {
+			if b[i][name] != value {
+				t.Errorf("Alert #%d has %s=%s, expected %s with the same seed", i, name, b[i][name], value)
+			}
+		}
+	}
+}
+
+func TestSyntheticServerErrors(t *testing.T) {
+	ts := httptest.NewServer(synthetic.NewServer(synthetic.Options{}))
+	defer ts.Close()
+
+	for _, testCase := range []struct {
+		method string
+		path   string
+		code   int
+	}{
+		{method: "GET", path: "/api/v1/status", code: http.StatusOK},
+		{method: "GET", path: "/api/v1/alerts/groups", code: http.StatusOK},
+		{method: "GET", path: "/api/v1/silences", code: http.StatusOK},
+		{method: "GET", path: "/api/v1/alerts", code: http.StatusNotFound},
+		{method: "POST", path: "/api/v1/silences", code: http.StatusMethodNotAllowed},
+	} {
+		req, err := http.NewRequest(testCase.method, ts.URL+testCase.path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != testCase.code {
+			t.Errorf("%s %s returned %d, expected %d", testCase.method, testCase.path, resp.StatusCode, testCase.code)
+		}
+	}
+}
@@ -35,6 +35,9 @@ type Alert struct {
 	StartsAt    time.Time         `json:"startsAt"`
 	EndsAt      time.Time         `json:"endsAt"`
 	State       string            `json:"state"`
+	// Fingerprint is the alert fingerprint as computed by Alertmanager, it
+	// depends only on alert labels
+	Fingerprint string `json:"fingerprint" hash:"-"`
 	// those are not exposed in JSON, Alertmanager specific value will be in kept
 	// in the Alertmanager slice
 	// skip those when generating alert fingerprint too
@@ -44,11 +47,73 @@ type Alert struct {
 	// unsee fields
 	Alertmanager []AlertmanagerInstance `json:"alertmanager"`
 	Receiver     string                 `json:"receiver"`
+	// Links are generated from alert labels using GRAFANA_LINKS rules, they
+	// only depend on fields that are already hashed
+	Links []AlertLink `json:"links" hash:"-"`
+	// timestamps formatted for display using TIMEZONE, those are set once
+	// alerts from all upstreams are merged
+	StartsAtDisplay string `json:"startsAtDisplay" hash:"-"`
+	EndsAtDisplay   string `json:"endsAtDisplay" hash:"-"`
+	// Incident is the open PagerDuty or Opsgenie incident created for this
+	// alert, if there's any
+	Incident *AlertIncident `json:"incident"`
+	// Flapping is true if the alert was added or resolved too many times
+	// within the flapping detection window
+	Flapping bool `json:"flapping"`
+	// Timeline lists state transitions of the alert observed by unsee, it's
+	// only set for alerts returned by the alert group details endpoint
+	Timeline []AlertTransition `json:"timeline,omitempty" hash:"-"`
+	// Rule is the Prometheus rule that generated the alert, it's only set for
+	// alerts returned by the alert group details endpoint
+	Rule *AlertRule `json:"rule,omitempty" hash:"-"`
+	// CollapsedAlerts is the number of alerts folded into this one using
+	// ALERTS_COLLAPSE_LABELS and CollapsedLabels lists all values of those
+	// labels, both are only set for alerts returned by the alerts endpoint
+	CollapsedAlerts int                 `json:"collapsedAlerts,omitempty" hash:"-"`
+	CollapsedLabels map[string][]string `json:"collapsedLabels,omitempty" hash:"-"`
 	// fingerprints are precomputed for speed
 	labelsFP  string `hash:"-"`
 	contentFP string `hash:"-"`
 }
 
+// AlertLink is a link generated by unsee for an alert
+type AlertLink struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+// AlertIncident is an open incident created for an alert in an external
+// incident management service
+type AlertIncident struct {
+	Provider string `json:"provider"`
+	ID       string `json:"id"`
+	Status   string `json:"status"`
+	URL      string `json:"url"`
+}
+
+// AlertRule is the Prometheus alerting rule that generated an alert, URL and
+// Expression are parsed from the generatorURL of the alert, other fields are
+// only set if the rule was found on the Prometheus server
+type AlertRule struct {
+	// URL is the generatorURL of the alert, it links to the expression in the
+	// Prometheus UI
+	URL string `json:"url"`
+	// Prometheus is the URL of the Prometheus server that evaluated the rule
+	Prometheus string `json:"prometheus"`
+	Expression string `json:"expression"`
+	Name       string `json:"name,omitempty"`
+	Group      string `json:"group,omitempty"`
+	File       string `json:"file,omitempty"`
+	// Duration is the value of the for field of the rule
+	Duration string `json:"duration,omitempty"`
+}
+
+// AlertTransition is a single change of the alert state observed by unsee
+type AlertTransition struct {
+	Type      string    `json:"type"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
 // UpdateFingerprints will generate a new set of fingerprints for this alert
 // it should be called after modifying any field that isn't tagged with hash:"-"
 func (a *Alert) UpdateFingerprints() {
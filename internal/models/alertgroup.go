@@ -4,6 +4,9 @@ import (
 	"crypto/sha1"
 	"fmt"
 	"io"
+	"sort"
+
+	"github.com/cloudflare/unsee/internal/slices"
 
 	"github.com/cnf/structhash"
 )
@@ -39,14 +42,46 @@ type AlertGroup struct {
 	ID         string            `json:"id"`
 	Hash       string            `json:"hash"`
 	StateCount map[string]int    `json:"stateCount"`
+	// TotalAlerts is the number of alerts in this group, Alerts will only
+	// include some of those if the number of alerts per group was limited
+	TotalAlerts int `json:"totalAlerts"`
+	// Collapse is a hint for clients that only a summary of alerts should be
+	// rendered for this group, it's set using GROUP_COLLAPSE_* options
+	Collapse bool `json:"collapse" hash:"-"`
+	// SharedAnnotations lists annotations with the same value on every alert
+	// in the group, those are removed from annotations of each alert, it's
+	// only set if annotations were split using SplitAnnotations
+	SharedAnnotations Annotations `json:"sharedAnnotations,omitempty" hash:"-"`
 }
 
 // LabelsFingerprint is a checksum of this AlertGroup labels and the receiver
 // it should be unique for each AlertGroup
+// It's used as the group ID, which is a part of links to the group, so it's
+// computed from an explicit encoding of sorted labels instead of a generic
+// struct hash, this encoding must never change or existing links will break
 func (ag AlertGroup) LabelsFingerprint() string {
+	// labels are encoded as ["name":"value",...] sorted by the quoted name,
+	// which is how IDs were generated by older releases
+	names := make([]string, 0, len(ag.Labels))
+	for name := range ag.Labels {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return `"`+names[i]+`"` < `"`+names[j]+`"`
+	})
+	labelsHasher := sha1.New()
+	io.WriteString(labelsHasher, "[")
+	for i, name := range names {
+		if i != 0 {
+			io.WriteString(labelsHasher, ",")
+		}
+		fmt.Fprintf(labelsHasher, `"%s":"%s"`, name, ag.Labels[name])
+	}
+	io.WriteString(labelsHasher, "]")
+
 	agIDHasher := sha1.New()
 	io.WriteString(agIDHasher, ag.Receiver)
-	io.WriteString(agIDHasher, fmt.Sprintf("%x", structhash.Sha1(ag.Labels, 1)))
+	io.WriteString(agIDHasher, fmt.Sprintf("%x", labelsHasher.Sum(nil)))
 	return fmt.Sprintf("%x", agIDHasher.Sum(nil))
 }
 
@@ -58,3 +93,98 @@ func (ag AlertGroup) ContentFingerprint() string {
 	}
 	return fmt.Sprintf("%x", h.Sum(nil))
 }
+
+// SplitAnnotations returns annotations with the same value on every alert in
+// the group and a copy of all alerts with only annotations that differ, alerts
+// in the group are not modified
+func (ag AlertGroup) SplitAnnotations() (Annotations, AlertList) {
+	shared := Annotations{}
+	alerts := make(AlertList, 0, len(ag.Alerts))
+	if len(ag.Alerts) == 0 {
+		return shared, alerts
+	}
+
+	annotationCount := map[Annotation]int{}
+	for _, alert := range ag.Alerts {
+		for _, annotation := range alert.Annotations {
+			annotationCount[annotation]++
+		}
+	}
+	for _, annotation := range ag.Alerts[0].Annotations {
+		if annotationCount[annotation] == len(ag.Alerts) {
+			shared = append(shared, annotation)
+		}
+	}
+
+	for _, alert := range ag.Alerts {
+		annotations := Annotations{}
+		for _, annotation := range alert.Annotations {
+			if annotationCount[annotation] != len(ag.Alerts) {
+				annotations = append(annotations, annotation)
+			}
+		}
+		alert.Annotations = annotations
+		alerts = append(alerts, alert)
+	}
+	return shared, alerts
+}
+
+// CollapseAlerts returns a copy of all alerts in the group where alerts with
+// the same state that only differ by values of given labels are folded into
+// the first of them, those labels are removed from folded alerts and all their
+// values are listed in CollapsedLabels, alerts in the group are not modified
+func (ag AlertGroup) CollapseAlerts(labels []string) AlertList {
+	alerts := AlertList{}
+	if len(labels) == 0 {
+		return append(alerts, ag.Alerts...)
+	}
+
+	keys := make([]string, len(ag.Alerts))
+	counts := map[string]int{}
+	for i, alert := range ag.Alerts {
+		rest := map[string]string{}
+		for name, value := range alert.Labels {
+			if !slices.StringInSlice(labels, name) {
+				rest[name] = value
+			}
+		}
+		keys[i] = fmt.Sprintf("%s/%x", alert.State, structhash.Sha1(rest, 1))
+		counts[keys[i]]++
+	}
+
+	folded := map[string]int{}
+	for i, alert := range ag.Alerts {
+		if counts[keys[i]] == 1 {
+			alerts = append(alerts, alert)
+			continue
+		}
+		pos, found := folded[keys[i]]
+		if !found {
+			alert.Labels = map[string]string{}
+			for name, value := range ag.Alerts[i].Labels {
+				if !slices.StringInSlice(labels, name) {
+					alert.Labels[name] = value
+				}
+			}
+			alert.CollapsedLabels = map[string][]string{}
+			alert.UpdateFingerprints()
+			alerts = append(alerts, alert)
+			pos = len(alerts) - 1
+			folded[keys[i]] = pos
+		}
+		alerts[pos].CollapsedAlerts++
+		for _, name := range labels {
+			value, ok := ag.Alerts[i].Labels[name]
+			if ok && !slices.StringInSlice(alerts[pos].CollapsedLabels[name], value) {
+				alerts[pos].CollapsedLabels[name] = append(alerts[pos].CollapsedLabels[name], value)
+			}
+		}
+	}
+
+	for _, alert := range alerts {
+		for name := range alert.CollapsedLabels {
+			sort.Strings(alert.CollapsedLabels[name])
+		}
+	}
+	return alerts
+}
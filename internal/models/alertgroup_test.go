@@ -179,3 +179,99 @@ func TestAlertGroupContentFingerprint(t *testing.T) {
 		})
 	}
 }
+
+func TestSplitAnnotations(t *testing.T) {
+	summary := models.Annotation{Name: "summary", Value: "disk full", Visible: true}
+	ag := models.AlertGroup{
+		Alerts: models.AlertList{
+			models.Alert{Annotations: models.Annotations{
+				models.Annotation{Name: "instance", Value: "a", Visible: true},
+				summary,
+			}},
+			models.Alert{Annotations: models.Annotations{
+				models.Annotation{Name: "instance", Value: "b", Visible: true},
+				summary,
+			}},
+		},
+	}
+
+	shared, alerts := ag.SplitAnnotations()
+	if len(shared) != 1 || shared[0] != summary {
+		t.Errorf("SplitAnnotations() returned shared annotations %v, expected only %v", shared, summary)
+	}
+	for i, alert := range alerts {
+		if len(alert.Annotations) != 1 || alert.Annotations[0].Name != "instance" {
+			t.Errorf("Alert %d has annotations %v, expected only instance", i, alert.Annotations)
+		}
+		if len(ag.Alerts[i].Annotations) != 2 {
+			t.Errorf("SplitAnnotations() modified annotations of alert %d in the group", i)
+		}
+	}
+
+	shared, alerts = models.AlertGroup{}.SplitAnnotations()
+	if len(shared) != 0 || len(alerts) != 0 {
+		t.Errorf("SplitAnnotations() on an empty group returned %d shared annotations and %d alerts", len(shared), len(alerts))
+	}
+}
+
+func TestCollapseAlerts(t *testing.T) {
+	ag := models.AlertGroup{
+		Alerts: models.AlertList{
+			models.Alert{State: "active", Labels: map[string]string{"alertname": "Pod_Down", "pod": "b"}},
+			models.Alert{State: "active", Labels: map[string]string{"alertname": "Pod_Down", "pod": "a"}},
+			models.Alert{State: "suppressed", Labels: map[string]string{"alertname": "Pod_Down", "pod": "c"}},
+			models.Alert{State: "active", Labels: map[string]string{"alertname": "Node_Down", "pod": "a"}},
+		},
+	}
+
+	alerts := ag.CollapseAlerts([]string{"pod"})
+	if len(alerts) != 3 {
+		t.Fatalf("CollapseAlerts() returned %d alerts, expected 3", len(alerts))
+	}
+	if alerts[0].CollapsedAlerts != 2 {
+		t.Errorf("First alert has CollapsedAlerts=%d, expected 2", alerts[0].CollapsedAlerts)
+	}
+	if values := alerts[0].CollapsedLabels["pod"]; len(values) != 2 || values[0] != "a" || values[1] != "b" {
+		t.Errorf("First alert has collapsed pod values %v, expected [a b]", values)
+	}
+	if _, found := alerts[0].Labels["pod"]; found {
+		t.Errorf("Collapsed alert still has the pod label")
+	}
+	for _, alert := range alerts[1:] {
+		if alert.CollapsedAlerts != 0 || alert.Labels["pod"] == "" {
+			t.Errorf("Alert %v was collapsed, but no other alert differs only by the pod label", alert.Labels)
+		}
+	}
+	if ag.Alerts[0].Labels["pod"] != "b" || ag.Alerts[0].CollapsedAlerts != 0 {
+		t.Errorf("CollapseAlerts() modified alerts in the group")
+	}
+
+	if alerts = ag.CollapseAlerts([]string{}); len(alerts) != len(ag.Alerts) {
+		t.Errorf("CollapseAlerts() without labels returned %d alerts, expected %d", len(alerts), len(ag.Alerts))
+	}
+}
+
+func TestAlertGroupLabelsFingerprint(t *testing.T) {
+	ag := models.AlertGroup{
+		Receiver: "by-cluster-service",
+		Labels:   map[string]string{"alertname": "Host_Down", "cluster": "prod"},
+	}
+	// group IDs are used in links, so they must never change
+	id := "98c1a53d0f71af9c734c9180697383f3b8aff80f"
+	for i := 0; i < 10; i++ {
+		if fp := ag.LabelsFingerprint(); fp != id {
+			t.Fatalf("Got group ID %s, expected %s", fp, id)
+		}
+	}
+
+	for _, other := range []models.AlertGroup{
+		models.AlertGroup{Receiver: "default", Labels: ag.Labels},
+		models.AlertGroup{Receiver: ag.Receiver, Labels: map[string]string{"alertname": "Host_Down"}},
+		models.AlertGroup{Receiver: ag.Receiver, Labels: map[string]string{"alertname": "Host_Down", "cluster": "dev"}},
+		models.AlertGroup{Receiver: ag.Receiver, Labels: map[string]string{"alertname": "Host_Down", "cluster": "prod", "job": "node"}},
+	} {
+		if other.LabelsFingerprint() == id {
+			t.Errorf("Group %v has the same ID as %v", other, ag)
+		}
+	}
+}
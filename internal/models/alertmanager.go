@@ -1,6 +1,9 @@
 package models
 
-import "time"
+import (
+	"regexp"
+	"time"
+)
 
 // AlertmanagerInstance describes the Alertmanager instance alert was collected
 // from
@@ -25,6 +28,21 @@ type AlertmanagerAPIStatus struct {
 	Name  string `json:"name"`
 	URI   string `json:"uri"`
 	Error string `json:"error"`
+	// Stale is true if alerts were restored from a snapshot saved on the last
+	// shutdown and the instance wasn't collected since, or if the last
+	// successful collection is older than ALERTMANAGER_TTL allows
+	Stale bool `json:"stale"`
+	// LastRefresh is the time of the last successful collection, zero if the
+	// instance was never collected
+	LastRefresh time.Time `json:"lastRefresh"`
+	// ClockSkew is the number of seconds the upstream clock is ahead of the
+	// unsee clock, negative if it's behind, null if it couldn't be detected
+	ClockSkew *float64 `json:"clockSkew"`
+	// ClockSkewed is true if ClockSkew is above ALERTMANAGER_MAX_CLOCK_SKEW
+	ClockSkewed bool `json:"clockSkewed"`
+	// Paused is true if collection from the instance was paused, data from
+	// the last collection is still used
+	Paused bool `json:"paused"`
 }
 
 // AlertmanagerAPICounters returns number of Alertmanager instances in each
@@ -40,3 +58,105 @@ type AlertmanagerAPISummary struct {
 	Counters  AlertmanagerAPICounters `json:"counters"`
 	Instances []AlertmanagerAPIStatus `json:"instances"`
 }
+
+// AlertmanagerRoute is a single node of the Alertmanager routing tree
+type AlertmanagerRoute struct {
+	Receiver       string              `json:"receiver"`
+	GroupBy        []string            `json:"groupBy"`
+	GroupWait      string              `json:"groupWait"`
+	GroupInterval  string              `json:"groupInterval"`
+	RepeatInterval string              `json:"repeatInterval"`
+	Match          map[string]string   `json:"match"`
+	MatchRE        map[string]string   `json:"matchRE"`
+	Continue       bool                `json:"continue"`
+	Routes         []AlertmanagerRoute `json:"routes"`
+}
+
+// AlertmanagerStatus describes the Alertmanager instance as reported by its
+// status API, Route and Receivers are only set if the instance exports its
+// configuration as JSON
+type AlertmanagerStatus struct {
+	Name        string            `json:"name"`
+	URI         string            `json:"uri"`
+	Version     string            `json:"version"`
+	VersionInfo map[string]string `json:"versionInfo"`
+	StartedAt   time.Time         `json:"startedAt"`
+	// Uptime is the number of seconds since the instance was started
+	Uptime      float64            `json:"uptime"`
+	Route       *AlertmanagerRoute `json:"route"`
+	Receivers   []string           `json:"receivers"`
+	CollectedAt time.Time          `json:"collectedAt"`
+}
+
+// AlertmanagerRoutePrediction lists routes of an Alertmanager upstream that an
+// alert with given labels would be sent to
+type AlertmanagerRoutePrediction struct {
+	Name      string              `json:"name"`
+	Labels    map[string]string   `json:"labels"`
+	Receivers []string            `json:"receivers"`
+	Routes    []AlertmanagerRoute `json:"routes"`
+}
+
+// Matches returns true if labels match all matchers of the route, missing
+// labels have an empty value and regexp matchers are anchored, like in
+// Alertmanager, invalid regexps never match
+func (r *AlertmanagerRoute) Matches(labels map[string]string) bool {
+	for name, value := range r.Match {
+		if labels[name] != value {
+			return false
+		}
+	}
+	for name, expr := range r.MatchRE {
+		re, err := regexp.Compile("^(?:" + expr + ")$")
+		if err != nil || !re.MatchString(labels[name]) {
+			return false
+		}
+	}
+	return true
+}
+
+// inherit fills all unset options of a child route from its parent
+func (r AlertmanagerRoute) inherit(parent *AlertmanagerRoute) AlertmanagerRoute {
+	if r.Receiver == "" {
+		r.Receiver = parent.Receiver
+	}
+	if len(r.GroupBy) == 0 {
+		r.GroupBy = parent.GroupBy
+	}
+	if r.GroupWait == "" || r.GroupWait == "0s" {
+		r.GroupWait = parent.GroupWait
+	}
+	if r.GroupInterval == "" || r.GroupInterval == "0s" {
+		r.GroupInterval = parent.GroupInterval
+	}
+	if r.RepeatInterval == "" || r.RepeatInterval == "0s" {
+		r.RepeatInterval = parent.RepeatInterval
+	}
+	return r
+}
+
+// MatchingRoutes returns all routes an alert with given labels would be sent
+// to, the route itself is assumed to match, child routes are checked in order
+// and the alert descends into the first matching one, or into all matching
+// ones with continue set up to the first without it, the route is used if no
+// child matches, returned routes have options inherited from parents and no
+// children
+func (r *AlertmanagerRoute) MatchingRoutes(labels map[string]string) []AlertmanagerRoute {
+	matched := []AlertmanagerRoute{}
+	for _, child := range r.Routes {
+		if !child.Matches(labels) {
+			continue
+		}
+		child = child.inherit(r)
+		matched = append(matched, child.MatchingRoutes(labels)...)
+		if !child.Continue {
+			break
+		}
+	}
+	if len(matched) == 0 {
+		route := *r
+		route.Routes = []AlertmanagerRoute{}
+		matched = append(matched, route)
+	}
+	return matched
+}
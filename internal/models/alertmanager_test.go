@@ -0,0 +1,111 @@
+package models_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/cloudflare/unsee/internal/models"
+)
+
+var testRoute = models.AlertmanagerRoute{
+	Receiver:       "default",
+	GroupBy:        []string{"alertname"},
+	GroupWait:      "30s",
+	GroupInterval:  "5m0s",
+	RepeatInterval: "4h0m0s",
+	Routes: []models.AlertmanagerRoute{
+		models.AlertmanagerRoute{
+			Receiver: "audit",
+			MatchRE:  map[string]string{"alertname": ".+"},
+			Continue: true,
+		},
+		models.AlertmanagerRoute{
+			Match:          map[string]string{"cluster": "prod"},
+			GroupBy:        []string{"alertname", "cluster"},
+			RepeatInterval: "1h0m0s",
+			Routes: []models.AlertmanagerRoute{
+				models.AlertmanagerRoute{
+					Receiver: "pager",
+					Match:    map[string]string{"severity": "critical"},
+				},
+				models.AlertmanagerRoute{
+					Receiver: "chat",
+					MatchRE:  map[string]string{"severity": "warning|info"},
+				},
+			},
+		},
+		models.AlertmanagerRoute{
+			Receiver: "dev",
+			MatchRE:  map[string]string{"cluster": "dev|staging"},
+		},
+		models.AlertmanagerRoute{
+			Receiver: "never",
+			MatchRE:  map[string]string{"cluster": "("},
+		},
+	},
+}
+
+type matchingRoutesTest struct {
+	labels    map[string]string
+	receivers []string
+}
+
+var matchingRoutesTests = []matchingRoutesTest{
+	matchingRoutesTest{
+		labels:    map[string]string{},
+		receivers: []string{"default"},
+	},
+	matchingRoutesTest{
+		labels:    map[string]string{"alertname": "Foo"},
+		receivers: []string{"audit"},
+	},
+	matchingRoutesTest{
+		labels:    map[string]string{"alertname": "Foo", "cluster": "prod", "severity": "critical"},
+		receivers: []string{"audit", "pager"},
+	},
+	matchingRoutesTest{
+		labels:    map[string]string{"alertname": "Foo", "cluster": "prod", "severity": "info"},
+		receivers: []string{"audit", "chat"},
+	},
+	// no child of the prod route matches, so the prod route itself is used
+	// with the receiver inherited from the root
+	matchingRoutesTest{
+		labels:    map[string]string{"alertname": "Foo", "cluster": "prod"},
+		receivers: []string{"audit", "default"},
+	},
+	// regexps are anchored
+	matchingRoutesTest{
+		labels:    map[string]string{"cluster": "devel"},
+		receivers: []string{"default"},
+	},
+	matchingRoutesTest{
+		labels:    map[string]string{"cluster": "staging"},
+		receivers: []string{"dev"},
+	},
+}
+
+func TestMatchingRoutes(t *testing.T) {
+	for _, test := range matchingRoutesTests {
+		receivers := []string{}
+		for _, route := range testRoute.MatchingRoutes(test.labels) {
+			receivers = append(receivers, route.Receiver)
+			if len(route.Routes) != 0 {
+				t.Errorf("Route %s returned for labels %v has child routes", route.Receiver, test.labels)
+			}
+		}
+		if !reflect.DeepEqual(receivers, test.receivers) {
+			t.Errorf("Labels %v matched receivers %v, expected %v", test.labels, receivers, test.receivers)
+		}
+	}
+}
+
+func TestMatchingRoutesInherit(t *testing.T) {
+	routes := testRoute.MatchingRoutes(map[string]string{"cluster": "prod", "severity": "critical"})
+	if len(routes) != 1 {
+		t.Fatalf("Got %d routes, expected 1", len(routes))
+	}
+	route := routes[0]
+	if !reflect.DeepEqual(route.GroupBy, []string{"alertname", "cluster"}) || route.GroupWait != "30s" || route.GroupInterval != "5m0s" || route.RepeatInterval != "1h0m0s" {
+		t.Errorf("Route %v didn't inherit options from parents", route)
+	}
+}
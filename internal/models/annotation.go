@@ -1,7 +1,9 @@
 package models
 
 import (
+	"fmt"
 	"net/url"
+	"regexp"
 	"sort"
 
 	"github.com/cloudflare/unsee/internal/config"
@@ -66,6 +68,27 @@ func isLink(s string) bool {
 	return false
 }
 
+var hiddenAnnotationPatterns = []*regexp.Regexp{}
+
+// SetHiddenAnnotationPatterns compiles regexps from ANNOTATIONS_HIDDEN_REGEX,
+// annotations with names matching any of those are hidden unless listed in
+// ANNOTATIONS_VISIBLE, patterns aren't anchored
+func SetHiddenAnnotationPatterns(patterns []string) error {
+	compiled := []*regexp.Regexp{}
+	for _, pattern := range patterns {
+		if pattern == "" {
+			continue
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("Invalid hidden annotation pattern '%s': %s", pattern, err)
+		}
+		compiled = append(compiled, re)
+	}
+	hiddenAnnotationPatterns = compiled
+	return nil
+}
+
 func isVisible(name string) bool {
 	if slices.StringInSlice(config.Config.AnnotationsVisible, name) {
 		// annotation was explicitly marked as visible
@@ -75,6 +98,12 @@ func isVisible(name string) bool {
 		// annotation was explicitly marked as hidden
 		return false
 	}
+	for _, re := range hiddenAnnotationPatterns {
+		if re.MatchString(name) {
+			// annotation name matches a hidden pattern
+			return false
+		}
+	}
 	if config.Config.AnnotationsDefaultHidden {
 		// user specified that default is to hide anything without explicit rules
 		return false
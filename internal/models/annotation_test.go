@@ -4,6 +4,7 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/cloudflare/unsee/internal/config"
 	"github.com/cloudflare/unsee/internal/models"
 )
 
@@ -83,3 +84,43 @@ func TestAnnotationsFromMap(t *testing.T) {
 		}
 	}
 }
+
+type hiddenPatternTest struct {
+	visible  []string
+	hidden   []string
+	patterns []string
+	name     string
+	isHidden bool
+}
+
+var hiddenPatternTests = []hiddenPatternTest{
+	hiddenPatternTest{patterns: []string{"^internal_"}, name: "internal_id", isHidden: true},
+	hiddenPatternTest{patterns: []string{"^internal_"}, name: "my_internal_id", isHidden: false},
+	hiddenPatternTest{patterns: []string{"_id$", "^debug"}, name: "debug", isHidden: true},
+	hiddenPatternTest{patterns: []string{"^internal_"}, visible: []string{"internal_summary"}, name: "internal_summary", isHidden: false},
+	hiddenPatternTest{patterns: []string{}, hidden: []string{"internal_id"}, name: "internal_id", isHidden: true},
+	hiddenPatternTest{patterns: []string{}, name: "internal_id", isHidden: false},
+}
+
+func TestHiddenAnnotationPatterns(t *testing.T) {
+	defer func() {
+		config.Config.AnnotationsVisible = []string{}
+		config.Config.AnnotationsHidden = []string{}
+		models.SetHiddenAnnotationPatterns([]string{})
+	}()
+	for _, test := range hiddenPatternTests {
+		config.Config.AnnotationsVisible = test.visible
+		config.Config.AnnotationsHidden = test.hidden
+		if err := models.SetHiddenAnnotationPatterns(test.patterns); err != nil {
+			t.Fatal(err)
+		}
+		annotations := models.AnnotationsFromMap(map[string]string{test.name: "foo"})
+		if annotations[0].Visible == test.isHidden {
+			t.Errorf("Annotation %s with patterns %v has visible=%v, expected hidden=%v", test.name, test.patterns, annotations[0].Visible, test.isHidden)
+		}
+	}
+
+	if err := models.SetHiddenAnnotationPatterns([]string{"("}); err == nil {
+		t.Errorf("SetHiddenAnnotationPatterns() didn't return any error for an invalid regexp")
+	}
+}
@@ -5,6 +5,9 @@ type Filter struct {
 	Text    string `json:"text"`
 	Hits    int    `json:"hits"`
 	IsValid bool   `json:"isValid"`
+	// Error describes why the filter is invalid or will never match any alert,
+	// invalid filters are ignored
+	Error *FilterValidationError `json:"error,omitempty"`
 }
 
 // Color is used by UnseeLabelColor to reprenset colors as RGBA
@@ -38,6 +41,43 @@ type AlertsResponse struct {
 	Colors      LabelsColorMap         `json:"colors"`
 	Filters     []Filter               `json:"filters"`
 	Counters    LabelsCountMap         `json:"counters"`
+	// OmittedGroups is the number of alert groups that were removed from the
+	// response by the @group_limit filter
+	OmittedGroups int `json:"omittedGroups"`
+	// Macros lists all filter macros used in the query with the filter they
+	// were expanded to
+	Macros map[string]string `json:"macros"`
+	// CollectionVersion identifies the collection this response was generated
+	// from, it can be passed as the since argument to only get changes
+	CollectionVersion int64 `json:"collectionVersion"`
+	// Delta is true if only groups changed since requested version are
+	// included, RemovedGroups will list IDs of groups that should be removed
+	Delta         bool     `json:"delta"`
+	RemovedGroups []string `json:"removedGroups"`
+	// TotalGroups is the number of alert groups matching the query, groups
+	// will only include a page of those if offset or limit was passed
+	TotalGroups int `json:"totalGroups"`
+	Offset      int `json:"offset"`
+	Limit       int `json:"limit"`
+	// Grouping is the grouping mode requested using the grouping argument,
+	// Receivers is only populated if it's GroupingReceiver
+	Grouping  string           `json:"grouping"`
+	Receivers []ReceiverGroups `json:"receivers"`
+}
+
+// GroupingReceiver organizes alert groups by the receiver
+const GroupingReceiver = "receiver"
+
+// AnnotationsShared moves annotations with the same value on every alert in
+// a group to SharedAnnotations of that group
+const AnnotationsShared = "shared"
+
+// ReceiverGroups lists all alert groups matching the query that were routed
+// to the receiver, in the order they are returned
+type ReceiverGroups struct {
+	Receiver   string         `json:"receiver"`
+	Groups     []string       `json:"groups"`
+	StateCount map[string]int `json:"stateCount"`
 }
 
 // Autocomplete is the structure of autocomplete object for filter hints
@@ -45,4 +85,291 @@ type AlertsResponse struct {
 type Autocomplete struct {
 	Value  string   `json:"value"`
 	Tokens []string `json:"tokens"`
+	// Weight is used to rank hints, it's the number of alerts the hint was
+	// generated from
+	Weight int `json:"weight"`
+}
+
+// SuggestionLabelName is a suggestion for a label name
+const SuggestionLabelName = "labelName"
+
+// SuggestionKeyword is a suggestion for a special filter name, like @state
+const SuggestionKeyword = "keyword"
+
+// SuggestionOperator is a suggestion for the filter operator
+const SuggestionOperator = "operator"
+
+// SuggestionValue is a suggestion for the value of a label or special filter
+const SuggestionValue = "value"
+
+// Suggestion is a single typed autocomplete suggestion, Value is the
+// suggested part of the filter and Filter is the complete filter expression
+// that should replace what was typed
+type Suggestion struct {
+	Type   string `json:"type"`
+	Value  string `json:"value"`
+	Filter string `json:"filter"`
+	Weight int    `json:"weight"`
+}
+
+// SuggestionsResponse is the structure of JSON response for typed autocomplete
+type SuggestionsResponse struct {
+	// Context is the filter expression suggestions were generated for
+	Context     string       `json:"context"`
+	Suggestions []Suggestion `json:"suggestions"`
+}
+
+// AlertsSummary is the structure of JSON response for the summary endpoint, it
+// only includes alert counts so it's cheap to poll by status pages and badges
+type AlertsSummary struct {
+	Timestamp string `json:"timestamp"`
+	Total     int    `json:"total"`
+	Groups    int    `json:"groups"`
+	// States, Severities and Upstreams map state, severity label value and
+	// Alertmanager upstream name to the number of matching alerts
+	States     map[string]int `json:"states"`
+	Severities map[string]int `json:"severities"`
+	Upstreams  map[string]int `json:"upstreams"`
+	Filters    []Filter       `json:"filters"`
+}
+
+// LabelValueCount is the number of alerts with given label value
+type LabelValueCount struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// TopLabelValues is the structure of JSON response for the top label values
+// endpoint, Values are sorted by the number of alerts, most frequent first,
+// Total is the number of matching alerts with the label
+type TopLabelValues struct {
+	Timestamp string            `json:"timestamp"`
+	Label     string            `json:"label"`
+	Total     int               `json:"total"`
+	Values    []LabelValueCount `json:"values"`
+	Filters   []Filter          `json:"filters"`
+}
+
+// RefreshedUpstream is the result of collecting a single upstream requested
+// using the refresh endpoint, Error is empty if it was collected
+type RefreshedUpstream struct {
+	Name  string `json:"name"`
+	Error string `json:"error"`
+}
+
+// RefreshResponse is the structure of JSON response for the refresh endpoint,
+// Coalesced is true if the request joined a refresh that was already in
+// progress instead of starting a new one
+type RefreshResponse struct {
+	Timestamp string              `json:"timestamp"`
+	Coalesced bool                `json:"coalesced"`
+	Upstreams []RefreshedUpstream `json:"upstreams"`
+}
+
+// UpstreamCollection is the collection state of a single upstream
+type UpstreamCollection struct {
+	Name   string `json:"name"`
+	Paused bool   `json:"paused"`
+}
+
+// CollectionResponse is the structure of JSON response for endpoints pausing
+// and resuming collection, it lists all upstreams the request was for
+type CollectionResponse struct {
+	Upstreams []UpstreamCollection `json:"upstreams"`
+}
+
+// AlertCounters is the structure of JSON response for the counters endpoint,
+// it only includes the total number of alerts and counts by state and it's
+// designed for very frequent polling, like by favicon badges
+type AlertCounters struct {
+	Total  int            `json:"total"`
+	States map[string]int `json:"states"`
+}
+
+// AlertCountsBucket holds alert counts recorded within a single time bucket,
+// Timestamp is the start of the bucket and Samples is the number of
+// collections recorded in it
+type AlertCountsBucket struct {
+	Timestamp  string         `json:"timestamp"`
+	Samples    int            `json:"samples"`
+	Total      int            `json:"total"`
+	States     map[string]int `json:"states"`
+	Severities map[string]int `json:"severities"`
+}
+
+// AlertCountsHistory is the structure of JSON response for the history
+// endpoint, Resolution and Range are in seconds
+type AlertCountsHistory struct {
+	Resolution int                 `json:"resolution"`
+	Range      int                 `json:"range"`
+	Buckets    []AlertCountsBucket `json:"buckets"`
+}
+
+// SavedFilter is a named filter expression stored on the server, so it can be
+// shared between users
+type SavedFilter struct {
+	Name   string `json:"name"`
+	Filter string `json:"filter"`
+}
+
+// UserSettings are UI preferences stored on the server for the authenticated
+// user, so they're the same in every browser
+type UserSettings struct {
+	DefaultFilter     string   `json:"defaultFilter"`
+	CollapsedGroups   []string `json:"collapsedGroups"`
+	HiddenAnnotations []string `json:"hiddenAnnotations"`
+}
+
+// AlertEvent describes a change to an alert detected after collecting latest
+// alerts from Alertmanager, events are pushed to clients subscribed to live
+// updates
+type AlertEvent struct {
+	Type     string            `json:"type"`
+	GroupID  string            `json:"groupID"`
+	Receiver string            `json:"receiver"`
+	Labels   map[string]string `json:"labels"`
+	Alert    Alert             `json:"alert"`
+}
+
+// FilterValidationError describes why a filter expression is invalid,
+// position is the index of the invalid part of the expression and expected
+// lists filter names, operators or the "value" token that would be valid there
+type FilterValidationError struct {
+	Reason   string   `json:"reason"`
+	Message  string   `json:"message"`
+	Position int      `json:"position"`
+	Expected []string `json:"expected,omitempty"`
+}
+
+// FilterValidation is the result of validating a single filter expression
+type FilterValidation struct {
+	Text    string                 `json:"text"`
+	IsValid bool                   `json:"isValid"`
+	Error   *FilterValidationError `json:"error,omitempty"`
+}
+
+// FilterValidationResponse is the response of the filter validation endpoint
+type FilterValidationResponse struct {
+	IsValid bool               `json:"isValid"`
+	Filters []FilterValidation `json:"filters"`
+}
+
+// FilterPreset is a named filter expression defined in the config
+type FilterPreset struct {
+	Name   string `json:"name"`
+	Filter string `json:"filter"`
+}
+
+// ShortURL is a short token that resolves back to the full filter expression,
+// so it's easier to share long filters in places that limit the link length
+type ShortURL struct {
+	Token  string `json:"token"`
+	URL    string `json:"url"`
+	Filter string `json:"filter"`
+}
+
+// VersionInfo describes the running unsee build
+type VersionInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildDate string `json:"buildDate"`
+	GoVersion string `json:"goVersion"`
+}
+
+// SilencePreview lists alerts that would be suppressed by a silence created on
+// an Alertmanager upstream
+type SilencePreview struct {
+	Alertmanager string `json:"alertmanager"`
+	// Allowed is false if the user isn't allowed to create this silence
+	Allowed bool `json:"allowed"`
+	// Total is the number of matched alerts
+	Total       int          `json:"total"`
+	AlertGroups []AlertGroup `json:"alertGroups"`
+}
+
+// UIConfig holds branding options used by the UI, so different instances can
+// be easily told apart
+type UIConfig struct {
+	Title   string `json:"title"`
+	LogoURL string `json:"logoURL"`
+	Banner  string `json:"banner"`
+	// Timezone is the name of the timezone used for timestamps formatted
+	// for display
+	Timezone string `json:"timezone"`
+	// LabelNames maps label names to names displayed in the UI, filters
+	// still use original label names
+	LabelNames map[string]string `json:"labelNames"`
+	// ReadOnly is true in demo mode, when all changes are rejected
+	ReadOnly bool `json:"readOnly"`
+}
+
+// UIDefaults holds default values of all UI options, those are used by
+// clients unless the user changed the option
+type UIDefaults struct {
+	Filter string `json:"filter"`
+	// RefreshInterval is the number of seconds between alert refreshes
+	RefreshInterval int                 `json:"refreshInterval"`
+	AutoRefresh     bool                `json:"autoRefresh"`
+	Flash           bool                `json:"flash"`
+	AppendTop       bool                `json:"appendTop"`
+	GroupByReceiver bool                `json:"groupByReceiver"`
+	Annotations     UIAnnotationsConfig `json:"annotations"`
+	Colors          UIColorsConfig      `json:"colors"`
+	Branding        UIConfig            `json:"branding"`
+}
+
+// UIAnnotationsConfig lists annotations that are hidden or visible by default
+type UIAnnotationsConfig struct {
+	DefaultHidden bool     `json:"defaultHidden"`
+	Hidden        []string `json:"hidden"`
+	HiddenRegex   []string `json:"hiddenRegex"`
+	Visible       []string `json:"visible"`
+}
+
+// UIColorsConfig lists label names that get colors in the UI
+type UIColorsConfig struct {
+	Unique []string `json:"unique"`
+	Static []string `json:"static"`
+}
+
+// AlertGroupDetails is the structure of JSON response for a single alert group
+type AlertGroupDetails struct {
+	AlertGroup
+	// Silences contains all silences muting any alert in the group
+	Silences map[string]Silence `json:"silences"`
+	// Alertmanagers lists all upstreams reporting any alert in the group
+	Alertmanagers []AlertmanagerAPIStatus `json:"alertmanagers"`
+}
+
+// SearchMatch is a single label or annotation value matching a free text
+// search, Type is either SearchMatchLabel or SearchMatchAnnotation
+type SearchMatch struct {
+	Type  string `json:"type"`
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// SearchMatchLabel is used for matching label values
+const SearchMatchLabel = "label"
+
+// SearchMatchAnnotation is used for matching annotation values
+const SearchMatchAnnotation = "annotation"
+
+// SearchResult is a single alert matching a free text search, alerts sent to
+// multiple receivers are only returned once with IDs of all their groups
+type SearchResult struct {
+	Alert   Alert         `json:"alert"`
+	Groups  []string      `json:"groups"`
+	Score   int           `json:"score"`
+	Matches []SearchMatch `json:"matches"`
+}
+
+// SearchResponse is the structure of JSON response for the search endpoint,
+// Total is the number of all matching alerts, Results only include the
+// highest ranked ones
+type SearchResponse struct {
+	Query   string         `json:"query"`
+	Tokens  []string       `json:"tokens"`
+	Total   int            `json:"total"`
+	Results []SearchResult `json:"results"`
 }
@@ -0,0 +1,72 @@
+package models
+
+import (
+	"sync"
+)
+
+// stringPool keeps a single copy of every string passed to it, so alerts
+// sharing the same label names and values will also share memory used to
+// store them, strings are kept in two generations and those not used since
+// the last rotation are dropped
+type stringPool struct {
+	lock     sync.Mutex
+	current  map[string]string
+	previous map[string]string
+}
+
+func (p *stringPool) intern(s string) string {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if v, found := p.current[s]; found {
+		return v
+	}
+	v, found := p.previous[s]
+	if !found {
+		v = s
+	}
+	p.current[v] = v
+	return v
+}
+
+func (p *stringPool) rotate() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.previous = p.current
+	p.current = map[string]string{}
+}
+
+func (p *stringPool) size() int {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	return len(p.current) + len(p.previous)
+}
+
+var labelStrings = &stringPool{
+	current:  map[string]string{},
+	previous: map[string]string{},
+}
+
+// Intern returns a shared copy of the string
+func Intern(s string) string {
+	return labelStrings.intern(s)
+}
+
+// InternLabels returns a copy of the labels map with all label names and
+// values interned
+func InternLabels(labels map[string]string) map[string]string {
+	interned := make(map[string]string, len(labels))
+	for k, v := range labels {
+		interned[Intern(k)] = Intern(v)
+	}
+	return interned
+}
+
+// RotateInterned drops all strings that weren't interned since the previous
+// call, it should be called after every collection so strings from resolved
+// alerts are released
+func RotateInterned() {
+	labelStrings.rotate()
+}
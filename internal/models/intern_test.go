@@ -0,0 +1,32 @@
+package models
+
+import (
+	"testing"
+)
+
+func TestIntern(t *testing.T) {
+	labelStrings.rotate()
+	labelStrings.rotate()
+
+	labels := InternLabels(map[string]string{"cluster": "prod", "job": "node"})
+	if labels["cluster"] != "prod" || labels["job"] != "node" {
+		t.Errorf("Interned labels don't match, got %v", labels)
+	}
+	if size := labelStrings.size(); size != 4 {
+		t.Errorf("Expected %d interned strings, got %d", 4, size)
+	}
+
+	// strings used since the last rotation are kept
+	labelStrings.rotate()
+	Intern("cluster")
+	labelStrings.rotate()
+	if size := labelStrings.size(); size != 1 {
+		t.Errorf("Expected %d interned strings after rotation, got %d", 1, size)
+	}
+
+	labelStrings.rotate()
+	labelStrings.rotate()
+	if size := labelStrings.size(); size != 0 {
+		t.Errorf("Expected %d interned strings after rotation, got %d", 0, size)
+	}
+}
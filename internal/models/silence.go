@@ -21,4 +21,82 @@ type Silence struct {
 	// unsee fields
 	JiraID  string `json:"jiraID"`
 	JiraURL string `json:"jiraURL"`
+	// timestamps formatted for display using TIMEZONE
+	StartsAtDisplay  string `json:"startsAtDisplay"`
+	EndsAtDisplay    string `json:"endsAtDisplay"`
+	CreatedAtDisplay string `json:"createdAtDisplay"`
+}
+
+// SilenceStateActive means that the silence is in effect
+const SilenceStateActive = "active"
+
+// SilenceStatePending means that the silence will start in the future
+const SilenceStatePending = "pending"
+
+// SilenceStateExpired means that the silence already ended
+const SilenceStateExpired = "expired"
+
+// SilenceStateList exports all silence states so other packages can get this
+// list
+var SilenceStateList = []string{
+	SilenceStateActive,
+	SilenceStatePending,
+	SilenceStateExpired,
+}
+
+// ManagedSilence is a silence collected from one or more Alertmanager
+// upstreams, with the number of alerts it currently matches
+type ManagedSilence struct {
+	Silence
+	State         string   `json:"state"`
+	Alertmanagers []string `json:"alertmanagers"`
+	AlertCount    int      `json:"alertCount"`
+	// Conflicts lists inconsistencies of the silence between members of
+	// Alertmanager HA clusters
+	Conflicts []SilenceConflict `json:"conflicts"`
+	// Recreate is only set for expired silences, it's the body of a request
+	// creating the same silence again, with the same duration starting now
+	Recreate *SilencePayload `json:"recreate,omitempty"`
+}
+
+// SilencePayload is the body of a request creating a new silence, it can be
+// sent to the silence creation endpoint as is
+type SilencePayload struct {
+	Matchers []struct {
+		Name    string `json:"name"`
+		Value   string `json:"value"`
+		IsRegex bool   `json:"isRegex"`
+	} `json:"matchers"`
+	StartsAt  time.Time `json:"startsAt"`
+	EndsAt    time.Time `json:"endsAt"`
+	CreatedBy string    `json:"createdBy"`
+	Comment   string    `json:"comment"`
+}
+
+// SilenceConflictMissing is used when the silence is missing on some members
+// of the cluster
+const SilenceConflictMissing = "missing"
+
+// SilenceConflictEndsAt is used when members of the cluster have different end
+// time for the silence
+const SilenceConflictEndsAt = "endsAt"
+
+// SilenceConflict describes an inconsistency of a silence between members of
+// an Alertmanager HA cluster, Alertmanagers lists cluster members where the
+// silence is missing or ends earlier than on other members
+type SilenceConflict struct {
+	Cluster       string   `json:"cluster"`
+	Type          string   `json:"type"`
+	Alertmanagers []string `json:"alertmanagers"`
+}
+
+// StateAt returns the state of the silence at given time
+func (s Silence) StateAt(now time.Time) string {
+	if now.Before(s.StartsAt) {
+		return SilenceStatePending
+	}
+	if !s.EndsAt.IsZero() && now.After(s.EndsAt) {
+		return SilenceStateExpired
+	}
+	return SilenceStateActive
 }
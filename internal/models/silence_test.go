@@ -0,0 +1,54 @@
+package models_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cloudflare/unsee/internal/models"
+)
+
+type silenceStateTest struct {
+	silence models.Silence
+	state   string
+}
+
+var silenceNow = time.Date(2017, 10, 1, 12, 0, 0, 0, time.UTC)
+
+var silenceStateTests = []silenceStateTest{
+	silenceStateTest{
+		silence: models.Silence{
+			StartsAt: silenceNow.Add(-time.Hour),
+			EndsAt:   silenceNow.Add(time.Hour),
+		},
+		state: models.SilenceStateActive,
+	},
+	silenceStateTest{
+		silence: models.Silence{
+			StartsAt: silenceNow.Add(time.Minute),
+			EndsAt:   silenceNow.Add(time.Hour),
+		},
+		state: models.SilenceStatePending,
+	},
+	silenceStateTest{
+		silence: models.Silence{
+			StartsAt: silenceNow.Add(-time.Hour),
+			EndsAt:   silenceNow.Add(-time.Minute),
+		},
+		state: models.SilenceStateExpired,
+	},
+	silenceStateTest{
+		silence: models.Silence{
+			StartsAt: silenceNow.Add(-time.Hour),
+		},
+		state: models.SilenceStateActive,
+	},
+}
+
+func TestSilenceStateAt(t *testing.T) {
+	for _, testCase := range silenceStateTests {
+		state := testCase.silence.StateAt(silenceNow)
+		if state != testCase.state {
+			t.Errorf("StateAt() returned '%s' for %v, expected '%s'", state, testCase.silence, testCase.state)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package openapi_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/cloudflare/unsee/internal/openapi"
+)
+
+type embedded struct {
+	Embedded string `json:"embedded"`
+}
+
+type child struct {
+	Name   string `json:"name"`
+	Parent *node  `json:"parent"`
+}
+
+type node struct {
+	embedded
+	ID       string            `json:"id"`
+	Count    int               `json:"count"`
+	Version  int64             `json:"version"`
+	Ratio    float64           `json:"ratio"`
+	Enabled  bool              `json:"enabled"`
+	Created  time.Time         `json:"created"`
+	Labels   map[string]string `json:"labels"`
+	Children []child           `json:"children"`
+	Inline   struct {
+		Value string `json:"value"`
+	} `json:"inline"`
+	Untagged string
+	Hidden   string `json:"-"`
+	private  string
+}
+
+type schemaTest struct {
+	property string
+	schema   string
+}
+
+var schemaTests = []schemaTest{
+	{property: "embedded", schema: `{"type":"string"}`},
+	{property: "id", schema: `{"type":"string"}`},
+	{property: "count", schema: `{"type":"integer"}`},
+	{property: "version", schema: `{"type":"integer","format":"int64"}`},
+	{property: "ratio", schema: `{"type":"number"}`},
+	{property: "enabled", schema: `{"type":"boolean"}`},
+	{property: "created", schema: `{"type":"string","format":"date-time"}`},
+	{property: "labels", schema: `{"type":"object","additionalProperties":{"type":"string"}}`},
+	{property: "children", schema: `{"type":"array","items":{"$ref":"#/components/schemas/child"}}`},
+	{property: "inline", schema: `{"type":"object","properties":{"value":{"type":"string"}}}`},
+	{property: "Untagged", schema: `{"type":"string"}`},
+}
+
+func TestSchemaFor(t *testing.T) {
+	doc := openapi.NewDocument(openapi.Info{Title: "test", Version: "1"})
+	ref := doc.SchemaFor(node{})
+	if ref.Ref != "#/components/schemas/node" {
+		t.Fatalf("Invalid reference: %s", ref.Ref)
+	}
+
+	s, found := doc.Components.Schemas["node"]
+	if !found {
+		t.Fatal("node schema wasn't added to components")
+	}
+	for _, testCase := range schemaTests {
+		p, found := s.Properties[testCase.property]
+		if !found {
+			t.Errorf("Property '%s' is missing", testCase.property)
+			continue
+		}
+		data, _ := json.Marshal(p)
+		if string(data) != testCase.schema {
+			t.Errorf("Invalid schema for '%s', expected %s, got %s", testCase.property, testCase.schema, data)
+		}
+	}
+	for _, name := range []string{"Hidden", "private"} {
+		if _, found := s.Properties[name]; found {
+			t.Errorf("Property '%s' shouldn't be included", name)
+		}
+	}
+	if len(s.Properties) != len(schemaTests) {
+		t.Errorf("Got %d properties, expected %d", len(s.Properties), len(schemaTests))
+	}
+
+	c, found := doc.Components.Schemas["child"]
+	if !found {
+		t.Fatal("child schema wasn't added to components")
+	}
+	if c.Properties["parent"].Ref != "#/components/schemas/node" {
+		t.Errorf("Invalid reference for recursive type: %v", c.Properties["parent"])
+	}
+}
+
+func TestSchemaPrefixes(t *testing.T) {
+	doc := openapi.NewDocument(openapi.Info{Title: "test", Version: "1"})
+	doc.SchemaPrefixes["github.com/cloudflare/unsee/internal/openapi_test"] = "Test"
+	if ref := doc.SchemaFor(child{}); ref.Ref != "#/components/schemas/Testchild" {
+		t.Errorf("Invalid reference: %s", ref.Ref)
+	}
+	if _, found := doc.Components.Schemas["child"]; found {
+		t.Errorf("child schema was added to components without the prefix")
+	}
+}
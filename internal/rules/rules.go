@@ -0,0 +1,164 @@
+// Package rules links alerts to the Prometheus alerting rules that generated
+// them, the rule expression is parsed from the generatorURL of every alert and
+// the rest of the rule definition can be looked up using the Prometheus API
+package rules
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudflare/unsee/internal/models"
+	"github.com/cloudflare/unsee/internal/transport"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// apiRule is a single rule returned by /api/v1/rules
+type apiRule struct {
+	Type     string  `json:"type"`
+	Name     string  `json:"name"`
+	Query    string  `json:"query"`
+	Duration float64 `json:"duration"`
+}
+
+// apiRuleGroup is a single rule group returned by /api/v1/rules
+type apiRuleGroup struct {
+	Name  string    `json:"name"`
+	File  string    `json:"file"`
+	Rules []apiRule `json:"rules"`
+}
+
+type apiRulesResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error"`
+	Data   struct {
+		Groups []apiRuleGroup `json:"groups"`
+	} `json:"data"`
+}
+
+// cachedRules are rule groups fetched from a single Prometheus server, failed
+// requests are also cached, so a Prometheus that's down isn't queried for
+// every alert
+type cachedRules struct {
+	groups    []apiRuleGroup
+	err       error
+	fetchedAt time.Time
+}
+
+var lookup = struct {
+	sync.Mutex
+	enabled bool
+	timeout time.Duration
+	ttl     time.Duration
+	// Prometheus URL -> rules
+	cache map[string]cachedRules
+}{cache: map[string]cachedRules{}}
+
+// Setup configures rule lookups, if enabled rules are fetched from Prometheus
+// servers using the timeout and cached for ttl
+func Setup(enabled bool, timeout, ttl time.Duration) {
+	lookup.Lock()
+	defer lookup.Unlock()
+	lookup.enabled = enabled
+	lookup.timeout = timeout
+	lookup.ttl = ttl
+	lookup.cache = map[string]cachedRules{}
+}
+
+// Parse returns the rule with the Prometheus URL and the expression parsed from
+// given generatorURL, generatorURL is expected to link to the /graph page of
+// the Prometheus UI
+func Parse(generatorURL string) (*models.AlertRule, error) {
+	u, err := url.Parse(generatorURL)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid generatorURL '%s': %s", generatorURL, err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("Invalid generatorURL '%s', scheme or host is missing", generatorURL)
+	}
+	expr := u.Query().Get("g0.expr")
+	if expr == "" {
+		return nil, fmt.Errorf("Invalid generatorURL '%s', g0.expr query parameter is missing", generatorURL)
+	}
+	path := u.Path
+	if i := strings.LastIndex(path, "/graph"); i >= 0 {
+		path = path[:i]
+	}
+	prometheus := url.URL{Scheme: u.Scheme, User: u.User, Host: u.Host, Path: path}
+	return &models.AlertRule{
+		URL:        generatorURL,
+		Prometheus: strings.TrimSuffix(prometheus.String(), "/"),
+		Expression: expr,
+	}, nil
+}
+
+// getRules returns rule groups fetched from the Prometheus server, they're
+// fetched again once cached rules are older than the TTL
+func getRules(prometheus string) ([]apiRuleGroup, error) {
+	lookup.Lock()
+	defer lookup.Unlock()
+
+	if cached, found := lookup.cache[prometheus]; found && time.Since(cached.fetchedAt) < lookup.ttl {
+		return cached.groups, cached.err
+	}
+
+	resp := apiRulesResponse{}
+	err := transport.ReadJSON(prometheus+"/api/v1/rules", lookup.timeout, &resp)
+	if err == nil && resp.Status != "success" {
+		err = fmt.Errorf("Prometheus returned status '%s': %s", resp.Status, resp.Error)
+	}
+	if err != nil {
+		log.Errorf("Failed to fetch rules from %s: %s", prometheus, err)
+	}
+	lookup.cache[prometheus] = cachedRules{groups: resp.Data.Groups, err: err, fetchedAt: time.Now()}
+	return resp.Data.Groups, err
+}
+
+// Lookup returns the rule that generated given alert, or nil if the alert
+// doesn't have a valid generatorURL, if lookups are enabled the rule is
+// searched on the Prometheus server by the alertname label and the expression
+func Lookup(alert *models.Alert) *models.AlertRule {
+	generatorURL := alert.GeneratorURL
+	for _, am := range alert.Alertmanager {
+		if generatorURL != "" {
+			break
+		}
+		generatorURL = am.Source
+	}
+	if generatorURL == "" {
+		return nil
+	}
+
+	rule, err := Parse(generatorURL)
+	if err != nil {
+		return nil
+	}
+
+	lookup.Lock()
+	enabled := lookup.enabled
+	lookup.Unlock()
+	if !enabled {
+		return rule
+	}
+
+	groups, err := getRules(rule.Prometheus)
+	if err != nil {
+		return rule
+	}
+	name := alert.Labels["alertname"]
+	for _, group := range groups {
+		for _, r := range group.Rules {
+			if r.Type == "alerting" && r.Name == name && r.Query == rule.Expression {
+				rule.Name = r.Name
+				rule.Group = group.Name
+				rule.File = group.File
+				rule.Duration = time.Duration(r.Duration * float64(time.Second)).String()
+				return rule
+			}
+		}
+	}
+	return rule
+}
@@ -0,0 +1,125 @@
+package rules_test
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cloudflare/unsee/internal/models"
+	"github.com/cloudflare/unsee/internal/rules"
+
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/jarcoal/httpmock.v1"
+)
+
+const generatorURL = "http://prometheus.example.com:9090/graph?g0.expr=up+%3D%3D+0&g0.tab=1"
+
+func TestParse(t *testing.T) {
+	for _, testCase := range []struct {
+		generatorURL string
+		rule         *models.AlertRule
+	}{
+		{
+			generatorURL: generatorURL,
+			rule: &models.AlertRule{
+				URL:        generatorURL,
+				Prometheus: "http://prometheus.example.com:9090",
+				Expression: "up == 0",
+			},
+		},
+		{
+			generatorURL: "https://example.com/prometheus/graph?g0.expr=rate%28errors%5B5m%5D%29+%3E+1",
+			rule: &models.AlertRule{
+				URL:        "https://example.com/prometheus/graph?g0.expr=rate%28errors%5B5m%5D%29+%3E+1",
+				Prometheus: "https://example.com/prometheus",
+				Expression: "rate(errors[5m]) > 1",
+			},
+		},
+		{generatorURL: "localhost/prometheus"},
+		{generatorURL: "http://prometheus.example.com:9090/graph"},
+		{generatorURL: "http://[::1"},
+	} {
+		rule, err := rules.Parse(testCase.generatorURL)
+		if (err == nil) != (testCase.rule != nil) {
+			t.Errorf("[%s] Parse() returned error: %v", testCase.generatorURL, err)
+		}
+		if !reflect.DeepEqual(rule, testCase.rule) {
+			t.Errorf("[%s] Got rule %+v, expected %+v", testCase.generatorURL, rule, testCase.rule)
+		}
+	}
+}
+
+func TestLookup(t *testing.T) {
+	log.SetLevel(log.ErrorLevel)
+	defer rules.Setup(false, 0, 0)
+
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	requests := 0
+	httpmock.RegisterResponder("GET", "http://prometheus.example.com:9090/api/v1/rules", func(req *http.Request) (*http.Response, error) {
+		requests++
+		return httpmock.NewStringResponse(200, `{"status": "success", "data": {"groups": [
+			{"name": "recording", "file": "/etc/prometheus/recording.yaml", "rules": [
+				{"type": "recording", "name": "InstanceDown", "query": "up == 0"}
+			]},
+			{"name": "instances", "file": "/etc/prometheus/alerts.yaml", "rules": [
+				{"type": "alerting", "name": "InstanceDown", "query": "up == 1", "duration": 60},
+				{"type": "alerting", "name": "InstanceDown", "query": "up == 0", "duration": 300}
+			]}
+		]}}`), nil
+	})
+	httpmock.RegisterResponder("GET", "http://down.example.com/api/v1/rules", httpmock.NewStringResponder(503, "{}"))
+
+	alert := models.Alert{
+		Labels:       map[string]string{"alertname": "InstanceDown"},
+		Alertmanager: []models.AlertmanagerInstance{{Name: "default", Source: generatorURL}},
+	}
+	parsed := &models.AlertRule{
+		URL:        generatorURL,
+		Prometheus: "http://prometheus.example.com:9090",
+		Expression: "up == 0",
+	}
+	found := &models.AlertRule{
+		URL:        generatorURL,
+		Prometheus: "http://prometheus.example.com:9090",
+		Expression: "up == 0",
+		Name:       "InstanceDown",
+		Group:      "instances",
+		File:       "/etc/prometheus/alerts.yaml",
+		Duration:   "5m0s",
+	}
+
+	// lookups are disabled by default
+	if rule := rules.Lookup(&alert); !reflect.DeepEqual(rule, parsed) {
+		t.Errorf("Got rule %+v, expected %+v", rule, parsed)
+	}
+	if requests != 0 {
+		t.Errorf("Sent %d request(s) with lookups disabled", requests)
+	}
+
+	rules.Setup(true, time.Second, time.Minute)
+	for i := 0; i < 2; i++ {
+		if rule := rules.Lookup(&alert); !reflect.DeepEqual(rule, found) {
+			t.Errorf("Got rule %+v, expected %+v", rule, found)
+		}
+	}
+	// rules are cached
+	if requests != 1 {
+		t.Errorf("Sent %d request(s), expected 1", requests)
+	}
+
+	unknown := models.Alert{Labels: map[string]string{"alertname": "Foo"}, GeneratorURL: generatorURL}
+	if rule := rules.Lookup(&unknown); rule == nil || rule.Name != "" {
+		t.Errorf("Got rule %+v for an alert without a rule", rule)
+	}
+
+	down := models.Alert{Labels: map[string]string{"alertname": "InstanceDown"}, GeneratorURL: "http://down.example.com/graph?g0.expr=up+%3D%3D+0"}
+	if rule := rules.Lookup(&down); rule == nil || rule.Expression != "up == 0" || rule.Name != "" {
+		t.Errorf("Got rule %+v when Prometheus is down", rule)
+	}
+
+	if rule := rules.Lookup(&models.Alert{}); rule != nil {
+		t.Errorf("Got rule %+v for an alert without generatorURL", rule)
+	}
+}
@@ -19,3 +19,15 @@ func StringInSlice(stringArray []string, value string) bool {
 	}
 	return false
 }
+
+// NonEmptyStrings returns all strings from the slice that are not empty, it
+// never returns nil
+func NonEmptyStrings(stringArray []string) []string {
+	result := []string{}
+	for _, s := range stringArray {
+		if s != "" {
+			result = append(result, s)
+		}
+	}
+	return result
+}
@@ -1,6 +1,7 @@
 package slices_test
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/cloudflare/unsee/internal/slices"
@@ -111,3 +112,19 @@ func TestBoolInSlice(t *testing.T) {
 		}
 	}
 }
+
+func TestNonEmptyStrings(t *testing.T) {
+	for _, testCase := range []struct {
+		array    []string
+		expected []string
+	}{
+		{array: nil, expected: []string{}},
+		{array: []string{""}, expected: []string{}},
+		{array: []string{"a", "", "b"}, expected: []string{"a", "b"}},
+	} {
+		result := slices.NonEmptyStrings(testCase.array)
+		if !reflect.DeepEqual(result, testCase.expected) {
+			t.Errorf("NonEmptyStrings(%v) returned %v, expected %v", testCase.array, result, testCase.expected)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+// Package statsexport pushes alert counts as gauges to StatsD or Graphite,
+// for dashboards in parts of the infrastructure not scraped by Prometheus
+package statsexport
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// ProtocolStatsd sends gauges to StatsD over UDP
+	ProtocolStatsd = "statsd"
+	// ProtocolGraphite sends gauges to Graphite using the plaintext protocol
+	// over TCP
+	ProtocolGraphite = "graphite"
+)
+
+// maximum size of a single StatsD packet, gauges are split into multiple
+// packets so they won't be fragmented on common networks
+const maxPacketSize = 1432
+
+// Exporter sends gauges to a single StatsD or Graphite server
+type Exporter struct {
+	protocol string
+	address  string
+	prefix   string
+	timeout  time.Duration
+}
+
+// New returns an Exporter sending gauges to address using given protocol,
+// names of all gauges are prefixed with prefix
+func New(protocol, address, prefix string, timeout time.Duration) (*Exporter, error) {
+	if protocol != ProtocolStatsd && protocol != ProtocolGraphite {
+		return nil, fmt.Errorf("unsupported protocol '%s', supported protocols: %s, %s", protocol, ProtocolStatsd, ProtocolGraphite)
+	}
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid address '%s': %s", address, err)
+	}
+	if host == "" || port == "" {
+		return nil, fmt.Errorf("invalid address '%s', expected format 'host:port'", address)
+	}
+	return &Exporter{
+		protocol: protocol,
+		address:  address,
+		prefix:   strings.Trim(prefix, "."),
+		timeout:  timeout,
+	}, nil
+}
+
+// sanitize replaces all characters that aren't safe to use in a metric name
+// component, dots separate components and colons or pipes have special
+// meaning in the StatsD protocol
+func sanitize(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '-':
+			return r
+		}
+		return '_'
+	}, s)
+}
+
+// MetricName joins parts into a dot separated metric name, every part is
+// sanitized so label values can be used as parts
+func MetricName(parts ...string) string {
+	sanitized := make([]string, 0, len(parts))
+	for _, p := range parts {
+		sanitized = append(sanitized, sanitize(p))
+	}
+	return strings.Join(sanitized, ".")
+}
+
+func (e *Exporter) name(metric string) string {
+	if e.prefix == "" {
+		return metric
+	}
+	return e.prefix + "." + metric
+}
+
+// lines returns gauges formatted for the configured protocol, sorted by name
+func (e *Exporter) lines(gauges map[string]int, now time.Time) []string {
+	names := make([]string, 0, len(gauges))
+	for name := range gauges {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	lines := make([]string, 0, len(names))
+	for _, name := range names {
+		switch e.protocol {
+		case ProtocolStatsd:
+			lines = append(lines, fmt.Sprintf("%s:%d|g\n", e.name(name), gauges[name]))
+		case ProtocolGraphite:
+			lines = append(lines, fmt.Sprintf("%s %d %d\n", e.name(name), gauges[name], now.Unix()))
+		}
+	}
+	return lines
+}
+
+// Send pushes all gauges to the server, gauge names should be created using
+// MetricName, now is used as the timestamp of Graphite data points
+func (e *Exporter) Send(gauges map[string]int, now time.Time) error {
+	if len(gauges) == 0 {
+		return nil
+	}
+
+	network := "tcp"
+	if e.protocol == ProtocolStatsd {
+		network = "udp"
+	}
+	conn, err := net.DialTimeout(network, e.address, e.timeout)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if e.timeout > 0 {
+		conn.SetDeadline(time.Now().Add(e.timeout))
+	}
+
+	if e.protocol == ProtocolGraphite {
+		_, err = conn.Write([]byte(strings.Join(e.lines(gauges, now), "")))
+		return err
+	}
+
+	var packet bytes.Buffer
+	for _, line := range e.lines(gauges, now) {
+		if packet.Len() > 0 && packet.Len()+len(line) > maxPacketSize {
+			if _, err = conn.Write(packet.Bytes()); err != nil {
+				return err
+			}
+			packet.Reset()
+		}
+		packet.WriteString(line)
+	}
+	_, err = conn.Write(packet.Bytes())
+	return err
+}
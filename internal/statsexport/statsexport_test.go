@@ -0,0 +1,156 @@
+package statsexport_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudflare/unsee/internal/statsexport"
+)
+
+var gauges = map[string]int{
+	statsexport.MetricName("alerts", "total"):                   3,
+	statsexport.MetricName("alerts", "severity", "critical"):    2,
+	statsexport.MetricName("alerts", "upstream", "am1.example"): 1,
+}
+
+func TestMetricName(t *testing.T) {
+	type testCaseT struct {
+		parts    []string
+		expected string
+	}
+	for _, testCase := range []testCaseT{
+		{parts: []string{"alerts", "total"}, expected: "alerts.total"},
+		{parts: []string{"alerts", "upstream", "am1.example.com:9093"}, expected: "alerts.upstream.am1_example_com_9093"},
+		{parts: []string{"alerts", "severity", "page|now"}, expected: "alerts.severity.page_now"},
+		{parts: []string{"alerts", "state", "un-processed_1"}, expected: "alerts.state.un-processed_1"},
+	} {
+		if name := statsexport.MetricName(testCase.parts...); name != testCase.expected {
+			t.Errorf("[%v] Expected metric name '%s', got '%s'", testCase.parts, testCase.expected, name)
+		}
+	}
+}
+
+func TestNewErrors(t *testing.T) {
+	type testCaseT struct {
+		protocol string
+		address  string
+	}
+	for _, testCase := range []testCaseT{
+		{protocol: "collectd", address: "localhost:8125"},
+		{protocol: statsexport.ProtocolStatsd, address: "localhost"},
+		{protocol: statsexport.ProtocolGraphite, address: ":2003"},
+		{protocol: statsexport.ProtocolGraphite, address: "localhost:"},
+	} {
+		if _, err := statsexport.New(testCase.protocol, testCase.address, "unsee", time.Second); err == nil {
+			t.Errorf("[%s %s] Expected an error, got nil", testCase.protocol, testCase.address)
+		}
+	}
+}
+
+func TestSendStatsd(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	e, err := statsexport.New(statsexport.ProtocolStatsd, conn.LocalAddr().String(), "unsee.", time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = e.Send(gauges, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := make([]byte, 2048)
+	conn.SetReadDeadline(time.Now().Add(time.Second * 5))
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "unsee.alerts.severity.critical:2|g\nunsee.alerts.total:3|g\nunsee.alerts.upstream.am1_example:1|g\n"
+	if string(buf[:n]) != expected {
+		t.Errorf("Expected packet %q, got %q", expected, string(buf[:n]))
+	}
+}
+
+func TestSendStatsdSplitsPackets(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	many := map[string]int{}
+	for i := 0; i < 200; i++ {
+		many[statsexport.MetricName("alerts", "upstream", fmt.Sprintf("upstream%03d", i))] = i
+	}
+	e, err := statsexport.New(statsexport.ProtocolStatsd, conn.LocalAddr().String(), "unsee", time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = e.Send(many, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := 0
+	packets := 0
+	buf := make([]byte, 2048)
+	for lines < len(many) {
+		conn.SetReadDeadline(time.Now().Add(time.Second * 5))
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			t.Fatalf("Got %d lines in %d packets, expected %d lines: %s", lines, packets, len(many), err)
+		}
+		if n > 1432 {
+			t.Errorf("Packet #%d is %d bytes long, expected at most 1432", packets, n)
+		}
+		packets++
+		lines += strings.Count(string(buf[:n]), "\n")
+	}
+	if packets < 2 {
+		t.Errorf("Expected gauges to be split into multiple packets, got %d", packets)
+	}
+}
+
+func TestSendGraphite(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	received := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			received <- err.Error()
+			return
+		}
+		defer conn.Close()
+		body, _ := ioutil.ReadAll(conn)
+		received <- string(body)
+	}()
+
+	e, err := statsexport.New(statsexport.ProtocolGraphite, listener.Addr().String(), "", time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = e.Send(gauges, time.Unix(1500000000, 0)); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "alerts.severity.critical 2 1500000000\nalerts.total 3 1500000000\nalerts.upstream.am1_example 1 1500000000\n"
+	select {
+	case body := <-received:
+		if body != expected {
+			t.Errorf("Expected %q, got %q", expected, body)
+		}
+	case <-time.After(time.Second * 5):
+		t.Error("Timed out waiting for Graphite data points")
+	}
+}
@@ -0,0 +1,134 @@
+// Package store implements a simple key/value store used to keep user data
+// like saved filters, values are grouped into buckets and can be persisted to
+// a JSON file on disk
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// ErrNotFound is returned when requested key doesn't exist in the store
+var ErrNotFound = errors.New("Key not found")
+
+// Store holds all stored values, if path is set all changes will be written
+// to it and loaded back when a new Store is created
+type Store struct {
+	path string
+	lock sync.RWMutex
+	data map[string]map[string]json.RawMessage
+}
+
+// New creates a new instance of the Store, pass an empty path to only keep
+// data in memory
+func New(path string) (*Store, error) {
+	s := Store{
+		path: path,
+		data: map[string]map[string]json.RawMessage{},
+	}
+	if path == "" {
+		return &s, nil
+	}
+
+	content, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(content) > 0 {
+		err = json.Unmarshal(content, &s.data)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &s, nil
+}
+
+// Get will decode value stored under given key into target
+func (s *Store) Get(bucket, key string, target interface{}) error {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	raw, found := s.data[bucket][key]
+	if !found {
+		return ErrNotFound
+	}
+	return json.Unmarshal(raw, target)
+}
+
+// Set will store the value under given key, replacing any existing value
+func (s *Store) Set(bucket, key string, value interface{}) error {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if _, found := s.data[bucket]; !found {
+		s.data[bucket] = map[string]json.RawMessage{}
+	}
+	s.data[bucket][key] = raw
+	return s.save()
+}
+
+// Delete removes given key from the store
+func (s *Store) Delete(bucket, key string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if _, found := s.data[bucket][key]; !found {
+		return ErrNotFound
+	}
+	delete(s.data[bucket], key)
+	return s.save()
+}
+
+// Keys returns a sorted list of all keys in given bucket
+func (s *Store) Keys(bucket string) []string {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	keys := []string{}
+	for key := range s.data[bucket] {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// save writes all data to a temporary file first and then renames it, so we
+// never leave a partially written file behind, caller must hold the lock
+func (s *Store) save() error {
+	if s.path == "" {
+		return nil
+	}
+
+	content, err := json.Marshal(s.data)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(s.path), filepath.Base(s.path))
+	if err != nil {
+		return err
+	}
+	if _, err = tmp.Write(content); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
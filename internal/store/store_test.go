@@ -0,0 +1,92 @@
+package store_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"reflect"
+	"testing"
+
+	"github.com/cloudflare/unsee/internal/store"
+)
+
+type storeValue struct {
+	Name  string
+	Count int
+}
+
+func TestStoreInMemory(t *testing.T) {
+	s, err := store.New("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v := storeValue{}
+	if err := s.Get("bucket", "missing", &v); err != store.ErrNotFound {
+		t.Errorf("Get() on missing key returned %v, expected ErrNotFound", err)
+	}
+
+	if err := s.Set("bucket", "foo", storeValue{Name: "foo", Count: 1}); err != nil {
+		t.Error(err)
+	}
+	if err := s.Set("bucket", "bar", storeValue{Name: "bar", Count: 2}); err != nil {
+		t.Error(err)
+	}
+	if err := s.Get("bucket", "foo", &v); err != nil {
+		t.Error(err)
+	}
+	if v.Name != "foo" || v.Count != 1 {
+		t.Errorf("Get() returned %v, expected foo=1", v)
+	}
+
+	keys := s.Keys("bucket")
+	if !reflect.DeepEqual(keys, []string{"bar", "foo"}) {
+		t.Errorf("Keys() returned %v", keys)
+	}
+	if keys := s.Keys("other"); len(keys) != 0 {
+		t.Errorf("Keys() on empty bucket returned %v", keys)
+	}
+
+	if err := s.Delete("bucket", "foo"); err != nil {
+		t.Error(err)
+	}
+	if err := s.Delete("bucket", "foo"); err != store.ErrNotFound {
+		t.Errorf("Delete() on missing key returned %v, expected ErrNotFound", err)
+	}
+}
+
+func TestStorePersistence(t *testing.T) {
+	dir, err := ioutil.TempDir("", "unsee-store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	p := path.Join(dir, "store.json")
+
+	s, err := store.New(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Set("bucket", "foo", storeValue{Name: "foo", Count: 5}); err != nil {
+		t.Error(err)
+	}
+
+	s, err = store.New(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := storeValue{}
+	if err := s.Get("bucket", "foo", &v); err != nil {
+		t.Error(err)
+	}
+	if v.Count != 5 {
+		t.Errorf("Value wasn't loaded from disk, got %v", v)
+	}
+
+	if err := ioutil.WriteFile(p, []byte("{invalid"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.New(p); err == nil {
+		t.Error("New() didn't fail with invalid store file")
+	}
+}
@@ -0,0 +1,87 @@
+package transform
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/cloudflare/unsee/internal/models"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type annotationTemplate struct {
+	Name     string
+	Template *template.Template
+}
+
+var annotationTemplates = []annotationTemplate{}
+
+// ParseAnnotationTemplates will parse and validate the list of annotation
+// templates provided from config, valid templates will be stored for future
+// use in InjectAnnotations() calls
+// Each template is in the name:template format, name is the name of the
+// annotation and template can reference alert labels, like {{ .cluster }}
+func ParseAnnotationTemplates(templates []string) error {
+	parsed := []annotationTemplate{}
+	names := map[string]bool{}
+	for _, s := range templates {
+		if s == "" {
+			continue
+		}
+		ss := strings.SplitN(s, ":", 2)
+		if len(ss) != 2 || ss[0] == "" || ss[1] == "" {
+			return fmt.Errorf("Invalid annotation template '%s', expected format 'name:template'", s)
+		}
+		if names[ss[0]] {
+			return fmt.Errorf("Duplicated annotation template '%s'", ss[0])
+		}
+		names[ss[0]] = true
+		// labels missing from the alert fail the template, so the annotation is
+		// only added to alerts with all labels it references
+		tmpl, err := template.New(ss[0]).Option("missingkey=error").Parse(ss[1])
+		if err != nil {
+			return fmt.Errorf("Invalid annotation template '%s': %s", s, err)
+		}
+		parsed = append(parsed, annotationTemplate{Name: ss[0], Template: tmpl})
+	}
+	annotationTemplates = parsed
+	return nil
+}
+
+// InjectAnnotations adds annotations rendered from templates over alert labels,
+// annotations the alert already has are left untouched, as are templates
+// referencing labels the alert doesn't have
+func InjectAnnotations(alert *models.Alert) {
+	if len(annotationTemplates) == 0 {
+		return
+	}
+	existing := map[string]bool{}
+	for _, a := range alert.Annotations {
+		existing[a.Name] = true
+	}
+	rendered := map[string]string{}
+	for _, at := range annotationTemplates {
+		if existing[at.Name] {
+			continue
+		}
+		var buf bytes.Buffer
+		if err := at.Template.Execute(&buf, alert.Labels); err != nil {
+			log.Debugf("Skipping annotation template '%s': %s", at.Name, err)
+			continue
+		}
+		if buf.Len() > 0 {
+			rendered[at.Name] = buf.String()
+		}
+	}
+	if len(rendered) == 0 {
+		return
+	}
+	annotations := make(models.Annotations, 0, len(alert.Annotations)+len(rendered))
+	annotations = append(annotations, alert.Annotations...)
+	annotations = append(annotations, models.AnnotationsFromMap(rendered)...)
+	sort.Sort(annotations)
+	alert.Annotations = annotations
+}
@@ -0,0 +1,79 @@
+package transform_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/cloudflare/unsee/internal/models"
+	"github.com/cloudflare/unsee/internal/transform"
+)
+
+type annotationTemplateTest struct {
+	labels      map[string]string
+	annotations map[string]string
+	expected    map[string]string
+}
+
+var annotationTemplates = []string{
+	"dashboard:https://dashboards.example.com/{{ .cluster }}/{{ .service }}",
+	"owner:{{ if .team }}{{ .team }}{{ end }}",
+}
+
+var annotationTemplateTests = []annotationTemplateTest{
+	annotationTemplateTest{
+		labels:   map[string]string{"alertname": "Foo", "team": ""},
+		expected: map[string]string{},
+	},
+	annotationTemplateTest{
+		labels:   map[string]string{"alertname": "Foo", "cluster": "prod"},
+		expected: map[string]string{},
+	},
+	annotationTemplateTest{
+		labels:   map[string]string{"alertname": "Foo", "cluster": "prod", "service": "api", "team": "web"},
+		expected: map[string]string{"dashboard": "https://dashboards.example.com/prod/api", "owner": "web"},
+	},
+	annotationTemplateTest{
+		labels:      map[string]string{"alertname": "Foo", "cluster": "prod", "service": "api", "team": "web"},
+		annotations: map[string]string{"dashboard": "https://example.com", "summary": "foo"},
+		expected:    map[string]string{"dashboard": "https://example.com", "owner": "web", "summary": "foo"},
+	},
+}
+
+func TestInjectAnnotations(t *testing.T) {
+	defer transform.ParseAnnotationTemplates([]string{})
+	if err := transform.ParseAnnotationTemplates(annotationTemplates); err != nil {
+		t.Fatal(err)
+	}
+	for _, testCase := range annotationTemplateTests {
+		alert := models.Alert{
+			Labels:      testCase.labels,
+			Annotations: models.AnnotationsFromMap(testCase.annotations),
+		}
+		transform.InjectAnnotations(&alert)
+		annotations := map[string]string{}
+		for _, a := range alert.Annotations {
+			annotations[a.Name] = a.Value
+			if a.Name == "dashboard" && !a.IsLink {
+				t.Errorf("Dashboard annotation for labels %v isn't a link", testCase.labels)
+			}
+		}
+		if !reflect.DeepEqual(annotations, testCase.expected) {
+			t.Errorf("Invalid annotations for labels %v, expected %v, got %v", testCase.labels, testCase.expected, annotations)
+		}
+	}
+}
+
+func TestParseAnnotationTemplates(t *testing.T) {
+	defer transform.ParseAnnotationTemplates([]string{})
+	for _, templates := range [][]string{
+		{"dashboard"},
+		{":https://example.com"},
+		{"dashboard:"},
+		{"dashboard:https://example.com/{{ .cluster"},
+		{"dashboard:https://example.com", "dashboard:https://example.org"},
+	} {
+		if err := transform.ParseAnnotationTemplates(templates); err == nil {
+			t.Errorf("ParseAnnotationTemplates() didn't return any error for %v", templates)
+		}
+	}
+}
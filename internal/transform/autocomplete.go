@@ -1,8 +1,13 @@
 package transform
 
 import (
+	"sort"
+	"strings"
+
+	"github.com/cloudflare/unsee/internal/config"
 	"github.com/cloudflare/unsee/internal/filters"
 	"github.com/cloudflare/unsee/internal/models"
+	"github.com/cloudflare/unsee/internal/slices"
 )
 
 // BuildAutocomplete takes an alert object and generates list of autocomplete
@@ -20,5 +25,71 @@ func BuildAutocomplete(alerts []models.Alert) []models.Autocomplete {
 	for _, hint := range acHints {
 		acHintsSlice = append(acHintsSlice, hint)
 	}
-	return acHintsSlice
+	return LimitAutocomplete(acHintsSlice)
+}
+
+// labelHint returns the label name and value a hint was generated from, label
+// hints always start with the label name followed by the operator and value,
+// all other filters have names starting with @
+func labelHint(hint models.Autocomplete) (string, string, bool) {
+	if len(hint.Tokens) < 3 || strings.HasPrefix(hint.Tokens[0], "@") {
+		return "", "", false
+	}
+	return hint.Tokens[0], hint.Tokens[2], true
+}
+
+// LimitAutocomplete drops hints for labels that shouldn't be indexed and keeps
+// only hints for values with the highest weight for every other label, so
+// labels with lots of unique values don't make autocomplete data grow forever
+func LimitAutocomplete(hints []models.Autocomplete) []models.Autocomplete {
+	maxValues := config.Config.AutocompleteMaxValues
+	ignored := config.Config.AutocompleteIgnoredLabels
+	if maxValues <= 0 && len(ignored) == 0 {
+		return hints
+	}
+
+	// weight of a label value is the highest weight of all its hints
+	labelValues := map[string]map[string]int{}
+	for _, hint := range hints {
+		name, value, ok := labelHint(hint)
+		if !ok || slices.StringInSlice(ignored, name) {
+			continue
+		}
+		if _, found := labelValues[name]; !found {
+			labelValues[name] = map[string]int{}
+		}
+		if hint.Weight > labelValues[name][value] {
+			labelValues[name][value] = hint.Weight
+		}
+	}
+
+	kept := map[string]map[string]bool{}
+	for name, weights := range labelValues {
+		values := make([]string, 0, len(weights))
+		for value := range weights {
+			values = append(values, value)
+		}
+		if maxValues > 0 && len(values) > maxValues {
+			sort.Slice(values, func(i, j int) bool {
+				if weights[values[i]] != weights[values[j]] {
+					return weights[values[i]] > weights[values[j]]
+				}
+				return values[i] < values[j]
+			})
+			values = values[:maxValues]
+		}
+		kept[name] = make(map[string]bool, len(values))
+		for _, value := range values {
+			kept[name][value] = true
+		}
+	}
+
+	limited := make([]models.Autocomplete, 0, len(hints))
+	for _, hint := range hints {
+		if name, value, ok := labelHint(hint); ok && !kept[name][value] {
+			continue
+		}
+		limited = append(limited, hint)
+	}
+	return limited
 }
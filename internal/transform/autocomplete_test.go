@@ -0,0 +1,83 @@
+package transform_test
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/cloudflare/unsee/internal/config"
+	"github.com/cloudflare/unsee/internal/models"
+	"github.com/cloudflare/unsee/internal/transform"
+)
+
+type autocompleteLimitTest struct {
+	maxValues int
+	ignored   []string
+	alerts    []models.Alert
+	values    []string
+}
+
+func acAlert(labels map[string]string) models.Alert {
+	return models.Alert{Labels: labels, State: models.AlertStateActive}
+}
+
+var autocompleteLimitTests = []autocompleteLimitTest{
+	autocompleteLimitTest{
+		alerts: []models.Alert{
+			acAlert(map[string]string{"job": "node", "pod": "a1"}),
+			acAlert(map[string]string{"job": "node", "pod": "b2"}),
+		},
+		values: []string{"job!=node", "job=node", "pod!=a1", "pod!=b2", "pod=a1", "pod=b2"},
+	},
+	autocompleteLimitTest{
+		ignored: []string{"pod"},
+		alerts: []models.Alert{
+			acAlert(map[string]string{"job": "node", "pod": "a1"}),
+			acAlert(map[string]string{"job": "node", "pod": "b2"}),
+		},
+		values: []string{"job!=node", "job=node"},
+	},
+	autocompleteLimitTest{
+		maxValues: 1,
+		alerts: []models.Alert{
+			acAlert(map[string]string{"job": "node", "pod": "b2"}),
+			acAlert(map[string]string{"job": "node", "pod": "a1"}),
+			acAlert(map[string]string{"job": "blackbox", "pod": "a1"}),
+		},
+		values: []string{"job!=node", "job=node", "pod!=a1", "pod=a1"},
+	},
+	autocompleteLimitTest{
+		// values with the same weight are sorted by name
+		maxValues: 2,
+		ignored:   []string{"job"},
+		alerts: []models.Alert{
+			acAlert(map[string]string{"job": "node", "pod": "c3"}),
+			acAlert(map[string]string{"job": "node", "pod": "b2"}),
+			acAlert(map[string]string{"job": "node", "pod": "a1"}),
+		},
+		values: []string{"pod!=a1", "pod!=b2", "pod=a1", "pod=b2"},
+	},
+}
+
+func TestLimitAutocomplete(t *testing.T) {
+	defer func() {
+		config.Config.AutocompleteMaxValues = 0
+		config.Config.AutocompleteIgnoredLabels = []string{}
+	}()
+	for _, testCase := range autocompleteLimitTests {
+		config.Config.AutocompleteMaxValues = testCase.maxValues
+		config.Config.AutocompleteIgnoredLabels = testCase.ignored
+
+		values := []string{}
+		for _, hint := range transform.BuildAutocomplete(testCase.alerts) {
+			// only check label hints, there are no labels starting with @
+			if hint.Value[0] != '@' {
+				values = append(values, hint.Value)
+			}
+		}
+		sort.Strings(values)
+		if !reflect.DeepEqual(values, testCase.values) {
+			t.Errorf("maxValues=%d ignored=%v returned %v, expected %v", testCase.maxValues, testCase.ignored, values, testCase.values)
+		}
+	}
+}
@@ -85,3 +85,34 @@ func TestColorLabel(t *testing.T) {
 		}
 	}
 }
+
+func TestColorLabelCached(t *testing.T) {
+	config.Config.ColorLabelsUnique = []string{"node"}
+	defer func() {
+		config.Config.ColorLabelsUnique = []string{}
+	}()
+
+	first := models.LabelsColorMap{}
+	transform.ColorLabel(first, "node", "localhost")
+
+	// colors must be the same when served from cache and once the cache was
+	// rotated enough times to drop them
+	for i := 0; i < 3; i++ {
+		colorStore := models.LabelsColorMap{}
+		transform.ColorLabel(colorStore, "node", "localhost")
+		if colorStore["node"]["localhost"] != first["node"]["localhost"] {
+			t.Errorf("Color changed after %d rotation(s), got %v, expected %v", i, colorStore["node"]["localhost"], first["node"]["localhost"])
+		}
+		transform.RotateColorCache()
+	}
+}
+
+func BenchmarkColorLabel(b *testing.B) {
+	config.Config.ColorLabelsUnique = []string{"node"}
+	defer func() {
+		config.Config.ColorLabelsUnique = []string{}
+	}()
+	for n := 0; n < b.N; n++ {
+		transform.ColorLabel(models.LabelsColorMap{}, "node", "localhost")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"crypto/sha1"
 	"io"
 	"math/rand"
+	"sync"
 
 	"github.com/cloudflare/unsee/internal/config"
 	"github.com/cloudflare/unsee/internal/models"
@@ -23,6 +24,87 @@ func labelToSeed(key string, val string) int64 {
 	return seed
 }
 
+// colorCache keeps colors generated for label values, so colors are only
+// generated for values not seen before, it's split into two generations and
+// colors not used since the last rotation are dropped
+var colorCache = struct {
+	sync.Mutex
+	current  map[string]models.LabelColors
+	previous map[string]models.LabelColors
+}{
+	current:  map[string]models.LabelColors{},
+	previous: map[string]models.LabelColors{},
+}
+
+// RotateColorCache drops colors for all label values that weren't seen since
+// the previous call, it should be called after every collection
+func RotateColorCache() {
+	colorCache.Lock()
+	defer colorCache.Unlock()
+
+	colorCache.previous = colorCache.current
+	colorCache.current = map[string]models.LabelColors{}
+}
+
+// labelColor returns the color for given label key and value, generated
+// colors are cached
+func labelColor(key string, val string) models.LabelColors {
+	colorCache.Lock()
+	defer colorCache.Unlock()
+
+	cacheKey := key + "\x00" + val
+	if lc, found := colorCache.current[cacheKey]; found {
+		return lc
+	}
+	lc, found := colorCache.previous[cacheKey]
+	if !found {
+		// random numbers are seeded from the label, this is done with the cache
+		// lock held, so concurrent pulls won't reseed it
+		lc = generateLabelColor(key, val)
+	}
+	colorCache.current[cacheKey] = lc
+	return lc
+}
+
+func generateLabelColor(key string, val string) models.LabelColors {
+	rand.Seed(labelToSeed(key, val))
+	color := randomcolor.New(randomcolor.Random, randomcolor.LIGHT)
+	red, green, blue, alpha := color.RGBA()
+	bc := models.Color{
+		Red:   uint8(red >> 8),
+		Green: uint8(green >> 8),
+		Blue:  uint8(blue >> 8),
+		Alpha: uint8(alpha >> 8),
+	}
+	// check if color is bright or dark and pick the right background
+	// uses https://www.w3.org/WAI/ER/WD-AERT/#color-contrast method
+	var brightness int32
+	brightness = ((int32(bc.Red) * 299) + (int32(bc.Green) * 587) + (int32(bc.Blue) * 114)) / 1000
+	var fc models.Color
+	if brightness <= 125 {
+		// background color is dark, use white font
+		fc = models.Color{
+			Red:   255,
+			Green: 255,
+			Blue:  255,
+			Alpha: 255,
+		}
+	} else {
+		// background color is bright, use dark font
+		fc = models.Color{
+			Red:   44,
+			Green: 62,
+			Blue:  80,
+			Alpha: 255,
+		}
+	}
+
+	return models.LabelColors{
+		Font:       fc,
+		Background: bc,
+	}
+}
+
 // ColorLabel update UnseeColorMap object with a color object generated
 // from label key and value passed here
 // It's used to generate unique colors for configured labels
@@ -32,42 +114,7 @@ func ColorLabel(colorStore models.LabelsColorMap, key string, val string) {
 			colorStore[key] = make(map[string]models.LabelColors)
 		}
 		if _, found := colorStore[key][val]; !found {
-			rand.Seed(labelToSeed(key, val))
-			color := randomcolor.New(randomcolor.Random, randomcolor.LIGHT)
-			red, green, blue, alpha := color.RGBA()
-			bc := models.Color{
-				Red:   uint8(red >> 8),
-				Green: uint8(green >> 8),
-				Blue:  uint8(blue >> 8),
-				Alpha: uint8(alpha >> 8),
-			}
-			// check if color is bright or dark and pick the right background
-			// uses https://www.w3.org/WAI/ER/WD-AERT/#color-contrast method
-			var brightness int32
-			brightness = ((int32(bc.Red) * 299) + (int32(bc.Green) * 587) + (int32(bc.Blue) * 114)) / 1000
-			var fc models.Color
-			if brightness <= 125 {
-				// background color is dark, use white font
-				fc = models.Color{
-					Red:   255,
-					Green: 255,
-					Blue:  255,
-					Alpha: 255,
-				}
-			} else {
-				// background color is bright, use dark font
-				fc = models.Color{
-					Red:   44,
-					Green: 62,
-					Blue:  80,
-					Alpha: 255,
-				}
-			}
-
-			colorStore[key][val] = models.LabelColors{
-				Font:       fc,
-				Background: bc,
-			}
+			colorStore[key][val] = labelColor(key, val)
 		}
 	}
 }
@@ -0,0 +1,155 @@
+package transform
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"plugin"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/cloudflare/unsee/internal/models"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// PluginSymbol is the name of the variable Go plugins must export, it must
+// implement the Transform interface
+const PluginSymbol = "Transform"
+
+// Transform is an extra processing step run for alerts collected from a
+// single Alertmanager upstream, it's called for every alert group whenever
+// any alert in it changes, so it must always return the same output for the
+// same alerts and be safe to call concurrently
+type Transform interface {
+	// Name is used in logs
+	Name() string
+	// Apply returns modified alerts, it must return the same number of alerts
+	Apply(ctx context.Context, alerts []models.Alert) ([]models.Alert, error)
+}
+
+var extensions = []Transform{}
+
+// RegisterTransform adds a Transform that will be applied to collected alerts
+// after all built-in transforms, transforms are run in the order they were
+// registered
+func RegisterTransform(t Transform) {
+	extensions = append(extensions, t)
+}
+
+// ResetTransforms removes all registered transforms
+func ResetTransforms() {
+	extensions = []Transform{}
+}
+
+// LoadTransforms registers transforms loaded from Go plugins and transforms
+// running external commands, every plugin must export a variable named
+// Transform implementing the Transform interface, commands are killed after
+// timeout
+func LoadTransforms(plugins []string, commands []string, timeout time.Duration) error {
+	for _, path := range plugins {
+		if path == "" {
+			continue
+		}
+		p, err := plugin.Open(path)
+		if err != nil {
+			return fmt.Errorf("Failed to load transform plugin '%s': %s", path, err)
+		}
+		sym, err := p.Lookup(PluginSymbol)
+		if err != nil {
+			return fmt.Errorf("Failed to load transform plugin '%s': %s", path, err)
+		}
+		// exported variables are looked up as pointers, the variable can be
+		// declared using the interface from this package or pkg/transform
+		t, ok := sym.(Transform)
+		if v := reflect.ValueOf(sym); !ok && v.Kind() == reflect.Ptr && !v.IsNil() {
+			t, ok = v.Elem().Interface().(Transform)
+		}
+		if !ok || t == nil {
+			return fmt.Errorf("Failed to load transform plugin '%s': %s doesn't implement the Transform interface", path, PluginSymbol)
+		}
+		log.Infof("Loaded transform '%s' from '%s'", t.Name(), path)
+		RegisterTransform(t)
+	}
+	for _, command := range commands {
+		if command == "" {
+			continue
+		}
+		if _, err := exec.LookPath(command); err != nil {
+			return fmt.Errorf("Invalid transform command '%s': %s", command, err)
+		}
+		RegisterTransform(&commandTransform{command: command, timeout: timeout})
+	}
+	return nil
+}
+
+// ApplyTransforms runs all registered transforms on alerts, if a transform
+// fails an error is logged and alerts are passed to the next one unmodified
+func ApplyTransforms(alerts []models.Alert) []models.Alert {
+	for _, t := range extensions {
+		transformed, err := t.Apply(context.Background(), alerts)
+		if err == nil && len(transformed) != len(alerts) {
+			err = fmt.Errorf("got %d alert(s) back, expected %d", len(transformed), len(alerts))
+		}
+		if err != nil {
+			log.Errorf("Transform '%s' failed: %s", t.Name(), err)
+			continue
+		}
+		alerts = transformed
+	}
+	return alerts
+}
+
+// commandTransform runs an external command passing alerts as a JSON list on
+// stdin, the command must print the modified list on stdout
+type commandTransform struct {
+	command string
+	timeout time.Duration
+}
+
+func (ct *commandTransform) Name() string {
+	return ct.command
+}
+
+func (ct *commandTransform) Apply(ctx context.Context, alerts []models.Alert) ([]models.Alert, error) {
+	payload, err := json.Marshal(alerts)
+	if err != nil {
+		return nil, err
+	}
+
+	if ct.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, ct.timeout)
+		defer cancel()
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, ct.command)
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err = cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return nil, fmt.Errorf("command timed out after %s", ct.timeout)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	transformed := []models.Alert{}
+	if err = json.Unmarshal(stdout.Bytes(), &transformed); err != nil {
+		return nil, fmt.Errorf("invalid JSON output: %s", err)
+	}
+	// some fields aren't included in JSON, keep those from the input
+	if len(transformed) == len(alerts) {
+		for i := range transformed {
+			transformed[i].GeneratorURL = alerts[i].GeneratorURL
+			transformed[i].SilencedBy = alerts[i].SilencedBy
+			transformed[i].InhibitedBy = alerts[i].InhibitedBy
+		}
+	}
+	return transformed, nil
+}
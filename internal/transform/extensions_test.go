@@ -0,0 +1,126 @@
+package transform_test
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cloudflare/unsee/internal/models"
+	"github.com/cloudflare/unsee/internal/transform"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type labelTransform struct {
+	name  string
+	value string
+	err   error
+}
+
+func (lt labelTransform) Name() string {
+	return lt.name
+}
+
+func (lt labelTransform) Apply(ctx context.Context, alerts []models.Alert) ([]models.Alert, error) {
+	if lt.err != nil {
+		return nil, lt.err
+	}
+	transformed := []models.Alert{}
+	for _, alert := range alerts {
+		labels := map[string]string{lt.name: lt.value}
+		for k, v := range alert.Labels {
+			labels[k] = v
+		}
+		alert.Labels = labels
+		transformed = append(transformed, alert)
+	}
+	return transformed, nil
+}
+
+func TestApplyTransforms(t *testing.T) {
+	log.SetLevel(log.FatalLevel)
+	defer log.SetLevel(log.InfoLevel)
+	defer transform.ResetTransforms()
+
+	transform.RegisterTransform(labelTransform{name: "team", value: "sre"})
+	transform.RegisterTransform(labelTransform{name: "broken", err: fmt.Errorf("failed")})
+	transform.RegisterTransform(labelTransform{name: "owner", value: "alice"})
+
+	alerts := transform.ApplyTransforms([]models.Alert{
+		{Labels: map[string]string{"alertname": "Foo"}},
+		{Labels: map[string]string{"alertname": "Bar"}},
+	})
+	for _, alert := range alerts {
+		if alert.Labels["team"] != "sre" || alert.Labels["owner"] == "" {
+			t.Errorf("Transforms weren't applied to %v", alert.Labels)
+		}
+		if _, found := alert.Labels["broken"]; found {
+			t.Errorf("Failed transform modified %v", alert.Labels)
+		}
+	}
+}
+
+func TestCommandTransforms(t *testing.T) {
+	log.SetLevel(log.FatalLevel)
+	defer log.SetLevel(log.InfoLevel)
+	defer transform.ResetTransforms()
+
+	dir, err := ioutil.TempDir("", "unsee-transform")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	scripts := map[string]string{
+		"rename.sh":  `sed 's/"alertname":"Foo"/"alertname":"Bar"/g'`,
+		"invalid.sh": "echo foo",
+		"drop.sh":    "echo '[]'",
+		"fail.sh":    "echo error >&2; exit 1",
+		"sleep.sh":   "exec sleep 10",
+	}
+	for name, body := range scripts {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+body+"\n"), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	commands := []string{}
+	for _, name := range []string{"invalid.sh", "drop.sh", "fail.sh", "rename.sh", "sleep.sh"} {
+		commands = append(commands, filepath.Join(dir, name))
+	}
+	if err := transform.LoadTransforms([]string{}, commands, time.Millisecond*500); err != nil {
+		t.Fatal(err)
+	}
+
+	alerts := transform.ApplyTransforms([]models.Alert{
+		{Labels: map[string]string{"alertname": "Foo"}, GeneratorURL: "http://localhost", SilencedBy: []string{"1"}},
+	})
+	if len(alerts) != 1 {
+		t.Fatalf("Got %d alert(s), expected 1", len(alerts))
+	}
+	if alerts[0].Labels["alertname"] != "Bar" {
+		t.Errorf("Command transform wasn't applied, got labels %v", alerts[0].Labels)
+	}
+	if alerts[0].GeneratorURL != "http://localhost" || len(alerts[0].SilencedBy) != 1 {
+		t.Errorf("Command transform didn't keep fields excluded from JSON: %v", alerts[0])
+	}
+}
+
+func TestLoadTransforms(t *testing.T) {
+	defer transform.ResetTransforms()
+	for _, testCase := range []struct {
+		plugins  []string
+		commands []string
+	}{
+		{plugins: []string{"/non/existing/plugin.so"}},
+		{commands: []string{"/non/existing/command"}},
+	} {
+		if err := transform.LoadTransforms(testCase.plugins, testCase.commands, time.Second); err == nil {
+			t.Errorf("LoadTransforms(%v, %v) didn't return any error", testCase.plugins, testCase.commands)
+		}
+	}
+}
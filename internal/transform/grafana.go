@@ -0,0 +1,138 @@
+package transform
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cloudflare/unsee/internal/models"
+)
+
+const (
+	grafanaLinkDashboard = "dashboard"
+	grafanaLinkExplore   = "explore"
+	// time range of generated links starts this long before the alert
+	grafanaLinkRangeBefore = time.Hour
+)
+
+// grafanaVariable maps a dashboard variable or a query label to an alert label
+type grafanaVariable struct {
+	Name  string
+	Label string
+}
+
+type grafanaLinkRule struct {
+	Name string
+	Type string
+	// dashboard UID for dashboard links, datasource name for explore links
+	Target    string
+	Variables []grafanaVariable
+}
+
+var (
+	grafanaURL       string
+	grafanaLinkRules = []grafanaLinkRule{}
+)
+
+// ParseGrafanaRules will parse and validate the list of Grafana link rules
+// provided from config, valid rules will be stored for future use in
+// GrafanaLinks() calls
+// Each rule is in the name:type:target:variables format, where type is either
+// dashboard (target is dashboard UID) or explore (target is datasource name),
+// and variables is a comma separated list of name=label pairs
+func ParseGrafanaRules(baseURL string, rules []string) error {
+	parsed := []grafanaLinkRule{}
+	for _, s := range rules {
+		if s == "" {
+			continue
+		}
+		ss := strings.SplitN(s, ":", 4)
+		if len(ss) != 4 || ss[0] == "" || ss[2] == "" || ss[3] == "" {
+			return fmt.Errorf("Invalid Grafana link rule '%s', expected format 'name:type:target:variable=label,...'", s)
+		}
+		if ss[1] != grafanaLinkDashboard && ss[1] != grafanaLinkExplore {
+			return fmt.Errorf("Invalid Grafana link rule '%s', type must be '%s' or '%s'", s, grafanaLinkDashboard, grafanaLinkExplore)
+		}
+		rule := grafanaLinkRule{Name: ss[0], Type: ss[1], Target: ss[2]}
+		for _, v := range strings.Split(ss[3], ",") {
+			kv := strings.SplitN(v, "=", 2)
+			if len(kv) != 2 || kv[0] == "" || kv[1] == "" {
+				return fmt.Errorf("Invalid Grafana link rule '%s', variable '%s' isn't in name=label format", s, v)
+			}
+			rule.Variables = append(rule.Variables, grafanaVariable{Name: kv[0], Label: kv[1]})
+		}
+		parsed = append(parsed, rule)
+	}
+	if len(parsed) > 0 {
+		u, err := url.Parse(baseURL)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("Invalid Grafana URL '%s', it's required when Grafana link rules are set", baseURL)
+		}
+	}
+	grafanaURL = strings.TrimSuffix(baseURL, "/")
+	grafanaLinkRules = parsed
+	return nil
+}
+
+// GrafanaLinks generates Grafana links for an alert using rules parsed by
+// ParseGrafanaRules, rules are skipped if the alert is missing any label
+// used by their variables
+func GrafanaLinks(alert *models.Alert) []models.AlertLink {
+	links := []models.AlertLink{}
+	for _, rule := range grafanaLinkRules {
+		values := map[string]string{}
+		for _, v := range rule.Variables {
+			value, found := alert.Labels[v.Label]
+			if !found {
+				break
+			}
+			values[v.Name] = value
+		}
+		if len(values) != len(rule.Variables) {
+			continue
+		}
+		from := alert.StartsAt.Add(-grafanaLinkRangeBefore).UnixNano() / int64(time.Millisecond)
+		var link string
+		if rule.Type == grafanaLinkDashboard {
+			link = dashboardLink(rule, values, from)
+		} else {
+			link = exploreLink(rule, values, from)
+		}
+		links = append(links, models.AlertLink{Name: rule.Name, URL: link})
+	}
+	return links
+}
+
+func dashboardLink(rule grafanaLinkRule, values map[string]string, from int64) string {
+	query := url.Values{}
+	for name, value := range values {
+		query.Set("var-"+name, value)
+	}
+	query.Set("from", fmt.Sprintf("%d", from))
+	query.Set("to", "now")
+	return fmt.Sprintf("%s/d/%s?%s", grafanaURL, url.PathEscape(rule.Target), query.Encode())
+}
+
+func exploreLink(rule grafanaLinkRule, values map[string]string, from int64) string {
+	// variables are turned into a label selector, which works for both
+	// Prometheus and Loki datasources
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	matchers := make([]string, 0, len(names))
+	for _, name := range names {
+		matchers = append(matchers, fmt.Sprintf("%s=%q", name, values[name]))
+	}
+	state := map[string]interface{}{
+		"datasource": rule.Target,
+		"queries":    []map[string]string{{"refId": "A", "expr": "{" + strings.Join(matchers, ",") + "}"}},
+		"range":      map[string]string{"from": fmt.Sprintf("%d", from), "to": "now"},
+	}
+	left, _ := json.Marshal(state)
+	return fmt.Sprintf("%s/explore?%s", grafanaURL, url.Values{"left": []string{string(left)}}.Encode())
+}
@@ -0,0 +1,84 @@
+package transform_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cloudflare/unsee/internal/models"
+	"github.com/cloudflare/unsee/internal/transform"
+)
+
+type grafanaTest struct {
+	labels map[string]string
+	links  []models.AlertLink
+}
+
+var grafanaRules = []string{
+	"Node:dashboard:rYdddlPWk:instance=instance,job=job",
+	"Logs:explore:Loki:job=job,host=instance",
+}
+
+var grafanaTests = []grafanaTest{
+	grafanaTest{
+		labels: map[string]string{"alertname": "Foo"},
+		links:  []models.AlertLink{},
+	},
+	grafanaTest{
+		labels: map[string]string{"instance": "web1"},
+		links:  []models.AlertLink{},
+	},
+	grafanaTest{
+		labels: map[string]string{"instance": "web1", "job": "node exporter"},
+		links: []models.AlertLink{
+			models.AlertLink{
+				Name: "Node",
+				URL:  "https://grafana.example.com/d/rYdddlPWk?from=1514775600000&to=now&var-instance=web1&var-job=node+exporter",
+			},
+			models.AlertLink{
+				Name: "Logs",
+				URL:  "https://grafana.example.com/explore?left=%7B%22datasource%22%3A%22Loki%22%2C%22queries%22%3A%5B%7B%22expr%22%3A%22%7Bhost%3D%5C%22web1%5C%22%2Cjob%3D%5C%22node+exporter%5C%22%7D%22%2C%22refId%22%3A%22A%22%7D%5D%2C%22range%22%3A%7B%22from%22%3A%221514775600000%22%2C%22to%22%3A%22now%22%7D%7D",
+			},
+		},
+	},
+}
+
+func TestGrafanaLinks(t *testing.T) {
+	defer transform.ParseGrafanaRules("", []string{})
+	if err := transform.ParseGrafanaRules("https://grafana.example.com/", grafanaRules); err != nil {
+		t.Fatal(err)
+	}
+	for _, testCase := range grafanaTests {
+		alert := models.Alert{
+			Labels:   testCase.labels,
+			StartsAt: time.Date(2018, time.January, 1, 4, 0, 0, 0, time.UTC),
+		}
+		links := transform.GrafanaLinks(&alert)
+		if !reflect.DeepEqual(links, testCase.links) {
+			t.Errorf("Invalid links generated for labels %v, expected %v, got %v", testCase.labels, testCase.links, links)
+		}
+	}
+}
+
+func TestParseGrafanaRules(t *testing.T) {
+	defer transform.ParseGrafanaRules("", []string{})
+	for _, rules := range [][]string{
+		{"Node"},
+		{"Node:dashboard:uid"},
+		{"Node:graph:uid:instance=instance"},
+		{":dashboard:uid:instance=instance"},
+		{"Node:dashboard::instance=instance"},
+		{"Node:dashboard:uid:instance"},
+		{"Node:dashboard:uid:instance=instance,=job"},
+	} {
+		if err := transform.ParseGrafanaRules("https://grafana.example.com", rules); err == nil {
+			t.Errorf("ParseGrafanaRules() didn't return any error for %v", rules)
+		}
+	}
+	if err := transform.ParseGrafanaRules("", grafanaRules); err == nil {
+		t.Error("ParseGrafanaRules() didn't return any error without Grafana URL")
+	}
+	if err := transform.ParseGrafanaRules("", []string{""}); err != nil {
+		t.Errorf("ParseGrafanaRules() returned an error without any rules: %s", err)
+	}
+}
@@ -0,0 +1,7 @@
+//go:build (linux && cgo) || (darwin && cgo) || (freebsd && cgo)
+
+package transform
+
+// PluginsSupported is true if this binary can load Go plugins, this requires
+// cgo and one of the operating systems supported by the plugin package
+const PluginsSupported = true
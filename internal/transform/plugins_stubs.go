@@ -0,0 +1,7 @@
+//go:build (!linux && !freebsd && !darwin) || !cgo
+
+package transform
+
+// PluginsSupported is false since plugin.Open always fails in binaries built
+// without cgo or for operating systems without plugin support
+const PluginsSupported = false
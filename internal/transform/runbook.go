@@ -0,0 +1,110 @@
+package transform
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/cloudflare/unsee/internal/models"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// RunbookAnnotation is the name of the annotation runbook URLs are injected as
+const RunbookAnnotation = "runbook"
+
+type runbookRule struct {
+	Label    string
+	Value    string
+	Regexp   *regexp.Regexp
+	Template *template.Template
+}
+
+var runbookRules = []runbookRule{}
+
+// matches returns true if the rule matches given alert labels
+func (rr runbookRule) matches(labels map[string]string) bool {
+	value, found := labels[rr.Label]
+	if !found {
+		return false
+	}
+	if rr.Regexp != nil {
+		return rr.Regexp.MatchString(value)
+	}
+	return value == rr.Value
+}
+
+// ParseRunbookRules will parse and validate the list of runbook rules
+// provided from config, valid rules will be stored for future use in
+// InjectRunbook() calls
+// Each rule is in the matcher@template format, matcher is either an alertname
+// or a label matcher (name=value or name=~regex) and template is a URL
+// template that can reference alert labels, like {{ .instance }}
+func ParseRunbookRules(rules []string) error {
+	parsed := []runbookRule{}
+	for _, s := range rules {
+		if s == "" {
+			continue
+		}
+		ss := strings.SplitN(s, "@", 2)
+		if len(ss) != 2 || ss[0] == "" || ss[1] == "" {
+			return fmt.Errorf("Invalid runbook rule '%s', expected format 'matcher@url'", s)
+		}
+		rule := runbookRule{Label: "alertname", Value: ss[0]}
+		if kv := strings.SplitN(ss[0], "=", 2); len(kv) == 2 {
+			if kv[0] == "" {
+				return fmt.Errorf("Invalid runbook rule '%s', label name is empty", s)
+			}
+			rule.Label = kv[0]
+			rule.Value = kv[1]
+			if strings.HasPrefix(kv[1], "~") {
+				re, err := regexp.Compile("^(?:" + strings.TrimPrefix(kv[1], "~") + ")$")
+				if err != nil {
+					return fmt.Errorf("Invalid runbook rule '%s': %s", s, err)
+				}
+				rule.Regexp = re
+			}
+		}
+		tmpl, err := template.New(s).Option("missingkey=zero").Parse(ss[1])
+		if err != nil {
+			return fmt.Errorf("Invalid runbook rule '%s': %s", s, err)
+		}
+		rule.Template = tmpl
+		parsed = append(parsed, rule)
+	}
+	runbookRules = parsed
+	return nil
+}
+
+// InjectRunbook adds a runbook annotation to the alert using the first rule
+// matching its labels, alerts that already have a runbook annotation are left
+// untouched
+func InjectRunbook(alert *models.Alert) {
+	if len(runbookRules) == 0 {
+		return
+	}
+	for _, a := range alert.Annotations {
+		if a.Name == RunbookAnnotation {
+			return
+		}
+	}
+	for _, rule := range runbookRules {
+		if !rule.matches(alert.Labels) {
+			continue
+		}
+		var buf bytes.Buffer
+		if err := rule.Template.Execute(&buf, alert.Labels); err != nil {
+			log.Errorf("Failed to generate runbook URL using rule '%s': %s", rule.Template.Name(), err)
+			return
+		}
+		annotations := make(models.Annotations, 0, len(alert.Annotations)+1)
+		annotations = append(annotations, alert.Annotations...)
+		annotations = append(annotations, models.AnnotationsFromMap(map[string]string{RunbookAnnotation: buf.String()})...)
+		sort.Sort(annotations)
+		alert.Annotations = annotations
+		return
+	}
+}
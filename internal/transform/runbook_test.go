@@ -0,0 +1,95 @@
+package transform_test
+
+import (
+	"testing"
+
+	"github.com/cloudflare/unsee/internal/models"
+	"github.com/cloudflare/unsee/internal/transform"
+)
+
+type runbookTest struct {
+	labels      map[string]string
+	annotations map[string]string
+	runbook     string
+}
+
+var runbookRules = []string{
+	"HTTP_Probe_Failed@https://wiki.example.com/runbooks/http-probe?instance={{ .instance }}",
+	"team=~db|mysql@https://wiki.example.com/runbooks/db/{{ .alertname }}",
+	"cluster=dev@https://wiki.example.com/runbooks/dev",
+}
+
+var runbookTests = []runbookTest{
+	runbookTest{
+		labels: map[string]string{"alertname": "Foo"},
+	},
+	runbookTest{
+		labels:  map[string]string{"alertname": "HTTP_Probe_Failed", "instance": "web1"},
+		runbook: "https://wiki.example.com/runbooks/http-probe?instance=web1",
+	},
+	runbookTest{
+		labels:  map[string]string{"alertname": "HTTP_Probe_Failed"},
+		runbook: "https://wiki.example.com/runbooks/http-probe?instance=",
+	},
+	runbookTest{
+		labels:  map[string]string{"alertname": "Disk_Full", "team": "mysql", "cluster": "dev"},
+		runbook: "https://wiki.example.com/runbooks/db/Disk_Full",
+	},
+	runbookTest{
+		labels:  map[string]string{"alertname": "Disk_Full", "team": "dba", "cluster": "dev"},
+		runbook: "https://wiki.example.com/runbooks/dev",
+	},
+	runbookTest{
+		labels:      map[string]string{"alertname": "HTTP_Probe_Failed", "instance": "web1"},
+		annotations: map[string]string{"runbook": "https://example.com", "summary": "foo"},
+		runbook:     "https://example.com",
+	},
+}
+
+func TestInjectRunbook(t *testing.T) {
+	defer transform.ParseRunbookRules([]string{})
+	if err := transform.ParseRunbookRules(runbookRules); err != nil {
+		t.Fatal(err)
+	}
+	for _, testCase := range runbookTests {
+		alert := models.Alert{
+			Labels:      testCase.labels,
+			Annotations: models.AnnotationsFromMap(testCase.annotations),
+		}
+		transform.InjectRunbook(&alert)
+		runbook := ""
+		for _, a := range alert.Annotations {
+			if a.Name == transform.RunbookAnnotation {
+				if runbook != "" {
+					t.Errorf("Duplicated runbook annotation for labels %v", testCase.labels)
+				}
+				runbook = a.Value
+				if !a.IsLink {
+					t.Errorf("Runbook annotation for labels %v isn't a link", testCase.labels)
+				}
+			}
+		}
+		if runbook != testCase.runbook {
+			t.Errorf("Invalid runbook for labels %v, expected '%s', got '%s'", testCase.labels, testCase.runbook, runbook)
+		}
+		if len(alert.Annotations) != len(testCase.annotations) && testCase.runbook == "" {
+			t.Errorf("Annotations modified for labels %v: %v", testCase.labels, alert.Annotations)
+		}
+	}
+}
+
+func TestParseRunbookRules(t *testing.T) {
+	defer transform.ParseRunbookRules([]string{})
+	for _, rules := range [][]string{
+		{"Foo"},
+		{"@https://example.com"},
+		{"Foo@"},
+		{"=foo@https://example.com"},
+		{"team=~(db@https://example.com"},
+		{"Foo@https://example.com/{{ .instance"},
+	} {
+		if err := transform.ParseRunbookRules(rules); err == nil {
+			t.Errorf("ParseRunbookRules() didn't return any error for %v", rules)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package transform
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/cloudflare/unsee/internal/models"
+)
+
+// values shorter than this are only scrubbed in labels, replacing them
+// anywhere else would mangle unrelated text
+const scrubMinTextLength = 3
+
+var scrubber = struct {
+	sync.RWMutex
+	labels map[string]bool
+	key    []byte
+}{}
+
+// SetScrubLabels sets the list of label names with values that are replaced
+// with a hash in collected alerts and silences, the hash is keyed with a
+// random value generated for every process, so it's consistent across all
+// responses but can't be reversed by hashing known values
+func SetScrubLabels(labels []string) error {
+	parsed := map[string]bool{}
+	for _, name := range labels {
+		if name == "" {
+			continue
+		}
+		parsed[name] = true
+	}
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return fmt.Errorf("Failed to generate scrubbing key: %s", err)
+	}
+	scrubber.Lock()
+	defer scrubber.Unlock()
+	scrubber.labels = parsed
+	scrubber.key = key
+	return nil
+}
+
+// ScrubValue returns the replacement for the value of given label, values of
+// labels that don't need scrubbing are returned as they are
+func ScrubValue(label, value string) string {
+	scrubber.RLock()
+	defer scrubber.RUnlock()
+	return scrubValue(label, value)
+}
+
+// scrubValue needs to be called with the scrubber lock held
+func scrubValue(label, value string) string {
+	if !scrubber.labels[label] || value == "" {
+		return value
+	}
+	mac := hmac.New(sha256.New, scrubber.key)
+	// label name isn't hashed, so the same value is replaced with the same
+	// hash in every label
+	mac.Write([]byte(value))
+	return fmt.Sprintf("%s-%s", label, hex.EncodeToString(mac.Sum(nil))[:8])
+}
+
+// ScrubLabels returns a copy of labels with values of scrubbed labels
+// replaced, labels are returned as they are if there's nothing to scrub
+func ScrubLabels(labels map[string]string) map[string]string {
+	scrubber.RLock()
+	defer scrubber.RUnlock()
+	scrubbed, _ := scrubLabels(labels)
+	return scrubbed
+}
+
+// scrubLabels returns scrubbed labels and a replacer for original values
+// found in those, it needs to be called with the scrubber lock held
+func scrubLabels(labels map[string]string) (map[string]string, *strings.Replacer) {
+	if len(scrubber.labels) == 0 {
+		return labels, nil
+	}
+	found := false
+	for name := range labels {
+		if scrubber.labels[name] {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return labels, nil
+	}
+
+	scrubbed := make(map[string]string, len(labels))
+	replacements := map[string]string{}
+	for name, value := range labels {
+		scrubbed[name] = scrubValue(name, value)
+		if scrubbed[name] != value && len(value) >= scrubMinTextLength {
+			replacements[value] = scrubbed[name]
+		}
+	}
+	return scrubbed, newScrubReplacer(replacements)
+}
+
+// newScrubReplacer returns a replacer for given values, longer values are
+// replaced first, so values that are a part of others don't break them
+func newScrubReplacer(replacements map[string]string) *strings.Replacer {
+	if len(replacements) == 0 {
+		return nil
+	}
+	values := make([]string, 0, len(replacements))
+	for value := range replacements {
+		values = append(values, value)
+	}
+	sort.Slice(values, func(i, j int) bool {
+		if len(values[i]) != len(values[j]) {
+			return len(values[i]) > len(values[j])
+		}
+		return values[i] < values[j]
+	})
+	pairs := make([]string, 0, len(values)*2)
+	for _, value := range values {
+		pairs = append(pairs, value, replacements[value])
+	}
+	return strings.NewReplacer(pairs...)
+}
+
+// ScrubAlert replaces values of scrubbed labels on the alert, those values
+// are also replaced anywhere in annotations, since those are often generated
+// from labels
+func ScrubAlert(alert *models.Alert) {
+	scrubber.RLock()
+	defer scrubber.RUnlock()
+	labels, replacer := scrubLabels(alert.Labels)
+	alert.Labels = labels
+	if replacer == nil || len(alert.Annotations) == 0 {
+		return
+	}
+	// annotations can be shared with other alerts, so always copy them
+	annotations := make(models.Annotations, len(alert.Annotations))
+	copy(annotations, alert.Annotations)
+	for i := range annotations {
+		annotations[i].Value = replacer.Replace(annotations[i].Value)
+	}
+	alert.Annotations = annotations
+}
+
+// ScrubSilence replaces values of matchers for scrubbed labels, regex
+// matchers are replaced as a whole, those values are also replaced in the
+// comment
+func ScrubSilence(silence *models.Silence) {
+	scrubber.RLock()
+	defer scrubber.RUnlock()
+	if len(scrubber.labels) == 0 {
+		return
+	}
+	matchers := silence.Matchers[:0:0]
+	replacements := map[string]string{}
+	for _, m := range silence.Matchers {
+		if scrubbed := scrubValue(m.Name, m.Value); scrubbed != m.Value {
+			if len(m.Value) >= scrubMinTextLength {
+				replacements[m.Value] = scrubbed
+			}
+			m.Value = scrubbed
+		}
+		matchers = append(matchers, m)
+	}
+	silence.Matchers = matchers
+	if replacer := newScrubReplacer(replacements); replacer != nil {
+		silence.Comment = replacer.Replace(silence.Comment)
+	}
+}
@@ -0,0 +1,81 @@
+package transform_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/cloudflare/unsee/internal/models"
+	"github.com/cloudflare/unsee/internal/transform"
+)
+
+func TestScrubAlert(t *testing.T) {
+	defer transform.SetScrubLabels([]string{})
+	if err := transform.SetScrubLabels([]string{"instance", "customer", "id"}); err != nil {
+		t.Fatal(err)
+	}
+
+	labels := map[string]string{"alertname": "Host_Down", "instance": "web1.example.com", "customer": "acme", "id": "1"}
+	annotations := models.Annotations{
+		models.Annotation{Name: "summary", Value: "web1.example.com is down for acme"},
+		models.Annotation{Name: "help", Value: "Restart it", IsLink: false},
+	}
+	alert := models.Alert{Labels: labels, Annotations: annotations}
+	transform.ScrubAlert(&alert)
+
+	instance := transform.ScrubValue("instance", "web1.example.com")
+	customer := transform.ScrubValue("customer", "acme")
+	if !strings.HasPrefix(instance, "instance-") || instance == "web1.example.com" {
+		t.Errorf("Invalid replacement for instance label: %s", instance)
+	}
+	expected := map[string]string{"alertname": "Host_Down", "instance": instance, "customer": customer, "id": transform.ScrubValue("id", "1")}
+	if !reflect.DeepEqual(alert.Labels, expected) {
+		t.Errorf("Invalid labels, expected %v, got %v", expected, alert.Labels)
+	}
+	if summary := instance + " is down for " + customer; alert.Annotations[0].Value != summary {
+		t.Errorf("Invalid summary annotation, expected %q, got %q", summary, alert.Annotations[0].Value)
+	}
+	if alert.Annotations[1].Value != "Restart it" {
+		t.Errorf("Short values were replaced in annotations: %q", alert.Annotations[1].Value)
+	}
+	if labels["instance"] != "web1.example.com" || annotations[0].Value != "web1.example.com is down for acme" {
+		t.Error("ScrubAlert() modified original labels or annotations")
+	}
+
+	// the same value is always replaced with the same hash
+	other := models.Alert{Labels: map[string]string{"instance": "web1.example.com"}}
+	transform.ScrubAlert(&other)
+	if other.Labels["instance"] != instance {
+		t.Errorf("Inconsistent replacement, got %s and %s", instance, other.Labels["instance"])
+	}
+	if transform.ScrubValue("alertname", "Host_Down") != "Host_Down" {
+		t.Error("ScrubValue() replaced the value of a label that isn't scrubbed")
+	}
+}
+
+func TestScrubSilence(t *testing.T) {
+	defer transform.SetScrubLabels([]string{})
+	if err := transform.SetScrubLabels([]string{"instance"}); err != nil {
+		t.Fatal(err)
+	}
+
+	silence := models.Silence{Comment: "Maintenance of web1.example.com"}
+	silence.Matchers = append(silence.Matchers, struct {
+		Name    string `json:"name"`
+		Value   string `json:"value"`
+		IsRegex bool   `json:"isRegex"`
+	}{Name: "instance", Value: "web1.example.com"}, struct {
+		Name    string `json:"name"`
+		Value   string `json:"value"`
+		IsRegex bool   `json:"isRegex"`
+	}{Name: "alertname", Value: "Host_Down"})
+	transform.ScrubSilence(&silence)
+
+	instance := transform.ScrubValue("instance", "web1.example.com")
+	if silence.Matchers[0].Value != instance || silence.Matchers[1].Value != "Host_Down" {
+		t.Errorf("Invalid matchers: %v", silence.Matchers)
+	}
+	if silence.Comment != "Maintenance of "+instance {
+		t.Errorf("Invalid comment: %q", silence.Comment)
+	}
+}
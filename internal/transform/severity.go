@@ -0,0 +1,82 @@
+package transform
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cloudflare/unsee/internal/models"
+)
+
+type severityMapping struct {
+	Label           string
+	NormalizedLabel string
+	// lower cased value -> canonical severity
+	Values map[string]string
+}
+
+var severityMap = severityMapping{}
+
+// ParseSeverityMap will parse and validate the severity mapping provided from
+// config, the mapping will be stored for future use in NormalizeSeverity()
+// calls
+// Each rule is in the severity:value,value,... format, values of label are
+// compared case insensitively and the canonical severity is stored as
+// normalizedLabel
+func ParseSeverityMap(label, normalizedLabel string, rules []string) error {
+	parsed := severityMapping{Label: label, NormalizedLabel: normalizedLabel, Values: map[string]string{}}
+	for _, s := range rules {
+		if s == "" {
+			continue
+		}
+		ss := strings.SplitN(s, ":", 2)
+		if len(ss) != 2 || ss[0] == "" || ss[1] == "" {
+			return fmt.Errorf("Invalid severity mapping '%s', expected format 'severity:value,value,...'", s)
+		}
+		// canonical values are always mapped to themselves
+		for _, value := range append([]string{ss[0]}, strings.Split(ss[1], ",")...) {
+			if value == "" {
+				return fmt.Errorf("Invalid severity mapping '%s', value is empty", s)
+			}
+			key := strings.ToLower(value)
+			if canonical, found := parsed.Values[key]; found && canonical != ss[0] {
+				return fmt.Errorf("Invalid severity mapping '%s', '%s' is already mapped to '%s'", s, value, canonical)
+			}
+			parsed.Values[key] = ss[0]
+		}
+	}
+	if len(parsed.Values) > 0 {
+		if label == "" || normalizedLabel == "" {
+			return fmt.Errorf("Severity label names can't be empty")
+		}
+		if label == normalizedLabel {
+			return fmt.Errorf("Normalized severity label can't be the same as the severity label '%s'", label)
+		}
+	}
+	severityMap = parsed
+	return nil
+}
+
+// NormalizeSeverity adds the normalized severity label to alerts with the
+// severity label, values that aren't mapped to any canonical severity are
+// copied as they are, so the normalized label is present on every alert with
+// a severity
+func NormalizeSeverity(alert *models.Alert) {
+	if len(severityMap.Values) == 0 {
+		return
+	}
+	value, found := alert.Labels[severityMap.Label]
+	if !found {
+		return
+	}
+	normalized, found := severityMap.Values[strings.ToLower(value)]
+	if !found {
+		normalized = value
+	}
+	// labels can be shared with other alerts, so always copy them
+	labels := make(map[string]string, len(alert.Labels)+1)
+	for k, v := range alert.Labels {
+		labels[k] = v
+	}
+	labels[severityMap.NormalizedLabel] = normalized
+	alert.Labels = labels
+}
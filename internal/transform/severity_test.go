@@ -0,0 +1,85 @@
+package transform_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/cloudflare/unsee/internal/models"
+	"github.com/cloudflare/unsee/internal/transform"
+)
+
+type severityTest struct {
+	labels   map[string]string
+	expected map[string]string
+}
+
+var severityMap = []string{
+	"critical:page,sev1,P1",
+	"warning:sev2,P2,warn",
+}
+
+var severityTests = []severityTest{
+	severityTest{
+		labels:   map[string]string{"alertname": "Foo"},
+		expected: map[string]string{"alertname": "Foo"},
+	},
+	severityTest{
+		labels:   map[string]string{"alertname": "Foo", "severity": "page"},
+		expected: map[string]string{"alertname": "Foo", "severity": "page", "severity_normalized": "critical"},
+	},
+	severityTest{
+		labels:   map[string]string{"alertname": "Foo", "severity": "p1"},
+		expected: map[string]string{"alertname": "Foo", "severity": "p1", "severity_normalized": "critical"},
+	},
+	severityTest{
+		labels:   map[string]string{"alertname": "Foo", "severity": "Warning"},
+		expected: map[string]string{"alertname": "Foo", "severity": "Warning", "severity_normalized": "warning"},
+	},
+	severityTest{
+		labels:   map[string]string{"alertname": "Foo", "severity": "info"},
+		expected: map[string]string{"alertname": "Foo", "severity": "info", "severity_normalized": "info"},
+	},
+}
+
+func TestNormalizeSeverity(t *testing.T) {
+	defer transform.ParseSeverityMap("severity", "severity_normalized", []string{})
+	if err := transform.ParseSeverityMap("severity", "severity_normalized", severityMap); err != nil {
+		t.Fatal(err)
+	}
+	for _, testCase := range severityTests {
+		original := map[string]string{}
+		for k, v := range testCase.labels {
+			original[k] = v
+		}
+		alert := models.Alert{Labels: testCase.labels}
+		transform.NormalizeSeverity(&alert)
+		if !reflect.DeepEqual(alert.Labels, testCase.expected) {
+			t.Errorf("Invalid labels for %v, expected %v, got %v", original, testCase.expected, alert.Labels)
+		}
+		if !reflect.DeepEqual(testCase.labels, original) {
+			t.Errorf("NormalizeSeverity() modified original labels %v", original)
+		}
+	}
+}
+
+func TestParseSeverityMap(t *testing.T) {
+	defer transform.ParseSeverityMap("severity", "severity_normalized", []string{})
+	for _, testCase := range []struct {
+		label           string
+		normalizedLabel string
+		rules           []string
+	}{
+		{label: "severity", normalizedLabel: "severity_normalized", rules: []string{"critical"}},
+		{label: "severity", normalizedLabel: "severity_normalized", rules: []string{":page"}},
+		{label: "severity", normalizedLabel: "severity_normalized", rules: []string{"critical:"}},
+		{label: "severity", normalizedLabel: "severity_normalized", rules: []string{"critical:page,,sev1"}},
+		{label: "severity", normalizedLabel: "severity_normalized", rules: []string{"critical:page", "warning:PAGE"}},
+		{label: "severity", normalizedLabel: "severity_normalized", rules: []string{"critical:page", "page:sev1"}},
+		{label: "severity", normalizedLabel: "severity", rules: []string{"critical:page"}},
+		{label: "", normalizedLabel: "severity_normalized", rules: []string{"critical:page"}},
+	} {
+		if err := transform.ParseSeverityMap(testCase.label, testCase.normalizedLabel, testCase.rules); err == nil {
+			t.Errorf("ParseSeverityMap() didn't return any error for %v", testCase)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package transform
+
+import (
+	"fmt"
+	"time"
+)
+
+// DisplayTimeFormat is the layout of timestamps formatted for display
+const DisplayTimeFormat = "2006-01-02 15:04:05 MST"
+
+var displayLocation = time.UTC
+
+// SetTimezone sets the timezone used by FormatTime, name is an IANA timezone
+// name like Europe/London, UTC is used if it's empty
+func SetTimezone(name string) error {
+	if name == "" {
+		displayLocation = time.UTC
+		return nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return fmt.Errorf("Invalid timezone '%s': %s", name, err)
+	}
+	displayLocation = loc
+	return nil
+}
+
+// FormatTime formats a timestamp for display using the timezone set with
+// SetTimezone, zero time is formatted as an empty string
+func FormatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.In(displayLocation).Format(DisplayTimeFormat)
+}
+
+// Timezone returns the name of the timezone used by FormatTime
+func Timezone() string {
+	return displayLocation.String()
+}
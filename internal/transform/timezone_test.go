@@ -0,0 +1,33 @@
+package transform_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cloudflare/unsee/internal/transform"
+)
+
+func TestFormatTime(t *testing.T) {
+	defer transform.SetTimezone("")
+	ts := time.Date(2018, time.January, 1, 23, 30, 0, 0, time.UTC)
+	for _, testCase := range []struct {
+		timezone string
+		ts       time.Time
+		result   string
+	}{
+		{timezone: "", ts: ts, result: "2018-01-01 23:30:00 UTC"},
+		{timezone: "Asia/Tokyo", ts: ts, result: "2018-01-02 08:30:00 JST"},
+		{timezone: "America/New_York", ts: ts, result: "2018-01-01 18:30:00 EST"},
+		{timezone: "Asia/Tokyo", ts: time.Time{}, result: ""},
+	} {
+		if err := transform.SetTimezone(testCase.timezone); err != nil {
+			t.Fatal(err)
+		}
+		if result := transform.FormatTime(testCase.ts); result != testCase.result {
+			t.Errorf("FormatTime(%s) with timezone '%s' returned '%s', expected '%s'", testCase.ts, testCase.timezone, result, testCase.result)
+		}
+	}
+	if err := transform.SetTimezone("Mars/Olympus_Mons"); err == nil {
+		t.Error("SetTimezone() didn't return any error for an invalid timezone")
+	}
+}
@@ -0,0 +1,49 @@
+package transform
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+
+	"github.com/cloudflare/unsee/internal/config"
+)
+
+func workerCount() int {
+	if config.Config.TransformWorkers > 0 {
+		return config.Config.TransformWorkers
+	}
+	return runtime.NumCPU()
+}
+
+// ForEach calls fn for every index from 0 to n-1, calls are spread across
+// a pool of goroutines as configured with TRANSFORM_WORKERS, fn must be safe
+// to call concurrently, ForEach returns once all calls are done
+func ForEach(n int, fn func(i int)) {
+	workers := workerCount()
+	if workers > n {
+		workers = n
+	}
+	if workers <= 1 {
+		for i := 0; i < n; i++ {
+			fn(i)
+		}
+		return
+	}
+
+	next := int64(-1)
+	wg := sync.WaitGroup{}
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for {
+				i := int(atomic.AddInt64(&next, 1))
+				if i >= n {
+					return
+				}
+				fn(i)
+			}
+		}()
+	}
+	wg.Wait()
+}
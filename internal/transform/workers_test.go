@@ -0,0 +1,29 @@
+package transform_test
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"github.com/cloudflare/unsee/internal/config"
+	"github.com/cloudflare/unsee/internal/transform"
+)
+
+func TestForEach(t *testing.T) {
+	defer func() {
+		config.Config.TransformWorkers = 0
+	}()
+	for _, workers := range []int{0, 1, 3, 100} {
+		for _, n := range []int{0, 1, 10, 1000} {
+			config.Config.TransformWorkers = workers
+			calls := make([]int32, n)
+			transform.ForEach(n, func(i int) {
+				atomic.AddInt32(&calls[i], 1)
+			})
+			for i, c := range calls {
+				if c != 1 {
+					t.Errorf("[workers=%d n=%d] Expected index %d to be processed once, got %d", workers, n, i, c)
+				}
+			}
+		}
+	}
+}
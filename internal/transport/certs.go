@@ -0,0 +1,53 @@
+package transport
+
+import (
+	"crypto/tls"
+	"sync"
+)
+
+var clientCerts = struct {
+	sync.RWMutex
+	// certificates keyed by the URI prefix they're used for
+	certs map[string]*tls.Certificate
+}{certs: map[string]*tls.Certificate{}}
+
+// SetClientCertificate makes all HTTPS requests to URIs starting with uri use
+// given TLS client certificate, it can be called again to replace a rotated
+// certificate, idle connections are closed, so new connections use it
+// Requests to uri get their own transport, so it should be called after
+// SetProxy
+func SetClientCertificate(uri string, cert tls.Certificate) {
+	clientCerts.Lock()
+	_, rotated := clientCerts.certs[uri]
+	clientCerts.certs[uri] = &cert
+	clientCerts.Unlock()
+
+	proxies.Lock()
+	defer proxies.Unlock()
+	t, found := proxies.transports[uri]
+	if !found {
+		t = NewTransport(GetPoolSettings())
+		proxies.transports[uri] = t
+	}
+	if rotated && t.TLSClientConfig != nil && t.TLSClientConfig.GetClientCertificate != nil {
+		t.CloseIdleConnections()
+		return
+	}
+	if t.TLSClientConfig == nil {
+		t.TLSClientConfig = &tls.Config{}
+	}
+	t.TLSClientConfig.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+		return clientCertificate(uri), nil
+	}
+}
+
+// clientCertificate returns the current client certificate for uri, an empty
+// certificate is returned if there's none, so the server can reject it
+func clientCertificate(uri string) *tls.Certificate {
+	clientCerts.RLock()
+	defer clientCerts.RUnlock()
+	if cert, found := clientCerts.certs[uri]; found {
+		return cert
+	}
+	return &tls.Certificate{}
+}
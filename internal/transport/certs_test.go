@@ -0,0 +1,55 @@
+package transport_test
+
+import (
+	"crypto/tls"
+	"net/http"
+	"testing"
+
+	"github.com/cloudflare/unsee/internal/transport"
+)
+
+func clientCert(uri string) []byte {
+	rt, ok := transport.RoundTripper(uri).(*http.Transport)
+	if !ok || rt.TLSClientConfig == nil || rt.TLSClientConfig.GetClientCertificate == nil {
+		return nil
+	}
+	cert, err := rt.TLSClientConfig.GetClientCertificate(&tls.CertificateRequestInfo{})
+	if err != nil || len(cert.Certificate) == 0 {
+		return nil
+	}
+	return cert.Certificate[0]
+}
+
+func TestSetClientCertificate(t *testing.T) {
+	uri := "https://localhost:9093"
+	defer transport.SetProxy(uri, "")
+
+	transport.SetClientCertificate(uri, tls.Certificate{Certificate: [][]byte{[]byte("first")}})
+	if string(clientCert(uri+"/api/v1/alerts/groups")) != "first" {
+		t.Errorf("Invalid client certificate, got '%s'", clientCert(uri))
+	}
+	transport.SetClientCertificate(uri, tls.Certificate{Certificate: [][]byte{[]byte("second")}})
+	if string(clientCert(uri+"/api/v1/alerts/groups")) != "second" {
+		t.Errorf("Invalid client certificate after rotation, got '%s'", clientCert(uri))
+	}
+	if clientCert("https://localhost:9094") != nil {
+		t.Errorf("Client certificate was used for a different URI")
+	}
+}
+
+func TestSetClientCertificateSharedPrefix(t *testing.T) {
+	defer transport.SetProxy("https://alertmanager", "")
+	defer transport.SetProxy("https://alertmanager-staging", "")
+
+	transport.SetClientCertificate("https://alertmanager", tls.Certificate{Certificate: [][]byte{[]byte("prod")}})
+	transport.SetClientCertificate("https://alertmanager-staging", tls.Certificate{Certificate: [][]byte{[]byte("staging")}})
+	for uri, expected := range map[string]string{
+		"https://alertmanager/api/v1/alerts/groups":         "prod",
+		"https://alertmanager-staging/api/v1/alerts/groups": "staging",
+		"https://alertmanager-dev/api/v1/alerts/groups":     "",
+	} {
+		if cert := string(clientCert(uri)); cert != expected {
+			t.Errorf("Invalid client certificate for %s, expected '%s', got '%s'", uri, expected, cert)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package transport
+
+import (
+	"net/http"
+	"sync"
+)
+
+// Credentials are used to authenticate requests sent to an upstream, either
+// with basic authentication or with a bearer token
+type Credentials struct {
+	Username string
+	Password string
+	Token    string
+}
+
+var credentials = struct {
+	sync.RWMutex
+	// credentials keyed by the URI prefix they're used for
+	values map[string]Credentials
+}{values: map[string]Credentials{}}
+
+// SetCredentials makes all HTTP requests to URIs starting with uri use given
+// credentials, it can be called again to replace credentials that were
+// rotated, passing empty credentials removes them
+func SetCredentials(uri string, c Credentials) {
+	credentials.Lock()
+	defer credentials.Unlock()
+	if c == (Credentials{}) {
+		delete(credentials.values, uri)
+		return
+	}
+	credentials.values[uri] = c
+}
+
+// setAuthorization sets the Authorization header on a request if credentials
+// were set for its URI, the longest matching prefix is used
+func setAuthorization(req *http.Request) {
+	credentials.RLock()
+	defer credentials.RUnlock()
+	if len(credentials.values) == 0 || req.URL == nil {
+		return
+	}
+	uri := req.URL.String()
+	var prefix string
+	var c Credentials
+	for p, v := range credentials.values {
+		if hasURIPrefix(uri, p) && len(p) > len(prefix) {
+			prefix = p
+			c = v
+		}
+	}
+	switch {
+	case prefix == "":
+	case c.Token != "":
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	default:
+		req.SetBasicAuth(c.Username, c.Password)
+	}
+}
@@ -0,0 +1,61 @@
+package transport_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/cloudflare/unsee/internal/transport"
+)
+
+func TestSetCredentials(t *testing.T) {
+	defer transport.SetCredentials("http://localhost:9093", transport.Credentials{})
+	defer transport.SetCredentials("http://localhost:9093/prod", transport.Credentials{})
+
+	transport.SetCredentials("http://localhost:9093", transport.Credentials{Username: "user", Password: "pass"})
+	transport.SetCredentials("http://localhost:9093/prod", transport.Credentials{Token: "secret"})
+	for _, testCase := range []struct {
+		uri           string
+		authorization string
+	}{
+		{uri: "http://localhost:9093/api/v1/alerts/groups", authorization: "Basic dXNlcjpwYXNz"},
+		{uri: "http://localhost:9093/prod/api/v1/alerts/groups", authorization: "Bearer secret"},
+		{uri: "http://localhost:9094/api/v1/alerts/groups", authorization: ""},
+	} {
+		req, _ := http.NewRequest(http.MethodGet, testCase.uri, nil)
+		transport.SetRequestHeaders(req)
+		if req.Header.Get("Authorization") != testCase.authorization {
+			t.Errorf("Invalid Authorization header for %s, expected '%s', got '%s'", testCase.uri, testCase.authorization, req.Header.Get("Authorization"))
+		}
+	}
+
+	transport.SetCredentials("http://localhost:9093/prod", transport.Credentials{})
+	req, _ := http.NewRequest(http.MethodGet, "http://localhost:9093/prod/api/v1/alerts/groups", nil)
+	transport.SetRequestHeaders(req)
+	if req.Header.Get("Authorization") != "Basic dXNlcjpwYXNz" {
+		t.Errorf("Invalid Authorization header after removing credentials: %s", req.Header.Get("Authorization"))
+	}
+}
+
+func TestSetCredentialsSharedPrefix(t *testing.T) {
+	defer transport.SetCredentials("http://alertmanager", transport.Credentials{})
+	defer transport.SetCredentials("http://alertmanager-staging", transport.Credentials{})
+
+	transport.SetCredentials("http://alertmanager", transport.Credentials{Token: "prod"})
+	transport.SetCredentials("http://alertmanager-staging", transport.Credentials{Token: "staging"})
+	for _, testCase := range []struct {
+		uri           string
+		authorization string
+	}{
+		{uri: "http://alertmanager/api/v1/alerts/groups", authorization: "Bearer prod"},
+		{uri: "http://alertmanager", authorization: "Bearer prod"},
+		{uri: "http://alertmanager-staging/api/v1/alerts/groups", authorization: "Bearer staging"},
+		{uri: "http://alertmanager-dev/api/v1/alerts/groups", authorization: ""},
+		{uri: "http://alertmanager:9093/api/v1/alerts/groups", authorization: ""},
+	} {
+		req, _ := http.NewRequest(http.MethodGet, testCase.uri, nil)
+		transport.SetRequestHeaders(req)
+		if req.Header.Get("Authorization") != testCase.authorization {
+			t.Errorf("Invalid Authorization header for %s, expected '%s', got '%s'", testCase.uri, testCase.authorization, req.Header.Get("Authorization"))
+		}
+	}
+}
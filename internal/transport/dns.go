@@ -0,0 +1,128 @@
+package transport
+
+import (
+	"context"
+	"net"
+	"sort"
+	"sync"
+
+	"github.com/cloudflare/unsee/internal/slices"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// lookupHost is used to re-resolve host names of open connections
+var lookupHost = net.DefaultResolver.LookupHost
+
+var dialed = struct {
+	sync.Mutex
+	// number of open connections keyed by the host name used to dial them and
+	// the IP they're connected to
+	conns map[string]map[string]int
+}{conns: map[string]map[string]int{}}
+
+func trackConn(host, ip string, delta int) {
+	dialed.Lock()
+	defer dialed.Unlock()
+	ips, found := dialed.conns[host]
+	if !found {
+		ips = map[string]int{}
+		dialed.conns[host] = ips
+	}
+	ips[ip] += delta
+	if ips[ip] <= 0 {
+		delete(ips, ip)
+	}
+	if len(ips) == 0 {
+		delete(dialed.conns, host)
+	}
+}
+
+// trackedConn is a connection dialed using a host name, it's tracked until
+// closed, so RefreshDNS knows which addresses are in use
+type trackedConn struct {
+	net.Conn
+	host string
+	ip   string
+	once sync.Once
+}
+
+func (tc *trackedConn) Close() error {
+	tc.once.Do(func() { trackConn(tc.host, tc.ip, -1) })
+	return tc.Conn.Close()
+}
+
+type dialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// trackingDialer wraps dial so that all connections to host names are tracked,
+// connections to IPs don't depend on DNS so they're not tracked
+func trackingDialer(dial dialFunc) dialFunc {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := dial(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil || net.ParseIP(host) != nil {
+			return conn, nil
+		}
+		ip, _, err := net.SplitHostPort(conn.RemoteAddr().String())
+		if err != nil {
+			return conn, nil
+		}
+		trackConn(host, ip, 1)
+		return &trackedConn{Conn: conn, host: host, ip: ip}, nil
+	}
+}
+
+// RefreshDNS re-resolves host names of all open connections to upstreams, if
+// any connection uses an IP that's no longer returned for its host name idle
+// connections are closed, so the next request will connect to the current IP,
+// connections that are in use are closed by a later refresh once they're idle,
+// it returns host names that have stale connections
+func RefreshDNS(ctx context.Context) []string {
+	dialed.Lock()
+	hosts := map[string][]string{}
+	for host, ips := range dialed.conns {
+		for ip := range ips {
+			hosts[host] = append(hosts[host], ip)
+		}
+	}
+	dialed.Unlock()
+
+	stale := []string{}
+	for host, ips := range hosts {
+		addrs, err := lookupHost(ctx, host)
+		if err != nil {
+			// keep using existing connections if DNS is failing
+			log.Warningf("Failed to resolve '%s': %s", host, err)
+			continue
+		}
+		for _, ip := range ips {
+			if !slices.StringInSlice(addrs, ip) {
+				stale = append(stale, host)
+				break
+			}
+		}
+	}
+	sort.Strings(stale)
+
+	if len(stale) > 0 {
+		closeIdleConnections()
+	}
+	return stale
+}
+
+// closeIdleConnections closes idle connections of all transports
+func closeIdleConnections() {
+	pool.RLock()
+	if pool.transport != nil {
+		pool.transport.CloseIdleConnections()
+	}
+	pool.RUnlock()
+	proxies.RLock()
+	defer proxies.RUnlock()
+	for _, t := range proxies.transports {
+		t.CloseIdleConnections()
+	}
+}
@@ -0,0 +1,93 @@
+package transport
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+)
+
+func TestRefreshDNS(t *testing.T) {
+	log.SetLevel(log.ErrorLevel)
+
+	var lock sync.Mutex
+	conns := 0
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "{}")
+	}))
+	server.Config.ConnState = func(c net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			lock.Lock()
+			conns++
+			lock.Unlock()
+		}
+	}
+	server.Start()
+	defer server.Close()
+	uri := strings.Replace(server.URL, "127.0.0.1", "localhost", 1)
+
+	var addrs []string
+	var lookupErr error
+	defer func(f func(context.Context, string) ([]string, error)) { lookupHost = f }(lookupHost)
+	lookupHost = func(ctx context.Context, host string) ([]string, error) {
+		if host != "localhost" {
+			t.Errorf("Unexpected lookup for '%s'", host)
+		}
+		return addrs, lookupErr
+	}
+
+	pool.Lock()
+	pool.transport = NewTransport(DefaultPoolSettings)
+	client := &http.Client{Transport: pool.transport}
+	pool.Unlock()
+	defer func() {
+		pool.Lock()
+		pool.transport.CloseIdleConnections()
+		pool.transport = nil
+		pool.Unlock()
+	}()
+
+	get := func() {
+		resp, err := client.Get(uri)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+	}
+
+	for _, testCase := range []struct {
+		addrs []string
+		err   error
+		stale []string
+		conns int
+	}{
+		{addrs: []string{"127.0.0.1"}, stale: []string{}, conns: 1},
+		{addrs: []string{"::1", "127.0.0.1"}, stale: []string{}, conns: 1},
+		{err: fmt.Errorf("no such host"), stale: []string{}, conns: 1},
+		{addrs: []string{"10.0.0.1"}, stale: []string{"localhost"}, conns: 2},
+		{addrs: []string{"127.0.0.1"}, stale: []string{}, conns: 2},
+	} {
+		get()
+		addrs = testCase.addrs
+		lookupErr = testCase.err
+		stale := RefreshDNS(context.Background())
+		if fmt.Sprint(stale) != fmt.Sprint(testCase.stale) {
+			t.Errorf("[%v] Got stale hosts %v, expected %v", testCase.addrs, stale, testCase.stale)
+		}
+		get()
+		lock.Lock()
+		if conns != testCase.conns {
+			t.Errorf("[%v] Got %d connection(s), expected %d", testCase.addrs, conns, testCase.conns)
+		}
+		lock.Unlock()
+	}
+}
@@ -0,0 +1,68 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+)
+
+// classes of errors returned by ErrorClass
+const (
+	ErrorClassTimeout    = "timeout"
+	ErrorClassDNS        = "dns"
+	ErrorClassConnection = "connection"
+	ErrorClassHTTP       = "http"
+	ErrorClassDecode     = "decode"
+	ErrorClassOther      = "other"
+)
+
+// HTTPError is returned when the remote server responds with a status other
+// than 200
+type HTTPError struct {
+	StatusCode int
+	Status     string
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("Request to Alertmanager failed with %s", e.Status)
+}
+
+// DecodeError is returned when the response can't be decoded
+type DecodeError struct {
+	Err error
+}
+
+func (e *DecodeError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying decoder error
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// ErrorClass returns the class of an error returned when reading from any
+// transport, reading the response body can also time out while it's being
+// decoded, timeouts are always reported as such
+func ErrorClass(err error) string {
+	var netErr net.Error
+	var dnsErr *net.DNSError
+	var opErr *net.OpError
+	var httpErr *HTTPError
+	var decodeErr *DecodeError
+	switch {
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return ErrorClassTimeout
+	case errors.As(err, &dnsErr):
+		return ErrorClassDNS
+	case errors.As(err, &opErr):
+		return ErrorClassConnection
+	case errors.As(err, &httpErr):
+		return ErrorClassHTTP
+	case errors.As(err, &decodeErr):
+		return ErrorClassDecode
+	default:
+		return ErrorClassOther
+	}
+}
@@ -0,0 +1,58 @@
+package transport
+
+import (
+	"crypto/rand"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// RequestIDHeader is the name of the header with the ID of every request sent
+// to upstreams, it's also logged, so requests can be correlated with logs of
+// the upstream
+const RequestIDHeader = "X-Request-ID"
+
+var userAgent = struct {
+	sync.RWMutex
+	value string
+}{value: "unsee"}
+
+// SetUserAgent sets the value of the User-Agent header sent with all requests
+// to upstreams
+func SetUserAgent(value string) {
+	userAgent.Lock()
+	defer userAgent.Unlock()
+	userAgent.value = value
+}
+
+// UserAgent returns the value of the User-Agent header sent with all requests
+// to upstreams
+func UserAgent() string {
+	userAgent.RLock()
+	defer userAgent.RUnlock()
+	return userAgent.value
+}
+
+// NewRequestID returns a random ID for a request sent to an upstream
+func NewRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return fmt.Sprintf("%x", b)
+}
+
+// SetRequestHeaders sets User-Agent and X-Request-ID headers on a request sent
+// to an upstream, the request ID is only generated if the request doesn't have
+// one yet, it returns the request ID
+// Authorization header is also set if there are credentials for the upstream
+func SetRequestHeaders(req *http.Request) string {
+	req.Header.Set("User-Agent", UserAgent())
+	setAuthorization(req)
+	id := req.Header.Get(RequestIDHeader)
+	if id == "" {
+		id = NewRequestID()
+		req.Header.Set(RequestIDHeader, id)
+	}
+	return id
+}
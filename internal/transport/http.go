@@ -15,38 +15,62 @@ type httpReader struct {
 	Timeout time.Duration
 }
 
-func newHTTPReader(url string, timeout time.Duration) (io.ReadCloser, error) {
-	hr := httpReader{URL: url, Timeout: timeout}
+// gzipReader decompresses the response body as it's read, closing it will
+// also close the body
+type gzipReader struct {
+	*gzip.Reader
+	body io.ReadCloser
+}
 
-	log.Infof("GET %s timeout=%s", hr.URL, hr.Timeout)
+func (gr *gzipReader) Close() error {
+	gr.Reader.Close()
+	return gr.body.Close()
+}
+
+// newHTTPReader sends a GET request and returns the response body with the
+// value of the Date header, which is zero if it's missing or invalid
+func newHTTPReader(url string, timeout time.Duration) (io.ReadCloser, time.Time, error) {
+	hr := httpReader{URL: url, Timeout: timeout}
 
 	c := &http.Client{
-		Timeout: timeout,
+		Timeout:   timeout,
+		Transport: RoundTripper(url),
 	}
 
 	req, err := http.NewRequest("GET", hr.URL, nil)
 	if err != nil {
-		return nil, err
+		return nil, time.Time{}, err
 	}
 	req.Header.Add("Accept-Encoding", "gzip")
+	requestID := SetRequestHeaders(req)
+
+	log.Infof("GET %s timeout=%s request_id=%s", hr.URL, hr.Timeout, requestID)
+	start := time.Now()
 	resp, err := c.Do(req)
 	if err != nil {
-		return nil, err
+		log.Errorf("GET %s failed after %s request_id=%s: %s", hr.URL, time.Since(start), requestID, err)
+		return nil, time.Time{}, err
 	}
+	log.Infof("GET %s returned %s in %s request_id=%s", hr.URL, resp.Status, time.Since(start), requestID)
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("Request to Alertmanager failed with %s", resp.Status)
+		resp.Body.Close()
+		return nil, time.Time{}, &HTTPError{StatusCode: resp.StatusCode, Status: resp.Status}
 	}
 
+	date, _ := http.ParseTime(resp.Header.Get("Date"))
+
 	var reader io.ReadCloser
 	switch resp.Header.Get("Content-Encoding") {
 	case "gzip":
-		reader, err = gzip.NewReader(resp.Body)
+		gz, err := gzip.NewReader(resp.Body)
 		if err != nil {
-			return nil, fmt.Errorf("Failed to decode gzipped content: %s", err.Error())
+			resp.Body.Close()
+			return nil, time.Time{}, &DecodeError{Err: fmt.Errorf("Failed to decode gzipped content: %s", err.Error())}
 		}
+		reader = &gzipReader{Reader: gz, body: resp.Body}
 	default:
 		reader = resp.Body
 	}
-	return reader, nil
+	return reader, date, nil
 }
@@ -0,0 +1,103 @@
+package transport
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/net/http2"
+)
+
+// PoolSettings controls how connections to upstreams are pooled and reused
+// between requests
+type PoolSettings struct {
+	// MaxIdleConnsPerHost is the number of idle connections kept open for
+	// every upstream host, 0 uses the default of 2
+	MaxIdleConnsPerHost int
+	// IdleConnTimeout is how long idle connections are kept open, 0 keeps
+	// them open until the upstream closes them
+	IdleConnTimeout time.Duration
+	// KeepAlive is the interval of TCP keepalive probes, 0 disables them
+	KeepAlive time.Duration
+	// DisableHTTP2 forces HTTP/1.1 for connections to HTTPS upstreams
+	DisableHTTP2 bool
+}
+
+// DefaultPoolSettings mirrors settings of http.DefaultTransport
+var DefaultPoolSettings = PoolSettings{
+	MaxIdleConnsPerHost: http.DefaultMaxIdleConnsPerHost,
+	IdleConnTimeout:     90 * time.Second,
+	KeepAlive:           30 * time.Second,
+}
+
+var pool = struct {
+	sync.RWMutex
+	settings PoolSettings
+	// transport is nil until SetPoolSettings is called, so http.DefaultTransport
+	// is used
+	transport *http.Transport
+}{settings: DefaultPoolSettings}
+
+// NewTransport returns a transport using given pool settings, all requests
+// sent using it share the same connection pool
+func NewTransport(s PoolSettings) *http.Transport {
+	keepAlive := s.KeepAlive
+	if keepAlive == 0 {
+		// 0 would enable keepalive probes with the default interval
+		keepAlive = -1
+	}
+	t := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: trackingDialer((&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: keepAlive,
+		}).DialContext),
+		MaxIdleConnsPerHost:   s.MaxIdleConnsPerHost,
+		IdleConnTimeout:       s.IdleConnTimeout,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+	if s.DisableHTTP2 {
+		// a non-nil empty map prevents net/http from negotiating HTTP/2
+		t.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	} else {
+		// HTTP/2 isn't enabled automatically on transports with a custom dialer
+		_ = http2.ConfigureTransport(t)
+	}
+	return t
+}
+
+// SetPoolSettings replaces the transport used for all requests to upstreams,
+// it should be called before any proxy is set with SetProxy, since proxied
+// upstreams use transports created with settings passed here
+func SetPoolSettings(s PoolSettings) {
+	t := NewTransport(s)
+	pool.Lock()
+	old := pool.transport
+	pool.settings = s
+	pool.transport = t
+	pool.Unlock()
+	if old != nil {
+		old.CloseIdleConnections()
+	}
+}
+
+// GetPoolSettings returns settings used for new transports
+func GetPoolSettings() PoolSettings {
+	pool.RLock()
+	defer pool.RUnlock()
+	return pool.settings
+}
+
+// sharedTransport returns the transport set with SetPoolSettings or nil if it
+// wasn't called yet
+func sharedTransport() http.RoundTripper {
+	pool.RLock()
+	defer pool.RUnlock()
+	if pool.transport == nil {
+		return nil
+	}
+	return pool.transport
+}
@@ -0,0 +1,89 @@
+package transport_test
+
+import (
+	"crypto/tls"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cloudflare/unsee/internal/transport"
+)
+
+func TestNewTransport(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "{}")
+	}))
+	server.TLS = &tls.Config{NextProtos: []string{"h2", "http/1.1"}}
+	server.StartTLS()
+	defer server.Close()
+
+	for _, testCase := range []struct {
+		settings   transport.PoolSettings
+		protoMajor int
+	}{
+		{settings: transport.DefaultPoolSettings, protoMajor: 2},
+		{settings: transport.PoolSettings{MaxIdleConnsPerHost: 8, IdleConnTimeout: time.Minute * 10}, protoMajor: 2},
+		{settings: transport.PoolSettings{MaxIdleConnsPerHost: 1, DisableHTTP2: true}, protoMajor: 1},
+	} {
+		tr := transport.NewTransport(testCase.settings)
+		if tr.MaxIdleConnsPerHost != testCase.settings.MaxIdleConnsPerHost {
+			t.Errorf("[%v] Got MaxIdleConnsPerHost=%d", testCase.settings, tr.MaxIdleConnsPerHost)
+		}
+		if tr.IdleConnTimeout != testCase.settings.IdleConnTimeout {
+			t.Errorf("[%v] Got IdleConnTimeout=%s", testCase.settings, tr.IdleConnTimeout)
+		}
+
+		if tr.TLSClientConfig == nil {
+			tr.TLSClientConfig = &tls.Config{}
+		}
+		tr.TLSClientConfig.InsecureSkipVerify = true
+		resp, err := (&http.Client{Transport: tr}).Get(server.URL)
+		if err != nil {
+			t.Errorf("[%v] Request failed: %s", testCase.settings, err)
+			continue
+		}
+		resp.Body.Close()
+		if resp.ProtoMajor != testCase.protoMajor {
+			t.Errorf("[%v] Got response using %s, expected HTTP/%d", testCase.settings, resp.Proto, testCase.protoMajor)
+		}
+		tr.CloseIdleConnections()
+	}
+}
+
+func TestTransportReusesConnections(t *testing.T) {
+	var lock sync.Mutex
+	conns := 0
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "{}")
+	}))
+	server.Config.ConnState = func(c net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			lock.Lock()
+			conns++
+			lock.Unlock()
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	client := &http.Client{Transport: transport.NewTransport(transport.DefaultPoolSettings)}
+	for i := 0; i < 3; i++ {
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+	if conns != 1 {
+		t.Errorf("Expected 1 connection, got %d", conns)
+	}
+}
@@ -0,0 +1,73 @@
+package transport
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/cloudflare/unsee/internal/slices"
+)
+
+// ProxySchemes lists URI schemes supported by SetProxy
+var ProxySchemes = []string{"socks5", "socks5h", "http", "https"}
+
+var proxies = struct {
+	sync.RWMutex
+	// transports keyed by the URI prefix they're used for
+	transports map[string]*http.Transport
+}{transports: map[string]*http.Transport{}}
+
+// ParseProxyURL validates the URI of a proxy server, it must use one of the
+// supported schemes and include a host
+func ParseProxyURL(proxyURL string) (*url.URL, error) {
+	u, err := url.Parse(proxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid proxy URL '%s': %s", proxyURL, err)
+	}
+	if !slices.StringInSlice(ProxySchemes, u.Scheme) {
+		return nil, fmt.Errorf("Unsupported proxy URL scheme '%s' in '%s', supported schemes: %s", u.Scheme, proxyURL, strings.Join(ProxySchemes, ", "))
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("Invalid proxy URL '%s', host is missing", proxyURL)
+	}
+	return u, nil
+}
+
+// SetProxy makes all HTTP requests to URIs starting with uri use given proxy
+// server, passing an empty proxyURL removes the proxy
+func SetProxy(uri, proxyURL string) error {
+	proxies.Lock()
+	defer proxies.Unlock()
+	if proxyURL == "" {
+		delete(proxies.transports, uri)
+		return nil
+	}
+	u, err := ParseProxyURL(proxyURL)
+	if err != nil {
+		return err
+	}
+	t := NewTransport(GetPoolSettings())
+	t.Proxy = http.ProxyURL(u)
+	proxies.transports[uri] = t
+	return nil
+}
+
+// RoundTripper returns the transport that should be used for HTTP requests to
+// given URI, it's the transport shared by all upstreams (or nil if
+// SetPoolSettings wasn't called, so the default transport is used) unless a
+// proxy was set for it
+func RoundTripper(uri string) http.RoundTripper {
+	proxies.RLock()
+	defer proxies.RUnlock()
+	var prefix string
+	rt := sharedTransport()
+	for p, t := range proxies.transports {
+		if hasURIPrefix(uri, p) && len(p) > len(prefix) {
+			prefix = p
+			rt = t
+		}
+	}
+	return rt
+}
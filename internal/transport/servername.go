@@ -0,0 +1,45 @@
+package transport
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// ValidateServerName returns an error if serverName can't be used as the TLS
+// server name, it must be a host name or an IP address without a port
+func ValidateServerName(serverName string) error {
+	if serverName == "" {
+		return fmt.Errorf("TLS server name can't be empty")
+	}
+	if strings.ContainsAny(serverName, ":/@ ") && net.ParseIP(serverName) == nil {
+		return fmt.Errorf("Invalid TLS server name '%s', expected a host name without scheme or port", serverName)
+	}
+	return nil
+}
+
+// SetServerName makes all HTTPS requests to URIs starting with uri send
+// serverName using SNI and verify the certificate presented by the server
+// against it instead of the host from the URI, the Host header isn't changed
+// Requests to uri get their own transport, so it should be called after
+// SetProxy
+func SetServerName(uri, serverName string) error {
+	if err := ValidateServerName(serverName); err != nil {
+		return err
+	}
+
+	proxies.Lock()
+	defer proxies.Unlock()
+	t, found := proxies.transports[uri]
+	if !found {
+		t = NewTransport(GetPoolSettings())
+		proxies.transports[uri] = t
+	}
+	if t.TLSClientConfig == nil {
+		t.TLSClientConfig = &tls.Config{}
+	}
+	t.TLSClientConfig.ServerName = serverName
+	t.CloseIdleConnections()
+	return nil
+}
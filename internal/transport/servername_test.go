@@ -0,0 +1,90 @@
+package transport_test
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cloudflare/unsee/internal/transport"
+)
+
+func TestValidateServerName(t *testing.T) {
+	type testCaseT struct {
+		serverName string
+		valid      bool
+	}
+	for _, testCase := range []testCaseT{
+		{serverName: "alertmanager.example.com", valid: true},
+		{serverName: "localhost", valid: true},
+		{serverName: "10.0.0.1", valid: true},
+		{serverName: "::1", valid: true},
+		{serverName: ""},
+		{serverName: "alertmanager.example.com:9093"},
+		{serverName: "https://alertmanager.example.com"},
+	} {
+		if err := transport.ValidateServerName(testCase.serverName); (err == nil) != testCase.valid {
+			t.Errorf("[%s] ValidateServerName() returned %v, expected valid=%v", testCase.serverName, err, testCase.valid)
+		}
+	}
+}
+
+func TestSetServerName(t *testing.T) {
+	uri := "https://10.0.0.1:9093"
+	defer transport.SetProxy(uri, "")
+
+	if err := transport.SetServerName(uri, "alertmanager.example.com"); err != nil {
+		t.Fatal(err)
+	}
+	rt, ok := transport.RoundTripper(uri + "/api/v1/alerts/groups").(*http.Transport)
+	if !ok || rt.TLSClientConfig == nil || rt.TLSClientConfig.ServerName != "alertmanager.example.com" {
+		t.Fatalf("TLS server name wasn't set on the transport for %s", uri)
+	}
+
+	// client certificates are added to the same transport
+	transport.SetClientCertificate(uri, tls.Certificate{Certificate: [][]byte{[]byte("cert")}})
+	rt, ok = transport.RoundTripper(uri).(*http.Transport)
+	if !ok || rt.TLSClientConfig.ServerName != "alertmanager.example.com" || rt.TLSClientConfig.GetClientCertificate == nil {
+		t.Errorf("TLS server name or client certificate is missing after setting a client certificate")
+	}
+
+	if rt, ok := transport.RoundTripper("https://10.0.0.2:9093").(*http.Transport); ok && rt.TLSClientConfig != nil && rt.TLSClientConfig.ServerName != "" {
+		t.Errorf("TLS server name was used for a different URI")
+	}
+
+	if err := transport.SetServerName(uri, "example.com:443"); err == nil {
+		t.Errorf("SetServerName() accepted a server name with a port")
+	}
+}
+
+func TestSetServerNameVerifiesCertificate(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+	defer transport.SetProxy(ts.URL, "")
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ts.Certificate())
+	get := func(serverName string) (*http.Response, error) {
+		if err := transport.SetServerName(ts.URL, serverName); err != nil {
+			t.Fatal(err)
+		}
+		rt := transport.RoundTripper(ts.URL).(*http.Transport)
+		rt.TLSClientConfig.RootCAs = roots
+		return (&http.Client{Transport: rt}).Get(ts.URL)
+	}
+
+	// the httptest certificate is only valid for example.com and loopback
+	// addresses
+	resp, err := get("example.com")
+	if err != nil {
+		t.Fatalf("Request with a valid TLS server name failed: %s", err)
+	}
+	resp.Body.Close()
+	if resp.TLS == nil || resp.TLS.VerifiedChains == nil {
+		t.Errorf("Certificate wasn't verified")
+	}
+	if _, err = get("alertmanager.example.org"); err == nil {
+		t.Errorf("Request with a TLS server name not matching the certificate didn't fail")
+	}
+}
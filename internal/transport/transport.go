@@ -8,24 +8,124 @@ import (
 	"time"
 )
 
-// ReadJSON using one of supported transports (file:// http://)
-func ReadJSON(uri string, timeout time.Duration, target interface{}) error {
+// newReader returns a reader for given URI and the time reported by the
+// server, which is only known for http:// and https:// URIs
+func newReader(uri string, timeout time.Duration) (io.ReadCloser, time.Time, error) {
 	u, err := url.Parse(uri)
 	if err != nil {
-		return err
+		return nil, time.Time{}, err
 	}
-	var reader io.ReadCloser
 	switch u.Scheme {
 	case "http", "https":
-		reader, err = newHTTPReader(u.String(), timeout)
+		return newHTTPReader(u.String(), timeout)
 	case "file":
-		reader, err = newFileReader(u.Path)
+		reader, err := newFileReader(u.Path)
+		return reader, time.Time{}, err
 	default:
-		return fmt.Errorf("Unsupported URI scheme '%s' in '%s'", u.Scheme, u)
+		return nil, time.Time{}, fmt.Errorf("Unsupported URI scheme '%s' in '%s'", u.Scheme, u)
+	}
+}
+
+// ReadJSON using one of supported transports (file:// http://)
+func ReadJSON(uri string, timeout time.Duration, target interface{}) error {
+	_, err := ReadJSONWithDate(uri, timeout, target)
+	return err
+}
+
+// ReadJSONWithDate works like ReadJSON but it also returns the time from the
+// Date header of the response, it's zero if the server didn't send it or if
+// the transport doesn't support it
+func ReadJSONWithDate(uri string, timeout time.Duration, target interface{}) (time.Time, error) {
+	reader, date, err := newReader(uri, timeout)
+	if err != nil {
+		return time.Time{}, err
 	}
+	defer reader.Close()
+	if err = json.NewDecoder(reader).Decode(target); err != nil {
+		return date, &DecodeError{Err: err}
+	}
+	return date, nil
+}
+
+// StreamJSON reads a JSON object using one of supported transports, elements
+// of the array stored under the field key are decoded one by one as they're
+// read and passed to handler, so the array is never kept in memory, all other
+// fields are decoded into target
+func StreamJSON(uri string, timeout time.Duration, field string, handler func(*json.Decoder) error, target interface{}) error {
+	reader, _, err := newReader(uri, timeout)
 	if err != nil {
 		return err
 	}
 	defer reader.Close()
-	return json.NewDecoder(reader).Decode(target)
+
+	if err = decodeStream(json.NewDecoder(reader), field, handler, target); err != nil {
+		return &DecodeError{Err: err}
+	}
+	return nil
+}
+
+func decodeStream(dec *json.Decoder, field string, handler func(*json.Decoder) error, target interface{}) error {
+	if err := expectDelim(dec, '{'); err != nil {
+		return err
+	}
+	fields := map[string]json.RawMessage{}
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		key, ok := token.(string)
+		if !ok {
+			return fmt.Errorf("Expected object key, got %v", token)
+		}
+		if key != field {
+			var value json.RawMessage
+			if err = dec.Decode(&value); err != nil {
+				return err
+			}
+			fields[key] = value
+			continue
+		}
+
+		token, err = dec.Token()
+		if err != nil {
+			return err
+		}
+		if token == nil {
+			// error responses might have null here
+			continue
+		}
+		if delim, ok := token.(json.Delim); !ok || delim != '[' {
+			return fmt.Errorf("Expected array under '%s', got %v", field, token)
+		}
+		for dec.More() {
+			if err = handler(dec); err != nil {
+				return err
+			}
+		}
+		if err = expectDelim(dec, ']'); err != nil {
+			return err
+		}
+	}
+	if err := expectDelim(dec, '}'); err != nil {
+		return err
+	}
+
+	// remaining fields are small, so it's fine to decode those again
+	remaining, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(remaining, target)
+}
+
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if d, ok := token.(json.Delim); !ok || d != delim {
+		return fmt.Errorf("Expected '%s', got %v", delim, token)
+	}
+	return nil
 }
@@ -1,7 +1,14 @@
 package transport_test
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"reflect"
 	"testing"
 	"time"
 
@@ -16,6 +23,7 @@ type transportTest struct {
 	uri     string
 	timeout time.Duration
 	failed  bool
+	class   string
 }
 
 var transportTests = []transportTest{
@@ -25,10 +33,12 @@ var transportTests = []transportTest{
 	transportTest{
 		uri:    "http://localhost/404",
 		failed: true,
+		class:  transport.ErrorClassHTTP,
 	},
 	transportTest{
 		uri:    "http://localhost/invalid",
 		failed: true,
+		class:  transport.ErrorClassDecode,
 	},
 	transportTest{
 		uri: "https://localhost/status",
@@ -36,10 +46,12 @@ var transportTests = []transportTest{
 	transportTest{
 		uri:    "https://localhost/404",
 		failed: true,
+		class:  transport.ErrorClassHTTP,
 	},
 	transportTest{
 		uri:    "https://localhost/invalid",
 		failed: true,
+		class:  transport.ErrorClassDecode,
 	},
 	transportTest{
 		uri: fmt.Sprintf("file://%s", mock.GetAbsoluteMockPath("status", mock.ListAllMocks()[0])),
@@ -47,6 +59,7 @@ var transportTests = []transportTest{
 	transportTest{
 		uri:    "file:///non-existing-file.abcdef",
 		failed: true,
+		class:  transport.ErrorClassOther,
 	},
 	transportTest{
 		uri:    "file://transport.go",
@@ -84,5 +97,171 @@ func TestFileReader(t *testing.T) {
 		if (err != nil) != testCase.failed {
 			t.Errorf("[%s] Expected failure: %v, Read() failed: %v, error: %s", testCase.uri, testCase.failed, (err != nil), err)
 		}
+		if err != nil && testCase.class != "" && transport.ErrorClass(err) != testCase.class {
+			t.Errorf("[%s] Expected error class '%s', got '%s' for error: %s", testCase.uri, testCase.class, transport.ErrorClass(err), err)
+		}
+	}
+}
+
+type streamTest struct {
+	body     string
+	elements []int
+	status   string
+	failed   bool
+}
+
+var streamTests = []streamTest{
+	streamTest{
+		body:     `{"status": "success", "data": [1, 2, 3]}`,
+		elements: []int{1, 2, 3},
+		status:   "success",
+	},
+	streamTest{
+		body:     `{"data": [], "status": "success"}`,
+		elements: []int{},
+		status:   "success",
+	},
+	streamTest{
+		body:     `{"status": "error", "data": null}`,
+		elements: []int{},
+		status:   "error",
+	},
+	streamTest{
+		body:     `{"status": "success"}`,
+		elements: []int{},
+		status:   "success",
+	},
+	streamTest{
+		body:   `{"status": "success", "data": {"foo": 1}}`,
+		failed: true,
+	},
+	streamTest{
+		body:   `{"status": "success", "data": [1, "foo"]}`,
+		failed: true,
+	},
+	streamTest{
+		body:   `{"status": "success", "data": [1, 2`,
+		failed: true,
+	},
+	streamTest{
+		body:   `[1, 2]`,
+		failed: true,
+	},
+}
+
+type streamStatus struct {
+	Status string `json:"status"`
+}
+
+func TestStreamJSON(t *testing.T) {
+	log.SetLevel(log.ErrorLevel)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	for _, testCase := range streamTests {
+		httpmock.RegisterResponder("GET", "http://localhost/stream", httpmock.NewStringResponder(200, testCase.body))
+		elements := []int{}
+		r := streamStatus{}
+		err := transport.StreamJSON("http://localhost/stream", time.Second, "data", func(dec *json.Decoder) error {
+			var i int
+			if err := dec.Decode(&i); err != nil {
+				return err
+			}
+			elements = append(elements, i)
+			return nil
+		}, &r)
+		if (err != nil) != testCase.failed {
+			t.Errorf("[%s] Expected failure: %v, StreamJSON() failed: %v, error: %s", testCase.body, testCase.failed, (err != nil), err)
+		}
+		if err != nil && transport.ErrorClass(err) != transport.ErrorClassDecode {
+			t.Errorf("[%s] Expected error class '%s', got '%s'", testCase.body, transport.ErrorClassDecode, transport.ErrorClass(err))
+		}
+		if testCase.failed {
+			continue
+		}
+		if !reflect.DeepEqual(elements, testCase.elements) {
+			t.Errorf("[%s] Expected elements %v, got %v", testCase.body, testCase.elements, elements)
+		}
+		if r.Status != testCase.status {
+			t.Errorf("[%s] Expected status '%s', got '%s'", testCase.body, testCase.status, r.Status)
+		}
+	}
+}
+
+type errorClassTest struct {
+	err   error
+	class string
+}
+
+var errorClassTests = []errorClassTest{
+	errorClassTest{
+		err:   context.DeadlineExceeded,
+		class: transport.ErrorClassTimeout,
+	},
+	errorClassTest{
+		err:   &url.Error{Op: "Get", URL: "http://localhost", Err: &net.DNSError{Err: "no such host", Name: "localhost", IsTimeout: true}},
+		class: transport.ErrorClassTimeout,
+	},
+	errorClassTest{
+		err:   &url.Error{Op: "Get", URL: "http://localhost", Err: &net.OpError{Op: "dial", Err: &net.DNSError{Err: "no such host", Name: "localhost"}}},
+		class: transport.ErrorClassDNS,
+	},
+	errorClassTest{
+		err:   &url.Error{Op: "Get", URL: "http://localhost", Err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}},
+		class: transport.ErrorClassConnection,
+	},
+	errorClassTest{
+		err:   &transport.HTTPError{StatusCode: 500, Status: "500 Internal Server Error"},
+		class: transport.ErrorClassHTTP,
+	},
+	errorClassTest{
+		err:   &transport.DecodeError{Err: errors.New("unexpected EOF")},
+		class: transport.ErrorClassDecode,
+	},
+	errorClassTest{
+		err:   errors.New("foo"),
+		class: transport.ErrorClassOther,
+	},
+}
+
+func TestErrorClass(t *testing.T) {
+	for _, testCase := range errorClassTests {
+		if class := transport.ErrorClass(testCase.err); class != testCase.class {
+			t.Errorf("[%s] Expected error class '%s', got '%s'", testCase.err, testCase.class, class)
+		}
+	}
+}
+
+func TestRequestHeaders(t *testing.T) {
+	log.SetLevel(log.ErrorLevel)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	transport.SetUserAgent("unsee/test")
+	defer transport.SetUserAgent("unsee")
+
+	requestIDs := []string{}
+	httpmock.RegisterResponder("GET", "http://localhost/headers", func(req *http.Request) (*http.Response, error) {
+		if ua := req.Header.Get("User-Agent"); ua != "unsee/test" {
+			t.Errorf("Got User-Agent '%s', expected 'unsee/test'", ua)
+		}
+		requestIDs = append(requestIDs, req.Header.Get(transport.RequestIDHeader))
+		return httpmock.NewStringResponse(200, "{}"), nil
+	})
+
+	for i := 0; i < 2; i++ {
+		r := map[string]string{}
+		if err := transport.ReadJSON("http://localhost/headers", time.Second, &r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(requestIDs) != 2 || requestIDs[0] == "" || requestIDs[0] == requestIDs[1] {
+		t.Errorf("Expected 2 unique request IDs, got %v", requestIDs)
+	}
+
+	req, _ := http.NewRequest("GET", "http://localhost", nil)
+	req.Header.Set(transport.RequestIDHeader, "foo")
+	if id := transport.SetRequestHeaders(req); id != "foo" {
+		t.Errorf("SetRequestHeaders() returned '%s', expected the request ID already set on the request", id)
 	}
 }
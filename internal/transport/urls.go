@@ -3,6 +3,7 @@ package transport
 import (
 	"net/url"
 	"path"
+	"strings"
 )
 
 // JoinURL can be used to join a base url (http(s)://domain.com) and a path (/my/path)
@@ -15,3 +16,20 @@ func JoinURL(base string, sub string) (string, error) {
 	u.Path = path.Join(u.Path, sub)
 	return u.String(), nil
 }
+
+// hasURIPrefix returns true if uri is equal to prefix or if prefix is followed
+// by a path, query or fragment, so that prefix "http://alertmanager" won't
+// match "http://alertmanager-staging"
+func hasURIPrefix(uri, prefix string) bool {
+	if !strings.HasPrefix(uri, prefix) {
+		return false
+	}
+	if len(uri) == len(prefix) || strings.HasSuffix(prefix, "/") {
+		return true
+	}
+	switch uri[len(prefix)] {
+	case '/', '?', '#':
+		return true
+	}
+	return false
+}
@@ -0,0 +1,118 @@
+package vault
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Client reads secrets from a HashiCorp Vault server using the HTTP API
+type Client struct {
+	addr    string
+	token   string
+	timeout time.Duration
+}
+
+// Secret is a secret read from Vault, LeaseDuration is how long it can be
+// used for before it needs to be renewed or read again
+type Secret struct {
+	Data          map[string]string
+	LeaseID       string
+	LeaseDuration time.Duration
+	Renewable     bool
+}
+
+type secretResponse struct {
+	LeaseID       string                 `json:"lease_id"`
+	LeaseDuration int                    `json:"lease_duration"`
+	Renewable     bool                   `json:"renewable"`
+	Data          map[string]interface{} `json:"data"`
+	Errors        []string               `json:"errors"`
+}
+
+// NewClient returns a client for the Vault server at addr, all requests are
+// authenticated using token
+func NewClient(addr, token string, timeout time.Duration) *Client {
+	return &Client{addr: strings.TrimSuffix(addr, "/"), token: token, timeout: timeout}
+}
+
+// Read returns the secret stored at path, both versions of the KV secrets
+// engine are supported, KV version 2 paths must include the data/ segment,
+// like secret/data/alertmanager
+// Only string values are returned, other values are ignored
+func (c *Client) Read(path string) (*Secret, error) {
+	resp := secretResponse{}
+	if err := c.send(http.MethodGet, "/v1/"+strings.TrimPrefix(path, "/"), nil, &resp); err != nil {
+		return nil, err
+	}
+	data := resp.Data
+	// KV version 2 stores the secret under data and versioning details under
+	// metadata
+	if nested, ok := resp.Data["data"].(map[string]interface{}); ok {
+		if _, ok = resp.Data["metadata"]; ok {
+			data = nested
+		}
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("Vault secret '%s' not found", path)
+	}
+	secret := newSecret(resp)
+	for k, v := range data {
+		if s, ok := v.(string); ok {
+			secret.Data[k] = s
+		}
+	}
+	return secret, nil
+}
+
+// Renew extends the lease of a secret, only the lease details of the
+// returned secret are set, data needs to be read again after renewal fails
+func (c *Client) Renew(leaseID string) (*Secret, error) {
+	body, err := json.Marshal(map[string]string{"lease_id": leaseID})
+	if err != nil {
+		return nil, err
+	}
+	resp := secretResponse{}
+	if err = c.send(http.MethodPut, "/v1/sys/leases/renew", body, &resp); err != nil {
+		return nil, err
+	}
+	return newSecret(resp), nil
+}
+
+func newSecret(resp secretResponse) *Secret {
+	return &Secret{
+		Data:          map[string]string{},
+		LeaseID:       resp.LeaseID,
+		LeaseDuration: time.Duration(resp.LeaseDuration) * time.Second,
+		Renewable:     resp.Renewable,
+	}
+}
+
+// send sends a request to the Vault API and decodes the JSON response
+func (c *Client) send(method, path string, body []byte, target *secretResponse) error {
+	req, err := http.NewRequest(method, c.addr+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Vault-Token", c.token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	client := &http.Client{Timeout: c.timeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		// errors are returned as JSON, but only include them if it was decoded
+		if json.NewDecoder(resp.Body).Decode(target) == nil && len(target.Errors) > 0 {
+			return fmt.Errorf("Vault request to %s returned status %s: %s", path, resp.Status, strings.Join(target.Errors, ", "))
+		}
+		return fmt.Errorf("Vault request to %s returned status %s", path, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(target)
+}
@@ -0,0 +1,93 @@
+package vault_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cloudflare/unsee/internal/vault"
+)
+
+func TestRead(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "root" {
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"errors": ["permission denied"]}`)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/kv/alertmanager":
+			fmt.Fprint(w, `{"lease_duration": 3600, "data": {"username": "user", "password": "pass", "port": 9093}}`)
+		case "/v1/secret/data/alertmanager":
+			fmt.Fprint(w, `{"data": {"data": {"token": "secret"}, "metadata": {"version": 2}}}`)
+		case "/v1/database/creds/alertmanager":
+			fmt.Fprint(w, `{"lease_id": "database/creds/alertmanager/abc", "lease_duration": 60, "renewable": true, "data": {"username": "v-user", "password": "v-pass"}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"errors": []}`)
+		}
+	}))
+	defer srv.Close()
+
+	client := vault.NewClient(srv.URL+"/", "root", time.Second)
+	for _, testCase := range []struct {
+		path   string
+		secret vault.Secret
+	}{
+		{
+			path:   "kv/alertmanager",
+			secret: vault.Secret{Data: map[string]string{"username": "user", "password": "pass"}, LeaseDuration: time.Hour},
+		},
+		{
+			path:   "/secret/data/alertmanager",
+			secret: vault.Secret{Data: map[string]string{"token": "secret"}},
+		},
+		{
+			path: "database/creds/alertmanager",
+			secret: vault.Secret{
+				Data:          map[string]string{"username": "v-user", "password": "v-pass"},
+				LeaseID:       "database/creds/alertmanager/abc",
+				LeaseDuration: time.Minute,
+				Renewable:     true,
+			},
+		},
+	} {
+		secret, err := client.Read(testCase.path)
+		if err != nil {
+			t.Errorf("Read(%s) returned an error: %s", testCase.path, err)
+			continue
+		}
+		if !reflect.DeepEqual(*secret, testCase.secret) {
+			t.Errorf("Invalid secret read from %s, expected %v, got %v", testCase.path, testCase.secret, *secret)
+		}
+	}
+
+	if _, err := client.Read("kv/missing"); err == nil {
+		t.Error("Read() didn't return any error for a missing secret")
+	}
+	if _, err := vault.NewClient(srv.URL, "invalid", time.Second).Read("kv/alertmanager"); err == nil {
+		t.Error("Read() didn't return any error for an invalid token")
+	}
+}
+
+func TestRenew(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || r.URL.Path != "/v1/sys/leases/renew" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, `{"lease_id": "database/creds/alertmanager/abc", "lease_duration": 120, "renewable": true}`)
+	}))
+	defer srv.Close()
+
+	secret, err := vault.NewClient(srv.URL, "root", time.Second).Renew("database/creds/alertmanager/abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if secret.LeaseID != "database/creds/alertmanager/abc" || secret.LeaseDuration != 2*time.Minute || !secret.Renewable {
+		t.Errorf("Invalid renewed secret: %v", *secret)
+	}
+}
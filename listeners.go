@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/cloudflare/unsee/internal/config"
+	"github.com/cloudflare/unsee/internal/slices"
+)
+
+// scopes of HTTP listeners configured using LISTEN, health checks are served
+// by listeners with any scope
+const (
+	// listenScopeAll serves every endpoint
+	listenScopeAll = "all"
+	// listenScopePublic serves every endpoint except metrics and debug ones
+	listenScopePublic = "public"
+	// listenScopeAdmin only serves metrics, debug and admin endpoints
+	listenScopeAdmin = "admin"
+)
+
+var listenScopes = []string{listenScopeAll, listenScopePublic, listenScopeAdmin}
+
+// httpListener is a single address HTTP requests are served on
+type httpListener struct {
+	scope   string
+	address string
+}
+
+// getHTTPListeners returns all addresses HTTP requests should be served on,
+// LISTEN entries are either an address or scope:address, if LISTEN is not set
+// all endpoints are served on PORT
+func getHTTPListeners() ([]httpListener, error) {
+	if len(config.Config.Listen) == 0 {
+		return []httpListener{{scope: listenScopeAll, address: fmt.Sprintf(":%d", config.Config.Port)}}, nil
+	}
+
+	listeners := []httpListener{}
+	addresses := []string{}
+	for _, s := range config.Config.Listen {
+		l := httpListener{scope: listenScopeAll, address: s}
+		// addresses can also include colons, so only known scopes are
+		// stripped from the value
+		if parts := strings.SplitN(s, ":", 2); len(parts) == 2 && slices.StringInSlice(listenScopes, parts[0]) {
+			l.scope = parts[0]
+			l.address = parts[1]
+		}
+		if _, _, err := net.SplitHostPort(l.address); err != nil {
+			return nil, fmt.Errorf("Invalid listen address '%s': %s", s, err)
+		}
+		if slices.StringInSlice(addresses, l.address) {
+			return nil, fmt.Errorf("Duplicated listen address '%s'", l.address)
+		}
+		addresses = append(addresses, l.address)
+		listeners = append(listeners, l)
+	}
+	return listeners, nil
+}
+
+// isAdminPath returns true for metrics, debug and admin endpoints
+func isAdminPath(p string) bool {
+	return p == getViewURL("/metrics") || strings.HasPrefix(p, getViewURL("/debug/")) || strings.HasPrefix(p, getViewURL("/admin/"))
+}
+
+// isHealthPath returns true for health check endpoints
+func isHealthPath(p string) bool {
+	return p == getViewURL("/healthz") || p == getViewURL("/readyz")
+}
+
+// listenScope returns a handler that will only pass requests for endpoints
+// served with given scope to the next handler, 404 is returned for all other
+// requests
+func listenScope(next http.Handler, scope string) http.Handler {
+	if scope == listenScopeAll {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		admin := isAdminPath(r.URL.Path)
+		if !isHealthPath(r.URL.Path) && admin != (scope == listenScopeAdmin) {
+			http.NotFound(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// newListenerHTTPServer returns the server used to serve HTTP requests on
+// given listener
+func newListenerHTTPServer(handler http.Handler, l httpListener) *http.Server {
+	server := newHTTPServer(listenScope(handler, l.scope))
+	server.Addr = l.address
+	return server
+}
@@ -1,20 +1,35 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"html/template"
+	"net/http"
+	"net/url"
+	"os"
 	"path"
 	"strings"
 	"time"
 
 	"github.com/cloudflare/unsee/internal/alertmanager"
 	"github.com/cloudflare/unsee/internal/config"
+	"github.com/cloudflare/unsee/internal/counts"
+	"github.com/cloudflare/unsee/internal/events"
+	"github.com/cloudflare/unsee/internal/flapping"
+	"github.com/cloudflare/unsee/internal/hooks"
+	"github.com/cloudflare/unsee/internal/incidents"
+	"github.com/cloudflare/unsee/internal/models"
+	"github.com/cloudflare/unsee/internal/rules"
+	"github.com/cloudflare/unsee/internal/slices"
+	"github.com/cloudflare/unsee/internal/store"
+	"github.com/cloudflare/unsee/internal/tracing"
 	"github.com/cloudflare/unsee/internal/transform"
+	"github.com/cloudflare/unsee/internal/transport"
 
-	"github.com/DeanThompson/ginpprof"
-	"github.com/gin-contrib/gzip"
 	"github.com/gin-contrib/static"
 	"github.com/gin-gonic/contrib/sentry"
 	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
 	"github.com/patrickmn/go-cache"
 
 	raven "github.com/getsentry/raven-go"
@@ -22,8 +37,22 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
+// number of collections to keep in alertHistory
+const alertHistorySize = 10
+
+// maximum number of collections to keep in alertCounts, enough for 2 days of
+// collections every 10 seconds, older samples are also pruned once they're
+// older than HISTORY_RETENTION
+const alertCountsSize = 17280
+
+// maximum number of transitions kept in alertTimeline for every alert
+const alertTimelineSize = 50
+
 var (
 	version = "dev"
+	// commit and buildDate are set at build time using ldflags
+	commit    = "unknown"
+	buildDate = "unknown"
 
 	// ticker is a timer used by background loop that will keep pulling
 	// data from Alertmanager
@@ -33,6 +62,26 @@ var (
 	// If there are requests with the same filter we should respond from cache
 	// rather than do all the filtering every time
 	apiCache *cache.Cache
+
+	// eventBroker delivers alert changes detected after every collection to
+	// clients subscribed to live updates
+	eventBroker = events.NewBroker()
+
+	// alertHistory keeps alert group hashes for recent collections, it's used
+	// to only return changed groups to clients passing the since argument
+	alertHistory = events.NewHistory(alertHistorySize)
+
+	// alertCounts keeps the number of alerts from every collection, it's used
+	// by the history endpoint
+	alertCounts = counts.NewBuffer(alertCountsSize)
+
+	// alertTimeline keeps state transitions of every alert, those are returned
+	// by the alert group details endpoint
+	alertTimeline = events.NewTimeline(alertTimelineSize)
+
+	// dataStore keeps user data like saved filters, it's persisted to disk if
+	// STORE_PATH is set
+	dataStore *store.Store
 )
 
 func getViewURL(sub string) string {
@@ -46,17 +95,408 @@ func getViewURL(sub string) string {
 }
 
 func setupRouter(router *gin.Engine) {
-	router.Use(gzip.Gzip(gzip.DefaultCompression))
+	router.Use(httpMetrics())
+	router.Use(traceRequests())
+	router.Use(accessLog(config.Config.AccessLog))
+
+	compress := compressResponse(config.Config.CompressionMinSize, config.Config.CompressionBrotli)
+	router.Use(func(c *gin.Context) {
+		// websocket connections are hijacked and event streams need to be
+		// flushed after every event, so compression can't be used for those,
+		// proxied responses are passed as they are and profiles are already
+		// compressed
+		if websocket.IsWebSocketUpgrade(c.Request) || c.Request.URL.Path == getViewURL("/events") || strings.HasPrefix(c.Request.URL.Path, getViewURL("/proxy/")) || strings.HasPrefix(c.Request.URL.Path, getViewURL("/debug/pprof/")) {
+			c.Next()
+			return
+		}
+		compress(c)
+	})
+	router.Use(corsHeaders(config.Config.CorsAllowedOrigins, config.Config.CorsAllowedMethods, config.Config.CorsAllowCredentials))
+	router.Use(robotsTag(config.Config.RobotsNoindex))
+	// users are validated on startup
+	htpasswdUsers, _ := getHtpasswdUsers()
+	router.Use(requireBasicAuth(htpasswdUsers))
+	router.Use(readOnly(config.Config.DemoMode))
 	router.Use(static.Serve(getViewURL("/static"), newBinaryFileSystem("static")))
 
+	// API keys are validated on startup
+	apiKeys, _ := getAPIKeys()
+
 	router.GET(getViewURL("/favicon.ico"), favicon)
-	router.GET(getViewURL("/"), index)
-	router.GET(getViewURL("/help"), help)
-	router.GET(getViewURL("/alerts.json"), alerts)
-	router.GET(getViewURL("/autocomplete.json"), autocomplete)
+	router.GET(getViewURL("/robots.txt"), robotsTxt)
+	html := securityHeaders(config.Config.SecurityCsp, config.Config.SecurityFrameOptions, config.Config.SecurityHstsMaxAge, config.Config.SecurityAllowFraming)
+	if config.Config.WebPrefix != "/" {
+		router.GET(strings.TrimSuffix(getViewURL("/"), "/"), redirectToPrefix)
+	}
+	router.GET(getViewURL("/"), html, waitForStartup(config.Config.ReadinessDelayUi), index)
+	router.GET(getViewURL("/help"), html, help)
+	router.GET(getViewURL("/s/:token"), resolveShortURL)
+	router.GET(getViewURL("/openapi.json"), openAPISpec)
+	router.GET(getViewURL("/version"), versionInfo)
+	router.GET(getViewURL("/ui.json"), uiConfig)
+	router.GET(getViewURL("/healthz"), healthz)
+	router.GET(getViewURL("/readyz"), readyz)
+
+	// data endpoints are rate limited and require an API key if any is
+	// configured
+	data := router.Group(getViewURL("/"), rateLimit(config.Config.RateLimitRps, config.Config.RateLimitBurst), requireAPIKey(apiKeys))
+	data.GET("ws", websocketEvents)
+	data.GET("events", streamEvents)
+	if config.Config.Pprof || config.Config.Debug {
+		// profiles can take longer than request timeouts
+		data.GET("debug/pprof/*profile", pprofHandler)
+		data.POST("debug/pprof/*profile", pprofHandler)
+	}
+	if config.Config.AlertmanagerProxy {
+		for _, method := range []string{http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodDelete} {
+			data.Handle(method, "proxy/:alertmanager/*path", alertmanagerProxy)
+		}
+	}
+
+	data.POST("admin/pause", setPaused(true))
+	data.POST("admin/pause/:alertmanager", setPaused(true))
+	data.POST("admin/resume", setPaused(false))
+	data.POST("admin/resume/:alertmanager", setPaused(false))
+	// refresh waits for the collection to finish, which can take longer than
+	// request timeouts
+	data.POST("refresh", refresh)
+	data.POST("refresh/:alertmanager", refresh)
+
+	// long lived connections are excluded from request timeouts
+	api := data.Group("", requestTimeout(config.Config.HandlerTimeout))
+	api.GET("alerts.json", alerts)
+	api.GET("alerts/group/:id", alertGroup)
+	api.GET("autocomplete.json", autocomplete)
+	api.GET("suggestions.json", suggestions)
+	// upstream status and routing configuration aren't scrubbed, so they're
+	// not exposed in demo mode
+	if !config.Config.DemoMode {
+		api.GET("alertmanager/:alertmanager/status", alertmanagerStatus)
+		api.GET("alertmanager/:alertmanager/receivers", predictReceivers)
+	}
+	api.GET("silences.json", silences)
+	api.GET("silences/authors.json", silenceAuthors)
+	api.POST("silences/:alertmanager", createSilence)
+	api.POST("silences/:alertmanager/preview", previewSilence)
+	api.DELETE("silences/:alertmanager/:id", expireSilence)
+	api.GET("summary.json", summary)
+	api.GET("labels/top.json", topLabelValues)
+	api.GET("search", search)
+	api.GET("counters.json", counters)
+	api.GET("history.json", history)
+	api.GET("badge.svg", badge)
+	api.GET("ui-config.json", uiDefaults)
+	api.GET("filters/saved.json", savedFilters)
+	api.GET("filters/saved/:name", savedFilter)
+	api.PUT("filters/saved/:name", saveFilter)
+	api.DELETE("filters/saved/:name", deleteSavedFilter)
+	api.GET("filters/presets.json", filterPresets)
+	api.GET("filters/validate", validateFilters)
+	api.POST("s", createShortURL)
+	api.GET("settings.json", userSettings)
+	api.PUT("settings.json", saveUserSettings)
+	api.DELETE("settings.json", deleteUserSettings)
+	if (config.Config.DebugState || config.Config.Debug) && !config.Config.DemoMode {
+		api.GET("debug/state", debugState)
+	}
+
+	// versioned endpoints with a frozen response schema
+	v1 := api.Group("api/v1")
+	v1.GET("alerts", apiV1Alerts)
+	v1.GET("alerts/group/:id", apiV1AlertGroup)
+	v1.GET("silences", apiV1Silences)
+}
+
+// validateConfig checks all options that can't be validated when parsing
+// them, it returns the first error found
+func validateConfig() error {
+	// timer duration cannot be zero second or a negative one
+	if config.Config.AlertmanagerTTL <= time.Second*0 {
+		return fmt.Errorf("Invalid AlertmanagerTTL value '%v'", config.Config.AlertmanagerTTL)
+	}
+	for _, s := range config.Config.AlertmanagerURIs {
+		if len(strings.SplitN(s, ":", 2)) != 2 {
+			return fmt.Errorf("Invalid Alertmanager URI '%s', expected format 'name:uri'", s)
+		}
+	}
+	if _, err := getUpstreamProxies(); err != nil {
+		return err
+	}
+	if _, err := getUpstreamClusters(); err != nil {
+		return err
+	}
+	if _, err := getUpstreamServerNames(); err != nil {
+		return err
+	}
+	if _, err := getFilterMacros(); err != nil {
+		return err
+	}
+	if _, err := getFilterPresets(); err != nil {
+		return err
+	}
+	blackholeFilters, err := getBlackholeFilters()
+	if err != nil {
+		return err
+	}
+	alertmanager.SetBlackholeFilters(blackholeFilters)
+	if _, err := getLabelDisplayNames(); err != nil {
+		return err
+	}
+	if _, err := getAPIKeys(); err != nil {
+		return err
+	}
+	if _, err := getHtpasswdUsers(); err != nil {
+		return err
+	}
+	if _, err := getMetricsHtpasswdUsers(); err != nil {
+		return err
+	}
+	if _, err := getVaultSecrets(); err != nil {
+		return err
+	}
+	if _, err := getCredentialsFiles(); err != nil {
+		return err
+	}
+	if _, _, err := getAllowedNetworks(); err != nil {
+		return err
+	}
+	if _, err := getHTTPListeners(); err != nil {
+		return err
+	}
+	if _, err := getSilenceACL(); err != nil {
+		return err
+	}
+	if err := validateSilenceDefaults(); err != nil {
+		return err
+	}
+	upstreams := []string{}
+	for _, s := range config.Config.AlertmanagerURIs {
+		upstreams = append(upstreams, strings.SplitN(s, ":", 2)[0])
+	}
+	if err := alertmanager.ValidateAnnotationsMergePolicy(config.Config.AnnotationsMergePolicy, slices.NonEmptyStrings(config.Config.AnnotationsMergePreferred), upstreams); err != nil {
+		return err
+	}
+	if _, err := getReadinessQuorum(); err != nil {
+		return err
+	}
+	if _, err := getStatsExporter(); err != nil {
+		return err
+	}
+	if config.Config.GroupCollapseFilter != "" {
+		if err := validateFilterQuery(config.Config.GroupCollapseFilter); err != nil {
+			return fmt.Errorf("invalid GROUP_COLLAPSE_FILTER: %s", err)
+		}
+	}
+	// valid rules are stored and used to generate links for collected alerts
+	if err := transform.ParseGrafanaRules(config.Config.GrafanaURL, config.Config.GrafanaLinks); err != nil {
+		return err
+	}
+	if err := transform.SetTimezone(config.Config.Timezone); err != nil {
+		return err
+	}
+	if err := transform.ParseRunbookRules(config.Config.RunbookUrls); err != nil {
+		return err
+	}
+	if err := transform.ParseAnnotationTemplates(config.Config.AnnotationsTemplates); err != nil {
+		return err
+	}
+	if err := transform.ParseSeverityMap(config.Config.SeverityLabel, config.Config.SeverityNormalizedLabel, config.Config.SeverityMap); err != nil {
+		return err
+	}
+	if len(slices.NonEmptyStrings(config.Config.DemoScrubLabels)) > 0 && !config.Config.DemoMode {
+		return fmt.Errorf("DEMO_SCRUB_LABELS is only used in demo mode, DEMO_MODE needs to be enabled")
+	}
+	// those would expose raw upstream responses that aren't scrubbed
+	if config.Config.DemoMode && config.Config.AlertmanagerProxy {
+		return fmt.Errorf("ALERTMANAGER_PROXY can't be enabled in demo mode")
+	}
+	if config.Config.DemoMode && config.Config.DebugState {
+		return fmt.Errorf("DEBUG_STATE can't be enabled in demo mode")
+	}
+	if err := transform.SetScrubLabels(config.Config.DemoScrubLabels); err != nil {
+		return err
+	}
+	if err := models.SetHiddenAnnotationPatterns(config.Config.AnnotationsHiddenRegex); err != nil {
+		return err
+	}
+	if err := flapping.Setup(config.Config.FlappingWindow, config.Config.FlappingThreshold); err != nil {
+		return err
+	}
+	if err := hooks.Setup(config.Config.Hooks, config.Config.HooksTimeout, config.Config.HooksConcurrency); err != nil {
+		return err
+	}
+	if err := incidents.Setup(config.Config.IncidentsProvider, config.Config.IncidentsApiUrl, config.Config.IncidentsApiKey, config.Config.IncidentsServices, config.Config.IncidentsDedupLabel, config.Config.AlertmanagerTimeout); err != nil {
+		return err
+	}
+	if config.Config.AccessLog != "" && !slices.StringInSlice(accessLogFormats, config.Config.AccessLog) {
+		return fmt.Errorf("Invalid ACCESS_LOG value '%s', supported formats: %s", config.Config.AccessLog, strings.Join(accessLogFormats, ", "))
+	}
+	if config.Config.TracingSampleRatio < 0 || config.Config.TracingSampleRatio > 1 {
+		return fmt.Errorf("Invalid TRACING_SAMPLE_RATIO value '%v', it must be between 0 and 1", config.Config.TracingSampleRatio)
+	}
+	if config.Config.HistoryRetention <= 0 {
+		return fmt.Errorf("Invalid HISTORY_RETENTION value '%v', it must be positive", config.Config.HistoryRetention)
+	}
+	if config.Config.SilenceExpiredRetention < 0 {
+		return fmt.Errorf("Invalid SILENCE_EXPIRED_RETENTION value '%v', it can't be negative", config.Config.SilenceExpiredRetention)
+	}
+	if config.Config.AlertmanagerMaxIdleConnsPerHost < 0 {
+		return fmt.Errorf("Invalid ALERTMANAGER_MAX_IDLE_CONNS_PER_HOST value '%v', it can't be negative", config.Config.AlertmanagerMaxIdleConnsPerHost)
+	}
+	if config.Config.AlertmanagerIdleConnTimeout < 0 {
+		return fmt.Errorf("Invalid ALERTMANAGER_IDLE_CONN_TIMEOUT value '%v', it can't be negative", config.Config.AlertmanagerIdleConnTimeout)
+	}
+	if config.Config.AlertmanagerKeepalive < 0 {
+		return fmt.Errorf("Invalid ALERTMANAGER_KEEPALIVE value '%v', it can't be negative", config.Config.AlertmanagerKeepalive)
+	}
+	if config.Config.AlertmanagerDnsRefresh < 0 {
+		return fmt.Errorf("Invalid ALERTMANAGER_DNS_REFRESH value '%v', it can't be negative", config.Config.AlertmanagerDnsRefresh)
+	}
+	if config.Config.RefreshMinInterval < 0 {
+		return fmt.Errorf("Invalid REFRESH_MIN_INTERVAL value '%v', it can't be negative", config.Config.RefreshMinInterval)
+	}
+	if config.Config.UiRefreshInterval < time.Second {
+		return fmt.Errorf("Invalid UI_REFRESH_INTERVAL value '%v', it must be at least 1s", config.Config.UiRefreshInterval)
+	}
+	if len(slices.NonEmptyStrings(config.Config.TransformPlugins)) > 0 && !transform.PluginsSupported {
+		return fmt.Errorf("TRANSFORM_PLUGINS can't be used, this binary was built without cgo or for a platform without Go plugin support")
+	}
+	// any website could make authenticated requests on behalf of the user
+	if slices.StringInSlice(config.Config.CorsAllowedOrigins, "*") && config.Config.CorsAllowCredentials {
+		return fmt.Errorf("CORS_ALLOW_CREDENTIALS can't be enabled when CORS_ALLOWED_ORIGINS includes '*'")
+	}
+	if (config.Config.TlsCert == "") != (config.Config.TlsKey == "") {
+		return fmt.Errorf("TLS_CERT and TLS_KEY must be set together")
+	}
+	tenants, err := getTenantFilters()
+	if err != nil {
+		return err
+	}
+	if len(tenants) > 0 && config.Config.GrpcPort != 0 {
+		// gRPC requests don't carry user groups, so tenants can't be enforced
+		return fmt.Errorf("GRPC_PORT can't be used together with TENANT_FILTERS")
+	}
+	if len(tenants) > 0 && config.Config.AlertmanagerProxy {
+		// Alertmanager would return all alerts to every tenant
+		return fmt.Errorf("ALERTMANAGER_PROXY can't be used together with TENANT_FILTERS")
+	}
+	return nil
+}
+
+// getUpstreamProxies returns proxy URLs configured for Alertmanager upstreams
+// keyed by the upstream name
+func getUpstreamProxies() (map[string]string, error) {
+	names := map[string]bool{}
+	for _, s := range config.Config.AlertmanagerURIs {
+		names[strings.SplitN(s, ":", 2)[0]] = true
+	}
+	proxies := map[string]string{}
+	for _, s := range config.Config.AlertmanagerProxyURLs {
+		if s == "" {
+			continue
+		}
+		z := strings.SplitN(s, ":", 2)
+		if len(z) != 2 || z[0] == "" || z[1] == "" {
+			return nil, fmt.Errorf("Invalid Alertmanager proxy URL '%s', expected format 'name:proxy_url'", s)
+		}
+		if !names[z[0]] {
+			return nil, fmt.Errorf("Invalid Alertmanager proxy URL '%s', there's no Alertmanager upstream named '%s'", s, z[0])
+		}
+		if _, found := proxies[z[0]]; found {
+			return nil, fmt.Errorf("Duplicated Alertmanager proxy URL for '%s'", z[0])
+		}
+		if _, err := transport.ParseProxyURL(z[1]); err != nil {
+			return nil, err
+		}
+		proxies[z[0]] = z[1]
+	}
+	return proxies, nil
+}
+
+// getUpstreamServerNames returns TLS server names from
+// ALERTMANAGER_TLS_SERVER_NAMES keyed by the upstream name
+func getUpstreamServerNames() (map[string]string, error) {
+	uris := map[string]string{}
+	for _, s := range config.Config.AlertmanagerURIs {
+		z := strings.SplitN(s, ":", 2)
+		if len(z) == 2 {
+			uris[z[0]] = z[1]
+		}
+	}
+	serverNames := map[string]string{}
+	for _, s := range config.Config.AlertmanagerTLSServerNames {
+		if s == "" {
+			continue
+		}
+		z := strings.SplitN(s, ":", 2)
+		if len(z) != 2 || z[0] == "" || z[1] == "" {
+			return nil, fmt.Errorf("Invalid Alertmanager TLS server name '%s', expected format 'name:server_name'", s)
+		}
+		uri, found := uris[z[0]]
+		if !found {
+			return nil, fmt.Errorf("Invalid Alertmanager TLS server name '%s', there's no Alertmanager upstream named '%s'", s, z[0])
+		}
+		if _, found := serverNames[z[0]]; found {
+			return nil, fmt.Errorf("Duplicated Alertmanager TLS server name for '%s'", z[0])
+		}
+		if u, err := url.Parse(uri); err != nil || u.Scheme != "https" {
+			return nil, fmt.Errorf("Alertmanager upstream '%s' doesn't use HTTPS, TLS server name can't be used", z[0])
+		}
+		if err := transport.ValidateServerName(z[1]); err != nil {
+			return nil, err
+		}
+		serverNames[z[0]] = z[1]
+	}
+	return serverNames, nil
+}
+
+// getUpstreamClusters returns names of members of every Alertmanager cluster
+// from ALERTMANAGER_CLUSTERS, keyed by the cluster name
+func getUpstreamClusters() (map[string][]string, error) {
+	names := map[string]bool{}
+	for _, s := range config.Config.AlertmanagerURIs {
+		names[strings.SplitN(s, ":", 2)[0]] = true
+	}
+	clusters := map[string][]string{}
+	clustered := map[string]string{}
+	for _, s := range config.Config.AlertmanagerClusters {
+		if s == "" {
+			continue
+		}
+		z := strings.SplitN(s, ":", 2)
+		if len(z) != 2 || z[0] == "" || z[1] == "" {
+			return nil, fmt.Errorf("Invalid Alertmanager cluster '%s', expected format 'name:upstream,upstream,...'", s)
+		}
+		if _, found := clusters[z[0]]; found {
+			return nil, fmt.Errorf("Duplicated Alertmanager cluster '%s'", z[0])
+		}
+		members := strings.Split(z[1], ",")
+		if len(members) < 2 {
+			return nil, fmt.Errorf("Invalid Alertmanager cluster '%s', it needs at least 2 members", s)
+		}
+		for _, member := range members {
+			if !names[member] {
+				return nil, fmt.Errorf("Invalid Alertmanager cluster '%s', there's no Alertmanager upstream named '%s'", s, member)
+			}
+			if cluster, found := clustered[member]; found {
+				return nil, fmt.Errorf("Alertmanager upstream '%s' is a member of both '%s' and '%s' clusters", member, cluster, z[0])
+			}
+			clustered[member] = z[0]
+		}
+		clusters[z[0]] = members
+	}
+	return clusters, nil
 }
 
 func setupUpstreams() {
+	proxies, err := getUpstreamProxies()
+	if err != nil {
+		log.Fatal(err)
+	}
+	serverNames, err := getUpstreamServerNames()
+	if err != nil {
+		log.Fatal(err)
+	}
 	for _, s := range config.Config.AlertmanagerURIs {
 		z := strings.SplitN(s, ":", 2)
 		if len(z) != 2 {
@@ -69,38 +509,146 @@ func setupUpstreams() {
 		if err != nil {
 			log.Fatalf("Failed to configure Alertmanager '%s' with URI '%s': %s", name, uri, err)
 		}
+		if proxyURL, found := proxies[name]; found {
+			if err := transport.SetProxy(uri, proxyURL); err != nil {
+				log.Fatalf("Failed to configure proxy for Alertmanager '%s': %s", name, err)
+			}
+			log.Infof("[%s] Using proxy server at %s", name, proxyURL)
+		}
+		// set after the proxy, since it replaces the transport used for uri
+		if serverName, found := serverNames[name]; found {
+			if err := transport.SetServerName(uri, serverName); err != nil {
+				log.Fatalf("Failed to configure TLS server name for Alertmanager '%s': %s", name, err)
+			}
+			log.Infof("[%s] Verifying TLS certificate against server name %s", name, serverName)
+		}
 	}
 }
 
-func main() {
+// userAgent returns the User-Agent sent with requests to upstreams, it
+// includes the version and the hostname, so requests can be tracked back to
+// this unsee instance
+func userAgent() string {
+	if hostname, err := os.Hostname(); err == nil && hostname != "" {
+		return fmt.Sprintf("unsee/%s (%s)", version, hostname)
+	}
+	return "unsee/" + version
+}
+
+// serve runs the unsee server, it's the default command
+func serve(args []string) {
 	log.Infof("Version: %s", version)
 
-	config.Config.Read()
+	config.Config.ReadArgs(args)
 
-	// timer duration cannot be zero second or a negative one
-	if config.Config.AlertmanagerTTL <= time.Second*0 {
-		log.Fatalf("Invalid AlertmanagerTTL value '%v'", config.Config.AlertmanagerTTL)
+	logFile, err := setupLogFile()
+	if err != nil {
+		log.Fatalf("Failed to open log file '%s': %s", config.Config.LogFile, err)
+	}
+	if logFile != nil {
+		defer logFile.Close()
 	}
 
 	config.Config.LogValues()
+
+	if err := validateConfig(); err != nil {
+		log.Fatal(err)
+	}
 	transform.ParseRules(config.Config.JiraRegexp)
+	transport.SetUserAgent(userAgent())
+	transport.SetPoolSettings(transport.PoolSettings{
+		MaxIdleConnsPerHost: config.Config.AlertmanagerMaxIdleConnsPerHost,
+		IdleConnTimeout:     config.Config.AlertmanagerIdleConnTimeout,
+		KeepAlive:           config.Config.AlertmanagerKeepalive,
+		DisableHTTP2:        config.Config.AlertmanagerDisableHttp2,
+	})
+	rules.Setup(config.Config.PrometheusRules, config.Config.AlertmanagerTimeout, config.Config.AlertmanagerTTL)
+	// loading plugins runs their code and registers transforms, so it's done
+	// here rather than when validating config
+	if err := transform.LoadTransforms(config.Config.TransformPlugins, config.Config.TransformCommands, config.Config.TransformTimeout); err != nil {
+		log.Fatal(err)
+	}
+
+	if config.Config.TracingEndpoint != "" {
+		stopTracing, err := tracing.Setup(config.Config.TracingEndpoint, config.Config.TracingInsecure, config.Config.TracingSampleRatio, version)
+		if err != nil {
+			log.Fatalf("Failed to configure tracing: %s", err)
+		}
+		defer func() {
+			// send all spans that are still buffered
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := stopTracing(ctx); err != nil {
+				log.Errorf("Failed to flush traces: %s", err)
+			}
+		}()
+	}
 
 	apiCache = cache.New(cache.NoExpiration, 10*time.Second)
 
+	dataStore, err = store.New(config.Config.StorePath)
+	if err != nil {
+		log.Fatalf("Failed to load data store from '%s': %s", config.Config.StorePath, err)
+	}
+
+	if config.Config.HistoryPath != "" {
+		if err = alertCounts.Load(config.Config.HistoryPath); err != nil {
+			log.Errorf("Failed to load history from '%s': %s", config.Config.HistoryPath, err)
+		}
+	}
+
 	setupUpstreams()
+	if err := setupVaultCredentials(); err != nil {
+		log.Fatal(err)
+	}
+	if err := setupCredentialsFiles(); err != nil {
+		log.Fatal(err)
+	}
 
 	if len(alertmanager.GetAlertmanagers()) == 0 {
 		log.Fatal("No valid Alertmanager URIs defined")
 	}
 
-	// before we start try to fetch data from Alertmanager
-	log.Infof("Initial Alertmanager query, this can delay startup up to %s", 3*config.Config.AlertmanagerTimeout)
-	pullFromAlertmanager()
-	log.Info("Done, starting HTTP server")
+	warmStart := false
+	// snapshots can have values that weren't scrubbed
+	if config.Config.SnapshotPath != "" && !config.Config.DemoMode {
+		restored, err := alertmanager.LoadSnapshot(config.Config.SnapshotPath)
+		if err != nil {
+			log.Errorf("Failed to load snapshot from '%s': %s", config.Config.SnapshotPath, err)
+		}
+		if restored > 0 {
+			warmStart = true
+			// changes detected on the first pull are sent to clients
+			lastAlertGroups = alertmanager.DedupAlerts()
+			alertHistory.Add(lastAlertGroups)
+		}
+	}
 
 	// background loop that will fetch updates from Alertmanager
 	ticker = time.NewTicker(config.Config.AlertmanagerTTL)
-	go Tick()
+	if warmStart && !config.Config.AlertmanagerStartupCheck {
+		// restored alerts can be used right away, so pull in the background
+		log.Info("Alerts restored from snapshot, starting HTTP server")
+		go func() {
+			pullFromAlertmanager()
+			Tick()
+		}()
+	} else {
+		// before we start try to fetch data from Alertmanager
+		log.Infof("Initial Alertmanager query, this can delay startup up to %s", 3*config.Config.AlertmanagerTimeout)
+		pullFromAlertmanager()
+		if config.Config.AlertmanagerStartupCheck {
+			if err := checkUpstreams(); err != nil {
+				log.Fatal(err)
+			}
+		}
+		log.Info("Done, starting HTTP server")
+		go Tick()
+	}
+
+	if statsExporter, _ := getStatsExporter(); statsExporter != nil {
+		go exportStats(statsExporter, config.Config.StatsExportInterval)
+	}
 
 	switch config.Config.Debug {
 	case true:
@@ -115,23 +663,78 @@ func main() {
 	t = loadTemplates(t, "templates")
 	t = loadTemplates(t, "static/dist/templates")
 	router.SetHTMLTemplate(t)
+	clientTemplates = loadClientTemplates()
+
+	// metrics endpoint is registered right away, so its credentials need to
+	// be checked by the first middleware
+	metricsUsers, _ := getMetricsHtpasswdUsers()
+	router.Use(requireMetricsAuth(metricsUsers))
 
 	prom := ginprometheus.NewPrometheus("gin")
 	prom.MetricsPath = getViewURL("/metrics")
 	prom.Use(router)
 
-	if config.Config.Debug {
-		ginpprof.Wrapper(router)
-	}
-
 	if config.Config.SentryDSN != "" {
-		raven.SetRelease(version)
+		if err := setupSentry(raven.DefaultClient); err != nil {
+			log.Fatalf("Failed to configure Sentry: %s", err)
+		}
 		router.Use(sentry.Recovery(raven.DefaultClient, false))
 	}
 
-	setupRouter(router)
-	err := router.Run()
+	// sockets passed by systemd are used instead of LISTEN, PORT and GRPC_PORT
+	listeners, err := getActivatedListeners(listenFdsStart)
 	if err != nil {
 		log.Fatal(err)
 	}
+	if listeners.grpc != nil && config.Config.GrpcPort == 0 {
+		log.Warning("Ignoring gRPC socket passed by systemd, GRPC_PORT isn't set")
+		listeners.grpc.Close()
+		listeners.grpc = nil
+	}
+
+	var stopGRPC func(context.Context)
+	if config.Config.GrpcPort != 0 {
+		grpcServer := newGRPCServer()
+		stopGRPC = func(ctx context.Context) {
+			stopGRPCServer(ctx, grpcServer)
+		}
+		go func() {
+			if err := serveGRPC(grpcServer, config.Config.GrpcPort, listeners.grpc); err != nil {
+				log.Fatalf("gRPC server failed: %s", err)
+			}
+		}()
+	}
+
+	setupRouter(router)
+	servers := []*http.Server{}
+	if listeners.http != nil {
+		if len(config.Config.Listen) > 0 {
+			log.Warning("Ignoring LISTEN, using the socket passed by systemd")
+		}
+		server := newHTTPServer(router)
+		servers = append(servers, server)
+		go func() {
+			if err := listen(server, listeners.http); err != nil {
+				log.Fatal(err)
+			}
+		}()
+	} else {
+		// listen addresses are validated on startup
+		httpListeners, _ := getHTTPListeners()
+		for _, l := range httpListeners {
+			server := newListenerHTTPServer(router, l)
+			servers = append(servers, server)
+			go func() {
+				if err := listen(server, nil); err != nil {
+					log.Fatal(err)
+				}
+			}()
+		}
+	}
+
+	waitForShutdown()
+	if err := shutdown(servers, stopGRPC); err != nil {
+		log.Errorf("Graceful shutdown failed: %s", err)
+	}
+	log.Info("Shutdown completed")
 }
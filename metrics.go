@@ -0,0 +1,141 @@
+package main
+
+import (
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudflare/unsee/internal/filters"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	httpRequestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "unsee_http_request_duration_seconds",
+			Help:    "Time spent handling HTTP requests, labeled by handler and status code",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"handler", "code"},
+	)
+	httpResponseSize = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "unsee_http_response_size_bytes",
+			Help:    "Size of HTTP responses sent to clients, labeled by handler and status code",
+			Buckets: prometheus.ExponentialBuckets(100, 10, 6),
+		},
+		[]string{"handler", "code"},
+	)
+	filterTerms = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "unsee_filter_terms_total",
+			Help: "Total number of filter terms submitted to the API, labeled by filter name and operator",
+		},
+		[]string{"name", "operator"},
+	)
+)
+
+// maximum number of distinct label names tracked by unsee_filter_terms_total,
+// filters using any other label name are counted as "other" so that users
+// can't create unlimited number of metrics
+const maxFilterTermLabels = 100
+
+var filterTermLabels = struct {
+	sync.Mutex
+	names map[string]bool
+}{names: map[string]bool{}}
+
+// prefix of function names of all views, it's "main." when running the
+// binary, but it's different in tests
+var viewNamePrefix = strings.TrimSuffix(runtime.FuncForPC(reflect.ValueOf(index).Pointer()).Name(), "index")
+
+func init() {
+	prometheus.MustRegister(httpRequestDuration)
+	prometheus.MustRegister(httpResponseSize)
+	prometheus.MustRegister(filterTerms)
+}
+
+// filterTermName returns the name used to label the filter in metrics,
+// @-keywords are reported as is (without the argument, so @annotation:foo is
+// reported as @annotation), label names are reported until the limit of
+// tracked names is reached, "" is used for filters without a name
+func filterTermName(name string) string {
+	if name == "" {
+		return ""
+	}
+	for _, fc := range filters.AllFilters {
+		if !fc.LabelRe.MatchString(name) {
+			continue
+		}
+		if strings.HasPrefix(fc.Label, "@") {
+			return fc.Label
+		}
+		break
+	}
+	if strings.HasPrefix(name, "@") {
+		return "other"
+	}
+
+	filterTermLabels.Lock()
+	defer filterTermLabels.Unlock()
+	if filterTermLabels.names[name] {
+		return name
+	}
+	if len(filterTermLabels.names) >= maxFilterTermLabels {
+		return "other"
+	}
+	filterTermLabels.names[name] = true
+	return name
+}
+
+// countFilterTerms records every valid filter term from the query in the
+// unsee_filter_terms_total metric, macros are expanded first so that
+// metrics reflect filters that were actually used
+func countFilterTerms(q string) {
+	if q == "" {
+		return
+	}
+	q, _ = expandFilterMacros(q)
+	for _, expression := range strings.Split(q, ",") {
+		if !filters.NewFilter(expression).GetIsValid() {
+			continue
+		}
+		name, operator, _ := filters.SplitExpression(expression)
+		filterTerms.WithLabelValues(filterTermName(name), operator).Inc()
+	}
+}
+
+// handlerLabel returns the name of the view that handled the request, it's
+// used instead of the request path so that the number of label values is
+// fixed, requests that didn't match any view (static files, metrics and 404
+// responses) use "other"
+func handlerLabel(c *gin.Context) string {
+	name := c.HandlerName()
+	if !strings.HasPrefix(name, viewNamePrefix) || strings.Contains(name, ".func") {
+		return "other"
+	}
+	return strings.TrimPrefix(name, viewNamePrefix)
+}
+
+// httpMetrics returns a middleware that will record the duration and the
+// size of every response
+func httpMetrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		size := c.Writer.Size()
+		if size < 0 {
+			size = 0
+		}
+		handler := handlerLabel(c)
+		code := strconv.Itoa(c.Writer.Status())
+		httpRequestDuration.WithLabelValues(handler, code).Observe(time.Since(start).Seconds())
+		httpResponseSize.WithLabelValues(handler, code).Observe(float64(size))
+	}
+}
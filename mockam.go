@@ -0,0 +1,55 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/cloudflare/unsee/internal/mock/synthetic"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// runMockAlertmanager serves synthetic alerts and silences using the
+// Alertmanager API, so unsee can be load tested or demoed without a real
+// Alertmanager, it returns the exit code
+func runMockAlertmanager(args []string) int {
+	flags := flag.NewFlagSet("mock-alertmanager", flag.ExitOnError)
+	listen := flags.String("listen", ":9093", "Address to listen on for Alertmanager API requests")
+	opts := synthetic.Options{}
+	flags.IntVar(&opts.Groups, "groups", 10, "Number of alert groups")
+	flags.IntVar(&opts.AlertsPerGroup, "alerts.per.group", 10, "Number of alerts in every alert group")
+	flags.IntVar(&opts.Silences, "silences", 5, "Number of silences, each one silences a single alert")
+	flags.Float64Var(&opts.ChurnRate, "churn.rate", 0.1, "Fraction of alerts and silences replaced with new ones every churn interval, between 0 and 1")
+	flags.Int64Var(&opts.Seed, "seed", time.Now().UnixNano(), "Seed for the random generator, the same seed generates the same labels")
+	churnInterval := flags.Duration("churn.interval", time.Minute, "How often alerts and silences are replaced, 0 disables churn")
+	flags.Parse(args)
+
+	if opts.Groups < 0 || opts.AlertsPerGroup < 0 || opts.Silences < 0 {
+		fmt.Fprintln(os.Stderr, "Error: number of alert groups, alerts and silences can't be negative")
+		return 2
+	}
+	if opts.ChurnRate < 0 || opts.ChurnRate > 1 {
+		fmt.Fprintf(os.Stderr, "Error: invalid churn rate %v, it must be between 0 and 1\n", opts.ChurnRate)
+		return 2
+	}
+
+	s := synthetic.NewServer(opts)
+	if *churnInterval > 0 {
+		go func() {
+			for range time.Tick(*churnInterval) {
+				alerts, silences := s.Churn()
+				log.Infof("Replaced %d alert(s) and %d silence(s)", alerts, silences)
+			}
+		}()
+	}
+
+	log.Infof("Serving %d alert(s) in %d group(s) and %d silence(s) on %s", opts.Groups*opts.AlertsPerGroup, opts.Groups, opts.Silences, *listen)
+	if err := http.ListenAndServe(*listen, s); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+	return 0
+}
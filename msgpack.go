@@ -0,0 +1,16 @@
+package main
+
+import (
+	"bytes"
+
+	"github.com/ugorji/go/codec"
+)
+
+// msgpackHandle is used to encode responses for clients that asked for
+// msgpack, struct fields use the same names as in JSON responses
+var msgpackHandle = &codec.MsgpackHandle{WriteExt: true}
+
+// writeMsgpack writes msgpack encoded value to the buffer
+func writeMsgpack(buf *bytes.Buffer, v interface{}) error {
+	return codec.NewEncoder(buf, msgpackHandle).Encode(v)
+}
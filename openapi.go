@@ -0,0 +1,742 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/cloudflare/unsee/internal/config"
+	"github.com/cloudflare/unsee/internal/filters"
+	"github.com/cloudflare/unsee/internal/models"
+	"github.com/cloudflare/unsee/internal/openapi"
+	"github.com/cloudflare/unsee/internal/slices"
+	"github.com/cloudflare/unsee/pkg/apiv1"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// filterSyntaxDescription documents the filter syntax accepted by all
+// endpoints taking the q argument, it's generated from the filter registry
+func filterSyntaxDescription() string {
+	lines := []string{
+		"Comma separated list of filter expressions, alerts must match all of them.",
+		"Each expression uses `name<operator>value` format, expressions without an operator will match any label, annotation or silence comment value.",
+		"Filter macros can be referenced as `$name`.",
+		"",
+		"Supported filters and operators:",
+		"",
+	}
+	for _, f := range filters.AllFilters {
+		name := f.Label
+		if !strings.HasPrefix(name, "@") {
+			name = "label name"
+		}
+		lines = append(lines, fmt.Sprintf("* `%s`: `%s`", name, strings.Join(f.SupportedOperators, "`, `")))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func openAPIJSON(schema *openapi.Schema) map[string]openapi.MediaType {
+	return map[string]openapi.MediaType{gin.MIMEJSON: openapi.MediaType{Schema: schema}}
+}
+
+// openAPIEncoded returns the content of responses that can also be encoded
+// using msgpack or YAML
+func openAPIEncoded(schema *openapi.Schema) map[string]openapi.MediaType {
+	return map[string]openapi.MediaType{
+		gin.MIMEJSON:         openapi.MediaType{Schema: schema},
+		binding.MIMEMSGPACK2: openapi.MediaType{Schema: schema},
+		mimeYAML:             openapi.MediaType{Schema: schema},
+	}
+}
+
+// formatParam returns the parameter selecting the response encoding
+func formatParam() openapi.Parameter {
+	return openapi.Parameter{
+		Name:        "format",
+		In:          "query",
+		Description: "Encoding of the response, it's negotiated using the Accept header if not set",
+		Schema:      &openapi.Schema{Type: "string", Enum: []string{"json", "msgpack", "yaml"}},
+	}
+}
+
+// openAPIDocument returns the OpenAPI specification for all JSON endpoints
+func openAPIDocument() *openapi.Document {
+	doc := openapi.NewDocument(openapi.Info{
+		Title:       "unsee",
+		Description: "Alert dashboard for Prometheus Alertmanager",
+		Version:     version,
+	})
+	doc.Servers = []openapi.Server{openapi.Server{URL: strings.TrimSuffix(config.Config.WebPrefix, "/")}}
+	// frozen types use the same names as models
+	doc.SchemaPrefixes[reflect.TypeOf(apiv1.Alert{}).PkgPath()] = "V1"
+
+	doc.Components.Schemas["Error"] = &openapi.Schema{
+		Type:       "object",
+		Properties: map[string]*openapi.Schema{"error": &openapi.Schema{Type: "string"}},
+	}
+	errorResponse := func(description string) openapi.Response {
+		return openapi.Response{
+			Description: description,
+			Content:     openAPIJSON(&openapi.Schema{Ref: "#/components/schemas/Error"}),
+		}
+	}
+
+	filterParam := func(required bool) openapi.Parameter {
+		return openapi.Parameter{
+			Name:        "q",
+			In:          "query",
+			Description: filterSyntaxDescription(),
+			Required:    required,
+			Schema:      &openapi.Schema{Type: "string"},
+		}
+	}
+	intParam := func(name string, description string) openapi.Parameter {
+		return openapi.Parameter{
+			Name:        name,
+			In:          "query",
+			Description: description,
+			Schema:      doc.SchemaFor(0),
+		}
+	}
+	pathParam := func(name string, description string) openapi.Parameter {
+		return openapi.Parameter{
+			Name:        name,
+			In:          "path",
+			Description: description,
+			Required:    true,
+			Schema:      &openapi.Schema{Type: "string"},
+		}
+	}
+	jsonBody := func(v interface{}) *openapi.RequestBody {
+		return &openapi.RequestBody{Required: true, Content: openAPIJSON(doc.SchemaFor(v))}
+	}
+
+	doc.AddOperation("/alerts.json", http.MethodGet, openapi.Operation{
+		OperationID: "getAlerts",
+		Summary:     "Deduplicated alert groups matching the filter",
+		Parameters: []openapi.Parameter{
+			filterParam(false),
+			openapi.Parameter{
+				Name:        "since",
+				In:          "query",
+				Description: "Only return groups changed since this collectionVersion",
+				Schema:      doc.SchemaFor(int64(0)),
+			},
+			intParam("offset", "Number of alert groups to skip"),
+			intParam("limit", "Maximum number of alert groups to return, 0 means no limit"),
+			intParam("alertsPerGroup", "Maximum number of alerts included in every group, it can't be higher than ALERTS_PER_GROUP, 0 means no limit unless ALERTS_PER_GROUP is set"),
+			openapi.Parameter{
+				Name:        "grouping",
+				In:          "query",
+				Description: "Set to receiver to sort groups by the receiver and list groups of every receiver",
+				Schema:      &openapi.Schema{Type: "string", Enum: []string{models.GroupingReceiver}},
+			},
+			openapi.Parameter{
+				Name:        "annotations",
+				In:          "query",
+				Description: "Set to shared to return annotations with the same value on every alert in a group only once, as sharedAnnotations of that group",
+				Schema:      &openapi.Schema{Type: "string", Enum: []string{models.AnnotationsShared}},
+			},
+			formatParam(),
+			openapi.Parameter{
+				Name:        "If-None-Match",
+				In:          "header",
+				Description: "ETag of a previous response",
+				Schema:      &openapi.Schema{Type: "string"},
+			},
+		},
+		Responses: map[string]openapi.Response{
+			"200": openapi.Response{
+				Description: "Alert groups, encoded using msgpack or YAML if requested",
+				Content:     openAPIEncoded(doc.SchemaFor(models.AlertsResponse{})),
+			},
+			"304": openapi.Response{Description: "Alerts didn't change since the response with ETag passed in If-None-Match"},
+			"400": errorResponse("Invalid offset, limit, alertsPerGroup, grouping, annotations or format"),
+			"503": errorResponse("Request timed out"),
+		},
+	})
+
+	doc.AddOperation("/alerts/group/{id}", http.MethodGet, openapi.Operation{
+		OperationID: "getAlertGroup",
+		Summary:     "Details of a single alert group",
+		Parameters:  []openapi.Parameter{pathParam("id", "Alert group ID")},
+		Responses: map[string]openapi.Response{
+			"200": openapi.Response{Description: "Alert group", Content: openAPIJSON(doc.SchemaFor(models.AlertGroupDetails{}))},
+			"404": errorResponse("Alert group not found"),
+		},
+	})
+
+	doc.AddOperation("/autocomplete.json", http.MethodGet, openapi.Operation{
+		OperationID: "getAutocomplete",
+		Summary:     "Filter expression hints for the search term",
+		Parameters: []openapi.Parameter{
+			openapi.Parameter{Name: "term", In: "query", Required: true, Schema: &openapi.Schema{Type: "string"}},
+		},
+		Responses: map[string]openapi.Response{
+			"200": openapi.Response{Description: "Filter hints", Content: openAPIJSON(doc.SchemaFor([]string{}))},
+			"400": errorResponse("Missing term"),
+		},
+	})
+
+	doc.AddOperation("/suggestions.json", http.MethodGet, openapi.Operation{
+		OperationID: "getSuggestions",
+		Summary:     "Typed suggestions for the filter being typed",
+		Description: "Suggests label names and keywords, then operators and finally values, depending on what was typed so far",
+		Parameters: []openapi.Parameter{
+			openapi.Parameter{
+				Name:        "context",
+				In:          "query",
+				Description: "Filter query typed so far, suggestions are generated for the last expression",
+				Schema:      &openapi.Schema{Type: "string"},
+			},
+		},
+		Responses: map[string]openapi.Response{
+			"200": openapi.Response{Description: "Suggestions", Content: openAPIJSON(doc.SchemaFor(models.SuggestionsResponse{}))},
+		},
+	})
+
+	doc.AddOperation("/silences.json", http.MethodGet, openapi.Operation{
+		OperationID: "listSilences",
+		Summary:     "Silences collected from all Alertmanager upstreams",
+		Parameters: []openapi.Parameter{
+			openapi.Parameter{Name: "author", In: "query", Description: "Only return silences created by this author", Schema: &openapi.Schema{Type: "string"}},
+			openapi.Parameter{Name: "comment", In: "query", Description: "Only return silences with comment containing this text", Schema: &openapi.Schema{Type: "string"}},
+			openapi.Parameter{Name: "state", In: "query", Description: "Only return silences in this state", Schema: &openapi.Schema{Type: "string", Enum: models.SilenceStateList}},
+			formatParam(),
+		},
+		Responses: map[string]openapi.Response{
+			"200": openapi.Response{Description: "Silences, encoded using msgpack or YAML if requested", Content: openAPIEncoded(doc.SchemaFor([]models.ManagedSilence{}))},
+			"400": errorResponse("Invalid state or format"),
+		},
+	})
+
+	doc.AddOperation("/silences/authors.json", http.MethodGet, openapi.Operation{
+		OperationID: "listSilenceAuthors",
+		Summary:     "Authors of silences seen on any Alertmanager upstream",
+		Responses: map[string]openapi.Response{
+			"200": openapi.Response{Description: "Sorted list of silence authors", Content: openAPIJSON(doc.SchemaFor([]string{}))},
+		},
+	})
+
+	doc.AddOperation("/api/v1/alerts", http.MethodGet, openapi.Operation{
+		OperationID: "getAlertsV1",
+		Summary:     "Deduplicated alert groups matching the filter, using the frozen v1 schema",
+		Description: "Fields of v1 responses are never renamed or removed, use it for integrations",
+		Parameters:  []openapi.Parameter{filterParam(false)},
+		Responses: map[string]openapi.Response{
+			"200": openapi.Response{Description: "Alert groups", Content: openAPIJSON(doc.SchemaFor(apiv1.AlertsResponse{}))},
+			"400": errorResponse("Invalid filter"),
+			"503": errorResponse("Request timed out"),
+		},
+	})
+
+	doc.AddOperation("/api/v1/alerts/group/{id}", http.MethodGet, openapi.Operation{
+		OperationID: "getAlertGroupV1",
+		Summary:     "A single alert group, using the frozen v1 schema",
+		Parameters:  []openapi.Parameter{pathParam("id", "Alert group ID")},
+		Responses: map[string]openapi.Response{
+			"200": openapi.Response{Description: "Alert group", Content: openAPIJSON(doc.SchemaFor(apiv1.AlertGroupResponse{}))},
+			"404": errorResponse("Alert group not found"),
+		},
+	})
+
+	doc.AddOperation("/api/v1/silences", http.MethodGet, openapi.Operation{
+		OperationID: "listSilencesV1",
+		Summary:     "Silences collected from all Alertmanager upstreams, using the frozen v1 schema",
+		Parameters: []openapi.Parameter{
+			openapi.Parameter{Name: "author", In: "query", Description: "Only return silences created by this author", Schema: &openapi.Schema{Type: "string"}},
+			openapi.Parameter{Name: "comment", In: "query", Description: "Only return silences with comment containing this text", Schema: &openapi.Schema{Type: "string"}},
+			openapi.Parameter{Name: "state", In: "query", Description: "Only return silences in this state", Schema: &openapi.Schema{Type: "string", Enum: models.SilenceStateList}},
+		},
+		Responses: map[string]openapi.Response{
+			"200": openapi.Response{Description: "Silences", Content: openAPIJSON(doc.SchemaFor(apiv1.SilencesResponse{}))},
+			"400": errorResponse("Invalid state"),
+		},
+	})
+
+	doc.AddOperation("/alertmanager/{alertmanager}/status", http.MethodGet, openapi.Operation{
+		OperationID: "getAlertmanagerStatus",
+		Summary:     "Version, uptime and routing configuration of given Alertmanager upstream",
+		Description: "Status is collected on every pull, the routing configuration isn't filtered so it's not available to users restricted by tenant filters",
+		Parameters:  []openapi.Parameter{pathParam("alertmanager", "Alertmanager upstream name")},
+		Responses: map[string]openapi.Response{
+			"200": openapi.Response{Description: "Alertmanager status", Content: openAPIJSON(doc.SchemaFor(models.AlertmanagerStatus{}))},
+			"403": errorResponse("User is restricted by tenant filters"),
+			"404": errorResponse("Alertmanager upstream not found"),
+			"503": errorResponse("Status wasn't collected yet"),
+		},
+	})
+
+	doc.AddOperation("/alertmanager/{alertmanager}/receivers", http.MethodGet, openapi.Operation{
+		OperationID: "predictReceivers",
+		Summary:     "Receivers an alert with given labels would be routed to by given Alertmanager upstream",
+		Description: "Every query argument is used as a label, routes are matched against the routing configuration collected from the status API, so it's not available to users restricted by tenant filters",
+		Parameters:  []openapi.Parameter{pathParam("alertmanager", "Alertmanager upstream name")},
+		Responses: map[string]openapi.Response{
+			"200": openapi.Response{Description: "Matched routes", Content: openAPIJSON(doc.SchemaFor(models.AlertmanagerRoutePrediction{}))},
+			"400": errorResponse("No labels passed"),
+			"403": errorResponse("User is restricted by tenant filters"),
+			"404": errorResponse("Alertmanager upstream not found"),
+			"503": errorResponse("Routing configuration isn't available"),
+		},
+	})
+
+	doc.AddOperation("/silences/{alertmanager}", http.MethodPost, openapi.Operation{
+		OperationID: "createSilence",
+		Summary:     "Create a silence using given Alertmanager upstream",
+		Description: "The body is passed to the Alertmanager silences API if the authenticated user is allowed to create the silence, the response from Alertmanager is returned",
+		Parameters:  []openapi.Parameter{pathParam("alertmanager", "Alertmanager upstream name")},
+		RequestBody: jsonBody(models.Silence{}),
+		Responses: map[string]openapi.Response{
+			"200": openapi.Response{Description: "Response from Alertmanager", Content: openAPIJSON(&openapi.Schema{Type: "object"})},
+			"400": errorResponse("Invalid silence"),
+			"403": errorResponse("User isn't allowed to create this silence"),
+			"404": errorResponse("Alertmanager upstream not found"),
+			"502": errorResponse("Request to Alertmanager failed"),
+		},
+	})
+
+	doc.AddOperation("/silences/{alertmanager}/preview", http.MethodPost, openapi.Operation{
+		OperationID: "previewSilence",
+		Summary:     "List alerts that a silence would suppress on given Alertmanager upstream",
+		Description: "The body uses the same format as requests creating silences, nothing is sent to Alertmanager",
+		Parameters:  []openapi.Parameter{pathParam("alertmanager", "Alertmanager upstream name")},
+		RequestBody: jsonBody(models.Silence{}),
+		Responses: map[string]openapi.Response{
+			"200": openapi.Response{Description: "Matched alerts", Content: openAPIJSON(doc.SchemaFor(models.SilencePreview{}))},
+			"400": errorResponse("Invalid silence"),
+			"404": errorResponse("Alertmanager upstream not found"),
+		},
+	})
+
+	doc.AddOperation("/silences/{alertmanager}/{id}", http.MethodDelete, openapi.Operation{
+		OperationID: "expireSilence",
+		Summary:     "Expire a silence using given Alertmanager upstream",
+		Description: "The silence is expired if the authenticated user is allowed to create a silence with the same matchers, the response from Alertmanager is returned",
+		Parameters:  []openapi.Parameter{pathParam("alertmanager", "Alertmanager upstream name"), pathParam("id", "Silence ID")},
+		Responses: map[string]openapi.Response{
+			"200": openapi.Response{Description: "Response from Alertmanager", Content: openAPIJSON(&openapi.Schema{Type: "object"})},
+			"403": errorResponse("User isn't allowed to expire this silence"),
+			"404": errorResponse("Alertmanager upstream or silence not found"),
+			"502": errorResponse("Request to Alertmanager failed"),
+		},
+	})
+
+	doc.AddOperation("/summary.json", http.MethodGet, openapi.Operation{
+		OperationID: "getSummary",
+		Summary:     "Number of alerts matching the query, counted by state, severity and upstream",
+		Parameters:  []openapi.Parameter{filterParam(false)},
+		Responses: map[string]openapi.Response{
+			"200": openapi.Response{Description: "Alert counts", Content: openAPIJSON(doc.SchemaFor(models.AlertsSummary{}))},
+			"400": errorResponse("Invalid filter"),
+		},
+	})
+
+	doc.AddOperation("/refresh", http.MethodPost, openapi.Operation{
+		OperationID: "refresh",
+		Summary:     "Collect alerts and silences from all Alertmanager upstreams right away",
+		Description: fmt.Sprintf("The response is sent once the collection finished, requests sent while a refresh is in progress wait for it instead of starting another one, a new refresh can only be started %s after the last one", config.Config.RefreshMinInterval),
+		Responses: map[string]openapi.Response{
+			"200": openapi.Response{Description: "Collection result of every refreshed upstream", Content: openAPIJSON(doc.SchemaFor(models.RefreshResponse{}))},
+			"429": errorResponse("Last refresh was started too recently"),
+		},
+	})
+
+	doc.AddOperation("/refresh/{alertmanager}", http.MethodPost, openapi.Operation{
+		OperationID: "refreshAlertmanager",
+		Summary:     "Collect alerts and silences from given Alertmanager upstream right away",
+		Description: "Works like /refresh, but only given upstream is collected",
+		Parameters:  []openapi.Parameter{pathParam("alertmanager", "Alertmanager upstream name")},
+		Responses: map[string]openapi.Response{
+			"200": openapi.Response{Description: "Collection result of the upstream", Content: openAPIJSON(doc.SchemaFor(models.RefreshResponse{}))},
+			"404": errorResponse("Alertmanager upstream not found"),
+			"429": errorResponse("Last refresh of this upstream was started too recently"),
+		},
+	})
+
+	doc.AddOperation("/admin/pause", http.MethodPost, openapi.Operation{
+		OperationID: "pauseCollection",
+		Summary:     "Pause collection from all Alertmanager upstreams",
+		Description: "Alerts and silences from the last collection are kept until collection is resumed",
+		Responses: map[string]openapi.Response{
+			"200": openapi.Response{Description: "Collection state of every upstream", Content: openAPIJSON(doc.SchemaFor(models.CollectionResponse{}))},
+		},
+	})
+
+	doc.AddOperation("/admin/pause/{alertmanager}", http.MethodPost, openapi.Operation{
+		OperationID: "pauseAlertmanagerCollection",
+		Summary:     "Pause collection from given Alertmanager upstream",
+		Parameters:  []openapi.Parameter{pathParam("alertmanager", "Alertmanager upstream name")},
+		Responses: map[string]openapi.Response{
+			"200": openapi.Response{Description: "Collection state of the upstream", Content: openAPIJSON(doc.SchemaFor(models.CollectionResponse{}))},
+			"404": errorResponse("Alertmanager upstream not found"),
+		},
+	})
+
+	doc.AddOperation("/admin/resume", http.MethodPost, openapi.Operation{
+		OperationID: "resumeCollection",
+		Summary:     "Resume collection from all Alertmanager upstreams",
+		Responses: map[string]openapi.Response{
+			"200": openapi.Response{Description: "Collection state of every upstream", Content: openAPIJSON(doc.SchemaFor(models.CollectionResponse{}))},
+		},
+	})
+
+	doc.AddOperation("/admin/resume/{alertmanager}", http.MethodPost, openapi.Operation{
+		OperationID: "resumeAlertmanagerCollection",
+		Summary:     "Resume collection from given Alertmanager upstream",
+		Parameters:  []openapi.Parameter{pathParam("alertmanager", "Alertmanager upstream name")},
+		Responses: map[string]openapi.Response{
+			"200": openapi.Response{Description: "Collection state of the upstream", Content: openAPIJSON(doc.SchemaFor(models.CollectionResponse{}))},
+			"404": errorResponse("Alertmanager upstream not found"),
+		},
+	})
+
+	doc.AddOperation("/labels/top.json", http.MethodGet, openapi.Operation{
+		OperationID: "getTopLabelValues",
+		Summary:     "Most frequent values of a label across alerts matching the query",
+		Parameters: []openapi.Parameter{
+			openapi.Parameter{Name: "label", In: "query", Description: "Name of the label to count values of", Required: true, Schema: doc.SchemaFor("")},
+			filterParam(false),
+			intParam("limit", fmt.Sprintf("Maximum number of values to return, default is %d", topLabelValuesLimit)),
+		},
+		Responses: map[string]openapi.Response{
+			"200": openapi.Response{Description: "Label values, most frequent first", Content: openAPIJSON(doc.SchemaFor(models.TopLabelValues{}))},
+			"400": errorResponse("Missing label, invalid filter or invalid limit"),
+		},
+	})
+
+	doc.AddOperation("/search", http.MethodGet, openapi.Operation{
+		OperationID: "search",
+		Summary:     "Free text search across label and annotation values of all alerts",
+		Description: "The query is split into words, alerts match if every word is found in any label or annotation value, alerts containing the whole query get a higher score",
+		Parameters: []openapi.Parameter{
+			openapi.Parameter{Name: "q", In: "query", Description: "Text to search for", Required: true, Schema: doc.SchemaFor("")},
+			intParam("limit", fmt.Sprintf("Maximum number of results to return, default is %d", searchResultsLimit)),
+		},
+		Responses: map[string]openapi.Response{
+			"200": openapi.Response{Description: "Matching alerts, highest score first", Content: openAPIJSON(doc.SchemaFor(models.SearchResponse{}))},
+			"400": errorResponse("Empty query or invalid limit"),
+		},
+	})
+
+	doc.AddOperation("/counters.json", http.MethodGet, openapi.Operation{
+		OperationID: "getCounters",
+		Summary:     "Number of alerts matching the query, counted by state",
+		Description: "Responses are cached until the next collection, it's designed for very frequent polling",
+		Parameters:  []openapi.Parameter{filterParam(false)},
+		Responses: map[string]openapi.Response{
+			"200": openapi.Response{Description: "Alert counts", Content: openAPIJSON(doc.SchemaFor(models.AlertCounters{}))},
+			"400": errorResponse("Invalid filter"),
+		},
+	})
+
+	doc.AddOperation("/history.json", http.MethodGet, openapi.Operation{
+		OperationID: "getHistory",
+		Summary:     "Number of alerts recorded on every collection, grouped into time buckets",
+		Description: "Every bucket reports counts from the collection with the highest number of alerts in it, counts are recorded for all alerts so it's not available to users restricted by tenant filters",
+		Parameters: []openapi.Parameter{
+			openapi.Parameter{Name: "resolution", In: "query", Description: "Duration of every bucket, default is 5m", Schema: doc.SchemaFor("")},
+			openapi.Parameter{Name: "range", In: "query", Description: "Time range covered by all buckets, default is 24h", Schema: doc.SchemaFor("")},
+		},
+		Responses: map[string]openapi.Response{
+			"200": openapi.Response{Description: "Alert counts history", Content: openAPIJSON(doc.SchemaFor(models.AlertCountsHistory{}))},
+			"400": errorResponse("Invalid resolution or range"),
+			"403": errorResponse("User is restricted by tenant filters"),
+		},
+	})
+
+	doc.AddOperation("/badge.svg", http.MethodGet, openapi.Operation{
+		OperationID: "getBadge",
+		Summary:     "SVG badge with the number of alerts matching the query",
+		Description: "Badge color depends on the most severe severity label value of matching alerts",
+		Parameters: []openapi.Parameter{
+			filterParam(false),
+			openapi.Parameter{Name: "label", In: "query", Description: "Text on the left side of the badge, default is alerts", Schema: doc.SchemaFor("")},
+		},
+		Responses: map[string]openapi.Response{
+			"200": openapi.Response{Description: "Badge", Content: map[string]openapi.MediaType{badgeContentType: openapi.MediaType{Schema: doc.SchemaFor("")}}},
+			"400": openapi.Response{Description: "Badge for an invalid filter", Content: map[string]openapi.MediaType{badgeContentType: openapi.MediaType{Schema: doc.SchemaFor("")}}},
+		},
+	})
+
+	doc.AddOperation("/ws", http.MethodGet, openapi.Operation{
+		OperationID: "getEventsWebsocket",
+		Summary:     "WebSocket stream of alert changes",
+		Description: "Every message is a JSON encoded AlertEvent",
+		Parameters:  []openapi.Parameter{filterParam(false)},
+		Responses: map[string]openapi.Response{
+			"101": openapi.Response{Description: "WebSocket connection established"},
+			"400": errorResponse("Invalid filter"),
+		},
+	})
+
+	doc.AddOperation("/events", http.MethodGet, openapi.Operation{
+		OperationID: "getEventsStream",
+		Summary:     "Server-Sent Events stream of alert changes",
+		Description: "Event name is the change type, data is a JSON encoded AlertEvent",
+		Parameters:  []openapi.Parameter{filterParam(false)},
+		Responses: map[string]openapi.Response{
+			"200": openapi.Response{
+				Description: "Event stream",
+				Content:     map[string]openapi.MediaType{"text/event-stream": openapi.MediaType{Schema: doc.SchemaFor(models.AlertEvent{})}},
+			},
+			"400": errorResponse("Invalid filter"),
+		},
+	})
+
+	doc.AddOperation("/filters/saved.json", http.MethodGet, openapi.Operation{
+		OperationID: "listSavedFilters",
+		Summary:     "All saved filters",
+		Responses: map[string]openapi.Response{
+			"200": openapi.Response{Description: "Saved filters", Content: openAPIJSON(doc.SchemaFor([]models.SavedFilter{}))},
+		},
+	})
+	doc.AddOperation("/filters/saved/{name}", http.MethodGet, openapi.Operation{
+		OperationID: "getSavedFilter",
+		Summary:     "Saved filter with given name",
+		Parameters:  []openapi.Parameter{pathParam("name", "Saved filter name")},
+		Responses: map[string]openapi.Response{
+			"200": openapi.Response{Description: "Saved filter", Content: openAPIJSON(doc.SchemaFor(models.SavedFilter{}))},
+			"404": errorResponse("Saved filter not found"),
+		},
+	})
+	doc.AddOperation("/filters/saved/{name}", http.MethodPut, openapi.Operation{
+		OperationID: "saveFilter",
+		Summary:     "Create or update a saved filter",
+		Parameters:  []openapi.Parameter{pathParam("name", "Saved filter name, only letters, digits, '.', '_' and '-' are allowed")},
+		RequestBody: jsonBody(models.SavedFilter{}),
+		Responses: map[string]openapi.Response{
+			"200": openapi.Response{Description: "Saved filter", Content: openAPIJSON(doc.SchemaFor(models.SavedFilter{}))},
+			"400": errorResponse("Invalid name or filter"),
+		},
+	})
+	doc.AddOperation("/filters/saved/{name}", http.MethodDelete, openapi.Operation{
+		OperationID: "deleteSavedFilter",
+		Summary:     "Delete a saved filter",
+		Parameters:  []openapi.Parameter{pathParam("name", "Saved filter name")},
+		Responses: map[string]openapi.Response{
+			"204": openapi.Response{Description: "Saved filter was deleted"},
+			"404": errorResponse("Saved filter not found"),
+		},
+	})
+
+	doc.AddOperation("/settings.json", http.MethodGet, openapi.Operation{
+		OperationID: "getUserSettings",
+		Summary:     "UI settings of the authenticated user",
+		Responses: map[string]openapi.Response{
+			"200": openapi.Response{Description: "User settings", Content: openAPIJSON(doc.SchemaFor(models.UserSettings{}))},
+			"401": errorResponse("No authenticated user"),
+		},
+	})
+	doc.AddOperation("/settings.json", http.MethodPut, openapi.Operation{
+		OperationID: "saveUserSettings",
+		Summary:     "Replace UI settings of the authenticated user",
+		RequestBody: jsonBody(models.UserSettings{}),
+		Responses: map[string]openapi.Response{
+			"200": openapi.Response{Description: "User settings", Content: openAPIJSON(doc.SchemaFor(models.UserSettings{}))},
+			"400": errorResponse("Invalid settings"),
+			"401": errorResponse("No authenticated user"),
+		},
+	})
+	doc.AddOperation("/settings.json", http.MethodDelete, openapi.Operation{
+		OperationID: "deleteUserSettings",
+		Summary:     "Delete UI settings of the authenticated user",
+		Responses: map[string]openapi.Response{
+			"204": openapi.Response{Description: "User settings were deleted"},
+			"401": errorResponse("No authenticated user"),
+		},
+	})
+
+	doc.AddOperation("/filters/presets.json", http.MethodGet, openapi.Operation{
+		OperationID: "listFilterPresets",
+		Summary:     "Filter presets from the server configuration",
+		Responses: map[string]openapi.Response{
+			"200": openapi.Response{Description: "Filter presets", Content: openAPIJSON(doc.SchemaFor([]models.FilterPreset{}))},
+		},
+	})
+
+	doc.AddOperation("/filters/validate", http.MethodGet, openapi.Operation{
+		OperationID: "validateFilters",
+		Summary:     "Validate filter expressions",
+		Parameters:  []openapi.Parameter{filterParam(true)},
+		Responses: map[string]openapi.Response{
+			"200": openapi.Response{Description: "Validation result for every filter", Content: openAPIJSON(doc.SchemaFor(models.FilterValidationResponse{}))},
+			"400": errorResponse("Missing filter"),
+		},
+	})
+
+	doc.AddOperation("/s", http.MethodPost, openapi.Operation{
+		OperationID: "createShortURL",
+		Summary:     "Create a short URL for a filter",
+		RequestBody: jsonBody(models.ShortURL{}),
+		Responses: map[string]openapi.Response{
+			"200": openapi.Response{Description: "Short URL", Content: openAPIJSON(doc.SchemaFor(models.ShortURL{}))},
+			"400": errorResponse("Invalid filter"),
+		},
+	})
+	doc.AddOperation("/s/{token}", http.MethodGet, openapi.Operation{
+		OperationID: "resolveShortURL",
+		Summary:     "Redirect to the UI with the filter stored for the short URL",
+		Parameters:  []openapi.Parameter{pathParam("token", "Short URL token")},
+		Responses: map[string]openapi.Response{
+			"302": openapi.Response{Description: "Redirect to the UI"},
+			"404": errorResponse("Unknown token"),
+		},
+	})
+
+	doc.AddOperation("/version", http.MethodGet, openapi.Operation{
+		OperationID: "getVersion",
+		Summary:     "Version and build details",
+		Responses: map[string]openapi.Response{
+			"200": openapi.Response{Description: "Version details", Content: openAPIJSON(doc.SchemaFor(models.VersionInfo{}))},
+		},
+	})
+
+	doc.AddOperation("/ui.json", http.MethodGet, openapi.Operation{
+		OperationID: "getUIConfig",
+		Summary:     "Branding options used by the UI",
+		Responses: map[string]openapi.Response{
+			"200": openapi.Response{Description: "UI config", Content: openAPIJSON(doc.SchemaFor(models.UIConfig{}))},
+		},
+	})
+
+	doc.AddOperation("/ui-config.json", http.MethodGet, openapi.Operation{
+		OperationID: "getUIDefaults",
+		Summary:     "Default values of all UI options",
+		Description: "Clients should use those unless the user changed the option",
+		Responses: map[string]openapi.Response{
+			"200": openapi.Response{Description: "UI defaults", Content: openAPIJSON(doc.SchemaFor(models.UIDefaults{}))},
+		},
+	})
+
+	statusResponse := openapi.Response{
+		Description: "OK",
+		Content: openAPIJSON(&openapi.Schema{
+			Type: "object",
+			Properties: map[string]*openapi.Schema{
+				"status": &openapi.Schema{Type: "string"},
+				"paused": doc.SchemaFor([]string{}),
+			},
+		}),
+	}
+	doc.AddOperation("/healthz", http.MethodGet, openapi.Operation{
+		OperationID: "getHealth",
+		Summary:     "Liveness check",
+		Responses:   map[string]openapi.Response{"200": statusResponse},
+	})
+	doc.AddOperation("/readyz", http.MethodGet, openapi.Operation{
+		OperationID: "getReadiness",
+		Summary:     "Readiness check, requires at least one Alertmanager upstream to be recently collected and READINESS_UPSTREAMS to be collected after startup",
+		Responses: map[string]openapi.Response{
+			"200": statusResponse,
+			"503": errorResponse("No upstream was collected recently or the first collection is still in progress"),
+		},
+	})
+
+	doc.AddOperation("/openapi.json", http.MethodGet, openapi.Operation{
+		OperationID: "getOpenAPI",
+		Summary:     "This OpenAPI specification",
+		Responses: map[string]openapi.Response{
+			"200": openapi.Response{Description: "OpenAPI document", Content: openAPIJSON(&openapi.Schema{Type: "object"})},
+		},
+	})
+
+	if (config.Config.DebugState || config.Config.Debug) && !config.Config.DemoMode {
+		doc.AddOperation("/debug/state", http.MethodGet, openapi.Operation{
+			OperationID: "getDebugState",
+			Summary:     "Internal state of all Alertmanager upstreams and the config",
+			Description: "Secrets are hidden so it can be attached to bug reports, upstream state isn't filtered so it's not available to users restricted by tenant filters",
+			Responses: map[string]openapi.Response{
+				"200": openapi.Response{Description: "Debug state", Content: openAPIJSON(doc.SchemaFor(debugStateResponse{}))},
+				"403": errorResponse("User is restricted by tenant filters"),
+			},
+		})
+	}
+
+	if config.Config.AlertmanagerProxy {
+		for _, method := range []string{http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodDelete} {
+			doc.AddOperation("/proxy/{alertmanager}/{path}", method, openapi.Operation{
+				OperationID: "proxy" + strings.Title(strings.ToLower(method)),
+				Summary:     "Proxy request to given Alertmanager upstream",
+				Parameters: []openapi.Parameter{
+					pathParam("alertmanager", "Alertmanager upstream name"),
+					pathParam("path", "Path that will be requested from Alertmanager"),
+				},
+				Responses: map[string]openapi.Response{
+					"200": openapi.Response{Description: "Response from Alertmanager"},
+					"403": errorResponse("Request can't be proxied when silence ACL is enabled"),
+					"404": errorResponse("Alertmanager upstream not found"),
+					"502": errorResponse("Request to Alertmanager failed"),
+				},
+			})
+		}
+	}
+
+	keys, _ := getAPIKeys()
+	users, _ := getHtpasswdUsers()
+	metricsUsers, _ := getMetricsHtpasswdUsers()
+	schemes := map[string]*openapi.SecurityScheme{}
+	if len(keys) > 0 {
+		schemes["apiKeyHeader"] = &openapi.SecurityScheme{Type: "apiKey", Name: apiKeyHeader, In: "header"}
+		schemes["apiKeyQuery"] = &openapi.SecurityScheme{Type: "apiKey", Name: apiKeyQueryParam, In: "query"}
+	}
+	if len(users) > 0 {
+		schemes["basicAuth"] = &openapi.SecurityScheme{Type: "http", Scheme: "basic"}
+	}
+	if len(metricsUsers) > 0 {
+		schemes["metricsBasicAuth"] = &openapi.SecurityScheme{Type: "http", Scheme: "basic"}
+	}
+	if len(schemes) > 0 {
+		doc.Components.SecuritySchemes = schemes
+		public := []string{"/s/{token}", "/openapi.json", "/version", "/ui.json", "/healthz", "/readyz"}
+		for path, ops := range doc.Paths {
+			// basic auth protects everything but health checks, API keys are
+			// required on top of it
+			requirements := []openapi.SecurityRequirement{}
+			if len(keys) > 0 && !slices.StringInSlice(public, path) {
+				requirements = append(requirements,
+					openapi.SecurityRequirement{"apiKeyHeader": []string{}},
+					openapi.SecurityRequirement{"apiKeyQuery": []string{}},
+				)
+			}
+			// admin endpoints can have their own users
+			auth := ""
+			switch {
+			case path == "/healthz" || path == "/readyz":
+			case len(metricsUsers) > 0 && (strings.HasPrefix(path, "/admin/") || strings.HasPrefix(path, "/debug/")):
+				auth = "metricsBasicAuth"
+			case len(users) > 0:
+				auth = "basicAuth"
+			}
+			if auth != "" {
+				if len(requirements) == 0 {
+					requirements = append(requirements, openapi.SecurityRequirement{})
+				}
+				for _, r := range requirements {
+					r[auth] = []string{}
+				}
+			}
+			if len(requirements) == 0 {
+				continue
+			}
+			for _, op := range ops {
+				op.Security = requirements
+			}
+		}
+	}
+
+	return doc
+}
+
+// OpenAPI specification, json
+func openAPISpec(c *gin.Context) {
+	start := time.Now()
+	defer logView(c, start)
+	doc := openAPIDocument()
+	doc.Servers = []openapi.Server{openapi.Server{URL: strings.TrimSuffix(getPublicPrefix(c), "/")}}
+	c.JSON(http.StatusOK, doc)
+}
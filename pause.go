@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/cloudflare/unsee/internal/alertmanager"
+	"github.com/cloudflare/unsee/internal/models"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+// pausedUpstreams returns sorted names of all upstreams with paused collection
+func pausedUpstreams() []string {
+	names := []string{}
+	for _, am := range alertmanager.GetAlertmanagers() {
+		if am.IsPaused() {
+			names = append(names, am.Name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// collectionResponse returns the collection state of given upstreams
+func collectionResponse(upstreams []*alertmanager.Alertmanager) models.CollectionResponse {
+	resp := models.CollectionResponse{Upstreams: []models.UpstreamCollection{}}
+	for _, am := range upstreams {
+		resp.Upstreams = append(resp.Upstreams, models.UpstreamCollection{Name: am.Name, Paused: am.IsPaused()})
+	}
+	sort.Slice(resp.Upstreams, func(i, j int) bool {
+		return resp.Upstreams[i].Name < resp.Upstreams[j].Name
+	})
+	return resp
+}
+
+// setPaused returns a handler that pauses or resumes collection from all
+// upstreams, or only the one passed in the URL
+func setPaused(paused bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		upstreams := alertmanager.GetAlertmanagers()
+		if name := c.Param("alertmanager"); name != "" {
+			am := alertmanager.GetAlertmanagerByName(name)
+			if am == nil {
+				c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("alertmanager '%s' not found", name)})
+				return
+			}
+			upstreams = []*alertmanager.Alertmanager{am}
+		}
+
+		for _, am := range upstreams {
+			if paused && am.Pause() {
+				log.Infof("[%s] Paused collection from '%s'", c.ClientIP(), am.Name)
+			} else if !paused && am.Resume() {
+				log.Infof("[%s] Resumed collection from '%s'", c.ClientIP(), am.Name)
+			}
+		}
+		// cached responses include the state of every upstream
+		apiCache.Flush()
+		c.JSON(http.StatusOK, collectionResponse(upstreams))
+	}
+}
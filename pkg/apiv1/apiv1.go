@@ -0,0 +1,109 @@
+// Package apiv1 defines responses of /api/v1/ endpoints, unlike types from
+// the models package those are frozen, fields can be added but are never
+// renamed or removed, so integrations can rely on them between unsee releases,
+// the schema mirrors messages of the gRPC API
+package apiv1
+
+import "time"
+
+// Status is the value of the status field of every successful response
+const Status = "success"
+
+// SilenceMatcher is a single matcher of a silence
+type SilenceMatcher struct {
+	Name    string `json:"name"`
+	Value   string `json:"value"`
+	IsRegex bool   `json:"isRegex"`
+}
+
+// Silence is a silence collected from Alertmanager
+type Silence struct {
+	ID        string           `json:"id"`
+	Matchers  []SilenceMatcher `json:"matchers"`
+	StartsAt  time.Time        `json:"startsAt"`
+	EndsAt    time.Time        `json:"endsAt"`
+	CreatedAt time.Time        `json:"createdAt"`
+	CreatedBy string           `json:"createdBy"`
+	Comment   string           `json:"comment"`
+	JiraID    string           `json:"jiraID"`
+	JiraURL   string           `json:"jiraURL"`
+}
+
+// ManagedSilence is a silence deduplicated across all Alertmanager upstreams
+type ManagedSilence struct {
+	Silence
+	// State is one of active, pending or expired
+	State string `json:"state"`
+	// Alertmanagers lists names of all upstreams the silence was found on
+	Alertmanagers []string `json:"alertmanagers"`
+	// AlertCount is the number of alerts muted by the silence
+	AlertCount int `json:"alertCount"`
+}
+
+// AlertmanagerInstance is the state of an alert on a single Alertmanager
+// upstream
+type AlertmanagerInstance struct {
+	Name     string    `json:"name"`
+	URI      string    `json:"uri"`
+	State    string    `json:"state"`
+	StartsAt time.Time `json:"startsAt"`
+	EndsAt   time.Time `json:"endsAt"`
+	Source   string    `json:"source"`
+	// Silences lists all silences muting the alert on this upstream, sorted
+	// by ID
+	Silences []Silence `json:"silences"`
+}
+
+// Annotation is a single alert annotation
+type Annotation struct {
+	Name    string `json:"name"`
+	Value   string `json:"value"`
+	Visible bool   `json:"visible"`
+	IsLink  bool   `json:"isLink"`
+}
+
+// Alert is a single alert deduplicated across all Alertmanager upstreams
+type Alert struct {
+	Annotations  []Annotation           `json:"annotations"`
+	Labels       map[string]string      `json:"labels"`
+	StartsAt     time.Time              `json:"startsAt"`
+	EndsAt       time.Time              `json:"endsAt"`
+	State        string                 `json:"state"`
+	Fingerprint  string                 `json:"fingerprint"`
+	Receiver     string                 `json:"receiver"`
+	Alertmanager []AlertmanagerInstance `json:"alertmanager"`
+}
+
+// AlertGroup is a group of alerts sharing the same receiver and group labels
+type AlertGroup struct {
+	ID         string            `json:"id"`
+	Receiver   string            `json:"receiver"`
+	Labels     map[string]string `json:"labels"`
+	Alerts     []Alert           `json:"alerts"`
+	Hash       string            `json:"hash"`
+	StateCount map[string]int    `json:"stateCount"`
+}
+
+// AlertsResponse is the response of /api/v1/alerts
+type AlertsResponse struct {
+	Status    string       `json:"status"`
+	Timestamp time.Time    `json:"timestamp"`
+	Version   string       `json:"version"`
+	Groups    []AlertGroup `json:"groups"`
+}
+
+// AlertGroupResponse is the response of /api/v1/alerts/group/{id}
+type AlertGroupResponse struct {
+	Status    string     `json:"status"`
+	Timestamp time.Time  `json:"timestamp"`
+	Version   string     `json:"version"`
+	Group     AlertGroup `json:"group"`
+}
+
+// SilencesResponse is the response of /api/v1/silences
+type SilencesResponse struct {
+	Status    string           `json:"status"`
+	Timestamp time.Time        `json:"timestamp"`
+	Version   string           `json:"version"`
+	Silences  []ManagedSilence `json:"silences"`
+}
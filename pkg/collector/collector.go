@@ -0,0 +1,109 @@
+// Package collector collects alerts and silences from multiple Alertmanager
+// upstreams and deduplicates them, it's the same aggregation logic that is
+// used by unsee
+package collector
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudflare/unsee/internal/alertmanager"
+	"github.com/cloudflare/unsee/internal/models"
+	"github.com/cloudflare/unsee/internal/transform"
+	"github.com/cloudflare/unsee/pkg/store"
+)
+
+// Upstream is an Alertmanager instance alerts are collected from
+type Upstream struct {
+	// Name must be unique, it's used as the Alertmanager instance name in
+	// collected alerts
+	Name string
+	// URI of the Alertmanager API, http://, https:// and file:// URIs are
+	// supported
+	URI     string
+	Timeout time.Duration
+}
+
+// Collector collects alerts from a fixed set of upstreams and stores
+// deduplicated results in a Store
+type Collector struct {
+	store     *store.Store
+	upstreams []*alertmanager.Alertmanager
+}
+
+// New returns a Collector for given upstreams, collected data is stored in s
+func New(s *store.Store, upstreams ...Upstream) (*Collector, error) {
+	if len(upstreams) == 0 {
+		return nil, fmt.Errorf("At least one upstream is required")
+	}
+	c := &Collector{store: s}
+	names := map[string]bool{}
+	for _, u := range upstreams {
+		if u.Name == "" || u.URI == "" {
+			return nil, fmt.Errorf("Upstream name and URI are required")
+		}
+		if names[u.Name] {
+			return nil, fmt.Errorf("Upstream '%s' is defined more than once", u.Name)
+		}
+		names[u.Name] = true
+		c.upstreams = append(c.upstreams, alertmanager.New(u.Name, u.URI, u.Timeout))
+	}
+	return c, nil
+}
+
+// Collect pulls alerts and silences from all upstreams concurrently and
+// updates the store, upstreams that failed are included in the store without
+// any data, the error lists all of them
+func (c *Collector) Collect(ctx context.Context) error {
+	errs := make([]string, len(c.upstreams))
+	wg := sync.WaitGroup{}
+	wg.Add(len(c.upstreams))
+	for i, am := range c.upstreams {
+		go func(i int, am *alertmanager.Alertmanager) {
+			defer wg.Done()
+			if err := am.Pull(ctx); err != nil {
+				errs[i] = fmt.Sprintf("%s: %s", am.Name, err)
+			}
+		}(i, am)
+	}
+	wg.Wait()
+
+	c.store.Update(alertmanager.DedupUpstreamAlerts(c.upstreams), alertmanager.DedupUpstreamSilences(c.upstreams))
+	// strings and colors only used by alerts from older collections can be
+	// released now
+	models.RotateInterned()
+	transform.RotateColorCache()
+
+	failed := []string{}
+	for _, err := range errs {
+		if err != "" {
+			failed = append(failed, err)
+		}
+	}
+	if len(failed) > 0 {
+		sort.Strings(failed)
+		return fmt.Errorf("Failed to collect %d upstream(s): %s", len(failed), strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// Run collects all upstreams every interval until ctx is cancelled, errors
+// are passed to onError if it's not nil
+func (c *Collector) Run(ctx context.Context, interval time.Duration, onError func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := c.Collect(ctx); err != nil && onError != nil {
+			onError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package collector_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/cloudflare/unsee/internal/mock"
+	"github.com/cloudflare/unsee/pkg/collector"
+	"github.com/cloudflare/unsee/pkg/filter"
+	"github.com/cloudflare/unsee/pkg/store"
+
+	log "github.com/sirupsen/logrus"
+)
+
+func init() {
+	log.SetLevel(log.ErrorLevel)
+}
+
+func mockUpstreams() []collector.Upstream {
+	upstreams := []collector.Upstream{}
+	for i, uri := range mock.ListAllMockURIs() {
+		upstreams = append(upstreams, collector.Upstream{Name: fmt.Sprintf("mock-%d", i), URI: uri, Timeout: time.Second})
+	}
+	return upstreams
+}
+
+func countAlerts(s *store.Store, query string) (int, int) {
+	f, err := filter.Parse(query)
+	if err != nil {
+		panic(err)
+	}
+	groups := s.Query(f)
+	alerts := 0
+	for _, ag := range groups {
+		alerts += len(ag.Alerts)
+	}
+	return len(groups), alerts
+}
+
+func TestCollect(t *testing.T) {
+	s := store.New()
+	c, err := collector.New(s, mockUpstreams()...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Collect(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if s.Updated().IsZero() {
+		t.Error("Store wasn't updated")
+	}
+
+	for _, testCase := range []struct {
+		query  string
+		groups int
+		alerts int
+	}{
+		{query: "", groups: 10, alerts: 24},
+		{query: "alertname=HTTP_Probe_Failed", groups: 2, alerts: 4},
+		{query: "alertname=HTTP_Probe_Failed,instance=web1", groups: 2, alerts: 2},
+		{query: "alertname=Foo", groups: 0, alerts: 0},
+	} {
+		groups, alerts := countAlerts(s, testCase.query)
+		if groups != testCase.groups || alerts != testCase.alerts {
+			t.Errorf("Query '%s' returned %d group(s) with %d alert(s), expected %d group(s) with %d alert(s)",
+				testCase.query, groups, alerts, testCase.groups, testCase.alerts)
+		}
+	}
+
+	// every mock has 3 silences, some mocks share silence IDs
+	found := 0
+	for _, silence := range s.Silences() {
+		found += len(silence.Alertmanagers)
+	}
+	if found != 3*len(mockUpstreams()) {
+		t.Errorf("Got %d silences from all upstreams, expected %d", found, 3*len(mockUpstreams()))
+	}
+}
+
+func TestCollectFailure(t *testing.T) {
+	s := store.New()
+	upstreams := append(mockUpstreams()[:1], collector.Upstream{Name: "broken", URI: "file:///non-existent", Timeout: time.Second})
+	c, err := collector.New(s, upstreams...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	log.SetLevel(log.FatalLevel)
+	defer log.SetLevel(log.ErrorLevel)
+	if err := c.Collect(context.Background()); err == nil {
+		t.Error("Collect() didn't return any error for broken upstream")
+	}
+	if groups, _ := countAlerts(s, ""); groups != 10 {
+		t.Errorf("Got %d alert groups, expected 10 from the working upstream", groups)
+	}
+}
+
+func TestNew(t *testing.T) {
+	for _, upstreams := range [][]collector.Upstream{
+		{},
+		{{Name: "foo"}},
+		{{URI: "http://localhost"}},
+		{{Name: "foo", URI: "http://localhost:9093"}, {Name: "foo", URI: "http://localhost:9094"}},
+	} {
+		if _, err := collector.New(store.New(), upstreams...); err == nil {
+			t.Errorf("New() didn't return any error for %v", upstreams)
+		}
+	}
+}
+
+func TestParseFilter(t *testing.T) {
+	for _, query := range []string{"foo=", "@state=foo", "alertname=Foo,@limit=x"} {
+		if _, err := filter.Parse(query); err == nil {
+			t.Errorf("Parse(%s) didn't return any error", query)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+// Package filter matches alerts using unsee filter expressions, the same
+// syntax that is used in the UI and the q argument of /alerts.json
+package filter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cloudflare/unsee/internal/filters"
+	"github.com/cloudflare/unsee/pkg/models"
+)
+
+// Filter is a parsed list of filter expressions, alerts must match all of
+// them
+// Filters keep track of matched alerts, so a single Filter shouldn't be used
+// concurrently
+type Filter struct {
+	query   string
+	filters []filters.FilterT
+}
+
+// Parse parses a comma separated list of filter expressions, like
+// "alertname=Foo,@state=active", an empty query matches every alert
+func Parse(query string) (*Filter, error) {
+	f := &Filter{query: query}
+	if query == "" {
+		return f, nil
+	}
+	for _, expression := range strings.Split(query, ",") {
+		expr := filters.NewFilter(expression)
+		if !expr.GetIsValid() {
+			return nil, fmt.Errorf("Invalid filter expression '%s'", expression)
+		}
+		f.filters = append(f.filters, expr)
+	}
+	return f, nil
+}
+
+// String returns the query the filter was parsed from
+func (f *Filter) String() string {
+	return f.query
+}
+
+// Apply returns alert groups with only matching alerts, groups without any
+// matching alert are removed, groups passed to it are never modified
+func (f *Filter) Apply(groups []models.AlertGroup) []models.AlertGroup {
+	if len(f.filters) == 0 {
+		return groups
+	}
+
+	matched := []models.AlertGroup{}
+	matches := 0
+	for _, ag := range groups {
+		for _, expr := range f.filters {
+			if gf, ok := expr.(filters.GroupFilterT); ok {
+				gf.SetGroup(&ag)
+			}
+		}
+		alerts := models.AlertList{}
+		for i := range ag.Alerts {
+			if f.match(&ag.Alerts[i], matches) {
+				matches++
+				alerts = append(alerts, ag.Alerts[i])
+			}
+		}
+		if len(alerts) == 0 {
+			continue
+		}
+		if len(alerts) != len(ag.Alerts) {
+			ag.Alerts = alerts
+			ag.Hash = ag.ContentFingerprint()
+		}
+		matched = append(matched, ag)
+	}
+
+	for _, expr := range f.filters {
+		if lf, ok := expr.(filters.GroupLimitFilterT); ok {
+			matched = lf.LimitGroups(matched)
+		}
+	}
+	return matched
+}
+
+func (f *Filter) match(alert *models.Alert, matches int) bool {
+	for _, expr := range f.filters {
+		if !expr.Match(alert, matches) {
+			return false
+		}
+	}
+	return true
+}
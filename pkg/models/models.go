@@ -0,0 +1,39 @@
+// Package models exports types used by unsee packages that can be imported by
+// other projects, those are aliases of types used internally, so values can
+// be passed between packages without any conversion
+package models
+
+import "github.com/cloudflare/unsee/internal/models"
+
+// Alert is a single alert deduplicated across all Alertmanager upstreams
+type Alert = models.Alert
+
+// AlertList is a sortable list of alerts
+type AlertList = models.AlertList
+
+// AlertGroup is a group of alerts sharing the same receiver and group labels
+type AlertGroup = models.AlertGroup
+
+// AlertmanagerInstance holds the state of an alert on a single Alertmanager
+// upstream
+type AlertmanagerInstance = models.AlertmanagerInstance
+
+// Annotation is a single alert annotation
+type Annotation = models.Annotation
+
+// Annotations is a sortable list of annotations
+type Annotations = models.Annotations
+
+// Silence is a silence collected from Alertmanager
+type Silence = models.Silence
+
+// ManagedSilence is a silence deduplicated across all Alertmanager upstreams,
+// with the list of upstreams it was found on
+type ManagedSilence = models.ManagedSilence
+
+// alert states
+const (
+	AlertStateUnprocessed = models.AlertStateUnprocessed
+	AlertStateActive      = models.AlertStateActive
+	AlertStateSuppressed  = models.AlertStateSuppressed
+)
@@ -0,0 +1,70 @@
+// Package store keeps deduplicated alert groups and silences collected from
+// Alertmanager upstreams
+package store
+
+import (
+	"sync"
+	"time"
+
+	"github.com/cloudflare/unsee/pkg/filter"
+	"github.com/cloudflare/unsee/pkg/models"
+)
+
+// Store holds the latest collected alert groups and silences, it's safe for
+// concurrent use
+type Store struct {
+	lock     sync.RWMutex
+	groups   []models.AlertGroup
+	silences []models.ManagedSilence
+	updated  time.Time
+}
+
+// New returns an empty Store
+func New() *Store {
+	return &Store{
+		groups:   []models.AlertGroup{},
+		silences: []models.ManagedSilence{},
+	}
+}
+
+// Update replaces all stored data, it's called by the collector after every
+// collection
+func (s *Store) Update(groups []models.AlertGroup, silences []models.ManagedSilence) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.groups = groups
+	s.silences = silences
+	s.updated = time.Now()
+}
+
+// AlertGroups returns all stored alert groups, sorted by group ID, returned
+// groups must not be modified
+func (s *Store) AlertGroups() []models.AlertGroup {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	groups := make([]models.AlertGroup, len(s.groups))
+	copy(groups, s.groups)
+	return groups
+}
+
+// Query returns stored alert groups with only alerts matching given filter
+func (s *Store) Query(f *filter.Filter) []models.AlertGroup {
+	return f.Apply(s.AlertGroups())
+}
+
+// Silences returns all stored silences, sorted by ID
+func (s *Store) Silences() []models.ManagedSilence {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	silences := make([]models.ManagedSilence, len(s.silences))
+	copy(silences, s.silences)
+	return silences
+}
+
+// Updated returns the time of the last update, it's zero if the store was
+// never updated
+func (s *Store) Updated() time.Time {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.updated
+}
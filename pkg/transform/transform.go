@@ -0,0 +1,32 @@
+// Package transform allows to extend alert processing with extra steps, it
+// can be used by programs embedding unsee packages or by Go plugins loaded
+// using TRANSFORM_PLUGINS
+package transform
+
+import (
+	"context"
+
+	"github.com/cloudflare/unsee/internal/transform"
+	"github.com/cloudflare/unsee/pkg/models"
+)
+
+// Transform is an extra processing step run for alerts collected from a
+// single Alertmanager upstream, it's called for every alert group whenever
+// any alert in it changes, so it must always return the same output for the
+// same alerts and be safe to call concurrently
+type Transform interface {
+	// Name is used in logs
+	Name() string
+	// Apply returns modified alerts, it must return the same number of alerts
+	Apply(ctx context.Context, alerts []models.Alert) ([]models.Alert, error)
+}
+
+// every Transform must be usable as an internal transform
+var _ transform.Transform = Transform(nil)
+
+// Register adds a Transform that will be applied to alerts collected from
+// every Alertmanager upstream after all built-in transforms, it must be called
+// before any alerts are collected
+func Register(t Transform) {
+	transform.RegisterTransform(t)
+}
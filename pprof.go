@@ -0,0 +1,29 @@
+package main
+
+import (
+	"net/http/pprof"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// profiling endpoints from net/http/pprof, mounted under /debug/pprof/, it
+// uses a single wildcard route and dispatches requests itself, so it works
+// with any WEB_PREFIX
+func pprofHandler(c *gin.Context) {
+	name := strings.Trim(c.Param("profile"), "/")
+	switch name {
+	case "":
+		pprof.Index(c.Writer, c.Request)
+	case "cmdline":
+		pprof.Cmdline(c.Writer, c.Request)
+	case "profile":
+		pprof.Profile(c.Writer, c.Request)
+	case "symbol":
+		pprof.Symbol(c.Writer, c.Request)
+	case "trace":
+		pprof.Trace(c.Writer, c.Request)
+	default:
+		pprof.Handler(name).ServeHTTP(c.Writer, c.Request)
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/cloudflare/unsee/internal/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// header set by reverse proxies and ingress controllers that strip a path
+// prefix before proxying requests to unsee
+const forwardedPrefixHeader = "X-Forwarded-Prefix"
+
+// only allow simple paths as forwarded prefix, this value is used in links
+// and redirects, so it must never point to a different host, paths with
+// relative segments are also ignored
+var forwardedPrefixRegex = regexp.MustCompile("^(/[a-zA-Z0-9._~-]+)+/?$")
+
+// getPublicPrefix returns the URL prefix that clients use to access unsee,
+// it's WEB_PREFIX with X-Forwarded-Prefix in front of it if the reverse proxy
+// set it, returned prefix always has a trailing slash
+func getPublicPrefix(c *gin.Context) string {
+	prefix := config.Config.WebPrefix
+	fp := c.GetHeader(forwardedPrefixHeader)
+	if forwardedPrefixRegex.MatchString(fp) && path.Clean(fp) == strings.TrimSuffix(fp, "/") {
+		prefix = path.Join(fp, prefix)
+	}
+	if !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	return prefix
+}
+
+// redirect requests for the prefix without a trailing slash, all URLs used
+// by the UI are relative, so they only work if the index is requested with a
+// trailing slash
+func redirectToPrefix(c *gin.Context) {
+	target := getPublicPrefix(c)
+	if c.Request.URL.RawQuery != "" {
+		target += "?" + c.Request.URL.RawQuery
+	}
+	c.Redirect(http.StatusMovedPermanently, target)
+}
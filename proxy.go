@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/cloudflare/unsee/internal/alertmanager"
+	"github.com/cloudflare/unsee/internal/config"
+	"github.com/cloudflare/unsee/internal/transport"
+
+	"github.com/gin-gonic/gin"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// proxyDirector returns a function that will rewrite requests so they're sent
+// to given Alertmanager URI, headers and query arguments used to authenticate
+// with unsee are removed and credentials from the URI are used instead
+func proxyDirector(target *url.URL, subPath string) func(req *http.Request) {
+	return func(req *http.Request) {
+		req.URL.Scheme = target.Scheme
+		req.URL.Host = target.Host
+		req.URL.Path = path.Join("/", target.Path, subPath)
+		if strings.HasSuffix(subPath, "/") && !strings.HasSuffix(req.URL.Path, "/") {
+			req.URL.Path += "/"
+		}
+		req.URL.RawPath = ""
+		req.Host = target.Host
+
+		query := req.URL.Query()
+		query.Del(apiKeyQueryParam)
+		req.URL.RawQuery = query.Encode()
+
+		req.Header.Del("Cookie")
+		req.Header.Del("Authorization")
+		req.Header.Del(apiKeyHeader)
+		if target.User != nil {
+			password, _ := target.User.Password()
+			req.SetBasicAuth(target.User.Username(), password)
+		}
+		transport.SetRequestHeaders(req)
+	}
+}
+
+// proxy to Alertmanager upstream web UI and API, everything after
+// /proxy/<alertmanager> is forwarded to the Alertmanager URI
+func alertmanagerProxy(c *gin.Context) {
+	am := alertmanager.GetAlertmanagerByName(c.Param("alertmanager"))
+	if am == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("alertmanager '%s' not found", c.Param("alertmanager"))})
+		return
+	}
+
+	// silences created using the proxy would bypass silence ACL checks
+	if len(config.Config.SilenceACL) > 0 && c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only read-only requests can be proxied when silence ACL is enabled"})
+		return
+	}
+
+	target, err := url.Parse(am.URI)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") {
+		c.JSON(http.StatusBadGateway, gin.H{"error": fmt.Sprintf("alertmanager '%s' can't be proxied", am.Name)})
+		return
+	}
+
+	proxy := &httputil.ReverseProxy{
+		Director:  proxyDirector(target, c.Param("path")),
+		Transport: transport.RoundTripper(am.URI),
+		ErrorHandler: func(w http.ResponseWriter, req *http.Request, err error) {
+			log.Errorf("[%s] Proxy request to '%s' failed: %s", c.ClientIP(), am.Name, err)
+			c.JSON(http.StatusBadGateway, gin.H{"error": fmt.Sprintf("request to alertmanager '%s' failed: %s", am.Name, err)})
+		},
+	}
+	log.Infof("[%s] Proxying %s %s to '%s'", c.ClientIP(), c.Request.Method, c.Param("path"), am.Name)
+	proxy.ServeHTTP(c.Writer, c.Request)
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// how often idle clients are removed from the rate limiter
+const rateLimitCleanupInterval = time.Minute
+
+var rateLimitedRequests = prometheus.NewCounter(
+	prometheus.CounterOpts{
+		Name: "unsee_rate_limited_requests_total",
+		Help: "Total number of API requests rejected because the client exceeded the rate limit",
+	},
+)
+
+func init() {
+	prometheus.MustRegister(rateLimitedRequests)
+}
+
+// tokenBucket tracks the number of requests a single client can still make
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// rateLimiter implements per client token bucket rate limiting
+type rateLimiter struct {
+	sync.Mutex
+	rate        float64
+	burst       float64
+	clients     map[string]*tokenBucket
+	lastCleanup time.Time
+}
+
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &rateLimiter{
+		rate:        rate,
+		burst:       float64(burst),
+		clients:     map[string]*tokenBucket{},
+		lastCleanup: time.Now(),
+	}
+}
+
+// refill adds tokens accumulated since the last request
+func (rl *rateLimiter) refill(b *tokenBucket, now time.Time) {
+	b.tokens = math.Min(rl.burst, b.tokens+now.Sub(b.last).Seconds()*rl.rate)
+	b.last = now
+}
+
+// allow returns true if the client can make a request now, if not it will
+// also return the time after which next request will be allowed
+func (rl *rateLimiter) allow(client string, now time.Time) (bool, time.Duration) {
+	rl.Lock()
+	defer rl.Unlock()
+
+	if now.Sub(rl.lastCleanup) >= rateLimitCleanupInterval {
+		// clients with full buckets wouldn't be limited anyway
+		for key, b := range rl.clients {
+			rl.refill(b, now)
+			if b.tokens >= rl.burst {
+				delete(rl.clients, key)
+			}
+		}
+		rl.lastCleanup = now
+	}
+
+	b, found := rl.clients[client]
+	if !found {
+		b = &tokenBucket{tokens: rl.burst, last: now}
+		rl.clients[client] = b
+	}
+	rl.refill(b, now)
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	return false, time.Duration((1 - b.tokens) / rl.rate * float64(time.Second))
+}
+
+// rateLimit returns a middleware that will respond with 429 to clients
+// making more than rate requests per second (with bursts up to burst
+// requests), it doesn't do anything if rate isn't set
+func rateLimit(rate float64, burst int) gin.HandlerFunc {
+	rl := newRateLimiter(rate, burst)
+	return func(c *gin.Context) {
+		if rate <= 0 {
+			c.Next()
+			return
+		}
+
+		// clients are identified by the connection address, c.ClientIP() would
+		// let them pick a new address for every request via X-Forwarded-For
+		client := remoteHost(c.Request)
+		allowed, retryAfter := rl.allow(client, time.Now())
+		if !allowed {
+			rateLimitedRequests.Inc()
+			log.Warningf("[%s] Rate limit exceeded for %s %s", client, c.Request.Method, c.Request.URL.Path)
+			c.Header("Retry-After", fmt.Sprintf("%d", int(math.Ceil(retryAfter.Seconds()))))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "rate limit exceeded, retry later"})
+			return
+		}
+		c.Next()
+	}
+}
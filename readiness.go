@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cloudflare/unsee/internal/alertmanager"
+	"github.com/cloudflare/unsee/internal/config"
+	"github.com/cloudflare/unsee/internal/slices"
+
+	"github.com/gin-gonic/gin"
+)
+
+// READINESS_UPSTREAMS values that aren't a number of upstreams
+const (
+	readinessUpstreamsAny = "any"
+	readinessUpstreamsAll = "all"
+)
+
+// how long clients should wait before retrying UI requests rejected while
+// waiting for the first collection
+const startupRetryAfter = 5 * time.Second
+
+// getReadinessQuorum returns the number of upstreams that must be collected
+// after startup before the instance is ready, 0 if it doesn't need to wait
+func getReadinessQuorum() (int, error) {
+	upstreams := len(slices.NonEmptyStrings(config.Config.AlertmanagerURIs))
+	switch config.Config.ReadinessUpstreams {
+	case readinessUpstreamsAny:
+		return 0, nil
+	case readinessUpstreamsAll:
+		return upstreams, nil
+	}
+	quorum, err := strconv.Atoi(config.Config.ReadinessUpstreams)
+	if err != nil || quorum < 1 {
+		return 0, fmt.Errorf("Invalid READINESS_UPSTREAMS value '%s', expected %s, %s or a positive number", config.Config.ReadinessUpstreams, readinessUpstreamsAny, readinessUpstreamsAll)
+	}
+	if quorum > upstreams {
+		return 0, fmt.Errorf("Invalid READINESS_UPSTREAMS value '%s', only %d Alertmanager upstream(s) are configured", config.Config.ReadinessUpstreams, upstreams)
+	}
+	return quorum, nil
+}
+
+// checkStartup returns an error until at least quorum of upstreams completed
+// their first collection after startup, paused upstreams aren't collected so
+// they're not waited for
+func checkStartup(upstreams []*alertmanager.Alertmanager, quorum int) error {
+	if quorum == 0 {
+		return nil
+	}
+	active := 0
+	collected := 0
+	pending := []string{}
+	for _, upstream := range upstreams {
+		if upstream.IsPaused() {
+			continue
+		}
+		active++
+		if upstream.FirstCollected().IsZero() {
+			pending = append(pending, upstream.Name)
+		} else {
+			collected++
+		}
+	}
+	if quorum > active {
+		quorum = active
+	}
+	if collected >= quorum {
+		return nil
+	}
+	sort.Strings(pending)
+	return fmt.Errorf("waiting for the first collection from %d more Alertmanager upstream(s), not collected yet: %s", quorum-collected, strings.Join(pending, ", "))
+}
+
+// startupStatus returns an error until enough upstreams were collected for
+// the instance to be ready, the quorum is validated on startup
+func startupStatus() error {
+	quorum, _ := getReadinessQuorum()
+	return checkStartup(alertmanager.GetAlertmanagers(), quorum)
+}
+
+// waitForStartup rejects UI requests until enough upstreams were collected, so
+// users aren't shown an empty or partial dashboard right after startup
+func waitForStartup(enabled bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !enabled {
+			c.Next()
+			return
+		}
+		if err := startupStatus(); err != nil {
+			startupError(c, err)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// startupError renders the page shown while waiting for the first collection,
+// it's reloaded by the browser after startupRetryAfter
+func startupError(c *gin.Context, err error) {
+	retryAfter := strconv.Itoa(int(startupRetryAfter / time.Second))
+	noCache(c)
+	c.Header("Retry-After", retryAfter)
+	c.HTML(http.StatusServiceUnavailable, "startup-error", gin.H{
+		"Error":      err.Error(),
+		"RetryAfter": retryAfter,
+		"WebPrefix":  getPublicPrefix(c),
+	})
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cloudflare/unsee/internal/alertmanager"
+	"github.com/cloudflare/unsee/internal/config"
+	"github.com/cloudflare/unsee/internal/models"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+// refreshRun is a collection requested using the refresh endpoint, done is
+// closed once it finished and errs are set
+type refreshRun struct {
+	done chan struct{}
+	errs map[string]error
+}
+
+var refreshes = struct {
+	sync.Mutex
+	// runs that didn't finish yet keyed by the upstream name, an empty name
+	// is used for runs collecting all upstreams
+	running map[string]*refreshRun
+	// when the last run was started, keyed like running
+	started map[string]time.Time
+}{running: map[string]*refreshRun{}, started: map[string]time.Time{}}
+
+// requestRefresh returns the run collecting given upstreams, name is empty if
+// all upstreams are collected, requests are coalesced, so if there's a run in
+// progress that collects the upstream it's returned instead of starting a new
+// one, coalesced is true in that case
+// If a new run would start sooner than REFRESH_MIN_INTERVAL after the last one
+// nil is returned together with the time left until it can be started
+func requestRefresh(name string, upstreams []*alertmanager.Alertmanager, now time.Time) (run *refreshRun, coalesced bool, retryAfter time.Duration) {
+	refreshes.Lock()
+	defer refreshes.Unlock()
+
+	if run, found := refreshes.running[""]; found {
+		return run, true, 0
+	}
+	if run, found := refreshes.running[name]; found {
+		return run, true, 0
+	}
+	if started, found := refreshes.started[name]; found {
+		if elapsed := now.Sub(started); elapsed < config.Config.RefreshMinInterval {
+			return nil, false, config.Config.RefreshMinInterval - elapsed
+		}
+	}
+
+	run = &refreshRun{done: make(chan struct{})}
+	refreshes.running[name] = run
+	refreshes.started[name] = now
+	go func() {
+		run.errs = pullUpstreams(upstreams)
+		refreshes.Lock()
+		delete(refreshes.running, name)
+		refreshes.Unlock()
+		close(run.done)
+	}()
+	return run, false, 0
+}
+
+// refresh triggers an immediate collection of all upstreams, or only the one
+// passed in the URL, and responds once it's finished
+func refresh(c *gin.Context) {
+	name := c.Param("alertmanager")
+	upstreams := alertmanager.GetAlertmanagers()
+	if name != "" {
+		am := alertmanager.GetAlertmanagerByName(name)
+		if am == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("alertmanager '%s' not found", name)})
+			return
+		}
+		upstreams = []*alertmanager.Alertmanager{am}
+	}
+
+	run, coalesced, retryAfter := requestRefresh(name, upstreams, time.Now())
+	if run == nil {
+		c.Header("Retry-After", fmt.Sprintf("%d", int(math.Ceil(retryAfter.Seconds()))))
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": fmt.Sprintf("last refresh was less than %s ago, retry later", config.Config.RefreshMinInterval)})
+		return
+	}
+	if coalesced {
+		log.Infof("[%s] Waiting for refresh already in progress", c.ClientIP())
+	} else {
+		log.Infof("[%s] Refresh requested", c.ClientIP())
+	}
+	<-run.done
+
+	ts, _ := time.Now().UTC().MarshalText()
+	resp := models.RefreshResponse{
+		Timestamp: string(ts),
+		Coalesced: coalesced,
+		Upstreams: []models.RefreshedUpstream{},
+	}
+	for _, am := range upstreams {
+		u := models.RefreshedUpstream{Name: am.Name}
+		if err, found := run.errs[am.Name]; found {
+			u.Error = err.Error()
+		}
+		resp.Upstreams = append(resp.Upstreams, u)
+	}
+	sort.Slice(resp.Upstreams, func(i, j int) bool {
+		return resp.Upstreams[i].Name < resp.Upstreams[j].Name
+	})
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cloudflare/unsee/internal/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// robots.txt served if ROBOTS_TXT isn't set, it disallows crawling anything
+const defaultRobotsTxt = "User-agent: *\nDisallow: /\n"
+
+// robots.txt, text
+func robotsTxt(c *gin.Context) {
+	start := time.Now()
+	defer logView(c, start)
+
+	content := defaultRobotsTxt
+	if config.Config.RobotsTxt != "" {
+		content = config.Config.RobotsTxt
+		if !strings.HasSuffix(content, "\n") {
+			content += "\n"
+		}
+	}
+	c.String(http.StatusOK, content)
+}
+
+// robotsTag returns a middleware that will ask search engines not to index
+// any response or follow any link, it doesn't do anything if noindex is false
+func robotsTag(noindex bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if noindex {
+			c.Header("X-Robots-Tag", "noindex, nofollow")
+		}
+		c.Next()
+	}
+}
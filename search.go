@@ -0,0 +1,174 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/cloudflare/unsee/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// searchResultsLimit is the number of results returned by the search
+// endpoint unless the limit argument is passed
+const searchResultsLimit = 50
+
+// searchTokens splits text into lower case tokens, anything that's not a
+// letter or a digit is a separator, so "payments-db-3" is split into
+// "payments", "db" and "3"
+func searchTokens(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// searchScore returns the score of a single value for given query tokens, it
+// gets a point for every query token found in it and as many extra points as
+// there are query tokens if it contains all of them in the same order, one
+// more point is added if there's nothing else in the value, found is set for
+// every query token present in the value
+func searchScore(value string, tokens []string, found []bool) int {
+	valueTokens := searchTokens(value)
+	if len(valueTokens) == 0 {
+		return 0
+	}
+	present := make(map[string]bool, len(valueTokens))
+	for _, token := range valueTokens {
+		present[token] = true
+	}
+
+	score := 0
+	for i, token := range tokens {
+		if present[token] {
+			score++
+			found[i] = true
+		}
+	}
+	if score == len(tokens) {
+		phrase := " " + strings.Join(tokens, " ") + " "
+		joined := " " + strings.Join(valueTokens, " ") + " "
+		if strings.Contains(joined, phrase) {
+			score += len(tokens)
+			if joined == phrase {
+				score++
+			}
+		}
+	}
+	return score
+}
+
+// searchAlert returns the score of the alert and all its matching label and
+// annotation values, alerts only match if every query token is found in any
+// of those values
+func searchAlert(alert *models.Alert, tokens []string) (int, []models.SearchMatch) {
+	found := make([]bool, len(tokens))
+	score := 0
+	matches := []models.SearchMatch{}
+
+	names := make([]string, 0, len(alert.Labels))
+	for name := range alert.Labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if s := searchScore(alert.Labels[name], tokens, found); s > 0 {
+			score += s
+			matches = append(matches, models.SearchMatch{Type: models.SearchMatchLabel, Name: name, Value: alert.Labels[name]})
+		}
+	}
+	for _, annotation := range alert.Annotations {
+		if s := searchScore(annotation.Value, tokens, found); s > 0 {
+			score += s
+			matches = append(matches, models.SearchMatch{Type: models.SearchMatchAnnotation, Name: annotation.Name, Value: annotation.Value})
+		}
+	}
+
+	for _, f := range found {
+		if !f {
+			return 0, nil
+		}
+	}
+	return score, matches
+}
+
+// searchAlerts returns alerts the tenant can see with label or annotation
+// values matching the query, sorted by score, highest first
+func searchAlerts(t tenant, query string, limit int) models.SearchResponse {
+	resp := models.SearchResponse{
+		Query:   query,
+		Tokens:  searchTokens(query),
+		Results: []models.SearchResult{},
+	}
+	if len(resp.Tokens) == 0 {
+		return resp
+	}
+
+	results := map[string]*models.SearchResult{}
+	for _, ag := range t.alertGroups() {
+		for _, alert := range ag.Alerts {
+			key := alert.LabelsFingerprint()
+			if result, found := results[key]; found {
+				result.Groups = append(result.Groups, ag.ID)
+				continue
+			}
+			score, matches := searchAlert(&alert, resp.Tokens)
+			if score == 0 {
+				continue
+			}
+			results[key] = &models.SearchResult{
+				Alert:   alert,
+				Groups:  []string{ag.ID},
+				Score:   score,
+				Matches: matches,
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(results))
+	for key := range results {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if results[keys[i]].Score != results[keys[j]].Score {
+			return results[keys[i]].Score > results[keys[j]].Score
+		}
+		return keys[i] < keys[j]
+	})
+
+	resp.Total = len(keys)
+	if limit > 0 && len(keys) > limit {
+		keys = keys[:limit]
+	}
+	for _, key := range keys {
+		resp.Results = append(resp.Results, *results[key])
+	}
+	return resp
+}
+
+// free text search across all label and annotation values, json, it's meant
+// for users who don't know the filter syntax, results are ranked by how well
+// alerts match the query
+func search(c *gin.Context) {
+	noCache(c)
+	start := time.Now()
+	defer logView(c, start)
+
+	limit, err := parsePaginationArg(c.Query("limit"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid limit: %s", err)})
+		return
+	}
+	if limit == 0 {
+		limit = searchResultsLimit
+	}
+	if len(searchTokens(c.Query("q"))) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "q argument must include at least one word"})
+		return
+	}
+
+	c.JSON(http.StatusOK, searchAlerts(getTenant(c), c.Query("q"), limit))
+}
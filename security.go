@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// withoutFrameAncestors returns the Content-Security-Policy with the
+// frame-ancestors directive removed
+func withoutFrameAncestors(csp string) string {
+	directives := []string{}
+	for _, directive := range strings.Split(csp, ";") {
+		directive = strings.TrimSpace(directive)
+		if directive == "" || strings.HasPrefix(directive, "frame-ancestors") {
+			continue
+		}
+		directives = append(directives, directive)
+	}
+	return strings.Join(directives, "; ")
+}
+
+// securityHeaders returns a middleware that will set security headers on
+// HTML responses, empty values disable the header, if allowFraming is true
+// then X-Frame-Options header and frame-ancestors directive are never sent,
+// so the UI can be embedded on other pages
+func securityHeaders(csp string, frameOptions string, hstsMaxAge time.Duration, allowFraming bool) gin.HandlerFunc {
+	if allowFraming {
+		csp = withoutFrameAncestors(csp)
+		frameOptions = ""
+	}
+	return func(c *gin.Context) {
+		header := c.Writer.Header()
+		header.Set("X-Content-Type-Options", "nosniff")
+		if csp != "" {
+			header.Set("Content-Security-Policy", csp)
+		}
+		if frameOptions != "" {
+			header.Set("X-Frame-Options", frameOptions)
+		}
+		if hstsMaxAge > 0 {
+			header.Set("Strict-Transport-Security", fmt.Sprintf("max-age=%d; includeSubDomains", int(hstsMaxAge.Seconds())))
+		}
+		c.Next()
+	}
+}
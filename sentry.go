@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/cloudflare/unsee/internal/config"
+
+	raven "github.com/getsentry/raven-go"
+)
+
+// scrubbedValue replaces values of sensitive labels in Sentry events
+const scrubbedValue = "[Filtered]"
+
+// sensitiveLabelsRegexp returns a regexp matching values of given labels used
+// in filters (cluster=foo) or JSON and Go maps ("cluster":"foo" or
+// cluster:foo), it returns nil if there are no labels
+func sensitiveLabelsRegexp(labels []string) *regexp.Regexp {
+	names := []string{}
+	for _, label := range labels {
+		if label != "" {
+			names = append(names, regexp.QuoteMeta(label))
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	return regexp.MustCompile(fmt.Sprintf(`(\b(?:%s)"?(?:=~|!~|!=|=|>|<|:)"?)[^,&\s"'\]}]+`, strings.Join(names, "|")))
+}
+
+// scrubbingTransport removes values of sensitive labels from events before
+// those are sent to Sentry
+type scrubbingTransport struct {
+	re   *regexp.Regexp
+	next raven.Transport
+}
+
+func (t *scrubbingTransport) scrub(s string) string {
+	return t.re.ReplaceAllString(s, "${1}"+scrubbedValue)
+}
+
+// Send implements raven.Transport
+func (t *scrubbingTransport) Send(uri, authHeader string, packet *raven.Packet) error {
+	packet.Message = t.scrub(packet.Message)
+	packet.Culprit = t.scrub(packet.Culprit)
+	for i, tag := range packet.Tags {
+		packet.Tags[i].Value = t.scrub(tag.Value)
+	}
+	for k, v := range packet.Extra {
+		if s, ok := v.(string); ok {
+			packet.Extra[k] = t.scrub(s)
+		}
+	}
+	for _, iface := range packet.Interfaces {
+		switch i := iface.(type) {
+		case *raven.Http:
+			i.URL = t.scrub(i.URL)
+			i.Query = t.scrubQuery(i.Query)
+		case *raven.Exception:
+			i.Value = t.scrub(i.Value)
+		case *raven.Message:
+			i.Message = t.scrub(i.Message)
+		}
+	}
+	return t.next.Send(uri, authHeader, packet)
+}
+
+// scrubQuery scrubs URL encoded query string, filters are encoded there so
+// those need to be decoded first
+func (t *scrubbingTransport) scrubQuery(query string) string {
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		return t.scrub(query)
+	}
+	for k, vals := range values {
+		for i, v := range vals {
+			vals[i] = t.scrub(v)
+		}
+		values[k] = vals
+	}
+	return values.Encode()
+}
+
+// sentryRelease returns the release reported with Sentry events
+func sentryRelease() string {
+	if config.Config.SentryRelease != "" {
+		return config.Config.SentryRelease
+	}
+	return version
+}
+
+// setupSentry configures the Sentry client used to report Go exceptions
+func setupSentry(client *raven.Client) error {
+	if err := client.SetDSN(config.Config.SentryDSN); err != nil {
+		return err
+	}
+	client.SetRelease(sentryRelease())
+	client.SetEnvironment(config.Config.SentryEnvironment)
+	if err := client.SetSampleRate(float32(config.Config.SentrySampleRate)); err != nil {
+		return err
+	}
+	if re := sensitiveLabelsRegexp(config.Config.SentrySensitiveLabels); re != nil {
+		client.Transport = &scrubbingTransport{re: re, next: client.Transport}
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/cloudflare/unsee/internal/alertmanager"
+	"github.com/cloudflare/unsee/internal/config"
+	"github.com/cloudflare/unsee/internal/hooks"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// newHTTPServer returns the server used to serve all HTTP requests, HTTP/2 is
+// negotiated using ALPN when TLS is used, plain text connections can use
+// HTTP/2 with prior knowledge (h2c)
+func newHTTPServer(handler http.Handler) *http.Server {
+	server := &http.Server{
+		Addr:         fmt.Sprintf(":%d", config.Config.Port),
+		ReadTimeout:  config.Config.HttpReadTimeout,
+		WriteTimeout: config.Config.HttpWriteTimeout,
+		IdleTimeout:  config.Config.HttpIdleTimeout,
+	}
+	// networks are validated on startup
+	allowed, monitoring, _ := getAllowedNetworks()
+	handler = ipAllowlist(handler, allowed, monitoring)
+
+	h2 := &http2.Server{IdleTimeout: config.Config.HttpIdleTimeout}
+	if config.Config.TlsCert != "" {
+		server.Handler = handler
+		if err := http2.ConfigureServer(server, h2); err != nil {
+			log.Fatalf("Failed to configure HTTP/2: %s", err)
+		}
+	} else {
+		server.Handler = h2c.NewHandler(handler, h2)
+	}
+	return server
+}
+
+// listen will start serving HTTP requests using given server, it blocks until
+// the server is stopped, http.ErrServerClosed is not returned as an error,
+// if listener is nil the server will listen on its address
+func listen(server *http.Server, listener net.Listener) error {
+	if listener == nil {
+		var err error
+		if listener, err = net.Listen("tcp", server.Addr); err != nil {
+			return err
+		}
+	}
+
+	var err error
+	if config.Config.TlsCert != "" {
+		log.Infof("Listening for HTTPS requests on %s", listener.Addr())
+		err = server.ServeTLS(listener, config.Config.TlsCert, config.Config.TlsKey)
+	} else {
+		log.Infof("Listening for HTTP requests on %s", listener.Addr())
+		err = server.Serve(listener)
+	}
+	if err == http.ErrServerClosed {
+		return nil
+	}
+	return err
+}
+
+// waitForShutdown blocks until SIGTERM or SIGINT is received
+func waitForShutdown() {
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGTERM, syscall.SIGINT)
+	s := <-sig
+	signal.Stop(sig)
+	log.Infof("Received %s, shutting down", s)
+}
+
+// shutdown gracefully stops all servers, they will stop accepting new
+// connections, close all live update streams and wait for in-flight requests
+// to finish for up to SHUTDOWN_TIMEOUT, once that's done background pulls from
+// Alertmanager are stopped
+func shutdown(servers []*http.Server, stopGRPC func(context.Context)) error {
+	ctx, cancel := context.WithTimeout(context.Background(), config.Config.ShutdownTimeout)
+	defer cancel()
+
+	// live update connections would never finish on their own, websocket
+	// connections are hijacked so server.Shutdown() doesn't track them either
+	eventBroker.Close()
+
+	var err error
+	for _, server := range servers {
+		if serr := server.Shutdown(ctx); serr != nil && err == nil {
+			err = serr
+		}
+	}
+	if stopGRPC != nil {
+		stopGRPC(ctx)
+	}
+	stopPulling()
+	// let hook commands started by the last pull finish
+	hooks.Stop()
+	if config.Config.SnapshotPath != "" {
+		if serr := alertmanager.SaveSnapshot(config.Config.SnapshotPath); serr != nil {
+			log.Errorf("Failed to save snapshot to '%s': %s", config.Config.SnapshotPath, serr)
+		} else {
+			log.Infof("Saved snapshot to '%s'", config.Config.SnapshotPath)
+		}
+	}
+	if config.Config.HistoryPath != "" {
+		if herr := alertCounts.Save(config.Config.HistoryPath); herr != nil {
+			log.Errorf("Failed to save history to '%s': %s", config.Config.HistoryPath, herr)
+		} else {
+			log.Infof("Saved history to '%s'", config.Config.HistoryPath)
+		}
+	}
+	return err
+}
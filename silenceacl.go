@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cloudflare/unsee/internal/config"
+	"github.com/cloudflare/unsee/internal/models"
+	"github.com/cloudflare/unsee/internal/slices"
+)
+
+// silenceACLRule allows a single user or group to create silences as long as
+// they include all of the rule matchers, rules with the wildcard allow any
+// silence
+type silenceACLRule struct {
+	kind     string
+	name     string
+	any      bool
+	matchers map[string]string
+}
+
+// applies returns true if the rule is for given user or any of the groups
+func (r silenceACLRule) applies(user string, groups []string) bool {
+	switch r.kind {
+	case "user":
+		return user != "" && r.name == user
+	case "group":
+		return slices.StringInSlice(groups, r.name)
+	}
+	return false
+}
+
+// allows returns true if the silence only matches alerts the rule allows to
+// silence, which requires every rule matcher to be present in the silence as
+// a non-regex matcher with the same value
+func (r silenceACLRule) allows(silence models.Silence) bool {
+	if r.any {
+		return true
+	}
+	for name, value := range r.matchers {
+		found := false
+		for _, m := range silence.Matchers {
+			if m.Name == name && m.Value == value && !m.IsRegex {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// getSilenceACL parses silence ACL rules from the config, each rule uses
+// user:name:matchers or group:name:matchers format, where matchers is a comma
+// separated list of label=value pairs or * to allow any silence
+func getSilenceACL() ([]silenceACLRule, error) {
+	rules := []silenceACLRule{}
+	for _, s := range config.Config.SilenceACL {
+		if s == "" {
+			continue
+		}
+		z := strings.SplitN(s, ":", 3)
+		if len(z) != 3 || z[1] == "" || z[2] == "" {
+			return nil, fmt.Errorf("invalid silence ACL rule '%s', expected format 'user:name:matchers' or 'group:name:matchers'", s)
+		}
+		if z[0] != "user" && z[0] != "group" {
+			return nil, fmt.Errorf("invalid silence ACL rule '%s', '%s' is not user or group", s, z[0])
+		}
+		rule := silenceACLRule{kind: z[0], name: z[1], matchers: map[string]string{}}
+		if z[2] == "*" {
+			rule.any = true
+		} else {
+			for _, matcher := range strings.Split(z[2], ",") {
+				m := strings.SplitN(matcher, "=", 2)
+				if len(m) != 2 || m[0] == "" {
+					return nil, fmt.Errorf("invalid silence ACL rule '%s', matcher '%s' should use label=value format", s, matcher)
+				}
+				rule.matchers[m[0]] = m[1]
+			}
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// silenceAllowed returns true if given user or any of the groups is allowed
+// to create the silence, every silence is allowed if there are no rules
+func silenceAllowed(rules []silenceACLRule, user string, groups []string, silence models.Silence) bool {
+	if len(rules) == 0 {
+		return true
+	}
+	for _, rule := range rules {
+		if rule.applies(user, groups) && rule.allows(silence) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,310 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/cloudflare/unsee/internal/models"
+)
+
+// silenceClient talks to the silences API of a running unsee instance
+type silenceClient struct {
+	uri      string
+	apiKey   string
+	user     string
+	password string
+	timeout  time.Duration
+}
+
+// silenceMatcher is a single matcher of a silence created from the command
+// line
+type silenceMatcher struct {
+	Name    string `json:"name"`
+	Value   string `json:"value"`
+	IsRegex bool   `json:"isRegex"`
+}
+
+// matchers are passed as name=value or name=~regex
+var silenceMatcherRegexp = regexp.MustCompile(`^([a-zA-Z_][a-zA-Z0-9_]*)(=~|=)(.*)$`)
+
+func parseSilenceMatcher(s string) (silenceMatcher, error) {
+	m := silenceMatcherRegexp.FindStringSubmatch(s)
+	if m == nil {
+		return silenceMatcher{}, fmt.Errorf("invalid matcher '%s', expected name=value or name=~regex", s)
+	}
+	return silenceMatcher{Name: m[1], Value: m[3], IsRegex: m[2] == "=~"}, nil
+}
+
+func (sc *silenceClient) do(method, path string, query url.Values, body interface{}, target interface{}) error {
+	// unsee would reject it anyway, but with a less helpful error
+	if sc.apiKey != "" && sc.user == "" && method != http.MethodGet && method != http.MethodHead {
+		return fmt.Errorf("API keys only allow read-only access, pass -user and -password to %s %s", method, path)
+	}
+	uri := strings.TrimSuffix(sc.uri, "/") + path
+	if len(query) > 0 {
+		uri += "?" + query.Encode()
+	}
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, uri, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if sc.apiKey != "" {
+		req.Header.Set(apiKeyHeader, sc.apiKey)
+	}
+	if sc.user != "" {
+		req.SetBasicAuth(sc.user, sc.password)
+	}
+	client := &http.Client{Timeout: sc.timeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s %s returned status %d: %s", method, path, resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	if target != nil {
+		return json.Unmarshal(data, target)
+	}
+	return nil
+}
+
+func (sc *silenceClient) list(query url.Values) ([]models.ManagedSilence, error) {
+	silences := []models.ManagedSilence{}
+	err := sc.do(http.MethodGet, "/silences.json", query, nil, &silences)
+	return silences, err
+}
+
+// upstreams returns names of all Alertmanager upstreams configured in unsee
+func (sc *silenceClient) upstreams() ([]string, error) {
+	resp := struct {
+		Upstreams models.AlertmanagerAPISummary `json:"upstreams"`
+	}{}
+	// only upstreams are needed, so ask for as little alerts as possible
+	query := url.Values{"limit": []string{"1"}, "alertsPerGroup": []string{"1"}}
+	if err := sc.do(http.MethodGet, "/alerts.json", query, nil, &resp); err != nil {
+		return nil, err
+	}
+	names := []string{}
+	for _, upstream := range resp.Upstreams.Instances {
+		names = append(names, upstream.Name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// newSilenceFlags returns a flag set with flags shared by all silence commands
+func newSilenceFlags(name string) (*flag.FlagSet, *silenceClient) {
+	flags := flag.NewFlagSet("silence "+name, flag.ExitOnError)
+	sc := &silenceClient{}
+	defaultURI := os.Getenv("UNSEE_URI")
+	if defaultURI == "" {
+		defaultURI = "http://localhost:8080"
+	}
+	flags.StringVar(&sc.uri, "unsee.uri", defaultURI, "URI of the unsee instance, can also be set via UNSEE_URI environment variable")
+	flags.StringVar(&sc.apiKey, "api.key", os.Getenv("UNSEE_API_KEY"), "API key used for requests, can also be set via UNSEE_API_KEY environment variable")
+	flags.StringVar(&sc.user, "user", os.Getenv("UNSEE_USER"), "User name for basic authentication, required to modify silences if API keys are enabled, can also be set via UNSEE_USER environment variable")
+	flags.StringVar(&sc.password, "password", os.Getenv("UNSEE_PASSWORD"), "Password for basic authentication, can also be set via UNSEE_PASSWORD environment variable")
+	flags.DurationVar(&sc.timeout, "timeout", time.Minute, "Timeout for requests sent to unsee")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage of silence %s:\n", name)
+		flags.PrintDefaults()
+	}
+	return flags, sc
+}
+
+func silenceList(args []string, w io.Writer) error {
+	flags, sc := newSilenceFlags("list")
+	state := flags.String("state", "", "Only list silences in this state")
+	author := flags.String("author", "", "Only list silences created by this author")
+	comment := flags.String("comment", "", "Only list silences with comment containing this text")
+	flags.Parse(args)
+
+	query := url.Values{}
+	for key, val := range map[string]string{"state": *state, "author": *author, "comment": *comment} {
+		if val != "" {
+			query.Set(key, val)
+		}
+	}
+	silences, err := sc.list(query)
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tSTATE\tENDS AT\tCREATED BY\tALERTMANAGERS\tMATCHERS\tCOMMENT")
+	for _, silence := range silences {
+		matchers := []string{}
+		for _, m := range silence.Matchers {
+			op := "="
+			if m.IsRegex {
+				op = "=~"
+			}
+			matchers = append(matchers, m.Name+op+m.Value)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			silence.ID,
+			silence.State,
+			silence.EndsAt.Format(time.RFC3339),
+			silence.CreatedBy,
+			strings.Join(silence.Alertmanagers, ","),
+			strings.Join(matchers, " "),
+			silence.Comment,
+		)
+	}
+	return tw.Flush()
+}
+
+func silenceAdd(args []string, w io.Writer) error {
+	flags, sc := newSilenceFlags("add")
+	upstreams := flags.String("alertmanager", "", "Comma separated list of Alertmanager upstreams to create the silence on, all upstreams are used if not set")
+	duration := flags.Duration("duration", time.Hour, "How long the silence should last")
+	comment := flags.String("comment", "", "Silence comment, required")
+	author := flags.String("author", os.Getenv("USER"), "Silence author, unsee will use the authenticated user instead if it's known")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage of silence add: [flags] name=value name=~regex ...")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if *comment == "" {
+		return fmt.Errorf("-comment is required")
+	}
+	if flags.NArg() == 0 {
+		return fmt.Errorf("at least one matcher is required")
+	}
+	matchers := []silenceMatcher{}
+	for _, arg := range flags.Args() {
+		m, err := parseSilenceMatcher(arg)
+		if err != nil {
+			return err
+		}
+		matchers = append(matchers, m)
+	}
+
+	names := []string{}
+	if *upstreams != "" {
+		names = strings.Split(*upstreams, ",")
+	} else {
+		var err error
+		if names, err = sc.upstreams(); err != nil {
+			return err
+		}
+	}
+
+	now := time.Now()
+	body := map[string]interface{}{
+		"matchers":  matchers,
+		"startsAt":  now,
+		"endsAt":    now.Add(*duration),
+		"createdBy": *author,
+		"comment":   *comment,
+	}
+	failed := 0
+	for _, name := range names {
+		resp := struct {
+			Data struct {
+				SilenceID string `json:"silenceId"`
+			} `json:"data"`
+		}{}
+		if err := sc.do(http.MethodPost, "/silences/"+url.PathEscape(name), nil, body, &resp); err != nil {
+			fmt.Fprintf(w, "%s: failed to create silence: %s\n", name, err)
+			failed++
+			continue
+		}
+		fmt.Fprintf(w, "%s: %s\n", name, resp.Data.SilenceID)
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to create silence on %d Alertmanager upstream(s)", failed)
+	}
+	return nil
+}
+
+func silenceExpire(args []string, w io.Writer) error {
+	flags, sc := newSilenceFlags("expire")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage of silence expire: [flags] id ...")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() == 0 {
+		return fmt.Errorf("at least one silence ID is required")
+	}
+
+	silences, err := sc.list(nil)
+	if err != nil {
+		return err
+	}
+	byID := map[string]models.ManagedSilence{}
+	for _, silence := range silences {
+		byID[silence.ID] = silence
+	}
+
+	failed := 0
+	for _, id := range flags.Args() {
+		silence, found := byID[id]
+		if !found {
+			fmt.Fprintf(w, "%s: silence not found\n", id)
+			failed++
+			continue
+		}
+		// the same silence might be stored by multiple clustered upstreams
+		for _, name := range silence.Alertmanagers {
+			if err := sc.do(http.MethodDelete, "/silences/"+url.PathEscape(name)+"/"+url.PathEscape(id), nil, nil, nil); err != nil {
+				fmt.Fprintf(w, "%s: failed to expire silence on %s: %s\n", id, name, err)
+				failed++
+				continue
+			}
+			fmt.Fprintf(w, "%s: expired on %s\n", id, name)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to expire %d silence(s)", failed)
+	}
+	return nil
+}
+
+// runSilence runs silence subcommands, it returns the exit code
+func runSilence(args []string) int {
+	commands := map[string]func([]string, io.Writer) error{
+		"add":    silenceAdd,
+		"expire": silenceExpire,
+		"list":   silenceList,
+	}
+	if len(args) == 0 || commands[args[0]] == nil {
+		fmt.Fprintln(os.Stderr, "Usage: silence add|expire|list [flags], pass -h to any command to list its flags")
+		return 2
+	}
+	if err := commands[args[0]](args[1:], os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+	"time"
+
+	"github.com/cloudflare/unsee/internal/config"
+	"github.com/cloudflare/unsee/internal/models"
+)
+
+// supported SILENCE_AUTHOR_SOURCE values
+const (
+	// silenceAuthorUser attributes silences to the authenticated user, the
+	// author from the request or SILENCE_AUTHOR are used if it's not known
+	silenceAuthorUser = "user"
+	// silenceAuthorFixed always attributes silences to SILENCE_AUTHOR
+	silenceAuthorFixed = "fixed"
+)
+
+// silenceCommentData is passed to SILENCE_COMMENT_TEMPLATE
+type silenceCommentData struct {
+	// Comment is the comment sent by the client
+	Comment string
+	Author  string
+	// Labels holds values of all non-regex matchers
+	Labels map[string]string
+}
+
+// getSilenceCommentTemplate parses SILENCE_COMMENT_TEMPLATE, it returns nil if
+// it's not set
+func getSilenceCommentTemplate() (*template.Template, error) {
+	if config.Config.SilenceCommentTemplate == "" {
+		return nil, nil
+	}
+	tmpl, err := template.New("comment").Option("missingkey=zero").Parse(config.Config.SilenceCommentTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SILENCE_COMMENT_TEMPLATE: %s", err)
+	}
+	return tmpl, nil
+}
+
+// validateSilenceDefaults checks all options used to fill silences created
+// using unsee
+func validateSilenceDefaults() error {
+	switch config.Config.SilenceAuthorSource {
+	case silenceAuthorUser:
+	case silenceAuthorFixed:
+		if config.Config.SilenceAuthor == "" {
+			return fmt.Errorf("SILENCE_AUTHOR must be set if SILENCE_AUTHOR_SOURCE is '%s'", silenceAuthorFixed)
+		}
+	default:
+		return fmt.Errorf("invalid SILENCE_AUTHOR_SOURCE value '%s', supported values: %s, %s", config.Config.SilenceAuthorSource, silenceAuthorUser, silenceAuthorFixed)
+	}
+	if config.Config.SilenceDefaultDuration < 0 {
+		return fmt.Errorf("invalid SILENCE_DEFAULT_DURATION value '%s', it can't be negative", config.Config.SilenceDefaultDuration)
+	}
+	_, err := getSilenceCommentTemplate()
+	return err
+}
+
+// silenceAuthor returns the name silence should be attributed to
+func silenceAuthor(user string, silence models.Silence) string {
+	if config.Config.SilenceAuthorSource == silenceAuthorFixed {
+		return config.Config.SilenceAuthor
+	}
+	if user != "" {
+		return user
+	}
+	if silence.CreatedBy != "" {
+		return silence.CreatedBy
+	}
+	return config.Config.SilenceAuthor
+}
+
+// applySilenceDefaults fills the payload of a silence that will be sent to
+// Alertmanager with configured defaults, silence is the same payload decoded
+func applySilenceDefaults(payload map[string]interface{}, silence models.Silence, user string, now time.Time) error {
+	if author := silenceAuthor(user, silence); author != "" {
+		payload["createdBy"] = author
+	}
+
+	if silence.EndsAt.IsZero() && config.Config.SilenceDefaultDuration > 0 {
+		startsAt := silence.StartsAt
+		if startsAt.IsZero() {
+			startsAt = now
+			payload["startsAt"] = startsAt.UTC().Format(time.RFC3339)
+		}
+		payload["endsAt"] = startsAt.Add(config.Config.SilenceDefaultDuration).UTC().Format(time.RFC3339)
+	}
+
+	// comment template is validated on startup
+	tmpl, _ := getSilenceCommentTemplate()
+	if tmpl != nil {
+		data := silenceCommentData{
+			Comment: silence.Comment,
+			Labels:  map[string]string{},
+		}
+		data.Author, _ = payload["createdBy"].(string)
+		for _, m := range silence.Matchers {
+			if !m.IsRegex {
+				data.Labels[m.Name] = m.Value
+			}
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return fmt.Errorf("failed to render silence comment: %s", err)
+		}
+		payload["comment"] = buf.String()
+	}
+	return nil
+}
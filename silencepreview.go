@@ -0,0 +1,134 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/cloudflare/unsee/internal/alertmanager"
+	"github.com/cloudflare/unsee/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// compiledMatcher is a single silence matcher ready to be checked against
+// alert labels
+type compiledMatcher struct {
+	name  string
+	value string
+	re    *regexp.Regexp
+}
+
+// compileSilenceMatchers validates all matchers of a silence, regex matchers
+// are anchored the same way Alertmanager anchors them
+func compileSilenceMatchers(silence models.Silence) ([]compiledMatcher, error) {
+	matchers := []compiledMatcher{}
+	for _, m := range silence.Matchers {
+		if m.Name == "" {
+			return nil, fmt.Errorf("matcher label name can't be empty")
+		}
+		cm := compiledMatcher{name: m.Name, value: m.Value}
+		if m.IsRegex {
+			re, err := regexp.Compile("^(?:" + m.Value + ")$")
+			if err != nil {
+				return nil, fmt.Errorf("invalid regex matcher '%s=~%s': %s", m.Name, m.Value, err)
+			}
+			cm.re = re
+		}
+		matchers = append(matchers, cm)
+	}
+	return matchers, nil
+}
+
+// silenceMatchesLabels returns true if all matchers match given labels, labels
+// that aren't set are matched as empty strings
+func silenceMatchesLabels(matchers []compiledMatcher, labels map[string]string) bool {
+	for _, m := range matchers {
+		value := labels[m.name]
+		if m.re != nil {
+			if !m.re.MatchString(value) {
+				return false
+			}
+		} else if value != m.value {
+			return false
+		}
+	}
+	return true
+}
+
+// previewSilence returns alerts that a silence would suppress on given
+// Alertmanager upstream, json, the body uses the same format as requests
+// creating silences, nothing is sent to Alertmanager
+func previewSilence(c *gin.Context) {
+	noCache(c)
+	start := time.Now()
+	defer logView(c, start)
+
+	am := alertmanager.GetAlertmanagerByName(c.Param("alertmanager"))
+	if am == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("alertmanager '%s' not found", c.Param("alertmanager"))})
+		return
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(c.Request.Body, maxSilenceBodySize))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("failed to read request body: %s", err)})
+		return
+	}
+	silence := models.Silence{}
+	if err = json.Unmarshal(body, &silence); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid request body: %s", err)})
+		return
+	}
+	if len(silence.Matchers) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "silence must have at least one matcher"})
+		return
+	}
+	matchers, err := compileSilenceMatchers(silence)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rules, _ := getSilenceACL()
+	preview := models.SilencePreview{
+		Alertmanager: am.Name,
+		Allowed:      silenceAllowed(rules, getUserName(c), getUserGroups(c), silence),
+		AlertGroups:  []models.AlertGroup{},
+	}
+	for _, ag := range getTenant(c).alertGroups() {
+		agCopy := ag
+		agCopy.Alerts = models.AlertList{}
+		agCopy.StateCount = map[string]int{}
+		for _, state := range models.AlertStateList {
+			agCopy.StateCount[state] = 0
+		}
+		for _, alert := range ag.Alerts {
+			if !alertOnUpstream(alert, am.Name) || !silenceMatchesLabels(matchers, alert.Labels) {
+				continue
+			}
+			agCopy.Alerts = append(agCopy.Alerts, alert)
+			agCopy.StateCount[alert.State]++
+		}
+		if len(agCopy.Alerts) > 0 {
+			agCopy.Hash = agCopy.ContentFingerprint()
+			preview.AlertGroups = append(preview.AlertGroups, agCopy)
+			preview.Total += len(agCopy.Alerts)
+		}
+	}
+	c.JSON(http.StatusOK, preview)
+}
+
+// alertOnUpstream returns true if the alert was collected from given upstream
+func alertOnUpstream(alert models.Alert, name string) bool {
+	for _, am := range alert.Alertmanager {
+		if am.Name == name {
+			return true
+		}
+	}
+	return false
+}
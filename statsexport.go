@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cloudflare/unsee/internal/alertmanager"
+	"github.com/cloudflare/unsee/internal/config"
+	"github.com/cloudflare/unsee/internal/models"
+	"github.com/cloudflare/unsee/internal/statsexport"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// getStatsExporter returns the exporter pushing alert counts to StatsD or
+// Graphite, it's nil if STATS_EXPORT_ADDRESS isn't set
+func getStatsExporter() (*statsexport.Exporter, error) {
+	if config.Config.StatsExportAddress == "" {
+		return nil, nil
+	}
+	if config.Config.StatsExportInterval <= 0 {
+		return nil, fmt.Errorf("Invalid STATS_EXPORT_INTERVAL value '%v'", config.Config.StatsExportInterval)
+	}
+	e, err := statsexport.New(config.Config.StatsExportProtocol, config.Config.StatsExportAddress, config.Config.StatsExportPrefix, config.Config.AlertmanagerTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid STATS_EXPORT_* configuration: %s", err)
+	}
+	return e, nil
+}
+
+// summaryGauges returns the number of all alerts, counted by state, severity
+// and Alertmanager upstream, keyed by the metric name
+func summaryGauges(groups []models.AlertGroup, now time.Time) map[string]int {
+	gauges := map[string]int{}
+	for _, state := range models.AlertStateList {
+		gauges[statsexport.MetricName("alerts", "state", state)] = 0
+	}
+	for _, upstream := range alertmanager.GetAlertmanagers() {
+		gauges[statsexport.MetricName("alerts", "upstream", upstream.Name)] = 0
+	}
+
+	sample := countAlerts(groups, now)
+	gauges[statsexport.MetricName("alerts", "total")] = sample.Total
+	for state, count := range sample.States {
+		gauges[statsexport.MetricName("alerts", "state", state)] = count
+	}
+	for severity, count := range sample.Severities {
+		gauges[statsexport.MetricName("alerts", "severity", severity)] = count
+	}
+	for _, ag := range groups {
+		for _, alert := range ag.Alerts {
+			for _, am := range alert.Alertmanager {
+				gauges[statsexport.MetricName("alerts", "upstream", am.Name)]++
+			}
+		}
+	}
+	return gauges
+}
+
+// exportStats pushes alert counts every interval, it never returns
+func exportStats(e *statsexport.Exporter, interval time.Duration) {
+	// gauges keep their last value, so severities that are no longer used
+	// need to be pushed as 0
+	sent := map[string]bool{}
+	for range time.Tick(interval) {
+		now := time.Now()
+		gauges := summaryGauges(alertmanager.DedupAlerts(), now)
+		for name := range sent {
+			if _, found := gauges[name]; !found {
+				gauges[name] = 0
+			}
+		}
+		if err := e.Send(gauges, now); err != nil {
+			log.Errorf("Failed to push alert counts to %s: %s", config.Config.StatsExportAddress, err)
+			continue
+		}
+		for name := range gauges {
+			sent[name] = true
+		}
+	}
+}
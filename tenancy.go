@@ -0,0 +1,179 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/cloudflare/unsee/internal/alertmanager"
+	"github.com/cloudflare/unsee/internal/config"
+	"github.com/cloudflare/unsee/internal/filters"
+	"github.com/cloudflare/unsee/internal/models"
+	"github.com/cloudflare/unsee/internal/transform"
+
+	"github.com/gin-gonic/gin"
+)
+
+// tenant filter that gives access to all alerts
+const tenantFilterAll = "*"
+
+// getTenantFilters parses tenant filters from the config, each entry uses
+// group:filter format, returned map is keyed by the group name
+func getTenantFilters() (map[string]string, error) {
+	tenants := map[string]string{}
+	for _, s := range config.Config.TenantFilters {
+		if s == "" {
+			continue
+		}
+		z := strings.SplitN(s, ":", 2)
+		if len(z) != 2 || z[0] == "" || z[1] == "" {
+			return nil, fmt.Errorf("invalid tenant filter '%s', expected format 'group:filter'", s)
+		}
+		if _, found := tenants[z[0]]; found {
+			return nil, fmt.Errorf("duplicated tenant filter for group '%s'", z[0])
+		}
+		if z[1] != tenantFilterAll {
+			if err := validateFilterQuery(z[1]); err != nil {
+				return nil, fmt.Errorf("invalid tenant filter for group '%s': %s", z[0], err)
+			}
+		}
+		tenants[z[0]] = z[1]
+	}
+	return tenants, nil
+}
+
+// tenantFilter is a single tenant filter parsed once per request
+type tenantFilter struct {
+	matchFilters []filters.FilterT
+	validFilters bool
+}
+
+// tenant describes which alerts the user making the request can access, if
+// access is restricted only alerts matching any of the tenant filters can be
+// seen, a restricted tenant without any filters won't see any alert
+type tenant struct {
+	restricted bool
+	filters    []string
+	parsed     []tenantFilter
+}
+
+// getTenant returns the tenant for the user making the request, based on
+// groups the user belongs to, access is never restricted if there are no
+// tenant filters configured
+func getTenant(c *gin.Context) tenant {
+	tenants, _ := getTenantFilters()
+	if len(tenants) == 0 {
+		return tenant{}
+	}
+
+	t := tenant{restricted: true, filters: []string{}}
+	for _, group := range getUserGroups(c) {
+		filter, found := tenants[group]
+		if !found {
+			continue
+		}
+		if filter == tenantFilterAll {
+			return tenant{}
+		}
+		t.filters = append(t.filters, filter)
+	}
+	sort.Strings(t.filters)
+	for _, filter := range t.filters {
+		matchFilters, validFilters := getFiltersFromQuery(filter)
+		t.parsed = append(t.parsed, tenantFilter{matchFilters: matchFilters, validFilters: validFilters})
+	}
+	return t
+}
+
+// scope returns a string identifying alerts the tenant can see, it's used as
+// a cache key prefix, so tenants never get responses generated for others
+func (t tenant) scope() string {
+	if !t.restricted {
+		return ""
+	}
+	return fmt.Sprintf("tenant:%q:", t.filters)
+}
+
+// alertMatches returns true if the tenant can see the alert, ag is the group
+// the alert belongs to
+func (t tenant) alertMatches(ag *models.AlertGroup, alert *models.Alert) bool {
+	if !t.restricted {
+		return true
+	}
+	for _, tf := range t.parsed {
+		for _, filter := range tf.matchFilters {
+			if gf, ok := filter.(filters.GroupFilterT); ok {
+				gf.SetGroup(ag)
+			}
+		}
+		if alertMatchesFilters(alert, tf.matchFilters, tf.validFilters, 0) {
+			return true
+		}
+	}
+	return false
+}
+
+// alertGroups returns deduplicated alert groups with only alerts the tenant
+// can see, groups without any visible alert are removed
+func (t tenant) alertGroups() []models.AlertGroup {
+	groups := alertmanager.DedupAlerts()
+	if !t.restricted {
+		return groups
+	}
+
+	visible := []models.AlertGroup{}
+	for _, ag := range groups {
+		agCopy := ag
+		agCopy.Alerts = models.AlertList{}
+		agCopy.StateCount = map[string]int{}
+		for _, state := range models.AlertStateList {
+			agCopy.StateCount[state] = 0
+		}
+		for _, alert := range ag.Alerts {
+			if t.alertMatches(&ag, &alert) {
+				agCopy.Alerts = append(agCopy.Alerts, alert)
+				agCopy.StateCount[alert.State]++
+			}
+		}
+		if len(agCopy.Alerts) > 0 {
+			if len(agCopy.Alerts) != len(ag.Alerts) {
+				agCopy.Hash = agCopy.ContentFingerprint()
+			}
+			visible = append(visible, agCopy)
+		}
+	}
+	return visible
+}
+
+// autocomplete returns autocomplete hints generated only from alerts the
+// tenant can see
+func (t tenant) autocomplete() []models.Autocomplete {
+	if !t.restricted {
+		return alertmanager.DedupAutocomplete()
+	}
+	alerts := []models.Alert{}
+	for _, ag := range t.alertGroups() {
+		alerts = append(alerts, ag.Alerts...)
+	}
+	return transform.BuildAutocomplete(alerts)
+}
+
+// filterEvents returns only events for alerts the tenant can see
+func (t tenant) filterEvents(changes []models.AlertEvent) []models.AlertEvent {
+	if !t.restricted {
+		return changes
+	}
+	visible := []models.AlertEvent{}
+	for _, change := range changes {
+		ag := models.AlertGroup{
+			ID:       change.GroupID,
+			Receiver: change.Receiver,
+			Labels:   change.Labels,
+			Alerts:   models.AlertList{change.Alert},
+		}
+		if t.alertMatches(&ag, &change.Alert) {
+			visible = append(visible, change)
+		}
+	}
+	return visible
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// requestTimeout returns a middleware that will cancel the request context
+// once given duration passes, handlers doing expensive filtering should check
+// it using requestDone() and stop processing, it doesn't do anything if
+// timeout isn't set
+func requestTimeout(timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if timeout <= 0 {
+			c.Next()
+			return
+		}
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// requestDone returns true if the request context was cancelled, either
+// because it timed out or because the client went away, an error response is
+// written in that case and the handler should return without doing any more
+// work
+func requestDone(c *gin.Context) bool {
+	err := c.Request.Context().Err()
+	if err == nil {
+		return false
+	}
+	if err == context.DeadlineExceeded {
+		log.Warningf("[%s] %s %s timed out", c.ClientIP(), c.Request.Method, c.Request.RequestURI)
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "request timed out, try using more specific filters"})
+	} else {
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": fmt.Sprintf("request cancelled: %s", err)})
+	}
+	return true
+}
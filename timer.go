@@ -1,30 +1,96 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"runtime"
 	"sync"
+	"time"
 
 	"github.com/cloudflare/unsee/internal/alertmanager"
+	"github.com/cloudflare/unsee/internal/config"
+	"github.com/cloudflare/unsee/internal/events"
+	"github.com/cloudflare/unsee/internal/flapping"
+	"github.com/cloudflare/unsee/internal/hooks"
+	"github.com/cloudflare/unsee/internal/incidents"
+	"github.com/cloudflare/unsee/internal/models"
+	"github.com/cloudflare/unsee/internal/tracing"
+	"github.com/cloudflare/unsee/internal/transform"
+	"github.com/cloudflare/unsee/internal/transport"
 
 	log "github.com/sirupsen/logrus"
 )
 
+var (
+	// alert groups from the previous pull, used to detect changes
+	lastAlertGroups []models.AlertGroup
+
+	// when host names of upstreams were last re-resolved
+	lastDNSRefresh time.Time
+
+	// returned for paused upstreams, data from their last pull is kept
+	errCollectionPaused = errors.New("collection is paused")
+
+	// held while pulling, so pulls requested using the refresh endpoint never
+	// run together with the background timer
+	pullLock sync.Mutex
+
+	// closed to stop the background timer
+	tickerStop = make(chan bool)
+	// closed once the background timer is stopped
+	tickerDone = make(chan bool)
+)
+
 func pullFromAlertmanager() {
+	pullUpstreams(alertmanager.GetAlertmanagers())
+}
+
+// pullUpstreams collects alerts and silences from given upstreams, data of
+// all other upstreams is kept as it is, it returns errors of upstreams that
+// couldn't be collected keyed by the upstream name
+// Only one pull can run at a time, so callers will wait for any pull that is
+// already in progress
+func pullUpstreams(upstreams []*alertmanager.Alertmanager) map[string]error {
+	pullLock.Lock()
+	defer pullLock.Unlock()
+
 	// always flush cache once we're done
 	defer apiCache.Flush()
 
 	log.Info("Pulling latest alerts and silences from Alertmanager")
+	ctx, span := tracing.Start(context.Background(), "collect")
+	defer span.End()
+
+	// incidents are attached to alerts while they are processed
+	_, incidentsSpan := tracing.Start(ctx, "refresh incidents")
+	incidents.Refresh(ctx)
+	incidentsSpan.End()
 
-	upstreams := alertmanager.GetAlertmanagers()
+	refreshDNS(ctx, time.Now())
+	refreshCredentialsFiles()
+
+	errs := map[string]error{}
+	errsLock := sync.Mutex{}
 	wg := sync.WaitGroup{}
 	wg.Add(len(upstreams))
 
 	for _, upstream := range upstreams {
 		go func(am *alertmanager.Alertmanager) {
+			if am.IsPaused() {
+				log.Infof("[%s] Collection is paused, skipping", am.Name)
+				errsLock.Lock()
+				errs[am.Name] = errCollectionPaused
+				errsLock.Unlock()
+				wg.Done()
+				return
+			}
 			log.Infof("[%s] Collecting alerts and silences", am.Name)
-			err := am.Pull()
+			err := am.Pull(ctx)
 			if err != nil {
 				log.Errorf("[%s] %s", am.Name, err)
+				errsLock.Lock()
+				errs[am.Name] = err
+				errsLock.Unlock()
 			}
 			wg.Done()
 		}(upstream)
@@ -32,16 +98,66 @@ func pullFromAlertmanager() {
 
 	wg.Wait()
 
+	_, dedupSpan := tracing.Start(ctx, "deduplicate upstreams")
+	alertGroups := alertmanager.DedupAlerts()
+	dedupSpan.End()
+	if lastAlertGroups != nil {
+		changes := events.Diff(lastAlertGroups, alertGroups)
+		// the list of flapping alerts is checksummed into the dedup cache key,
+		// so alerts are marked as flapping by the next deduplication
+		flapping.Update(changes, time.Now())
+		log.Infof("Detected %d alert change(s), sending to %d subscriber(s)", len(changes), eventBroker.Subscribers())
+		eventBroker.Publish(changes)
+		hooks.Run(changes)
+	}
+	lastAlertGroups = alertGroups
+	alertHistory.Add(alertGroups)
+	now := time.Now()
+	alertCounts.Add(countAlerts(alertGroups, now))
+	alertCounts.Prune(now.Add(-config.Config.HistoryRetention))
+	alertTimeline.Record(alertGroups, now)
+	alertTimeline.Prune(now.Add(-config.Config.HistoryRetention))
+	// strings and colors only used by alerts from older pulls can be released
+	// now
+	models.RotateInterned()
+	transform.RotateColorCache()
+
 	log.Info("Pull completed")
 	runtime.GC()
+	return errs
+}
+
+// refreshDNS re-resolves host names of upstreams if ALERTMANAGER_DNS_REFRESH
+// passed since the last refresh, so connections to stale addresses are
+// replaced before collecting
+func refreshDNS(ctx context.Context, now time.Time) {
+	if config.Config.AlertmanagerDnsRefresh == 0 || now.Sub(lastDNSRefresh) < config.Config.AlertmanagerDnsRefresh {
+		return
+	}
+	lastDNSRefresh = now
+	for _, host := range transport.RefreshDNS(ctx) {
+		log.Infof("Addresses of '%s' changed, reconnecting", host)
+	}
 }
 
 // Tick is the background timer used to call PullFromAlertmanager
 func Tick() {
+	defer close(tickerDone)
 	for {
 		select {
 		case <-ticker.C:
 			pullFromAlertmanager()
+		case <-tickerStop:
+			return
 		}
 	}
 }
+
+// stopPulling stops the background timer, if there's a pull in progress it
+// will wait for it to finish
+func stopPulling() {
+	ticker.Stop()
+	close(tickerStop)
+	<-tickerDone
+	log.Info("Stopped pulling from Alertmanager")
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/cloudflare/unsee/internal/alertmanager"
+	"github.com/cloudflare/unsee/internal/config"
+	"github.com/cloudflare/unsee/internal/transport"
+	"github.com/cloudflare/unsee/internal/vault"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// vaultRetryInterval is how long to wait before trying again after credentials
+// couldn't be renewed or read from Vault
+const vaultRetryInterval = 30 * time.Second
+
+// getVaultSecrets returns Vault secret paths with credentials of Alertmanager
+// upstreams keyed by the upstream name
+func getVaultSecrets() (map[string]string, error) {
+	uris := map[string]string{}
+	for _, s := range config.Config.AlertmanagerURIs {
+		z := strings.SplitN(s, ":", 2)
+		if len(z) == 2 {
+			uris[z[0]] = z[1]
+		}
+	}
+	secrets := map[string]string{}
+	for _, s := range config.Config.VaultSecrets {
+		if s == "" {
+			continue
+		}
+		z := strings.SplitN(s, ":", 2)
+		if len(z) != 2 || z[0] == "" || z[1] == "" {
+			return nil, fmt.Errorf("Invalid Vault secret '%s', expected format 'name:path'", s)
+		}
+		uri, found := uris[z[0]]
+		if !found {
+			return nil, fmt.Errorf("Invalid Vault secret '%s', there's no Alertmanager upstream named '%s'", s, z[0])
+		}
+		if _, found := secrets[z[0]]; found {
+			return nil, fmt.Errorf("Duplicated Vault secret for '%s'", z[0])
+		}
+		if u, err := url.Parse(uri); err == nil && u.User != nil {
+			return nil, fmt.Errorf("Alertmanager upstream '%s' URI already includes credentials, those can't be used together with a Vault secret", z[0])
+		}
+		secrets[z[0]] = z[1]
+	}
+	if len(secrets) > 0 {
+		if config.Config.VaultAddr == "" {
+			return nil, fmt.Errorf("VAULT_ADDR must be set to use VAULT_SECRETS")
+		}
+		if config.Config.VaultToken == "" {
+			return nil, fmt.Errorf("VAULT_TOKEN must be set to use VAULT_SECRETS")
+		}
+	}
+	return secrets, nil
+}
+
+// credentialsFromSecret returns upstream credentials stored in a Vault secret,
+// it must have either username and password or token keys
+func credentialsFromSecret(secret *vault.Secret) (transport.Credentials, error) {
+	c := transport.Credentials{
+		Username: secret.Data["username"],
+		Password: secret.Data["password"],
+		Token:    secret.Data["token"],
+	}
+	if c.Token == "" && (c.Username == "" || c.Password == "") {
+		return c, fmt.Errorf("secret must have username and password or token keys")
+	}
+	return c, nil
+}
+
+// readVaultCredentials reads the secret from Vault and uses it for all
+// requests sent to the upstream
+func readVaultCredentials(client *vault.Client, am *alertmanager.Alertmanager, path string) (*vault.Secret, error) {
+	secret, err := client.Read(path)
+	if err != nil {
+		return nil, err
+	}
+	c, err := credentialsFromSecret(secret)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Vault secret '%s': %s", path, err)
+	}
+	transport.SetCredentials(am.URI, c)
+	return secret, nil
+}
+
+// renewVaultCredentials renews the lease of the secret, or reads it again if
+// it can't be renewed, it returns the secret with the current lease
+func renewVaultCredentials(client *vault.Client, am *alertmanager.Alertmanager, path string, secret *vault.Secret) (*vault.Secret, error) {
+	if secret.Renewable && secret.LeaseID != "" {
+		renewed, err := client.Renew(secret.LeaseID)
+		if err == nil {
+			renewed.Data = secret.Data
+			return renewed, nil
+		}
+		log.Warningf("[%s] Failed to renew the lease of Vault secret '%s', reading it again: %s", am.Name, path, err)
+	}
+	return readVaultCredentials(client, am, path)
+}
+
+// vaultRefreshInterval returns how long the secret can be used for before
+// it's renewed or read again, leases are renewed before they expire
+func vaultRefreshInterval(secret *vault.Secret) time.Duration {
+	if secret.LeaseDuration > 0 {
+		return secret.LeaseDuration * 2 / 3
+	}
+	return config.Config.VaultRefresh
+}
+
+// setupVaultCredentials reads credentials of Alertmanager upstreams from
+// Vault, upstreams can't be collected without those, so all secrets must be
+// read on startup, they're kept up to date in the background after that
+func setupVaultCredentials() error {
+	secrets, err := getVaultSecrets()
+	if err != nil || len(secrets) == 0 {
+		return err
+	}
+	client := vault.NewClient(config.Config.VaultAddr, config.Config.VaultToken, config.Config.AlertmanagerTimeout)
+	for name, path := range secrets {
+		am := alertmanager.GetAlertmanagerByName(name)
+		secret, err := readVaultCredentials(client, am, path)
+		if err != nil {
+			return fmt.Errorf("Failed to read credentials of Alertmanager '%s' from Vault: %s", name, err)
+		}
+		log.Infof("[%s] Using credentials from Vault secret '%s'", name, path)
+		go watchVaultCredentials(client, am, path, secret)
+	}
+	return nil
+}
+
+// watchVaultCredentials renews or reads the secret again every time it's
+// about to expire, last credentials are used until that succeeds
+func watchVaultCredentials(client *vault.Client, am *alertmanager.Alertmanager, path string, secret *vault.Secret) {
+	delay := vaultRefreshInterval(secret)
+	for {
+		time.Sleep(delay)
+		updated, err := renewVaultCredentials(client, am, path, secret)
+		if err != nil {
+			log.Errorf("[%s] Failed to refresh credentials from Vault secret '%s': %s", am.Name, path, err)
+			delay = vaultRetryInterval
+			continue
+		}
+		secret = updated
+		delay = vaultRefreshInterval(secret)
+	}
+}
@@ -1,25 +1,70 @@
 package main
 
 import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
 	"net/http"
+	"net/url"
+	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/cloudflare/unsee/internal/alertmanager"
 	"github.com/cloudflare/unsee/internal/config"
+	"github.com/cloudflare/unsee/internal/filters"
 	"github.com/cloudflare/unsee/internal/models"
 	"github.com/cloudflare/unsee/internal/slices"
+	"github.com/cloudflare/unsee/internal/store"
+	"github.com/cloudflare/unsee/internal/transform"
+	"github.com/cloudflare/unsee/internal/transport"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
 
 	log "github.com/sirupsen/logrus"
 )
 
+const (
+	// how often to send keepalive comments to event stream clients, so that
+	// proxies don't close idle connections
+	eventStreamKeepalive = time.Second * 30
+
+	savedFiltersBucket = "filters"
+	shortURLsBucket    = "shorturls"
+	userSettingsBucket = "settings"
+
+	// maximum size of the silence request body that will be forwarded to
+	// Alertmanager
+	maxSilenceBodySize = 1024 * 1024
+
+	// shortest token length used for short filter URLs, tokens are extended
+	// on hash collisions
+	shortURLTokenLength = 6
+
+	// maximum number of buckets returned by the history endpoint
+	maxHistoryBuckets = 1440
+)
+
 var (
 	// needed for serving favicon from binary assets
 	faviconFileServer = http.FileServer(newBinaryFileSystem("static/dist"))
+
+	// used to upgrade HTTP connections for clients subscribing to live updates
+	wsUpgrader = websocket.Upgrader{
+		ReadBufferSize:  1024,
+		WriteBufferSize: 1024,
+	}
+
+	// names of saved filters are used in URLs, so only allow safe characters
+	savedFilterNameRegex = regexp.MustCompile("^[a-zA-Z0-9_.-]+$")
 )
 
 func noCache(c *gin.Context) {
@@ -32,20 +77,32 @@ func index(c *gin.Context) {
 
 	noCache(c)
 
+	apiKeys, _ := getAPIKeys()
+	setDashboardCookie(c, apiKeys)
+
 	q, qPresent := c.GetQuery("q")
 	defaultUsed := true
 	if qPresent {
 		defaultUsed = false
 	}
 
+	uiConfig := getUIConfig()
+	labelNames, _ := json.Marshal(uiConfig.LabelNames)
 	c.HTML(http.StatusOK, "templates/index.html", gin.H{
 		"Version":           version,
 		"SentryDSN":         config.Config.SentryPublicDSN,
+		"SentryRelease":     sentryRelease(),
+		"SentryLabels":      strings.Join(config.Config.SentrySensitiveLabels, " "),
 		"Config":            config.Config,
 		"QFilter":           q,
 		"DefaultUsed":       defaultUsed,
 		"StaticColorLabels": strings.Join(config.Config.ColorLabelsStatic, " "),
-		"WebPrefix":         config.Config.WebPrefix,
+		"WebPrefix":         getPublicPrefix(c),
+		"UIConfig":          uiConfig,
+		"UIDefaults":        getUIDefaults(),
+		"RefreshIntervals":  getRefreshIntervals(int(config.Config.UiRefreshInterval / time.Second)),
+		"LabelNames":        string(labelNames),
+		"ClientTemplates":   clientTemplates,
 	})
 
 	log.Infof("[%s] %s %s took %s", c.ClientIP(), c.Request.Method, c.Request.RequestURI, time.Since(start))
@@ -57,13 +114,13 @@ func help(c *gin.Context) {
 	noCache(c)
 	c.HTML(http.StatusOK, "templates/help.html", gin.H{
 		"SentryDSN": config.Config.SentryPublicDSN,
-		"WebPrefix": config.Config.WebPrefix,
+		"WebPrefix": getPublicPrefix(c),
 	})
 	log.Infof("[%s] <%d> %s %s took %s", c.ClientIP(), http.StatusOK, c.Request.Method, c.Request.RequestURI, time.Since(start))
 }
 
 func logAlertsView(c *gin.Context, cacheStatus string, duration time.Duration) {
-	log.Infof("[%s %s] <%d> %s %s took %s", c.ClientIP(), cacheStatus, http.StatusOK, c.Request.Method, c.Request.RequestURI, duration)
+	log.Infof("[%s %s] <%d> %s %s took %s", c.ClientIP(), cacheStatus, c.Writer.Status(), c.Request.Method, c.Request.RequestURI, duration)
 }
 
 // alerts endpoint, json, JS will query this via AJAX call
@@ -79,27 +136,85 @@ func alerts(c *gin.Context) {
 	resp.Version = version
 	resp.Upstreams = getUpstreams()
 
-	// use full URI (including query args) as cache key
-	cacheKey := c.Request.RequestURI
+	offset, err := parsePaginationArg(c.Query("offset"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid offset: %s", err)})
+		return
+	}
+	limit, err := parsePaginationArg(c.Query("limit"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid limit: %s", err)})
+		return
+	}
+	alertsPerGroup, err := parsePaginationArg(c.Query("alertsPerGroup"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid alertsPerGroup: %s", err)})
+		return
+	}
+	grouping := c.Query("grouping")
+	if grouping != "" && grouping != models.GroupingReceiver {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid grouping '%s', only '%s' is supported", grouping, models.GroupingReceiver)})
+		return
+	}
+	annotations := c.Query("annotations")
+	if annotations != "" && annotations != models.AnnotationsShared {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid annotations '%s', only '%s' is supported", annotations, models.AnnotationsShared)})
+		return
+	}
+	countFilterTerms(c.Query("q"))
+
+	// alerts only change after each collection, so let clients revalidate
+	// responses using ETag instead of fetching the full body every time
+	c.Header("Cache-Control", "no-cache")
+	c.Writer.Header().Add("Vary", "Accept")
+	format, err := responseFormat(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	t := getTenant(c)
+	etag := alertsETag(format, t.scope()+c.Request.URL.RawQuery, resp.Upstreams)
+	c.Header("ETag", etag)
+	if c.Request.Header.Get("If-None-Match") == etag {
+		c.Status(http.StatusNotModified)
+		logAlertsView(c, "NOT MODIFIED", time.Since(start))
+		return
+	}
+
+	// use full URI (including query args) as cache key, responses in other
+	// formats are cached separately
+	cacheKey := t.scope() + c.Request.RequestURI
+	if format != gin.MIMEJSON {
+		cacheKey = format + ":" + cacheKey
+	}
+	// the cache is only flushed after collections, so if those stall cached
+	// responses would keep reporting upstreams as fresh
+	for _, upstream := range resp.Upstreams.Instances {
+		if upstream.Stale {
+			cacheKey += ":stale:" + upstream.Name
+		}
+	}
 
 	data, found := apiCache.Get(cacheKey)
 	if found {
-		c.Data(http.StatusOK, gin.MIMEJSON, data.([]byte))
+		c.Data(http.StatusOK, format, data.([]byte))
 		logAlertsView(c, "HIT", time.Since(start))
 		return
 	}
 
 	// get filters
 	apiFilters := []models.Filter{}
-	matchFilters, validFilters := getFiltersFromQuery(c.Query("q"))
+	q, macros := expandFilterMacros(c.Query("q"))
+	resp.Macros = macros
+	matchFilters, validFilters := getFiltersFromQuery(q)
 
 	// set pointers for data store objects, need a lock until end of view is reached
-	alerts := []models.AlertGroup{}
 	colors := models.LabelsColorMap{}
 	counters := models.LabelsCountMap{}
 
-	dedupedAlerts := alertmanager.DedupAlerts()
+	dedupedAlerts := t.alertGroups()
 	dedupedColors := alertmanager.DedupColors()
+	alerts := make([]models.AlertGroup, 0, len(dedupedAlerts))
 
 	var matches int
 	for _, ag := range dedupedAlerts {
@@ -107,103 +222,323 @@ func alerts(c *gin.Context) {
 			ID:         ag.ID,
 			Receiver:   ag.Receiver,
 			Labels:     ag.Labels,
-			Alerts:     []models.Alert{},
-			StateCount: map[string]int{},
+			StateCount: make(map[string]int, len(models.AlertStateList)),
 		}
 		for _, s := range models.AlertStateList {
 			agCopy.StateCount[s] = 0
 		}
 
-		for _, alert := range ag.Alerts {
-			results := []bool{}
-			if validFilters {
-				for _, filter := range matchFilters {
-					if filter.GetIsValid() {
-						match := filter.Match(&alert, matches)
-						results = append(results, match)
-					}
+		for _, filter := range matchFilters {
+			if gf, ok := filter.(filters.GroupFilterT); ok {
+				gf.SetGroup(&ag)
+			}
+		}
+
+		// alerts are never modified here, so if every alert in the group
+		// matches then the deduplicated alert list and hash can be reused,
+		// a new list is only allocated once the first alert doesn't match
+		var matched models.AlertList
+		allMatched := true
+		for i := range ag.Alerts {
+			alert := &ag.Alerts[i]
+			// stop filtering if the request timed out or the client went away
+			if requestDone(c) {
+				logAlertsView(c, "CANCELLED", time.Since(start))
+				return
+			}
+			if !alertMatchesFilters(alert, matchFilters, validFilters, matches) {
+				if allMatched {
+					allMatched = false
+					matched = make(models.AlertList, i, len(ag.Alerts))
+					copy(matched, ag.Alerts[:i])
 				}
+				continue
 			}
-			if !validFilters || (slices.BoolInSlice(results, true) && !slices.BoolInSlice(results, false)) {
-				matches++
-				// we need to update fingerprints since we've modified some fields in dedup
-				// and agCopy.ContentFingerprint() depends on per alert fingerprint
-				// we update it here rather than in dedup since here we can apply it
-				// only for alerts left after filtering
-				alert.UpdateFingerprints()
-				agCopy.Alerts = append(agCopy.Alerts, alert)
-
-				countLabel(counters, "@state", alert.State)
-
-				countLabel(counters, "@receiver", alert.Receiver)
-				if ck, foundKey := dedupedColors["@receiver"]; foundKey {
-					if cv, foundVal := ck[alert.Receiver]; foundVal {
-						if _, found := colors["@receiver"]; !found {
-							colors["@receiver"] = map[string]models.LabelColors{}
-						}
-						colors["@receiver"][alert.Receiver] = cv
+			matches++
+			if !allMatched {
+				matched = append(matched, *alert)
+			}
+
+			countLabel(counters, "@state", alert.State)
+
+			countLabel(counters, "@receiver", alert.Receiver)
+			if ck, foundKey := dedupedColors["@receiver"]; foundKey {
+				if cv, foundVal := ck[alert.Receiver]; foundVal {
+					if _, found := colors["@receiver"]; !found {
+						colors["@receiver"] = map[string]models.LabelColors{}
 					}
+					colors["@receiver"][alert.Receiver] = cv
 				}
+			}
 
-				agCopy.StateCount[alert.State]++
+			agCopy.StateCount[alert.State]++
 
-				for key, value := range alert.Labels {
-					if keyMap, foundKey := dedupedColors[key]; foundKey {
-						if color, foundColor := keyMap[value]; foundColor {
-							if _, found := colors[key]; !found {
-								colors[key] = map[string]models.LabelColors{}
-							}
-							colors[key][value] = color
+			for key, value := range alert.Labels {
+				if keyMap, foundKey := dedupedColors[key]; foundKey {
+					if color, foundColor := keyMap[value]; foundColor {
+						if _, found := colors[key]; !found {
+							colors[key] = map[string]models.LabelColors{}
 						}
+						colors[key][value] = color
 					}
-					countLabel(counters, key, value)
 				}
+				countLabel(counters, key, value)
 			}
 		}
 
-		if len(agCopy.Alerts) > 0 {
+		if allMatched {
+			agCopy.Alerts = ag.Alerts
+			agCopy.Hash = ag.Hash
+		} else {
+			agCopy.Alerts = matched
 			agCopy.Hash = agCopy.ContentFingerprint()
+		}
+		agCopy.TotalAlerts = len(agCopy.Alerts)
+		if len(agCopy.Alerts) > 0 {
+			agCopy.Collapse = groupCollapseHint(&agCopy)
 			alerts = append(alerts, agCopy)
 		}
 
 	}
 
-	resp.AlertGroups = alerts
+	totalGroups := len(alerts)
+	for _, filter := range matchFilters {
+		if lf, ok := filter.(filters.GroupLimitFilterT); ok && filter.GetIsValid() {
+			alerts = lf.LimitGroups(alerts)
+		}
+	}
+	resp.OmittedGroups = totalGroups - len(alerts)
+
+	resp.Grouping = grouping
+	resp.Receivers = []models.ReceiverGroups{}
+	if grouping == models.GroupingReceiver {
+		alerts, resp.Receivers = groupByReceiver(alerts)
+	}
+
+	resp.CollectionVersion = alertHistory.Version()
+	resp.RemovedGroups = []string{}
+	if since, err := strconv.ParseInt(c.Query("since"), 10, 64); err == nil {
+		alerts, resp.RemovedGroups, resp.Delta = deltaAlertGroups(alerts, since)
+	}
+
+	resp.TotalGroups = len(alerts)
+	resp.Offset = offset
+	resp.Limit = limit
+	resp.AlertGroups = limitGroupAlerts(collapseGroupAlerts(paginateAlertGroups(alerts, offset, limit), config.Config.AlertsCollapseLabels), groupAlertsLimit(alertsPerGroup))
+	if annotations == models.AnnotationsShared {
+		// groups are copies, but alerts can still be shared with the store,
+		// SplitAnnotations will return new alerts without modifying those
+		for i := range resp.AlertGroups {
+			resp.AlertGroups[i].SharedAnnotations, resp.AlertGroups[i].Alerts = resp.AlertGroups[i].SplitAnnotations()
+		}
+	}
 	resp.Colors = colors
 	resp.Counters = counters
 
 	for _, filter := range matchFilters {
-		af := models.Filter{
-			Text:    filter.GetRawText(),
-			Hits:    filter.GetHits(),
-			IsValid: filter.GetIsValid(),
-		}
-		apiFilters = append(apiFilters, af)
+		apiFilters = append(apiFilters, apiFilter(filter))
 	}
 	resp.Filters = apiFilters
 
-	data, err := json.Marshal(resp)
+	if requestDone(c) {
+		logAlertsView(c, "CANCELLED", time.Since(start))
+		return
+	}
+
+	if format == gin.MIMEJSON {
+		data, err = encodeAlertsResponse(resp, annotations)
+	} else {
+		data, err = encodeResponse(format, resp)
+	}
 	if err != nil {
 		log.Error(err.Error())
 		panic(err)
 	}
 	apiCache.Set(cacheKey, data, -1)
 
-	c.Data(http.StatusOK, gin.MIMEJSON, data.([]byte))
+	c.Data(http.StatusOK, format, data.([]byte))
 	logAlertsView(c, "MIS", time.Since(start))
 }
 
+// weightedHints is used to sort autocomplete hints, hints with the highest
+// weight come first, so values present on most alerts are suggested first,
+// hints with equal weight are sorted in reverse alphabetical order
+type weightedHints []models.Autocomplete
+
+func (wh weightedHints) Len() int {
+	return len(wh)
+}
+
+func (wh weightedHints) Swap(i, j int) {
+	wh[i], wh[j] = wh[j], wh[i]
+}
+
+func (wh weightedHints) Less(i, j int) bool {
+	if wh[i].Weight != wh[j].Weight {
+		return wh[i].Weight > wh[j].Weight
+	}
+	return wh[i].Value > wh[j].Value
+}
+
+// groupByReceiver sorts alert groups by the receiver, so all groups routed to
+// the same receiver are next to each other, the order of groups with the same
+// receiver is preserved, it also returns the list of all receivers with IDs
+// of their groups
+func groupByReceiver(groups []models.AlertGroup) ([]models.AlertGroup, []models.ReceiverGroups) {
+	sorted := make([]models.AlertGroup, len(groups))
+	copy(sorted, groups)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Receiver < sorted[j].Receiver
+	})
+
+	receivers := []models.ReceiverGroups{}
+	for _, ag := range sorted {
+		if len(receivers) == 0 || receivers[len(receivers)-1].Receiver != ag.Receiver {
+			rg := models.ReceiverGroups{Receiver: ag.Receiver, Groups: []string{}, StateCount: map[string]int{}}
+			for _, s := range models.AlertStateList {
+				rg.StateCount[s] = 0
+			}
+			receivers = append(receivers, rg)
+		}
+		rg := &receivers[len(receivers)-1]
+		rg.Groups = append(rg.Groups, ag.ID)
+		for s, n := range ag.StateCount {
+			rg.StateCount[s] += n
+		}
+	}
+	return sorted, receivers
+}
+
+// parsePaginationArg returns the value of offset or limit query argument,
+// 0 is returned if it's not set
+func parsePaginationArg(arg string) (int, error) {
+	if arg == "" {
+		return 0, nil
+	}
+	value, err := strconv.Atoi(arg)
+	if err != nil {
+		return 0, fmt.Errorf("'%s' is not a number", arg)
+	}
+	if value < 0 {
+		return 0, fmt.Errorf("%d is negative", value)
+	}
+	return value, nil
+}
+
+// paginateAlertGroups returns a page of alert groups starting at offset, with
+// no more than limit elements, limit of 0 means that there's no limit
+func paginateAlertGroups(groups []models.AlertGroup, offset int, limit int) []models.AlertGroup {
+	if offset >= len(groups) {
+		return []models.AlertGroup{}
+	}
+	groups = groups[offset:]
+	if limit > 0 && limit < len(groups) {
+		groups = groups[:limit]
+	}
+	return groups
+}
+
+// groupAlertsLimit returns the maximum number of alerts per group, clients
+// can ask for fewer alerts than ALERTS_PER_GROUP allows but never for more,
+// 0 means that there's no limit
+func groupAlertsLimit(requested int) int {
+	if config.Config.AlertsPerGroup > 0 && (requested == 0 || requested > config.Config.AlertsPerGroup) {
+		return config.Config.AlertsPerGroup
+	}
+	return requested
+}
+
+// collapseGroupAlerts folds alerts in every group that only differ by values
+// of given labels, TotalAlerts and StateCount still count all alerts
+func collapseGroupAlerts(groups []models.AlertGroup, labels []string) []models.AlertGroup {
+	if len(labels) == 0 {
+		return groups
+	}
+	for i := range groups {
+		groups[i].Alerts = groups[i].CollapseAlerts(labels)
+	}
+	return groups
+}
+
+// limitGroupAlerts returns alert groups with only the first limit alerts
+// included in each group, TotalAlerts and StateCount still count all alerts,
+// limit of 0 means that there's no limit
+func limitGroupAlerts(groups []models.AlertGroup, limit int) []models.AlertGroup {
+	if limit == 0 {
+		return groups
+	}
+	for i := range groups {
+		if len(groups[i].Alerts) > limit {
+			groups[i].Alerts = groups[i].Alerts[:limit]
+		}
+	}
+	return groups
+}
+
+// alertsETag returns the ETag value for alerts response generated for given
+// format and query, it will change every time alerts are modified in the store or any
+// upstream status changes, refresh timestamps are ignored so that collections
+// that didn't change anything don't invalidate it
+func alertsETag(format string, query string, upstreams models.AlertmanagerAPISummary) string {
+	hasher := sha1.New()
+	io.WriteString(hasher, alertHistory.Hash())
+	io.WriteString(hasher, format)
+	io.WriteString(hasher, query)
+	io.WriteString(hasher, version)
+	instances := make([]models.AlertmanagerAPIStatus, len(upstreams.Instances))
+	for i, upstream := range upstreams.Instances {
+		upstream.LastRefresh = time.Time{}
+		instances[i] = upstream
+	}
+	upstreams.Instances = instances
+	if data, err := json.Marshal(upstreams); err == nil {
+		hasher.Write(data)
+	}
+	return fmt.Sprintf("\"%x\"", hasher.Sum(nil))
+}
+
+// deltaAlertGroups will only return groups that changed since given
+// collection version, it also returns IDs of groups that were removed or
+// no longer match filters, last value will be false if requested version is
+// unknown, in which case all groups are returned
+func deltaAlertGroups(groups []models.AlertGroup, since int64) ([]models.AlertGroup, []string, bool) {
+	changed, removed, ok := alertHistory.Changes(since)
+	if !ok {
+		return groups, []string{}, false
+	}
+
+	delta := []models.AlertGroup{}
+	present := map[string]bool{}
+	for _, ag := range groups {
+		present[ag.ID] = true
+		if changed[ag.ID] {
+			delta = append(delta, ag)
+		}
+	}
+	// changed groups that are not present in the response were either
+	// filtered out or limited, client should remove those too
+	for id := range changed {
+		if !present[id] {
+			removed = append(removed, id)
+		}
+	}
+	sort.Strings(removed)
+	return delta, removed, true
+}
+
 // autocomplete endpoint, json, used for filter autocomplete hints
 func autocomplete(c *gin.Context) {
 	noCache(c)
 	start := time.Now()
 
+	t := getTenant(c)
 	cacheKey := c.Request.RequestURI
 	if cacheKey == "" {
 		// FIXME c.Request.RequestURI is empty when running tests for some reason
 		// needs checking, below acts as a workaround
 		cacheKey = c.Request.URL.RawQuery
 	}
+	cacheKey = t.scope() + cacheKey
 
 	data, found := apiCache.Get(cacheKey)
 	if found {
@@ -219,23 +554,27 @@ func autocomplete(c *gin.Context) {
 		return
 	}
 
-	acData := sort.StringSlice{}
+	hints := weightedHints{}
 
-	dedupedAutocomplete := alertmanager.DedupAutocomplete()
+	dedupedAutocomplete := t.autocomplete()
 
 	for _, hint := range dedupedAutocomplete {
 		if strings.HasPrefix(strings.ToLower(hint.Value), strings.ToLower(term)) {
-			acData = append(acData, hint.Value)
+			hints = append(hints, hint)
 		} else {
 			for _, token := range hint.Tokens {
 				if strings.HasPrefix(strings.ToLower(token), strings.ToLower(term)) {
-					acData = append(acData, hint.Value)
+					hints = append(hints, hint)
 				}
 			}
 		}
 	}
 
-	sort.Sort(sort.Reverse(acData))
+	sort.Sort(hints)
+	acData := []string{}
+	for _, hint := range hints {
+		acData = append(acData, hint.Value)
+	}
 	data, err := json.Marshal(acData)
 	if err != nil {
 		log.Error(err.Error())
@@ -254,3 +593,940 @@ func favicon(c *gin.Context) {
 	}
 	faviconFileServer.ServeHTTP(c.Writer, c.Request)
 }
+
+func logView(c *gin.Context, start time.Time) {
+	log.Infof("[%s] <%d> %s %s took %s", c.ClientIP(), c.Writer.Status(), c.Request.Method, c.Request.RequestURI, time.Since(start))
+}
+
+// validate filter expression, json, returns details about every invalid filter
+// so that it can be checked before being applied
+func validateFilters(c *gin.Context) {
+	noCache(c)
+	start := time.Now()
+	defer logView(c, start)
+
+	q, found := c.GetQuery("q")
+	if !found || q == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing q=<filter> parameter"})
+		return
+	}
+
+	resp := models.FilterValidationResponse{
+		IsValid: true,
+		Filters: []models.FilterValidation{},
+	}
+	offset := 0
+	for _, expression := range strings.Split(q, ",") {
+		fv := models.FilterValidation{
+			Text:    expression,
+			IsValid: true,
+		}
+		if err := filters.Validate(expression); err != nil {
+			// position should be relative to the full query
+			err.Position += offset
+			fv.IsValid = false
+			fv.Error = err
+			resp.IsValid = false
+		}
+		resp.Filters = append(resp.Filters, fv)
+		offset += len(expression) + 1
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// list of filter presets defined in the config, json
+func filterPresets(c *gin.Context) {
+	noCache(c)
+	start := time.Now()
+	defer logView(c, start)
+
+	presets, err := getFilterPresets()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, presets)
+}
+
+// validateFilterQuery returns an error if the query is empty or any of the
+// filters in it is invalid
+func validateFilterQuery(q string) error {
+	if q == "" {
+		return errors.New("missing filter expression")
+	}
+	q, _ = expandFilterMacros(q)
+	matchFilters, _ := getFiltersFromQuery(q)
+	for _, filter := range matchFilters {
+		if !filter.GetIsValid() {
+			if err := filters.Validate(filter.GetRawText()); err != nil {
+				return fmt.Errorf("invalid filter expression '%s': %s", filter.GetRawText(), err.Message)
+			}
+			return fmt.Errorf("invalid filter expression '%s'", filter.GetRawText())
+		}
+	}
+	return nil
+}
+
+// details of a single alert group, json
+func alertGroup(c *gin.Context) {
+	noCache(c)
+	start := time.Now()
+	defer logView(c, start)
+
+	for _, ag := range getTenant(c).alertGroups() {
+		if ag.ID == c.Param("id") {
+			c.JSON(http.StatusOK, getAlertGroupDetails(ag))
+			return
+		}
+	}
+	c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("alert group '%s' not found", c.Param("id"))})
+}
+
+// typed autocomplete suggestions for the filter being typed, json
+func suggestions(c *gin.Context) {
+	noCache(c)
+	start := time.Now()
+	defer logView(c, start)
+	c.JSON(http.StatusOK, getSuggestions(getTenant(c), c.Query("context")))
+}
+
+// alert counts, json, accepts optional q argument with filters, it's designed
+// for status pages polling unsee frequently
+func summary(c *gin.Context) {
+	noCache(c)
+	start := time.Now()
+	defer logView(c, start)
+
+	q, _ := expandFilterMacros(c.Query("q"))
+	if q != "" {
+		if err := validateFilterQuery(q); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	c.JSON(http.StatusOK, getAlertsSummary(getTenant(c), q, start))
+}
+
+// most frequent values of given label across alerts, json, accepts optional q
+// argument with filters and limit
+func topLabelValues(c *gin.Context) {
+	noCache(c)
+	start := time.Now()
+	defer logView(c, start)
+
+	label := c.Query("label")
+	if label == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing label=<name> parameter"})
+		return
+	}
+	limit, err := parsePaginationArg(c.Query("limit"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid limit: %s", err)})
+		return
+	}
+	if limit == 0 {
+		limit = topLabelValuesLimit
+	}
+	q, _ := expandFilterMacros(c.Query("q"))
+	if q != "" {
+		if err := validateFilterQuery(q); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	c.JSON(http.StatusOK, getTopLabelValues(getTenant(c), label, q, limit, start))
+}
+
+// alert counts by state, json, accepts optional q argument with filters,
+// responses are cached until the next collection so it can be polled very
+// frequently by the favicon badge
+func counters(c *gin.Context) {
+	noCache(c)
+	start := time.Now()
+	defer logView(c, start)
+
+	t := getTenant(c)
+	q, _ := expandFilterMacros(c.Query("q"))
+	cacheKey := "counters:" + t.scope() + q
+	if data, found := apiCache.Get(cacheKey); found {
+		c.Data(http.StatusOK, gin.MIMEJSON, data.([]byte))
+		return
+	}
+	if q != "" {
+		if err := validateFilterQuery(q); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	summary := getAlertsSummary(t, q, start)
+	data, err := json.Marshal(models.AlertCounters{Total: summary.Total, States: summary.States})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	apiCache.Set(cacheKey, data, -1)
+	c.Data(http.StatusOK, gin.MIMEJSON, data)
+}
+
+// alert counts recorded on every collection grouped into time buckets, json,
+// accepts optional resolution and range arguments, counts include all alerts
+// so users restricted by tenant filters can't use it
+func history(c *gin.Context) {
+	noCache(c)
+	start := time.Now()
+	defer logView(c, start)
+
+	if getTenant(c).restricted {
+		c.JSON(http.StatusForbidden, gin.H{"error": "history isn't available to users restricted by tenant filters"})
+		return
+	}
+
+	resolution, err := time.ParseDuration(c.DefaultQuery("resolution", "5m"))
+	if err != nil || resolution < time.Second {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid resolution '%s', it must be a duration of at least 1s", c.Query("resolution"))})
+		return
+	}
+	timeRange, err := time.ParseDuration(c.DefaultQuery("range", "24h"))
+	if err != nil || timeRange < resolution {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid range '%s', it must be a duration not shorter than the resolution", c.Query("range"))})
+		return
+	}
+	count := int((timeRange + resolution - 1) / resolution)
+	if count > maxHistoryBuckets {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("range '%s' with resolution '%s' would return %d buckets, at most %d are allowed", timeRange, resolution, count, maxHistoryBuckets)})
+		return
+	}
+
+	// buckets are aligned to the resolution, the last one includes now
+	end := start.Truncate(resolution).Add(resolution)
+	c.JSON(http.StatusOK, models.AlertCountsHistory{
+		Resolution: int(resolution / time.Second),
+		Range:      int(resolution * time.Duration(count) / time.Second),
+		Buckets:    alertCounts.Buckets(end.Add(-resolution*time.Duration(count)), resolution, count),
+	})
+}
+
+// list of all silences, json, msgpack or yaml, can be filtered using author,
+// comment and state arguments
+func silences(c *gin.Context) {
+	noCache(c)
+	start := time.Now()
+	defer logView(c, start)
+
+	state := c.Query("state")
+	if state != "" && !slices.StringInSlice(models.SilenceStateList, state) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid silence state '%s', expected one of: %s", state, strings.Join(models.SilenceStateList, ", "))})
+		return
+	}
+	renderResponse(c, getSilences(getTenant(c), c.Query("author"), c.Query("comment"), state, start))
+}
+
+// list of silence authors, json, used for author suggestions in the silence
+// form
+func silenceAuthors(c *gin.Context) {
+	noCache(c)
+	start := time.Now()
+	defer logView(c, start)
+
+	c.JSON(http.StatusOK, getSilenceAuthors(getTenant(c), start))
+}
+
+// status of given Alertmanager upstream, json, it includes the routing
+// configuration, which isn't filtered by tenant filters, so users restricted
+// by those can't use it
+func alertmanagerStatus(c *gin.Context) {
+	noCache(c)
+	start := time.Now()
+	defer logView(c, start)
+
+	if getTenant(c).restricted {
+		c.JSON(http.StatusForbidden, gin.H{"error": "alertmanager status isn't available to users restricted by tenant filters"})
+		return
+	}
+
+	am := alertmanager.GetAlertmanagerByName(c.Param("alertmanager"))
+	if am == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("alertmanager '%s' not found", c.Param("alertmanager"))})
+		return
+	}
+
+	status, found := am.Status()
+	if !found {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": fmt.Sprintf("status of alertmanager '%s' wasn't collected yet", am.Name)})
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
+
+// receivers an alert with labels passed as query arguments would be routed to
+// by given Alertmanager upstream, json, it uses the routing configuration
+// from the status API, so it's not available to users restricted by tenant
+// filters
+func predictReceivers(c *gin.Context) {
+	noCache(c)
+	start := time.Now()
+	defer logView(c, start)
+
+	if getTenant(c).restricted {
+		c.JSON(http.StatusForbidden, gin.H{"error": "receiver prediction isn't available to users restricted by tenant filters"})
+		return
+	}
+
+	am := alertmanager.GetAlertmanagerByName(c.Param("alertmanager"))
+	if am == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("alertmanager '%s' not found", c.Param("alertmanager"))})
+		return
+	}
+
+	labels := map[string]string{}
+	for name, values := range c.Request.URL.Query() {
+		labels[name] = values[len(values)-1]
+	}
+	if len(labels) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "at least one label must be passed as a query argument"})
+		return
+	}
+
+	status, found := am.Status()
+	if !found || status.Route == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": fmt.Sprintf("routing configuration of alertmanager '%s' isn't available", am.Name)})
+		return
+	}
+
+	prediction := models.AlertmanagerRoutePrediction{
+		Name:      am.Name,
+		Labels:    labels,
+		Receivers: []string{},
+		Routes:    status.Route.MatchingRoutes(labels),
+	}
+	for _, route := range prediction.Routes {
+		if !slices.StringInSlice(prediction.Receivers, route.Receiver) {
+			prediction.Receivers = append(prediction.Receivers, route.Receiver)
+		}
+	}
+	c.JSON(http.StatusOK, prediction)
+}
+
+// create a silence using given Alertmanager upstream, json, the body is
+// forwarded to the Alertmanager silences API as long as the authenticated user
+// is allowed to create it, the response from Alertmanager is passed back
+func createSilence(c *gin.Context) {
+	noCache(c)
+	start := time.Now()
+	defer logView(c, start)
+
+	am := alertmanager.GetAlertmanagerByName(c.Param("alertmanager"))
+	if am == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("alertmanager '%s' not found", c.Param("alertmanager"))})
+		return
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(c.Request.Body, maxSilenceBodySize))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("failed to read request body: %s", err)})
+		return
+	}
+	silence := models.Silence{}
+	payload := map[string]interface{}{}
+	if err = json.Unmarshal(body, &silence); err == nil {
+		err = json.Unmarshal(body, &payload)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid request body: %s", err)})
+		return
+	}
+	if len(silence.Matchers) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "silence must have at least one matcher"})
+		return
+	}
+
+	user := getUserName(c)
+	rules, _ := getSilenceACL()
+	if !silenceAllowed(rules, user, getUserGroups(c), silence) {
+		log.Warningf("[%s] User '%s' isn't allowed to create silence: %s", c.ClientIP(), user, body)
+		c.JSON(http.StatusForbidden, gin.H{"error": "you are not allowed to create silences with these matchers"})
+		return
+	}
+	// Alertmanager replaces the silence with the same ID and expires the old
+	// one, so the user must also be allowed to expire it
+	if silence.ID != "" {
+		existing, err := am.SilenceByID(silence.ID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("silence '%s' not found on alertmanager '%s'", silence.ID, am.Name)})
+			return
+		}
+		if !silenceAllowed(rules, user, getUserGroups(c), existing) {
+			log.Warningf("[%s] User '%s' isn't allowed to replace silence %s", c.ClientIP(), user, existing.ID)
+			c.JSON(http.StatusForbidden, gin.H{"error": "you are not allowed to replace silences with these matchers"})
+			return
+		}
+	}
+	if err = applySilenceDefaults(payload, silence, user, time.Now()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	body, _ = json.Marshal(payload)
+
+	if status, ok := sendSilenceRequest(c, am, http.MethodPost, "/api/v1/silences", body); ok {
+		log.Infof("[%s] User '%s' created silence on '%s' with response status %d", c.ClientIP(), user, am.Name, status)
+	}
+}
+
+// expire a silence on given Alertmanager upstream, json, the authenticated
+// user must be allowed to create a silence with the same matchers, the
+// response from Alertmanager is passed back
+func expireSilence(c *gin.Context) {
+	noCache(c)
+	start := time.Now()
+	defer logView(c, start)
+
+	am := alertmanager.GetAlertmanagerByName(c.Param("alertmanager"))
+	if am == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("alertmanager '%s' not found", c.Param("alertmanager"))})
+		return
+	}
+	silence, err := am.SilenceByID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("silence '%s' not found on alertmanager '%s'", c.Param("id"), am.Name)})
+		return
+	}
+
+	user := getUserName(c)
+	rules, _ := getSilenceACL()
+	if !silenceAllowed(rules, user, getUserGroups(c), silence) {
+		log.Warningf("[%s] User '%s' isn't allowed to expire silence %s", c.ClientIP(), user, silence.ID)
+		c.JSON(http.StatusForbidden, gin.H{"error": "you are not allowed to expire silences with these matchers"})
+		return
+	}
+
+	if status, ok := sendSilenceRequest(c, am, http.MethodDelete, "/api/v1/silence/"+url.PathEscape(silence.ID), nil); ok {
+		log.Infof("[%s] User '%s' expired silence %s on '%s' with response status %d", c.ClientIP(), user, silence.ID, am.Name, status)
+	}
+}
+
+// sendSilenceRequest sends a request to the silences API of given Alertmanager
+// upstream and passes the response back to the client, it returns the status
+// code of the response and false if the request failed
+func sendSilenceRequest(c *gin.Context, am *alertmanager.Alertmanager, method, path string, body []byte) (int, bool) {
+	uri, err := transport.JoinURL(am.URI, path)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return 0, false
+	}
+	req, err := http.NewRequest(method, uri, bytes.NewReader(body))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return 0, false
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", gin.MIMEJSON)
+	}
+	requestID := transport.SetRequestHeaders(req)
+	client := &http.Client{Timeout: am.Timeout, Transport: transport.RoundTripper(uri)}
+	resp, err := client.Do(req)
+	if err != nil {
+		log.Errorf("[%s] %s %s failed request_id=%s: %s", am.Name, method, path, requestID, err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": fmt.Sprintf("request to alertmanager '%s' failed: %s", am.Name, err)})
+		return 0, false
+	}
+	log.Infof("[%s] %s %s returned %s request_id=%s", am.Name, method, path, resp.Status, requestID)
+	defer resp.Body.Close()
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": fmt.Sprintf("failed to read response from alertmanager '%s': %s", am.Name, err)})
+		return 0, false
+	}
+
+	c.Data(resp.StatusCode, resp.Header.Get("Content-Type"), respBody)
+	return resp.StatusCode, true
+}
+
+// liveness check, it only tells that the process is running
+func healthz(c *gin.Context) {
+	noCache(c)
+	c.JSON(http.StatusOK, gin.H{"status": "ok", "paused": pausedUpstreams()})
+}
+
+// readiness check, fails until at least one upstream was recently collected
+// and enough upstreams completed their first collection
+func readyz(c *gin.Context) {
+	noCache(c)
+	if err := checkReadiness(time.Now()); err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error(), "paused": pausedUpstreams()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ok", "paused": pausedUpstreams()})
+}
+
+// version and build details of the running instance, json
+func versionInfo(c *gin.Context) {
+	noCache(c)
+	start := time.Now()
+	defer logView(c, start)
+	c.JSON(http.StatusOK, getVersionInfo())
+}
+
+func getUIConfig() models.UIConfig {
+	// display names are validated on startup
+	labelNames, _ := getLabelDisplayNames()
+	return models.UIConfig{
+		Title:      config.Config.UiTitle,
+		LogoURL:    config.Config.UiLogoUrl,
+		Banner:     config.Config.UiBanner,
+		Timezone:   transform.Timezone(),
+		LabelNames: labelNames,
+		ReadOnly:   config.Config.DemoMode,
+	}
+}
+
+// branding options for the UI, json
+func uiConfig(c *gin.Context) {
+	noCache(c)
+	start := time.Now()
+	defer logView(c, start)
+	c.JSON(http.StatusOK, getUIConfig())
+}
+
+func getUIDefaults() models.UIDefaults {
+	return models.UIDefaults{
+		Filter:          config.Config.FilterDefault,
+		RefreshInterval: int(config.Config.UiRefreshInterval / time.Second),
+		AutoRefresh:     config.Config.UiAutoRefresh,
+		Flash:           config.Config.UiFlash,
+		AppendTop:       config.Config.UiAppendTop,
+		GroupByReceiver: config.Config.UiGroupByReceiver,
+		Annotations: models.UIAnnotationsConfig{
+			DefaultHidden: config.Config.AnnotationsDefaultHidden,
+			Hidden:        slices.NonEmptyStrings(config.Config.AnnotationsHidden),
+			HiddenRegex:   slices.NonEmptyStrings(config.Config.AnnotationsHiddenRegex),
+			Visible:       slices.NonEmptyStrings(config.Config.AnnotationsVisible),
+		},
+		Colors: models.UIColorsConfig{
+			Unique: slices.NonEmptyStrings(config.Config.ColorLabelsUnique),
+			Static: slices.NonEmptyStrings(config.Config.ColorLabelsStatic),
+		},
+		Branding: getUIConfig(),
+	}
+}
+
+// refreshInterval is a single option of the refresh interval select in the UI
+type refreshInterval struct {
+	Seconds  int
+	Label    string
+	Selected bool
+}
+
+// intervals that can be selected in the UI, UI_REFRESH_INTERVAL is added if
+// it's not one of those
+var refreshIntervals = []int{10, 15, 20, 30, 45, 60, 120, 300}
+
+// getRefreshIntervals returns all intervals that can be selected in the UI,
+// the default one is selected
+func getRefreshIntervals(selected int) []refreshInterval {
+	seconds := append([]int{}, refreshIntervals...)
+	found := false
+	for _, s := range seconds {
+		if s == selected {
+			found = true
+		}
+	}
+	if !found {
+		seconds = append(seconds, selected)
+		sort.Ints(seconds)
+	}
+	intervals := make([]refreshInterval, 0, len(seconds))
+	for _, s := range seconds {
+		label := fmt.Sprintf("%ds refresh", s)
+		if s >= 60 && s%60 == 0 {
+			label = fmt.Sprintf("%dm refresh", s/60)
+		}
+		intervals = append(intervals, refreshInterval{Seconds: s, Label: label, Selected: s == selected})
+	}
+	return intervals
+}
+
+// default values of all UI options, json
+func uiDefaults(c *gin.Context) {
+	noCache(c)
+	start := time.Now()
+	defer logView(c, start)
+	c.JSON(http.StatusOK, getUIDefaults())
+}
+
+// list of all saved filters, json
+func savedFilters(c *gin.Context) {
+	noCache(c)
+	start := time.Now()
+	defer logView(c, start)
+
+	savedFilters := []models.SavedFilter{}
+	for _, name := range dataStore.Keys(savedFiltersBucket) {
+		sf := models.SavedFilter{}
+		if err := dataStore.Get(savedFiltersBucket, name, &sf); err == nil {
+			savedFilters = append(savedFilters, sf)
+		}
+	}
+	c.JSON(http.StatusOK, savedFilters)
+}
+
+// single saved filter, json
+func savedFilter(c *gin.Context) {
+	noCache(c)
+	start := time.Now()
+	defer logView(c, start)
+
+	sf := models.SavedFilter{}
+	err := dataStore.Get(savedFiltersBucket, c.Param("name"), &sf)
+	if err == store.ErrNotFound {
+		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("saved filter '%s' not found", c.Param("name"))})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, sf)
+}
+
+// create or update a saved filter, expects a json body with the filter
+// expression, like {"filter": "@state=active,cluster=prod"}
+func saveFilter(c *gin.Context) {
+	noCache(c)
+	start := time.Now()
+	defer logView(c, start)
+
+	name := c.Param("name")
+	if !savedFilterNameRegex.MatchString(name) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid saved filter name '%s', only letters, digits, '.', '_' and '-' are allowed", name)})
+		return
+	}
+
+	sf := models.SavedFilter{}
+	if err := json.NewDecoder(c.Request.Body).Decode(&sf); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid request body: %s", err)})
+		return
+	}
+	if err := validateFilterQuery(sf.Filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	sf.Name = name
+	if err := dataStore.Set(savedFiltersBucket, name, sf); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, sf)
+}
+
+// delete a saved filter
+func deleteSavedFilter(c *gin.Context) {
+	noCache(c)
+	start := time.Now()
+	defer logView(c, start)
+
+	err := dataStore.Delete(savedFiltersBucket, c.Param("name"))
+	if err == store.ErrNotFound {
+		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("saved filter '%s' not found", c.Param("name"))})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// settings of the authenticated user, json, defaults are returned if the user
+// didn't save any settings yet
+func userSettings(c *gin.Context) {
+	noCache(c)
+	start := time.Now()
+	defer logView(c, start)
+
+	user := getUserName(c)
+	if user == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user settings require an authenticated user"})
+		return
+	}
+
+	settings := models.UserSettings{CollapsedGroups: []string{}, HiddenAnnotations: []string{}}
+	err := dataStore.Get(userSettingsBucket, user, &settings)
+	if err != nil && err != store.ErrNotFound {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, settings)
+}
+
+// replace settings of the authenticated user, expects a json body with all
+// settings
+func saveUserSettings(c *gin.Context) {
+	noCache(c)
+	start := time.Now()
+	defer logView(c, start)
+
+	user := getUserName(c)
+	if user == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user settings require an authenticated user"})
+		return
+	}
+
+	settings := models.UserSettings{}
+	if err := json.NewDecoder(c.Request.Body).Decode(&settings); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid request body: %s", err)})
+		return
+	}
+	if settings.DefaultFilter != "" {
+		if err := validateFilterQuery(settings.DefaultFilter); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if settings.CollapsedGroups == nil {
+		settings.CollapsedGroups = []string{}
+	}
+	if settings.HiddenAnnotations == nil {
+		settings.HiddenAnnotations = []string{}
+	}
+
+	if err := dataStore.Set(userSettingsBucket, user, settings); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, settings)
+}
+
+// delete settings of the authenticated user, so defaults are used again
+func deleteUserSettings(c *gin.Context) {
+	noCache(c)
+	start := time.Now()
+	defer logView(c, start)
+
+	user := getUserName(c)
+	if user == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user settings require an authenticated user"})
+		return
+	}
+
+	err := dataStore.Delete(userSettingsBucket, user)
+	if err != nil && err != store.ErrNotFound {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// shortURLToken returns a token for given filter query, tokens are derived
+// from the query hash so the same filter will always get the same token,
+// unless there's a collision with a token that's already used for a
+// different query
+func shortURLToken(q string) (string, error) {
+	sum := sha1.Sum([]byte(q))
+	hash := hex.EncodeToString(sum[:])
+	for l := shortURLTokenLength; l <= len(hash); l++ {
+		token := hash[:l]
+		var stored string
+		err := dataStore.Get(shortURLsBucket, token, &stored)
+		if err == store.ErrNotFound || (err == nil && stored == q) {
+			return token, nil
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	return "", fmt.Errorf("no free short URL token for filter '%s'", q)
+}
+
+// create a short URL for a filter, expects a json body with the filter
+// expression, like {"filter": "@state=active,cluster=prod"}
+func createShortURL(c *gin.Context) {
+	noCache(c)
+	start := time.Now()
+	defer logView(c, start)
+
+	su := models.ShortURL{}
+	if err := json.NewDecoder(c.Request.Body).Decode(&su); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid request body: %s", err)})
+		return
+	}
+	if err := validateFilterQuery(su.Filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	token, err := shortURLToken(su.Filter)
+	if err == nil {
+		err = dataStore.Set(shortURLsBucket, token, su.Filter)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	su.Token = token
+	su.URL = getPublicPrefix(c) + "s/" + token
+	c.JSON(http.StatusOK, su)
+}
+
+// redirect from a short URL to the index view with the full filter
+func resolveShortURL(c *gin.Context) {
+	noCache(c)
+	start := time.Now()
+	defer logView(c, start)
+
+	var q string
+	err := dataStore.Get(shortURLsBucket, c.Param("token"), &q)
+	if err == store.ErrNotFound {
+		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("short URL '%s' not found", c.Param("token"))})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Redirect(http.StatusFound, getPublicPrefix(c)+"?q="+url.QueryEscape(q))
+}
+
+// filterEvents returns only events for alerts matching given filters, those
+// are parsed once per subscription, every event is matched as if the alert
+// was the only member of its group
+func filterEvents(changes []models.AlertEvent, matchFilters []filters.FilterT, validFilters bool) []models.AlertEvent {
+	if !validFilters {
+		return changes
+	}
+	filtered := []models.AlertEvent{}
+	var matches int
+	for _, change := range changes {
+		ag := models.AlertGroup{
+			ID:       change.GroupID,
+			Receiver: change.Receiver,
+			Labels:   change.Labels,
+			Alerts:   models.AlertList{change.Alert},
+		}
+		for _, filter := range matchFilters {
+			if gf, ok := filter.(filters.GroupFilterT); ok {
+				gf.SetGroup(&ag)
+			}
+		}
+		if alertMatchesFilters(&change.Alert, matchFilters, validFilters, matches) {
+			matches++
+			filtered = append(filtered, change)
+		}
+	}
+	return filtered
+}
+
+// live updates using websockets, every alert change detected after collecting
+// alerts from Alertmanager is sent to the client as a json message, pass
+// q=<filter> to only receive events for matching alerts
+func websocketEvents(c *gin.Context) {
+	start := time.Now()
+
+	q, _ := expandFilterMacros(c.Query("q"))
+	if q != "" {
+		if err := validateFilterQuery(q); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			logView(c, start)
+			return
+		}
+	}
+
+	conn, err := wsUpgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// Upgrade() already sent an error response
+		log.Errorf("[%s] Websocket upgrade failed: %s", c.ClientIP(), err)
+		return
+	}
+	defer conn.Close()
+
+	t := getTenant(c)
+	matchFilters, validFilters := getFiltersFromQuery(q)
+	ch := eventBroker.Subscribe()
+	defer eventBroker.Unsubscribe(ch)
+	log.Infof("[%s] Websocket client connected to %s", c.ClientIP(), c.Request.RequestURI)
+
+	// we don't expect any messages from the client, but we need to read
+	// in order to notice when the connection is closed
+	closed := make(chan bool)
+	go func() {
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				close(closed)
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-closed:
+			log.Infof("[%s] Websocket client disconnected after %s", c.ClientIP(), time.Since(start))
+			return
+		case changes, ok := <-ch:
+			if !ok {
+				log.Infof("[%s] Closing websocket connection, server is shutting down", c.ClientIP())
+				conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, "server is shutting down"))
+				return
+			}
+			for _, change := range filterEvents(t.filterEvents(changes), matchFilters, validFilters) {
+				if err := conn.WriteJSON(change); err != nil {
+					log.Errorf("[%s] Websocket write failed: %s", c.ClientIP(), err)
+					return
+				}
+			}
+		}
+	}
+}
+
+// live updates using server-sent events, for clients that can't use
+// websockets, events have the same payload as websocket messages and use the
+// change type as the event name
+func streamEvents(c *gin.Context) {
+	noCache(c)
+	start := time.Now()
+
+	q, _ := expandFilterMacros(c.Query("q"))
+	if q != "" {
+		if err := validateFilterQuery(q); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			logView(c, start)
+			return
+		}
+	}
+
+	t := getTenant(c)
+	matchFilters, validFilters := getFiltersFromQuery(q)
+	ch := eventBroker.Subscribe()
+	defer eventBroker.Unsubscribe(ch)
+	log.Infof("[%s] Event stream client connected to %s", c.ClientIP(), c.Request.RequestURI)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	keepalive := time.NewTicker(eventStreamKeepalive)
+	defer keepalive.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			log.Infof("[%s] Event stream client disconnected after %s", c.ClientIP(), time.Since(start))
+			return
+		case <-keepalive.C:
+			if _, err := io.WriteString(c.Writer, ": keepalive\n\n"); err != nil {
+				return
+			}
+		case changes, ok := <-ch:
+			if !ok {
+				log.Infof("[%s] Closing event stream, server is shutting down", c.ClientIP())
+				return
+			}
+			for _, change := range filterEvents(t.filterEvents(changes), matchFilters, validFilters) {
+				c.SSEvent(change.Type, change)
+			}
+		}
+		c.Writer.Flush()
+	}
+}
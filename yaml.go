@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"gopkg.in/yaml.v2"
+)
+
+// YAML content types, application/yaml is the registered one, but a lot of
+// tools still send application/x-yaml
+const (
+	mimeYAML  = "application/yaml"
+	mimeXYAML = "application/x-yaml"
+)
+
+// writeYAML writes YAML encoded value to the buffer, it's converted to JSON
+// first, so YAML responses use the same keys as JSON responses
+func writeYAML(buf *bytes.Buffer, v interface{}) error {
+	if err := writeJSON(buf, v); err != nil {
+		return err
+	}
+	// decoding consumes the whole buffer, so it can be reused for YAML
+	decoder := json.NewDecoder(buf)
+	decoder.UseNumber()
+	var obj interface{}
+	if err := decoder.Decode(&obj); err != nil {
+		return err
+	}
+	buf.Reset()
+	data, err := yaml.Marshal(yamlValue(obj))
+	if err != nil {
+		return err
+	}
+	buf.Write(data)
+	return nil
+}
+
+// yamlValue replaces all JSON numbers with integers or floats, numbers would
+// be encoded as strings otherwise and large integers, like timestamps, would
+// lose precision if they were decoded as floats
+func yamlValue(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for k, elem := range value {
+			value[k] = yamlValue(elem)
+		}
+	case []interface{}:
+		for i, elem := range value {
+			value[i] = yamlValue(elem)
+		}
+	case json.Number:
+		if i, err := value.Int64(); err == nil {
+			return i
+		}
+		f, _ := value.Float64()
+		return f
+	}
+	return v
+}